@@ -3,30 +3,116 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/jnst/agentic-todo-mcp/internal/config"
 	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
 )
 
+// flagKeys maps command-line flags to the config keys they override
+var flagKeys = map[string]string{
+	"data-dir":        "data_dir",
+	"transport":       "transport.type",
+	"addr":            "transport.addr",
+	"allowed-origins": "transport.allowed_origins",
+	"allowed-hosts":   "transport.allowed_hosts",
+}
+
 func main() {
-	ctx := context.Background()
+	configPath := flag.String("config", "", "config file (default .todo/config.toml, .yaml or .json)")
+	flag.String("data-dir", "", "data directory, relative to the project root")
+	flag.String("transport", "", "transport to serve: stdio or http")
+	flag.String("addr", "", "listen address for the http transport")
+	flag.String("allowed-origins", "", "comma-separated browser origins allowed to call the http transport")
+	flag.String("allowed-hosts", "", "comma-separated host names the http transport may be called by")
+	flag.Parse()
+
+	// Stop serving on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Find the project root above the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	root, err := workspace.Find(wd, storage.DefaultDataDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Using workspace root %s (%s)", root.Path, root.Source)
 
-	// Get current working directory as base path
-	basePath, err := os.Getwd()
+	// Load config: file, then environment, then flags
+	cfg, err := config.Load(root.Path, *configPath, os.LookupEnv)
 	if err != nil {
 		log.Fatal(err)
 	}
+	flag.Visit(func(f *flag.Flag) {
+		if key, ok := flagKeys[f.Name]; ok {
+			if err := cfg.Set(key, f.Value.String()); err != nil {
+				log.Fatalf("--%s: %v", f.Name, err)
+			}
+		}
+	})
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
 
 	// Create server and tool service
 	server := mcp.NewServer()
-	toolService := mcp.NewToolService(basePath)
+	toolService, err := mcp.NewToolServiceWithConfig(root, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, p := range toolService.Projects() {
+		log.Printf("Using project %s at %s", p.Name, p.Path)
+	}
 
 	// Register tools
 	mcp.AddCreateTaskTool(server, toolService)
+	mcp.AddListTasksTool(server, toolService)
+	mcp.AddSearchTasksTool(server, toolService)
+	mcp.AddUpdateTaskTool(server, toolService)
+	mcp.AddGetTaskTool(server, toolService)
+	mcp.AddReorderTaskTool(server, toolService)
+	mcp.AddSubtaskTools(server, toolService)
+	mcp.AddCategoryTools(server, toolService)
+	mcp.AddArchiveTools(server, toolService)
+	mcp.AddADRTools(server, toolService)
+	mcp.AddContextTools(server, toolService)
+	mcp.AddSyncCommitsTool(server, toolService)
+	mcp.AddImportTodosTool(server, toolService)
+	mcp.AddServerInfoTool(server, toolService)
+	mcp.AddResources(server, toolService)
+	mcp.UseClientRoots(server, toolService)
 
-	// Run the server over stdin/stdout
-	if err := mcp.RunServer(ctx, server); err != nil {
+	switch cfg.Transport.Type {
+	case config.TransportHTTP:
+		// Run one shared server for all HTTP clients
+		err = mcp.RunHTTPServer(ctx, server, cfg.Transport.Addr, &mcp.HTTPOptions{
+			Token:          cfg.Transport.Token,
+			AllowedOrigins: cfg.Transport.AllowedOrigins,
+			AllowedHosts:   cfg.Transport.AllowedHosts,
+			Addr:           cfg.Transport.Addr,
+			Drain:          func() { _ = toolService.Close() },
+		})
+	default:
+		// Run the server over stdin/stdout
+		err = mcp.RunServer(ctx, server)
+	}
+
+	// Let in-flight writes finish before exiting
+	if closeErr := toolService.Close(); closeErr != nil {
+		log.Print(closeErr)
+	}
+	if err != nil && ctx.Err() == nil {
 		log.Fatal(err)
 	}
 }
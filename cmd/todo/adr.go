@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// adr runs "todo adr"
+func (a *app) adr(ctx context.Context, args []string) error {
+	const synopsis = "adr new|list|status ..."
+	if len(args) == 0 {
+		fmt.Fprintf(a.stderr, "Usage: todo %s\n", synopsis)
+		return errUsage
+	}
+	switch args[0] {
+	case "new":
+		return a.adrNew(ctx, args[1:])
+	case "list":
+		return a.adrList(ctx, args[1:])
+	case "status":
+		return a.adrStatus(ctx, args[1:])
+	default:
+		fmt.Fprintf(a.stderr, "todo: unknown adr command %q\nUsage: todo %s\n", args[0], synopsis)
+		return errUsage
+	}
+}
+
+// adrNew runs "todo adr new"
+func (a *app) adrNew(ctx context.Context, args []string) error {
+	fs := a.flagSet("adr new")
+	params := mcp.CreateADRParams{}
+	fs.StringVar(&params.Context, "context", "", "background of the decision (required)")
+	fs.StringVar(&params.Decision, "decision", "", "the decision (required)")
+	fs.StringVar(&params.Rationale, "rationale", "", "why the decision was made (required)")
+	fs.StringVar(&params.Consequences, "consequences", "", "consequences of the decision")
+	fs.StringVar(&params.Status, "status", "", "`status`: Proposed, Accepted or Deprecated (default Proposed)")
+	positional, err := a.parse(fs, args,
+		"adr new --context text --decision text --rationale text [--consequences text] [--status status] <title>", 1, -1)
+	if err != nil {
+		return err
+	}
+	params.Title = strings.Join(positional, " ")
+	params.Project = a.project
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.CreateADR(ctx, params)
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Created ADR-%03d %s (%s): %s\n", result.ADRNumber, result.Title, result.Status, result.FilePath)
+	})
+}
+
+// adrList runs "todo adr list"
+func (a *app) adrList(ctx context.Context, args []string) error {
+	fs := a.flagSet("adr list")
+	status := fs.String("status", "", "only ADRs with this `status`")
+	limit := fs.Int("n", 0, "show at most `n` ADRs (default 50)")
+	if _, err := a.parse(fs, args, "adr list [--status status] [-n limit]", 0, 0); err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.ListADRs(ctx, mcp.ListADRsParams{Status: *status, Project: a.project, Limit: *limit})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if result.TotalCount == 0 {
+			fmt.Fprintln(w, "No ADRs found")
+			return
+		}
+		header := []string{"ADR", "STATUS", "TITLE", "CREATED"}
+		multi := a.multiProject()
+		if multi {
+			header = append([]string{"PROJECT"}, header...)
+		}
+		rows := make([][]string, 0, len(result.ADRs))
+		for _, adr := range result.ADRs {
+			row := []string{fmt.Sprintf("%03d", adr.ADRNumber), adr.Status, adr.Title, adr.CreatedAt}
+			if multi {
+				row = append([]string{adr.Project}, row...)
+			}
+			rows = append(rows, row)
+		}
+		table(w, header, rows)
+	})
+}
+
+// adrStatus runs "todo adr status"
+func (a *app) adrStatus(ctx context.Context, args []string) error {
+	fs := a.flagSet("adr status")
+	reason := fs.String("reason", "", "`reason` for the change, kept in the status history")
+	positional, err := a.parse(fs, args, "adr status [--reason text] <number> <status>", 2, 2)
+	if err != nil {
+		return err
+	}
+	number, err := strconv.Atoi(strings.TrimPrefix(strings.ToLower(positional[0]), "adr-"))
+	if err != nil {
+		fmt.Fprintf(a.stderr, "todo: invalid ADR number %q\n", positional[0])
+		return errUsage
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.UpdateADRStatus(ctx, mcp.UpdateADRStatusParams{
+		ADRNumber: number,
+		Status:    positional[1],
+		Reason:    *reason,
+		Project:   a.project,
+	})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "ADR-%03d %s: %s → %s\n", result.ADRNumber, result.Title, result.OldStatus, result.NewStatus)
+	})
+}
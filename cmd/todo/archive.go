@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// archive runs "todo archive"
+func (a *app) archive(ctx context.Context, args []string) error {
+	fs := a.flagSet("archive")
+	olderThan := fs.Int("older-than", 0, "archive done tasks completed at least `days` ago (default archive.after_days)")
+	all := fs.Bool("all", false, "archive all done tasks regardless of age")
+	dryRun := fs.Bool("dry-run", false, "show the tasks to archive without moving them")
+	positional, err := a.parse(fs, args, "archive [--older-than days] [--all] [--dry-run] [task-id...]", 0, -1)
+	if err != nil {
+		return err
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.ArchiveTasks(ctx, mcp.ArchiveTasksParams{
+		TaskIDs:       positional,
+		OlderThanDays: *olderThan,
+		All:           *all,
+		DryRun:        *dryRun,
+		Project:       a.project,
+	})
+	if err != nil {
+		return err
+	}
+	verb := "Archived"
+	if result.DryRun {
+		verb = "Would archive"
+	}
+	return a.output(result, a.archivedTasks(result, verb, "No tasks to archive"))
+}
+
+// restore runs "todo restore"
+func (a *app) restore(ctx context.Context, args []string) error {
+	fs := a.flagSet("restore")
+	positional, err := a.parse(fs, args, "restore <task-id>...", 1, -1)
+	if err != nil {
+		return err
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.RestoreTasks(ctx, mcp.RestoreTasksParams{TaskIDs: positional, Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, a.archivedTasks(result, "Restored", "No tasks to restore"))
+}
+
+// archivedTasks returns a printer of the tasks moved by archive or restore
+func (a *app) archivedTasks(result mcp.ArchiveTasksResult, verb, none string) func(io.Writer) {
+	return func(w io.Writer) {
+		if len(result.Tasks) == 0 {
+			fmt.Fprintln(w, none)
+			return
+		}
+		for _, task := range result.Tasks {
+			fmt.Fprintf(w, "%s %s %s (archive %s)\n", verb, a.taskID(result.Project, task.TaskID), task.Title, task.Archive)
+		}
+	}
+}
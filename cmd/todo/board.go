@@ -0,0 +1,449 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// Keys delivered to the board; other keys are delivered as the typed character
+const (
+	keyUp    = "up"
+	keyDown  = "down"
+	keyLeft  = "left"
+	keyRight = "right"
+	keyEnter = "enter"
+	keyEsc   = "esc"
+	keyCtrlC = "ctrl-c"
+)
+
+// ANSI escape sequences used for drawing
+const (
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleReset   = "\x1b[0m"
+)
+
+// columnSeparator separates the board columns
+const columnSeparator = " │ "
+
+// boardColumns are the workflow stages shown as columns, left to right;
+// statuses without a column of their own, such as blocked or cancelled,
+// are shown in the column of their stage
+var boardColumns = []int{model.StageOpen, model.StageActive, model.StageDone}
+
+// columnTitles are the headings of the board columns
+var columnTitles = map[int]string{
+	model.StageOpen:   "TODO",
+	model.StageActive: "IN PROGRESS",
+	model.StageDone:   "DONE",
+}
+
+// boardHelp describes the keys, shown in the status line
+const boardHelp = "←→ column  ↑↓ select  H/L move  K/J reorder  enter subtasks  x toggle  e edit  r reload  q quit"
+
+// boardRow is one selectable line of a column: a task, or one of its subtasks
+type boardRow struct {
+	task    *parser.ParsedTask
+	subtask []int // position of the subtask at each level of task.SubTasks, or nil for the task itself
+}
+
+// path returns the task ID or subtask path of the row, e.g. T001.2.1
+func (r boardRow) path() model.SubtaskPath {
+	return model.SubtaskPath{TaskID: r.task.Task.ID, Indexes: r.subtask}
+}
+
+// boardAction is a change requested by a key; at most one field is set
+type boardAction struct {
+	update  *mcp.UpdateTaskParams
+	reorder *mcp.ReorderTaskParams
+	check   *mcp.CheckSubtaskParams
+	edit    string // ID of the task whose context file to open
+	reload  bool
+	quit    bool
+}
+
+// board is the state of the kanban board: the tasks of one project laid out
+// in status columns, and the selection
+type board struct {
+	expanded map[string]bool
+	file     mcp.TaskFile
+	message  string
+	follow   string // task to select after the next reload
+	columns  [][]boardRow
+	selected []int
+	col      int
+}
+
+// newBoard returns an empty board
+func newBoard() *board {
+	return &board{
+		expanded: make(map[string]bool),
+		columns:  make([][]boardRow, len(boardColumns)),
+		selected: make([]int, len(boardColumns)),
+	}
+}
+
+// setFile lays out the tasks of file, keeping the selection where possible
+func (b *board) setFile(file mcp.TaskFile) {
+	previous := make([]boardRow, len(b.columns))
+	for i := range b.columns {
+		if row, ok := b.row(i); ok {
+			previous[i] = row
+		}
+	}
+
+	b.file = file
+	for i := range b.columns {
+		b.columns[i] = nil
+	}
+	for i := range file.Tasks {
+		task := &file.Tasks[i]
+		col := slices.Index(boardColumns, b.workflow().Stage(task.Task.Status))
+		b.columns[col] = append(b.columns[col], boardRow{task: task})
+		if b.expanded[task.Task.ID] {
+			model.WalkSubtasks(task.SubTasks, func(indexes []int, _ model.SubTask) {
+				b.columns[col] = append(b.columns[col], boardRow{task: task, subtask: indexes})
+			})
+		}
+	}
+
+	for i, rows := range b.columns {
+		path := ""
+		if previous[i].task != nil {
+			path = previous[i].path().String()
+		}
+		if i == b.col && b.follow != "" {
+			path = b.follow
+		}
+		if at := slices.IndexFunc(rows, func(r boardRow) bool { return r.path().String() == path }); at >= 0 {
+			b.selected[i] = at
+		}
+		b.selected[i] = max(0, min(b.selected[i], len(rows)-1))
+	}
+	b.follow = ""
+}
+
+// workflow returns the workflow of the tasks shown, by default model.DefaultWorkflow
+func (b *board) workflow() model.Workflow {
+	if len(b.file.Workflow.Statuses) == 0 {
+		return model.DefaultWorkflow
+	}
+	return b.file.Workflow
+}
+
+// row returns the selected row of a column
+func (b *board) row(col int) (boardRow, bool) {
+	rows := b.columns[col]
+	if len(rows) == 0 {
+		return boardRow{}, false
+	}
+	return rows[b.selected[col]], true
+}
+
+// handleKey applies a key to the board and returns the change it requests
+func (b *board) handleKey(key string) boardAction {
+	b.message = ""
+	switch key {
+	case "q", keyCtrlC:
+		return boardAction{quit: true}
+	case "r":
+		return boardAction{reload: true}
+	case keyLeft, "h":
+		b.col = max(0, b.col-1)
+	case keyRight, "l":
+		b.col = min(len(b.columns)-1, b.col+1)
+	case keyUp, "k":
+		b.selected[b.col] = max(0, b.selected[b.col]-1)
+	case keyDown, "j":
+		b.selected[b.col] = max(0, min(len(b.columns[b.col])-1, b.selected[b.col]+1))
+	case "?":
+		b.message = boardHelp
+	default:
+		row, ok := b.row(b.col)
+		if !ok {
+			return boardAction{}
+		}
+		return b.handleTaskKey(key, row)
+	}
+	return boardAction{}
+}
+
+// handleTaskKey applies a key acting on the selected task or subtask
+func (b *board) handleTaskKey(key string, row boardRow) boardAction {
+	id := row.task.Task.ID
+	switch key {
+	case "H", "L":
+		col := b.col - 1
+		if key == "L" {
+			col = b.col + 1
+		}
+		if col < 0 || col >= len(boardColumns) {
+			return boardAction{}
+		}
+		status := b.workflow().StageStatus(boardColumns[col])
+		if status == "" {
+			return boardAction{}
+		}
+		b.col, b.follow = col, id
+		return boardAction{update: &mcp.UpdateTaskParams{TaskID: id, Status: status, Project: b.file.Project}}
+	case "K", "J":
+		return b.reorder(row, key == "J")
+	case keyEnter:
+		b.expanded[id] = !b.expanded[id]
+		b.follow = id
+		b.setFile(b.file)
+		return boardAction{}
+	case "x", " ":
+		if row.subtask == nil {
+			b.message = "Select a subtask to toggle (enter shows subtasks)"
+			return boardAction{}
+		}
+		return boardAction{check: toggleSubtask(row, b.workflow(), b.file.Project)}
+	case "e":
+		return boardAction{edit: id}
+	}
+	return boardAction{}
+}
+
+// reorder moves the selected task above or below its neighbor in the same
+// column and category
+func (b *board) reorder(row boardRow, down bool) boardAction {
+	var neighbor string
+	rows := b.columns[b.col]
+	step := -1
+	if down {
+		step = 1
+	}
+	at := slices.IndexFunc(rows, func(r boardRow) bool { return r.task == row.task && r.subtask == nil })
+	for i := at + step; i >= 0 && i < len(rows); i += step {
+		if rows[i].subtask != nil {
+			continue
+		}
+		if rows[i].task.Task.Category == row.task.Task.Category {
+			neighbor = rows[i].task.Task.ID
+		}
+		break
+	}
+	if neighbor == "" {
+		b.message = "Already at the edge of its category"
+		return boardAction{}
+	}
+
+	position := mcp.PositionBefore
+	if down {
+		position = mcp.PositionAfter
+	}
+	b.follow = row.task.Task.ID
+	return boardAction{reorder: &mcp.ReorderTaskParams{
+		TaskID:          row.task.Task.ID,
+		Position:        position,
+		ReferenceTaskID: neighbor,
+		Project:         b.file.Project,
+	}}
+}
+
+// toggleSubtask returns a change flipping a subtask between done and the
+// initial status
+func toggleSubtask(row boardRow, workflow model.Workflow, project string) *mcp.CheckSubtaskParams {
+	list, _ := model.SubtaskList(&row.task.SubTasks, row.subtask[:len(row.subtask)-1])
+	status := workflow.StageStatus(model.StageDone)
+	if workflow.IsDone((*list)[row.subtask[len(row.subtask)-1]].Status) {
+		status = workflow.Initial()
+	}
+	return &mcp.CheckSubtaskParams{Path: row.path().String(), Status: status, Project: project}
+}
+
+// render draws the board into lines fitting width × height
+func (b *board) render(width, height int) []string {
+	const chrome = 4 // title, blank line, column headings, status line
+	colWidth := max(1, (width-stringWidth(columnSeparator)*(len(boardColumns)-1))/len(boardColumns))
+	bodyHeight := max(1, height-chrome)
+
+	lines := []string{
+		styleBold + fitWidth(fmt.Sprintf("%s — %d task(s)   ? for help", b.file.Project, len(b.file.Tasks)), width) + styleReset,
+		"",
+	}
+
+	headings := make([]string, len(boardColumns))
+	bodies := make([][]string, len(boardColumns))
+	for i, stage := range boardColumns {
+		heading := fitWidth(fmt.Sprintf("%s (%d)", columnTitles[stage], b.taskCount(i)), colWidth)
+		if i == b.col {
+			heading = styleBold + heading + styleReset
+		}
+		headings[i] = heading
+		bodies[i] = b.renderColumn(i, colWidth, bodyHeight)
+	}
+	lines = append(lines, strings.Join(headings, columnSeparator))
+	for y := range bodyHeight {
+		cells := make([]string, len(bodies))
+		for i, body := range bodies {
+			cells[i] = strings.Repeat(" ", colWidth)
+			if y < len(body) {
+				cells[i] = body[y]
+			}
+		}
+		lines = append(lines, strings.Join(cells, columnSeparator))
+	}
+
+	status := b.message
+	if status == "" {
+		status = boardHelp
+	}
+	return append(lines, styleDim+fitWidth(status, width)+styleReset)
+}
+
+// renderColumn draws one column, scrolled so that the selection is visible
+func (b *board) renderColumn(col, width, height int) []string {
+	var lines []string
+	workflow := b.workflow()
+	selectedLine := 0
+	category := ""
+	for i, row := range b.columns[col] {
+		task := row.task.Task
+		if row.subtask == nil && (i == 0 || task.Category != category) {
+			category = task.Category
+			lines = append(lines, styleDim+fitWidth("▸ "+category, width)+styleReset)
+		}
+
+		var text string
+		if row.subtask == nil {
+			text = task.ID + " " + statusBadge(workflow, task.Status) + task.Title + subtaskProgress(row.task, workflow)
+		} else {
+			list, _ := model.SubtaskList(&row.task.SubTasks, row.subtask[:len(row.subtask)-1])
+			subtask := (*list)[row.subtask[len(row.subtask)-1]]
+			text = " " + strings.Repeat("  ", len(row.subtask)) + workflow.Checkbox(subtask.Status) + " " + subtask.Title
+		}
+		text = fitWidth(text, width)
+		if i == b.selected[col] {
+			selectedLine = len(lines)
+			if col == b.col {
+				text = styleReverse + text + styleReset
+			}
+		}
+		lines = append(lines, text)
+	}
+
+	if offset := selectedLine - height + 1; offset > 0 {
+		lines = lines[offset:]
+	}
+	return lines
+}
+
+// taskCount returns the number of tasks in a column
+func (b *board) taskCount(col int) int {
+	n := 0
+	for _, row := range b.columns[col] {
+		if row.subtask == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// subtaskProgress returns " [done/total]" for a task with subtasks,
+// counting nested ones
+func subtaskProgress(task *parser.ParsedTask, workflow model.Workflow) string {
+	total := model.CountSubtasks(task.SubTasks)
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" [%d/%d]", model.CountDoneSubtasks(workflow, task.SubTasks), total)
+}
+
+// statusBadge returns the checkbox marking a task whose status is not the
+// one its column stands for, e.g. "[!] " for a blocked task
+func statusBadge(workflow model.Workflow, status string) string {
+	if status == workflow.StageStatus(workflow.Stage(status)) {
+		return ""
+	}
+	return workflow.Checkbox(status) + " "
+}
+
+// fitWidth truncates or pads s to exactly width terminal cells
+func fitWidth(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	w := 0
+	for i, r := range s {
+		rw := runeWidth(r)
+		if w+rw > width || (w+rw == width && i+utf8.RuneLen(r) < len(s)) {
+			// Leave room for the ellipsis
+			return s[:i] + "…" + strings.Repeat(" ", max(0, width-w-1))
+		}
+		w += rw
+	}
+	return s + strings.Repeat(" ", width-w)
+}
+
+// stringWidth returns the number of terminal cells s takes
+func stringWidth(s string) int {
+	w := 0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w
+}
+
+// runeWidth returns the number of terminal cells r takes: two for East
+// Asian wide and fullwidth characters, one otherwise
+func runeWidth(r rune) int {
+	switch {
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1FAFF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// parseKeys splits terminal input into keys
+func parseKeys(input []byte) []string {
+	var keys []string
+	for len(input) > 0 {
+		switch {
+		case input[0] == 0x1b && len(input) >= 3 && (input[1] == '[' || input[1] == 'O'):
+			if key, ok := arrowKeys[input[2]]; ok {
+				keys = append(keys, key)
+			}
+			input = input[3:]
+			continue
+		case input[0] == 0x1b:
+			keys = append(keys, keyEsc)
+		case input[0] == '\r' || input[0] == '\n':
+			keys = append(keys, keyEnter)
+		case input[0] == 0x03:
+			keys = append(keys, keyCtrlC)
+		default:
+			r, size := utf8.DecodeRune(input)
+			keys = append(keys, string(r))
+			input = input[size:]
+			continue
+		}
+		input = input[1:]
+	}
+	return keys
+}
+
+// arrowKeys maps the final byte of arrow key escape sequences to keys
+var arrowKeys = map[byte]string{
+	'A': keyUp,
+	'B': keyDown,
+	'C': keyRight,
+	'D': keyLeft,
+}
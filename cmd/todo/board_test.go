@@ -0,0 +1,312 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+const boardTaskFile = `# Task
+
+## Backend
+- [ ] Add login endpoint #T001
+  - [x] Hash passwords
+  - [ ] Rate limit
+- [-] Set up database #T002
+- [ ] Write migrations #T003
+
+## Frontend
+- [ ] Build login form #T004
+- [x] Pick colors #T005
+`
+
+// newBoardSession returns a session over a project holding boardTaskFile
+func newBoardSession(t *testing.T) (*session, string) {
+	t.Helper()
+	dir := newWorkspace(t)
+	path := filepath.Join(dir, ".todo", "task.md")
+	if err := os.WriteFile(path, []byte(boardTaskFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ts := mcp.NewToolService(dir)
+	file, err := ts.ReadTaskFile("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newBoard()
+	b.setFile(file)
+	return &session{ts: ts, board: b}, path
+}
+
+// parseTasks parses task.md content, failing the test on errors
+func parseTasks(t *testing.T, content string) []parser.ParsedTask {
+	t.Helper()
+	tasks, err := parser.ParseTaskContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tasks
+}
+
+// columnIDs lists the rows of each column as task IDs, with subtask rows as paths like "T001.1"
+func columnIDs(b *board) [][]string {
+	ids := make([][]string, len(b.columns))
+	for i, rows := range b.columns {
+		ids[i] = []string{}
+		for _, row := range rows {
+			ids[i] = append(ids[i], row.path().String())
+		}
+	}
+	return ids
+}
+
+func TestBoard_Layout(t *testing.T) {
+	s, _ := newBoardSession(t)
+	want := [][]string{{"T001", "T003", "T004"}, {"T002"}, {"T005"}}
+	if diff := cmp.Diff(want, columnIDs(s.board)); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+
+	s.board.handleKey(keyEnter)
+	want[0] = []string{"T001", "T001.1", "T001.2", "T003", "T004"}
+	if diff := cmp.Diff(want, columnIDs(s.board)); diff != "" {
+		t.Errorf("expanded columns mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBoard_BlockedAndCancelled(t *testing.T) {
+	b := newBoard()
+	b.setFile(mcp.TaskFile{Project: "app", Tasks: parseTasks(t, `## Ops
+- [!] Deploy #T001
+- [~] Migrate #T002
+- [ ] Monitor #T003
+`)})
+	want := [][]string{{"T003"}, {"T001"}, {"T002"}}
+	if diff := cmp.Diff(want, columnIDs(b)); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+	screen := stripStyles(strings.Join(b.render(90, 8), "\n"))
+	for _, want := range []string{"T001 [!] Deploy", "T002 [~] Migrate"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("render() lacks %q:\n%s", want, screen)
+		}
+	}
+}
+
+func TestBoard_CustomWorkflow(t *testing.T) {
+	workflow := model.Workflow{Statuses: []model.Status{
+		{Name: "todo", Marker: " "},
+		{Name: "in_progress", Marker: "-"},
+		{Name: "review", Marker: "r"},
+		{Name: "deployed", Marker: "x", Done: true},
+	}}
+	tasks, err := parser.NewParser(model.DefaultIDScheme, workflow).Parse(`## Ops
+- [r] Deploy #T001
+  - [x] Build image
+- [ ] Monitor #T002
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := newBoard()
+	b.setFile(mcp.TaskFile{Project: "app", Tasks: tasks, Workflow: workflow})
+	want := [][]string{{"T002"}, {"T001"}, {}}
+	if diff := cmp.Diff(want, columnIDs(b)); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+	screen := stripStyles(strings.Join(b.render(90, 8), "\n"))
+	if !strings.Contains(screen, "T001 [r] Deploy [1/1]") {
+		t.Errorf("render() lacks the review badge and progress:\n%s", screen)
+	}
+
+	b.col = 1
+	action := b.handleKey("L")
+	if action.update == nil || action.update.Status != "deployed" {
+		t.Errorf("handleKey(L) = %+v, want a move to deployed", action)
+	}
+}
+
+func TestBoard_NestedSubtasks(t *testing.T) {
+	b := newBoard()
+	b.setFile(mcp.TaskFile{Project: "app", Tasks: parseTasks(t, `## Ops
+- [ ] Deploy #T001
+  - [ ] Build image
+    - [x] Pin base image
+  - [x] Tag release
+`)})
+	b.handleKey(keyEnter)
+	want := [][]string{{"T001", "T001.1", "T001.1.1", "T001.2"}, {}, {}}
+	if diff := cmp.Diff(want, columnIDs(b)); diff != "" {
+		t.Errorf("columns mismatch (-want +got):\n%s", diff)
+	}
+	screen := stripStyles(strings.Join(b.render(90, 10), "\n"))
+	for _, want := range []string{"T001 Deploy [2/3]", "     [x] Pin base image"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("render() lacks %q:\n%s", want, screen)
+		}
+	}
+
+	b.handleKey("j")
+	b.handleKey("j")
+	action := b.handleKey("x")
+	wantCheck := &mcp.CheckSubtaskParams{Path: "T001.1.1", Status: "todo", Project: "app"}
+	if diff := cmp.Diff(wantCheck, action.check); diff != "" {
+		t.Errorf("x action mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestBoard_HandleKey(t *testing.T) {
+	s, _ := newBoardSession(t)
+	b := s.board
+
+	b.handleKey("j")
+	b.handleKey("j")
+	if row, _ := b.row(0); row.task.Task.ID != "T004" {
+		t.Errorf("selected %s, want T004", row.task.Task.ID)
+	}
+	b.handleKey("j")
+	if row, _ := b.row(0); row.task.Task.ID != "T004" {
+		t.Errorf("selection moved past the end: %s", row.task.Task.ID)
+	}
+
+	action := b.handleKey("K")
+	if action.reorder != nil || b.message == "" {
+		t.Errorf("K on the first task of a category = %+v, message %q", action, b.message)
+	}
+
+	b.handleKey("k")
+	action = b.handleKey("K")
+	want := &mcp.ReorderTaskParams{TaskID: "T003", Position: mcp.PositionBefore, ReferenceTaskID: "T001", Project: b.file.Project}
+	if diff := cmp.Diff(want, action.reorder); diff != "" {
+		t.Errorf("K action mismatch (-want +got):\n%s", diff)
+	}
+
+	action = b.handleKey("L")
+	wantUpdate := &mcp.UpdateTaskParams{TaskID: "T003", Status: "in_progress", Project: b.file.Project}
+	if diff := cmp.Diff(wantUpdate, action.update); diff != "" || b.col != 1 {
+		t.Errorf("L action mismatch (-want +got):\n%s, column %d", diff, b.col)
+	}
+
+	if action := b.handleKey("q"); !action.quit {
+		t.Error("q does not quit")
+	}
+}
+
+func TestSession_Handle(t *testing.T) {
+	s, path := newBoardSession(t)
+	ctx := context.Background()
+
+	// Move T001 to in progress, then toggle its second subtask
+	for _, key := range []string{"L", keyEnter, "j", "j", "x"} {
+		if quit := s.handle(ctx, key); quit {
+			t.Fatalf("handle(%q) quit", key)
+		}
+		if s.board.message != "" {
+			t.Fatalf("handle(%q) message = %q", key, s.board.message)
+		}
+	}
+	// Move T001 below T002
+	s.handle(ctx, "k")
+	s.handle(ctx, "k")
+	s.handle(ctx, "k")
+	s.handle(ctx, "J")
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Task
+
+## Backend
+- [-] Set up database #T002
+- [-] Add login endpoint #T001
+  - [x] Hash passwords
+  - [x] Rate limit
+- [ ] Write migrations #T003
+
+## Frontend
+- [ ] Build login form #T004
+- [x] Pick colors #T005
+
+`
+	if diff := cmp.Diff(want, string(content)); diff != "" {
+		t.Errorf("task.md mismatch (-want +got):\n%s", diff)
+	}
+	if row, _ := s.board.row(1); row.task.Task.ID != "T001" || row.subtask != nil {
+		t.Errorf("selection = %s, want T001 after reordering", row.task.Task.ID)
+	}
+}
+
+func TestBoard_Render(t *testing.T) {
+	s, _ := newBoardSession(t)
+	s.board.handleKey(keyEnter)
+	lines := s.board.render(60, 10)
+
+	if len(lines) != 10 {
+		t.Fatalf("render() = %d lines, want 10", len(lines))
+	}
+	for i, line := range lines {
+		plain := stripStyles(line)
+		if w := stringWidth(plain); w > 60 {
+			t.Errorf("line %d is %d cells wide: %q", i, w, plain)
+		}
+	}
+	screen := stripStyles(strings.Join(lines, "\n"))
+	for _, want := range []string{"TODO (3)", "IN PROGRESS (1)", "DONE (1)", "▸ Backend", "T001 Add login en…", "   [x] Hash passw…", "T002 Set up datab…"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("render() lacks %q:\n%s", want, screen)
+		}
+	}
+}
+
+// stripStyles removes the escape sequences the board draws with
+func stripStyles(s string) string {
+	for _, style := range []string{styleBold, styleDim, styleReverse, styleReset} {
+		s = strings.ReplaceAll(s, style, "")
+	}
+	return s
+}
+
+func TestFitWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcde", 5, "abcde"},
+		{"abcdef", 5, "abcd…"},
+		{"タスク管理", 6, "タス… "},
+		{"タスク", 6, "タスク"},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := fitWidth(tt.s, tt.width); got != tt.want {
+			t.Errorf("fitWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("j\x1b[A\x1bOBx\r\x03é\x1b"))
+	want := []string{"j", keyUp, keyDown, "x", keyEnter, keyCtrlC, "é", keyEsc}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseKeys() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestRun_TUINeedsTerminal(t *testing.T) {
+	dir := newWorkspace(t)
+	code, _, stderr := runTodo(t, dir, "", "tui")
+	if code != exitError || !strings.Contains(stderr, "terminal") {
+		t.Errorf("tui without a terminal: exit code = %d, stderr = %q", code, stderr)
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// category runs "todo category"
+func (a *app) category(ctx context.Context, args []string) error {
+	const synopsis = "category list|rename|merge|move|rm ..."
+	if len(args) == 0 {
+		fmt.Fprintf(a.stderr, "Usage: todo %s\n", synopsis)
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		return a.categoryList(ctx, args[1:])
+	case "rename":
+		return a.categoryRename(ctx, args[1:])
+	case "merge":
+		return a.categoryMerge(ctx, args[1:])
+	case "move":
+		return a.categoryMove(ctx, args[1:])
+	case "rm":
+		return a.categoryRemove(ctx, args[1:])
+	default:
+		fmt.Fprintf(a.stderr, "todo: unknown category command %q\nUsage: todo %s\n", args[0], synopsis)
+		return errUsage
+	}
+}
+
+// categoryList runs "todo category list"
+func (a *app) categoryList(ctx context.Context, args []string) error {
+	fs := a.flagSet("category list")
+	if _, err := a.parse(fs, args, "category list", 0, 0); err != nil {
+		return err
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.ListCategories(ctx, mcp.ListCategoriesParams{Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if len(result.Categories) == 0 {
+			fmt.Fprintln(w, "No categories found")
+			return
+		}
+		rows := make([][]string, 0, len(result.Categories))
+		for _, category := range result.Categories {
+			rows = append(rows, []string{category.Name, strconv.Itoa(len(category.TaskIDs)), a.taskIDs(result.Project, category.TaskIDs)})
+		}
+		table(w, []string{"CATEGORY", "TASKS", "IDS"}, rows)
+	})
+}
+
+// categoryRename runs "todo category rename"
+func (a *app) categoryRename(ctx context.Context, args []string) error {
+	fs := a.flagSet("category rename")
+	positional, err := a.parse(fs, args, "category rename <category> <new-name>", 2, 2)
+	if err != nil {
+		return err
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.RenameCategory(ctx, mcp.RenameCategoryParams{Category: positional[0], NewName: positional[1], Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if result.Category == result.NewName {
+			fmt.Fprintf(w, "Category %s is unchanged\n", result.Category)
+			return
+		}
+		fmt.Fprintf(w, "Renamed category %s to %s: %s\n", result.Category, result.NewName, a.taskIDs(result.Project, result.TaskIDs))
+	})
+}
+
+// categoryMerge runs "todo category merge"
+func (a *app) categoryMerge(ctx context.Context, args []string) error {
+	const synopsis = "category merge --into target <category>..."
+	fs := a.flagSet("category merge")
+	target := fs.String("into", "", "`category` to merge into; created if it does not exist")
+	positional, err := a.parse(fs, args, synopsis, 1, -1)
+	if err != nil {
+		return err
+	}
+	if *target == "" {
+		fmt.Fprintf(a.stderr, "todo: --into is required\nUsage: todo %s\n", synopsis)
+		return errUsage
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.MergeCategories(ctx, mcp.MergeCategoriesParams{Categories: positional, Target: *target, Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if len(result.Categories) == 0 {
+			fmt.Fprintf(w, "Category %s is unchanged\n", result.Target)
+			return
+		}
+		fmt.Fprintf(w, "Merged %s into %s: %s\n", strings.Join(result.Categories, ", "), result.Target,
+			a.taskIDs(result.Project, result.TaskIDs))
+	})
+}
+
+// categoryMove runs "todo category move"
+func (a *app) categoryMove(ctx context.Context, args []string) error {
+	fs := a.flagSet("category move")
+	positional, err := a.parse(fs, args, "category move <category> first|last|before <category>|after <category>", 2, 3)
+	if err != nil {
+		return err
+	}
+	params := mcp.ReorderCategoryParams{Category: positional[0], Position: positional[1], Project: a.project}
+	if len(positional) == 3 {
+		params.ReferenceCategory = positional[2]
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.ReorderCategory(ctx, params)
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if result.OldPosition == result.NewPosition {
+			fmt.Fprintf(w, "Category %s is unchanged\n", result.Category)
+			return
+		}
+		fmt.Fprintf(w, "Moved category %s from position %d to %d: %s\n", result.Category, result.OldPosition, result.NewPosition,
+			a.taskIDs(result.Project, result.TaskIDs))
+	})
+}
+
+// categoryRemove runs "todo category rm"
+func (a *app) categoryRemove(ctx context.Context, args []string) error {
+	fs := a.flagSet("category rm")
+	moveTo := fs.String("to", "", "`category` to move the tasks to (required unless the category has no tasks)")
+	positional, err := a.parse(fs, args, "category rm [--to category] <category>", 1, 1)
+	if err != nil {
+		return err
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.DeleteCategory(ctx, mcp.DeleteCategoryParams{Category: positional[0], MoveTo: *moveTo, Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if result.MovedTo == "" {
+			fmt.Fprintf(w, "Deleted category %s\n", result.Category)
+			return
+		}
+		fmt.Fprintf(w, "Deleted category %s, moved to %s: %s\n", result.Category, result.MovedTo, a.taskIDs(result.Project, result.TaskIDs))
+	})
+}
+
+// taskIDs returns task IDs to show as a comma-separated list
+func (a *app) taskIDs(project string, ids []string) string {
+	shown := make([]string, 0, len(ids))
+	for _, id := range ids {
+		shown = append(shown, a.taskID(project, id))
+	}
+	return strings.Join(shown, ", ")
+}
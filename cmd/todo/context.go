@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// taskContext runs "todo context"
+func (a *app) taskContext(ctx context.Context, args []string) error {
+	const synopsis = "context get|append ..."
+	if len(args) == 0 {
+		fmt.Fprintf(a.stderr, "Usage: todo %s\n", synopsis)
+		return errUsage
+	}
+	switch args[0] {
+	case "get":
+		return a.contextGet(ctx, args[1:])
+	case "append":
+		return a.contextAppend(ctx, args[1:])
+	default:
+		fmt.Fprintf(a.stderr, "todo: unknown context command %q\nUsage: todo %s\n", args[0], synopsis)
+		return errUsage
+	}
+}
+
+// contextGet runs "todo context get"
+func (a *app) contextGet(ctx context.Context, args []string) error {
+	fs := a.flagSet("context get")
+	positional, err := a.parse(fs, args, "context get <task-id>", 1, 1)
+	if err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.GetContext(ctx, mcp.GetContextParams{TaskID: a.splitTaskID(positional[0]), Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		fmt.Fprint(w, result.Content)
+	})
+}
+
+// contextAppend runs "todo context append"
+func (a *app) contextAppend(ctx context.Context, args []string) error {
+	fs := a.flagSet("context append")
+	section := fs.String("section", "", "append to the body of this `section`, created if missing")
+	positional, err := a.parse(fs, args, "context append [--section name] <task-id> <text|->", 2, -1)
+	if err != nil {
+		return err
+	}
+
+	text := strings.Join(positional[1:], " ")
+	if text == "-" {
+		data, err := io.ReadAll(a.stdin)
+		if err != nil {
+			return errcode.Wrap(err, errcode.FileReadError, "failed to read standard input")
+		}
+		text = string(data)
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.UpdateContext(ctx, mcp.UpdateContextParams{
+		TaskID:  a.splitTaskID(positional[0]),
+		Content: text,
+		Append:  true,
+		Section: *section,
+		Project: a.project,
+	})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Updated %s\n", result.FilePath)
+	})
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// importTodos runs "todo import"
+func (a *app) importTodos(ctx context.Context, args []string) error {
+	fs := a.flagSet("import")
+	category := fs.String("c", "", "`category` of the created tasks (default "+mcp.DefaultImportCategory+")")
+	dryRun := fs.Bool("dry-run", false, "print the changes without making them")
+	if _, err := a.parse(fs, args, "import [--dry-run] [-c category]", 0, 0); err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.ImportTodos(ctx, mcp.ImportTodosParams{Category: *category, DryRun: *dryRun, Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		for _, group := range []struct {
+			done, planned string
+			todos         []mcp.ImportedTodo
+		}{
+			{"Created", "Would create", result.Created},
+			{"Moved", "Would move", result.Moved},
+			{"Completed", "Would complete", result.Completed},
+		} {
+			verb := group.done
+			if result.DryRun {
+				verb = group.planned
+			}
+			for _, todo := range group.todos {
+				fmt.Fprintf(w, "%s %s %s (%s)\n", verb, a.taskID(result.Project, todo.TaskID), todo.Title, todo.Location)
+			}
+		}
+		fmt.Fprintf(w, "Found %d comments\n", result.Comments)
+	})
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRun_Import(t *testing.T) {
+	dir := newWorkspace(t)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\n// TODO: handle signals\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code, stdout, stderr := runTodo(t, dir, "", "import", "--dry-run")
+	if want := "Would create T001 handle signals (main.go:3)\nFound 1 comments\n"; code != exitOK || stdout != want {
+		t.Errorf("import --dry-run = %d, %q, %q; want %q", code, stdout, stderr, want)
+	}
+	code, stdout, stderr = runTodo(t, dir, "", "import", "-c", "Debt")
+	if want := "Created T001 handle signals (main.go:3)\nFound 1 comments\n"; code != exitOK || stdout != want {
+		t.Errorf("import = %d, %q, %q; want %q", code, stdout, stderr, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	code, stdout, stderr = runTodo(t, dir, "", "import")
+	if want := "Completed T001 handle signals (main.go:3)\nFound 0 comments\n"; code != exitOK || stdout != want {
+		t.Errorf("second import = %d, %q, %q; want %q", code, stdout, stderr, want)
+	}
+}
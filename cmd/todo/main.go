@@ -0,0 +1,299 @@
+// Package main provides the todo command, a command-line companion to the
+// agentic-todo-mcp server. It runs the same tool operations as the MCP tools
+// against the project found from the working directory.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+// Exit codes
+const (
+	exitOK       = 0
+	exitError    = 1
+	exitUsage    = 2
+	exitNotFound = 3
+)
+
+const usage = `Usage: todo [flags] <command> [arguments]
+
+Commands:
+  create <title>            create a task
+  update <task-id>          change the title, status, category, subtasks or metadata of a task
+  done <task-id>            mark a task as done
+  block <task-id> <reason>  mark a task as blocked
+  cancel <task-id> <reason> mark a task as cancelled
+  list                      list tasks
+  search <query>            search tasks
+  show <task-id>            show a task with its subtasks and context
+  subtask add|check|rename|move|rm <path> ...
+                            change one subtask, addressed as T003.2 or T003.2.1
+  category list|rename|merge|move|rm ...
+                            list, rename, merge, reorder or delete categories
+  archive [task-id...]      move done tasks into the monthly archive
+  restore <task-id>...      move archived tasks back to task.md
+  adr new <title>           create an ADR
+  adr list                  list ADRs
+  adr status <n> <status>   change the status of an ADR
+  context get <task-id>     print the context of a task
+  context append <task-id> <text|->
+                            append to the context of a task ("-" reads stdin)
+  import                    create tasks for TODO, FIXME and HACK comments in the code
+  sync                      update tasks referenced as "fixes #T001" in commit messages
+  tui                       open the task board in the terminal
+  merge-driver <base> <ours> <theirs> [path]
+                            merge task.md by task ID (git merge driver)
+
+Flags, accepted before or after the command:
+  --json            print results as JSON
+  --project name    project to use when several are open
+  -C dir            run as if started in dir
+  --config file     config file (default .todo/config.toml, .yaml or .json)
+
+Run "todo <command> -h" for the flags of a command.
+`
+
+// errUsage reports a command line that cannot be run
+var errUsage = errors.New("usage")
+
+// app holds the state of one todo invocation
+type app struct {
+	stdin      io.Reader
+	stdout     io.Writer
+	stderr     io.Writer
+	lookupEnv  func(string) (string, bool)
+	service    *mcp.ToolService
+	dir        string
+	configPath string
+	project    string
+	json       bool
+}
+
+// command runs one subcommand with its arguments
+type command func(a *app, ctx context.Context, args []string) error
+
+// commands maps subcommand names to their implementations
+var commands = map[string]command{
+	"create":       (*app).create,
+	"update":       (*app).update,
+	"done":         (*app).done,
+	"block":        (*app).block,
+	"cancel":       (*app).cancel,
+	"list":         (*app).list,
+	"search":       (*app).search,
+	"show":         (*app).show,
+	"subtask":      (*app).subtask,
+	"category":     (*app).category,
+	"archive":      (*app).archive,
+	"restore":      (*app).restore,
+	"adr":          (*app).adr,
+	"context":      (*app).taskContext,
+	"import":       (*app).importTodos,
+	"sync":         (*app).sync,
+	"tui":          (*app).tui,
+	"merge-driver": (*app).mergeDriver,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.LookupEnv))
+}
+
+// run executes the todo command line and returns the exit code
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, lookupEnv func(string) (string, bool)) int {
+	a := &app{stdin: stdin, stdout: stdout, stderr: stderr, lookupEnv: lookupEnv}
+
+	fs := a.flagSet("todo")
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitUsage
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "todo: unknown command %q\n\n%s", fs.Arg(0), usage)
+		return exitUsage
+	}
+
+	err := cmd(a, context.Background(), fs.Args()[1:])
+	if a.service != nil {
+		if closeErr := a.service.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return a.exitCode(err)
+}
+
+// exitCode reports err and returns the matching exit code
+func (a *app) exitCode(err error) int {
+	switch {
+	case err == nil:
+		return exitOK
+	case errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	}
+
+	if a.json {
+		a.writeJSON(a.stderr, mcp.NewErrorResponse(err))
+	} else {
+		e := errcode.As(err)
+		fmt.Fprintf(a.stderr, "todo: %s: %s\n", e.Code, e.Error())
+	}
+	switch errcode.CodeOf(err) {
+	case errcode.TaskNotFound, errcode.ADRNotFound, errcode.CategoryNotFound, errcode.ProjectNotFound, errcode.FileNotFound:
+		return exitNotFound
+	default:
+		return exitError
+	}
+}
+
+// flagSet returns a flag set with the global flags registered
+func (a *app) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.BoolVar(&a.json, "json", a.json, "print results as JSON")
+	fs.StringVar(&a.project, "project", a.project, "project to use when several are open")
+	fs.StringVar(&a.dir, "C", a.dir, "run as if started in `dir`")
+	fs.StringVar(&a.configPath, "config", a.configPath, "config `file`")
+	return fs
+}
+
+// parse parses the flags of a subcommand, which may be mixed with its
+// positional arguments, and checks the number of positional arguments
+func (a *app) parse(fs *flag.FlagSet, args []string, synopsis string, minArgs, maxArgs int) ([]string, error) {
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "Usage: todo %s\n\nFlags:\n", synopsis)
+		fs.PrintDefaults()
+	}
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, errUsage
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			break
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+
+	if len(positional) < minArgs || (maxArgs >= 0 && len(positional) > maxArgs) {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// toolService opens the tool service for the workspace around the working directory
+func (a *app) toolService() (*mcp.ToolService, error) {
+	if a.service != nil {
+		return a.service, nil
+	}
+
+	dir := a.dir
+	if dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, errcode.Wrap(err, errcode.FileReadError, "failed to get working directory")
+		}
+		dir = wd
+	}
+	root, err := workspace.Find(dir, storage.DefaultDataDir)
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to find project root")
+	}
+	cfg, err := config.Load(root.Path, a.configPath, a.lookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if a.service, err = mcp.NewToolServiceWithConfig(root, cfg); err != nil {
+		return nil, err
+	}
+	return a.service, nil
+}
+
+// multiProject reports whether several projects are open, so that output names the project
+func (a *app) multiProject() bool {
+	return a.service != nil && len(a.service.Projects()) > 1
+}
+
+// output prints result as JSON with --json, or with the text printer otherwise
+func (a *app) output(result any, text func(w io.Writer)) error {
+	if a.json {
+		a.writeJSON(a.stdout, result)
+		return nil
+	}
+	text(a.stdout)
+	return nil
+}
+
+// writeJSON prints v as indented JSON
+func (a *app) writeJSON(w io.Writer, v any) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(v)
+}
+
+// table prints rows aligned in columns under a header
+func table(w io.Writer, header []string, rows [][]string) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	_ = tw.Flush()
+}
+
+// taskID returns the task ID to show, qualified with the project when several are open
+func (a *app) taskID(project, id string) string {
+	if a.multiProject() {
+		return project + ":" + id
+	}
+	return id
+}
+
+// splitTaskID accepts a task ID qualified with its project (api:T001) as
+// printed by the other commands
+func (a *app) splitTaskID(arg string) string {
+	if project, id, ok := strings.Cut(arg, ":"); ok {
+		if a.project == "" {
+			a.project = project
+		}
+		return id
+	}
+	return arg
+}
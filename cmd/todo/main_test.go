@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// noEnv is an environment with no variables set
+func noEnv(string) (string, bool) { return "", false }
+
+// runTodo runs the todo command in dir and returns its exit code and output
+func runTodo(t *testing.T, dir, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut bytes.Buffer
+	code = run(append([]string{"-C", dir}, args...), strings.NewReader(stdin), &out, &errOut, noEnv)
+	return code, out.String(), errOut.String()
+}
+
+// newWorkspace returns a directory holding an empty task list
+func newWorkspace(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".todo"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRun_Tasks(t *testing.T) {
+	dir := newWorkspace(t)
+
+	steps := []struct {
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{
+			args:       []string{"create", "-c", "Backend", "Add", "login", "-s", "Hash passwords"},
+			wantStdout: "Created T001 Add login (Backend)\n",
+		},
+		{
+			args:       []string{"create", "Write docs"},
+			wantStdout: "Created T002 Write docs (Default)\n",
+		},
+		{
+			args:       []string{"done", "T001"},
+			wantStdout: "Updated T001: status\n",
+		},
+		{
+			args:       []string{"update", "T002", "--status", "in_progress", "-s", "[x] Outline", "-s", "Draft"},
+			wantStdout: "Updated T002: status, subtasks\n",
+		},
+		{
+			args: []string{"list"},
+			wantStdout: "ID    STATUS       CATEGORY  TITLE       SUBTASKS\n" +
+				"T001  done         Backend   Add login   0/1\n" +
+				"T002  in_progress  Default   Write docs  1/2\n",
+		},
+		{
+			args: []string{"list", "--status", "done"},
+			wantStdout: "ID    STATUS  CATEGORY  TITLE      SUBTASKS\n" +
+				"T001  done    Backend   Add login  0/1\n",
+		},
+		{
+			args: []string{"search", "outline"},
+			wantStdout: "ID    STATUS       SCORE  TITLE       MATCH\n" +
+				"T002  in_progress  0.7    Write docs  Outline\n",
+		},
+		{
+			args:       []string{"search", "nothing"},
+			wantStdout: "No tasks match \"nothing\"\n",
+		},
+	}
+
+	for _, step := range steps {
+		code, stdout, stderr := runTodo(t, dir, "", step.args...)
+		if code != step.wantCode {
+			t.Fatalf("todo %v: exit code = %d, want %d; stderr = %s", step.args, code, step.wantCode, stderr)
+		}
+		if diff := cmp.Diff(step.wantStdout, stdout); diff != "" {
+			t.Errorf("todo %v: output mismatch (-want +got):\n%s", step.args, diff)
+		}
+	}
+}
+
+func TestRun_Metadata(t *testing.T) {
+	dir := newWorkspace(t)
+
+	steps := []struct {
+		args       []string
+		wantStdout string
+	}{
+		{
+			args:       []string{"create", "--due", "2026-11-01", "--priority", "high", "--assignee", "alice", "--tag", "auth", "Add login"},
+			wantStdout: "Created T001 Add login (Default)\n",
+		},
+		{
+			args:       []string{"create", "--tag", "docs", "Write docs"},
+			wantStdout: "Created T002 Write docs (Default)\n",
+		},
+		{
+			args:       []string{"update", "T001", "--tag", "api", "--clear", "priority_label"},
+			wantStdout: "Updated T001: priority_label, tags\n",
+		},
+		{
+			args: []string{"list"},
+			wantStdout: "ID    STATUS  CATEGORY  TITLE                                 SUBTASKS\n" +
+				"T001  todo    Default   Add login @alice +api due:2026-11-01  0\n" +
+				"T002  todo    Default   Write docs +docs                      0\n",
+		},
+		{
+			args: []string{"list", "--due-before", "2026-12-31"},
+			wantStdout: "ID    STATUS  CATEGORY  TITLE                                 SUBTASKS\n" +
+				"T001  todo    Default   Add login @alice +api due:2026-11-01  0\n",
+		},
+		{
+			args: []string{"search", "--tag", "docs", "write"},
+			wantStdout: "ID    STATUS  SCORE  TITLE       MATCH\n" +
+				"T002  todo    1.0    Write docs  Write docs\n",
+		},
+		{
+			args:       []string{"show", "T001"},
+			wantStdout: "T001  Add login\nStatus:    todo\nCategory:  Default (priority 1)\nDue:       2026-11-01\nAssignee:  alice\nTags:      api\n",
+		},
+	}
+
+	for _, step := range steps {
+		code, stdout, stderr := runTodo(t, dir, "", step.args...)
+		if code != exitOK {
+			t.Fatalf("todo %v: exit code = %d; stderr = %s", step.args, code, stderr)
+		}
+		if step.args[0] == "show" {
+			// Drop the creation time and the context
+			stdout, _, _ = strings.Cut(stdout, "Created:")
+		}
+		if diff := cmp.Diff(step.wantStdout, stdout); diff != "" {
+			t.Errorf("todo %v: output mismatch (-want +got):\n%s", step.args, diff)
+		}
+	}
+}
+
+func TestRun_BlockAndCancel(t *testing.T) {
+	dir := newWorkspace(t)
+	for _, title := range []string{"Deploy", "Migrate"} {
+		if code, _, stderr := runTodo(t, dir, "", "create", title); code != exitOK {
+			t.Fatalf("create: %s", stderr)
+		}
+	}
+
+	if code, stdout, stderr := runTodo(t, dir, "", "block", "T001", "waiting", "for", "keys"); code != exitOK || stdout != "Updated T001: status, reason\n" {
+		t.Errorf("block: exit code = %d, stdout = %q, stderr = %s", code, stdout, stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "cancel", "T002"); code != exitUsage {
+		t.Errorf("cancel without a reason: exit code = %d, want %d; stderr = %s", code, exitUsage, stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "update", "T002", "--status", "cancelled"); code != exitError || !strings.Contains(stderr, "reason is required") {
+		t.Errorf("update to cancelled without a reason: exit code = %d, stderr = %s", code, stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "update", "T002", "--status", "cancelled", "--reason", "not needed"); code != exitOK {
+		t.Errorf("update to cancelled: %s", stderr)
+	}
+
+	_, stdout, _ := runTodo(t, dir, "", "list")
+	want := "ID    STATUS     CATEGORY  TITLE    SUBTASKS\n" +
+		"T001  blocked    Default   Deploy   0\n" +
+		"T002  cancelled  Default   Migrate  0\n"
+	if diff := cmp.Diff(want, stdout); diff != "" {
+		t.Errorf("list output mismatch (-want +got):\n%s", diff)
+	}
+	if _, stdout, _ := runTodo(t, dir, "", "show", "T001"); !strings.Contains(stdout, "Status:    blocked\nCategory:  Default (priority 1)\nReason:    waiting for keys\n") {
+		t.Errorf("show output = %q", stdout)
+	}
+}
+
+func TestRun_CustomWorkflow(t *testing.T) {
+	dir := newWorkspace(t)
+	config := `[workflow]
+statuses = ["todo", "in_progress", "review [r]", "shipped [x]"]
+done = ["shipped"]
+transitions = ["in_progress -> review", "review -> shipped|in_progress"]
+`
+	if err := os.WriteFile(filepath.Join(dir, ".todo", "config.toml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "create", "Release"); code != exitOK {
+		t.Fatalf("create: %s", stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "update", "T001", "-s", "[r] Write docs"); code != exitOK {
+		t.Fatalf("update subtasks: %s", stderr)
+	}
+
+	if code, _, stderr := runTodo(t, dir, "", "update", "T001", "--status", "in_progress"); code != exitOK {
+		t.Errorf("update to in_progress: %s", stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "done", "T001"); code != exitError || !strings.Contains(stderr, "cannot move from in_progress to shipped") {
+		t.Errorf("done before review: exit code = %d, stderr = %s", code, stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "update", "T001", "--status", "review"); code != exitOK {
+		t.Errorf("update to review: %s", stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "done", "T001"); code != exitOK {
+		t.Errorf("done after review: %s", stderr)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- [x] Release #T001\n  - [r] Write docs\n") {
+		t.Errorf("task.md = %q", content)
+	}
+}
+
+func TestRun_Subtask(t *testing.T) {
+	dir := newWorkspace(t)
+	if code, _, stderr := runTodo(t, dir, "", "create", "-s", "Build image", "-s", "Tag release", "Deploy"); code != exitOK {
+		t.Fatalf("create: %s", stderr)
+	}
+
+	steps := []struct {
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{[]string{"subtask", "add", "T001.1", "Pin", "base", "image"}, exitOK, "Added subtask T001.1.1: [todo] Pin base image\n"},
+		{[]string{"subtask", "add", "--at", "1", "T001.1", "Scan"}, exitOK, "Added subtask T001.1.1: [todo] Scan\n"},
+		{[]string{"subtask", "check", "T001.1.2"}, exitOK, "Checked subtask T001.1.2: [done] Pin base image\n"},
+		{[]string{"subtask", "check", "T001.1.2"}, exitOK, "Subtask T001.1.2 is unchanged: [done] Pin base image\n"},
+		{[]string{"subtask", "rename", "T001.2", "Tag", "v1.0"}, exitOK, "Renamed subtask T001.2: [todo] Tag v1.0\n"},
+		{[]string{"subtask", "move", "--to", "T001", "--at", "1", "T001.1.1"}, exitOK, "Moved subtask T001.1: [todo] Scan\n"},
+		{[]string{"subtask", "rm", "T001.3"}, exitOK, "Removed subtask T001.3: [todo] Tag v1.0\n"},
+		{[]string{"subtask", "rm", "T001.3"}, exitNotFound, ""},
+		{[]string{"subtask", "check", "T001"}, exitError, ""},
+		{[]string{"subtask", "archive", "T001.1"}, exitUsage, ""},
+	}
+	for _, step := range steps {
+		code, stdout, stderr := runTodo(t, dir, "", step.args...)
+		if code != step.wantCode || stdout != step.wantStdout {
+			t.Errorf("todo %s: exit code = %d, stdout = %q, want %d, %q; stderr = %s",
+				strings.Join(step.args, " "), code, stdout, step.wantCode, step.wantStdout, stderr)
+		}
+	}
+
+	_, stdout, _ := runTodo(t, dir, "", "show", "T001")
+	want := "Subtasks:\n  [todo] T001.1 Scan\n  [todo] T001.2 Build image\n    [done] T001.2.1 Pin base image\n"
+	if !strings.Contains(stdout, want) {
+		t.Errorf("show output = %q, want it to contain %q", stdout, want)
+	}
+}
+
+func TestRun_Category(t *testing.T) {
+	dir := newWorkspace(t)
+	for _, args := range [][]string{{"-c", "Backend", "API"}, {"-c", "Frontend", "Form"}, {"-c", "Docs", "Guide"}, {"-c", "Backend", "Jobs"}} {
+		if code, _, stderr := runTodo(t, dir, "", append([]string{"create"}, args...)...); code != exitOK {
+			t.Fatalf("create: %s", stderr)
+		}
+	}
+
+	steps := []struct {
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{[]string{"category", "list"}, exitOK, "CATEGORY  TASKS  IDS\nBackend   2      T001, T004\nFrontend  1      T002\nDocs      1      T003\n"},
+		{[]string{"category", "rename", "Frontend", "Web"}, exitOK, "Renamed category Frontend to Web: T002\n"},
+		{[]string{"category", "move", "Docs", "first"}, exitOK, "Moved category Docs from position 3 to 1: T003\n"},
+		{[]string{"category", "move", "Docs", "first"}, exitOK, "Category Docs is unchanged\n"},
+		{[]string{"category", "merge", "--into", "Backend", "Web"}, exitOK, "Merged Web into Backend: T002\n"},
+		{[]string{"category", "rm", "Docs"}, exitError, ""},
+		{[]string{"category", "rm", "--to", "Backend", "Docs"}, exitOK, "Deleted category Docs, moved to Backend: T003\n"},
+		{[]string{"category", "rm", "Docs"}, exitNotFound, ""},
+		{[]string{"category", "merge", "Backend"}, exitUsage, ""},
+		{[]string{"category", "list"}, exitOK, "CATEGORY  TASKS  IDS\nBackend   4      T001, T004, T002, T003\n"},
+	}
+	for _, step := range steps {
+		code, stdout, stderr := runTodo(t, dir, "", step.args...)
+		if code != step.wantCode || stdout != step.wantStdout {
+			t.Errorf("todo %s: exit code = %d, stdout = %q, want %d, %q; stderr = %s",
+				strings.Join(step.args, " "), code, stdout, step.wantCode, step.wantStdout, stderr)
+		}
+	}
+}
+
+func TestRun_Archive(t *testing.T) {
+	dir := newWorkspace(t)
+	for _, args := range [][]string{{"create", "API"}, {"create", "Guide"}, {"done", "T001"}} {
+		if code, _, stderr := runTodo(t, dir, "", args...); code != exitOK {
+			t.Fatalf("%s: %s", strings.Join(args, " "), stderr)
+		}
+	}
+	month := time.Now().Format("2006-01")
+
+	steps := []struct {
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{[]string{"archive"}, exitOK, "No tasks to archive\n"},
+		{[]string{"archive", "--all", "--dry-run"}, exitOK, "Would archive T001 API (archive " + month + ")\n"},
+		{[]string{"archive", "T002"}, exitError, ""},
+		{[]string{"archive", "--all"}, exitOK, "Archived T001 API (archive " + month + ")\n"},
+		{[]string{"search", "API"}, exitOK, "No tasks match \"API\"\n"},
+		{[]string{"search", "--archived", "API"}, exitOK,
+			"ID    STATUS  SCORE  TITLE  MATCH  ARCHIVE\nT001  done    1.0    API    API    " + month + "\n"},
+		{[]string{"create", "Deploy"}, exitOK, "Created T003 Deploy (Default)\n"},
+		{[]string{"restore", "T001"}, exitOK, "Restored T001 API (archive " + month + ")\n"},
+		{[]string{"restore", "T001"}, exitError, ""},
+		{[]string{"restore", "T009"}, exitNotFound, ""},
+		{[]string{"restore"}, exitUsage, ""},
+	}
+	for _, step := range steps {
+		code, stdout, stderr := runTodo(t, dir, "", step.args...)
+		if code != step.wantCode || stdout != step.wantStdout {
+			t.Errorf("todo %s: exit code = %d, stdout = %q, want %d, %q; stderr = %s",
+				strings.Join(step.args, " "), code, stdout, step.wantCode, step.wantStdout, stderr)
+		}
+	}
+}
+
+func TestRun_Rollup(t *testing.T) {
+	dir := newWorkspace(t)
+	if err := os.WriteFile(filepath.Join(dir, ".todo", "config.toml"), []byte("[workflow]\nrollup = [\"start\", \"complete\"]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "create", "-s", "Build image", "-s", "Tag release", "Deploy"); code != exitOK {
+		t.Fatalf("create: %s", stderr)
+	}
+
+	steps := []struct {
+		args       []string
+		wantStdout string
+	}{
+		{[]string{"subtask", "check", "T001.1"}, "Checked subtask T001.1: [done] Build image\nT001 moved automatically: todo -> in_progress\n"},
+		{[]string{"subtask", "check", "T001.2"}, "Checked subtask T001.2: [done] Tag release\nT001 moved automatically: in_progress -> done\n"},
+		{[]string{"list"}, "ID    STATUS  CATEGORY  TITLE   SUBTASKS\nT001  done    Default   Deploy  2/2\n"},
+	}
+	for _, step := range steps {
+		code, stdout, stderr := runTodo(t, dir, "", step.args...)
+		if code != exitOK || stdout != step.wantStdout {
+			t.Errorf("todo %s: exit code = %d, stdout = %q, want %q; stderr = %s",
+				strings.Join(step.args, " "), code, stdout, step.wantStdout, stderr)
+		}
+	}
+}
+
+func TestRun_Notes(t *testing.T) {
+	dir := newWorkspace(t)
+	if code, _, stderr := runTodo(t, dir, "", "create", "-s", "Build image", "Deploy"); code != exitOK {
+		t.Fatalf("create: %s", stderr)
+	}
+	if code, stdout, stderr := runTodo(t, dir, "", "update", "--notes", "Staging first\nthen production", "T001"); code != exitOK || stdout != "Updated T001: notes\n" {
+		t.Fatalf("update --notes: exit code = %d, stdout = %q; stderr = %s", code, stdout, stderr)
+	}
+
+	_, stdout, _ := runTodo(t, dir, "", "show", "T001")
+	if want := "Notes:\n  Staging first\n  then production\nSubtasks:\n  [todo] T001.1 Build image\n"; !strings.Contains(stdout, want) {
+		t.Errorf("show output = %q, want it to contain %q", stdout, want)
+	}
+
+	if code, _, stderr := runTodo(t, dir, "", "update", "--clear", "notes", "T001"); code != exitOK {
+		t.Fatalf("update --clear notes: %s", stderr)
+	}
+	if _, stdout, _ := runTodo(t, dir, "", "show", "T001"); strings.Contains(stdout, "Notes:") {
+		t.Errorf("show output after clearing notes = %q", stdout)
+	}
+}
+
+func TestRun_Show(t *testing.T) {
+	dir := newWorkspace(t)
+	if code, _, stderr := runTodo(t, dir, "", "create", "-s", "Hash passwords", "Add login"); code != exitOK {
+		t.Fatalf("create: %s", stderr)
+	}
+
+	code, stdout, stderr := runTodo(t, dir, "", "show", "T001", "--json")
+	if code != exitOK {
+		t.Fatalf("show: exit code = %d; stderr = %s", code, stderr)
+	}
+	var detail mcp.TaskDetail
+	if err := json.Unmarshal([]byte(stdout), &detail); err != nil {
+		t.Fatalf("show --json output is not JSON: %v\n%s", err, stdout)
+	}
+	wantSubtasks := []mcp.SubtaskInfo{{Path: "T001.1", Title: "Hash passwords", Status: "todo"}}
+	if detail.TaskID != "T001" || detail.Title != "Add login" || !cmp.Equal(wantSubtasks, detail.Subtasks) {
+		t.Errorf("show --json = %+v", detail)
+	}
+	if !strings.Contains(detail.Context, "# Context for T001") {
+		t.Errorf("show --json context = %q", detail.Context)
+	}
+
+	_, stdout, _ = runTodo(t, dir, "", "show", "T001")
+	if !strings.HasPrefix(stdout, "T001  Add login\nStatus:    todo\n") || !strings.Contains(stdout, "  [todo] T001.1 Hash passwords\n") {
+		t.Errorf("show output = %q", stdout)
+	}
+}
+
+func TestRun_Context(t *testing.T) {
+	dir := newWorkspace(t)
+	if code, _, stderr := runTodo(t, dir, "", "create", "Add login"); code != exitOK {
+		t.Fatalf("create: %s", stderr)
+	}
+
+	if code, _, stderr := runTodo(t, dir, "Found the cause\n", "context", "append", "--section", "Notes", "T001", "-"); code != exitOK {
+		t.Fatalf("context append: exit code = %d; stderr = %s", code, stderr)
+	}
+	if code, _, stderr := runTodo(t, dir, "", "context", "append", "T001", "Fixed", "it"); code != exitOK {
+		t.Fatalf("context append: exit code = %d; stderr = %s", code, stderr)
+	}
+	_, stdout, _ := runTodo(t, dir, "", "context", "get", "T001")
+	if !strings.HasSuffix(stdout, "## Notes\nFound the cause\n\nFixed it\n") {
+		t.Errorf("context get output = %q", stdout)
+	}
+}
+
+func TestRun_ADR(t *testing.T) {
+	dir := newWorkspace(t)
+
+	code, stdout, stderr := runTodo(t, dir, "", "adr", "new", "Use Markdown",
+		"--context", "c", "--decision", "d", "--rationale", "r")
+	if code != exitOK {
+		t.Fatalf("adr new: exit code = %d; stderr = %s", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "Created ADR-001 Use Markdown (Proposed): ") {
+		t.Errorf("adr new output = %q", stdout)
+	}
+
+	_, stdout, _ = runTodo(t, dir, "", "adr", "status", "1", "Accepted", "--reason", "agreed")
+	if stdout != "ADR-001 Use Markdown: Proposed → Accepted\n" {
+		t.Errorf("adr status output = %q", stdout)
+	}
+
+	_, stdout, _ = runTodo(t, dir, "", "adr", "list", "--json")
+	var result mcp.ListADRsResult
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("adr list --json output is not JSON: %v\n%s", err, stdout)
+	}
+	if result.TotalCount != 1 || result.ADRs[0].Status != "Accepted" {
+		t.Errorf("adr list --json = %+v", result)
+	}
+}
+
+func TestRun_Errors(t *testing.T) {
+	dir := newWorkspace(t)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStderr string
+	}{
+		{"no command", nil, exitUsage, "Usage: todo"},
+		{"unknown command", []string{"frobnicate"}, exitUsage, `unknown command "frobnicate"`},
+		{"missing argument", []string{"show"}, exitUsage, "Usage: todo show"},
+		{"unknown flag", []string{"list", "--bogus"}, exitUsage, "flag provided but not defined"},
+		{"help", []string{"list", "-h"}, exitOK, "Usage: todo list"},
+		{"not found", []string{"show", "T404"}, exitNotFound, "TASK_NOT_FOUND"},
+		{"invalid status", []string{"update", "T001", "--status", "later"}, exitError, "INVALID_STATUS"},
+		{"unknown ADR", []string{"adr", "status", "7", "Accepted"}, exitNotFound, "ADR_NOT_FOUND"},
+		{"JSON error", []string{"show", "T404", "--json"}, exitNotFound, `"code": "TASK_NOT_FOUND"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := runTodo(t, dir, "", tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d", code, tt.wantCode)
+			}
+			if !strings.Contains(stderr, tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRun_Projects(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{"api", "web"} {
+		if err := os.MkdirAll(filepath.Join(root, name, ".todo"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if code, _, stderr := runTodo(t, root, "", "create", "Orphan"); code != exitError || !strings.Contains(stderr, "PROJECT_REQUIRED") {
+		t.Errorf("create without project: exit code = %d, stderr = %q", code, stderr)
+	}
+	if code, _, stderr := runTodo(t, root, "", "create", "--project", "web", "Build form"); code != exitOK {
+		t.Fatalf("create: %s", stderr)
+	}
+	_, stdout, _ := runTodo(t, root, "", "list")
+	if !strings.Contains(stdout, "web:T001") {
+		t.Errorf("list output = %q, want qualified IDs", stdout)
+	}
+	if code, stdout, stderr := runTodo(t, root, "", "done", "web:T001"); code != exitOK || stdout != "Updated web:T001: status\n" {
+		t.Errorf("done web:T001: exit code = %d, stdout = %q, stderr = %q", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/git"
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+	"github.com/jnst/agentic-todo-mcp/internal/merge"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+)
+
+// theirRefs name the commit being merged in, by operation, when git has
+// not passed it in a GITHEAD_<commit> variable
+var theirRefs = []string{"MERGE_HEAD", "CHERRY_PICK_HEAD", "REBASE_HEAD", "REVERT_HEAD"}
+
+// mergeResult is the JSON output of "todo merge-driver"
+type mergeResult struct {
+	Renamed   map[string]string `json:"renamed"`
+	Conflicts []string          `json:"conflicts"`
+}
+
+// mergeDriver runs "todo merge-driver", the git merge driver for task.md.
+// Git calls it with the base, our and their versions in temporary files
+// and expects the result in our file, exiting non-zero on conflicts. It
+// also exits non-zero after writing the files of renumbered tasks,
+// which the merge commit would otherwise leave out.
+func (a *app) mergeDriver(ctx context.Context, args []string) error {
+	fs := a.flagSet("merge-driver")
+	positional, err := a.parse(fs, args, "merge-driver <base> <ours> <theirs> [path]", 3, 4)
+	if err != nil {
+		return err
+	}
+	basePath, oursPath, theirsPath := a.path(positional[0]), a.path(positional[1]), a.path(positional[2])
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	store, err := a.mergeStorage(ts, positional[3:])
+	if err != nil {
+		return err
+	}
+	if len(positional) == 4 {
+		switch filepath.Dir(a.path(positional[3])) {
+		case filepath.Dir(store.ContextFilePath("")):
+			return a.mergeContext(ctx, basePath, oursPath, theirsPath)
+		case filepath.Dir(store.TimestampsFilePath("")):
+			return mergeTimestamps(store, basePath, oursPath, theirsPath)
+		}
+	}
+
+	var versions [3][]byte
+	for i, path := range []string{basePath, oursPath, theirsPath} {
+		if versions[i], err = os.ReadFile(path); err != nil {
+			return errcode.WrapFS(err, errcode.FileReadError, "failed to read %s", path)
+		}
+	}
+	base, err := store.ParseTasks(string(versions[0]))
+	if err != nil {
+		return err
+	}
+	// The merged file keeps the style of our side
+	ours, style, err := store.ParseTasksWithStyle(string(versions[1]))
+	if err != nil {
+		return err
+	}
+	theirs, err := store.ParseTasks(string(versions[2]))
+	if err != nil {
+		return err
+	}
+	merged, err := merge.Tasks(base, ours, theirs, store.IDScheme(), store.Workflow())
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(oursPath, []byte(formatMerged(store, merged, style)), store.FilePerm()); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to write %s", oursPath)
+	}
+	result := mergeResult{Renamed: merged.Renamed, Conflicts: []string{}}
+	for _, c := range merged.Conflicts {
+		result.Conflicts = append(result.Conflicts, c.String())
+	}
+	var moved []string
+	for _, id := range sortedKeys(merged.Renamed) {
+		paths, err := a.moveTheirFiles(ctx, store, id, merged.Renamed[id])
+		if err != nil {
+			return err
+		}
+		moved = append(moved, paths...)
+	}
+
+	if err := a.output(result, func(w io.Writer) {
+		for _, id := range sortedKeys(merged.Renamed) {
+			fmt.Fprintf(w, "%s was added on both sides; their task is now %s\n", id, merged.Renamed[id])
+		}
+		for _, path := range moved {
+			fmt.Fprintf(w, "Wrote %s; add it to the merge with git add\n", path)
+		}
+	}); err != nil {
+		return err
+	}
+	if len(merged.Conflicts) > 0 {
+		return errcode.New(errcode.MergeConflict, "%d conflicting changes, marked in the file: %s",
+			len(merged.Conflicts), strings.Join(result.Conflicts, "; ")).WithDetails("conflicts", result.Conflicts)
+	}
+	if len(moved) > 0 {
+		// Git holds the index during the merge, so the files cannot be
+		// staged here; stop the merge so that they are not left out
+		return errcode.New(errcode.MergeConflict, "renumbered tasks have new files; add them with %s and commit",
+			"git add "+strings.Join(moved, " ")).WithDetails("files", moved)
+	}
+	return nil
+}
+
+// formatMerged formats the merged tasks in the given style, writing both
+// versions of each task with conflicts between git's conflict markers
+func formatMerged(store *storage.FileStorage, merged merge.Result, style parser.Style) string {
+	plain := style
+	plain.LineEnding, plain.BOM = "\n", false
+	content := store.FormatTasksWithStyle(merged.Tasks, plain)
+	for _, id := range slices.Sorted(maps.Keys(merged.Sides)) {
+		sides := merged.Sides[id]
+		marked := "<<<<<<< ours\n" + taskBlock(store, sides.Ours, plain) +
+			"=======\n" + taskBlock(store, sides.Theirs, plain) + ">>>>>>> theirs\n"
+		if start, end, ok := findTaskBlock(content, id); ok {
+			content = content[:start] + marked + content[end:]
+		}
+	}
+	return style.Finish(content)
+}
+
+// taskBlock returns the lines of a task and its notes and subtasks, or ""
+// for no task
+func taskBlock(store *storage.FileStorage, task *parser.ParsedTask, style parser.Style) string {
+	if task == nil {
+		return ""
+	}
+	formatted := store.FormatTasksWithStyle([]parser.ParsedTask{*task}, style)
+	// Skip the title and category headers
+	_, block, _ := strings.Cut(formatted, "\n## ")
+	_, block, _ = strings.Cut(block, "\n")
+	return strings.TrimSuffix(block, "\n")
+}
+
+// findTaskBlock returns the byte range of the lines of a task and its
+// notes and subtasks in formatted task.md content
+func findTaskBlock(content, id string) (start, end int, ok bool) {
+	lines := strings.SplitAfter(content, "\n")
+	offset := 0
+	for i, line := range lines {
+		if !ok && line != "" && line[0] != ' ' && line[0] != '\t' && strings.HasSuffix(line, " #"+id+"\n") {
+			start, ok = offset, true
+		} else if ok && (line == "" || (line[0] != ' ' && line[0] != '\t')) {
+			return start, offset, true
+		}
+		offset += len(lines[i])
+	}
+	return start, offset, ok
+}
+
+// mergeStorage returns the storage of the project whose task.md is at the
+// path git passed, or of the selected project
+func (a *app) mergeStorage(ts *mcp.ToolService, path []string) (*storage.FileStorage, error) {
+	if len(path) > 0 && a.project == "" {
+		target := a.path(path[0])
+		for _, p := range ts.Projects() {
+			store, err := ts.Storage(p.Name)
+			if err != nil {
+				return nil, err
+			}
+			if store.TaskFilePath() == target || filepath.Dir(store.ContextFilePath("")) == filepath.Dir(target) ||
+				filepath.Dir(store.TimestampsFilePath("")) == filepath.Dir(target) {
+				return store, nil
+			}
+		}
+	}
+	return ts.Storage(a.project)
+}
+
+// mergeContext merges a context file. When both sides added it, the task
+// on their side is renumbered by the task.md merge, which moves their
+// context file along, so our version is kept. Other changes are merged
+// line by line with git merge-file.
+func (a *app) mergeContext(ctx context.Context, basePath, oursPath, theirsPath string) error {
+	info, err := os.Stat(basePath)
+	if err != nil {
+		return errcode.WrapFS(err, errcode.FileReadError, "failed to read %s", basePath)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	repo, err := git.Open(ctx, filepath.Dir(oursPath))
+	if err != nil {
+		return err
+	}
+	_, err = repo.Run(ctx, "merge-file", "-L", "ours", "-L", "base", "-L", "theirs", oursPath, basePath, theirsPath)
+	if code := git.ExitCode(err); code > 0 {
+		return errcode.New(errcode.MergeConflict, "%d conflicts in %s", code, oursPath)
+	}
+	return err
+}
+
+// mergeTimestamps merges the timestamps file of a task. When both sides
+// added it, the task on their side is renumbered by the task.md merge,
+// which moves their file along, so our version is kept. Otherwise each
+// timestamp changed on one side only takes that side's value, and ours
+// wins when both changed it.
+func mergeTimestamps(store *storage.FileStorage, basePath, oursPath, theirsPath string) error {
+	var versions [3]model.Timestamps
+	for i, path := range []string{basePath, oursPath, theirsPath} {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return errcode.WrapFS(err, errcode.FileReadError, "failed to read %s", path)
+		}
+		if len(content) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(content, &versions[i]); err != nil {
+			return errcode.Wrap(err, errcode.ParseError, "failed to parse %s", path)
+		}
+	}
+	base, ours, theirs := versions[0], versions[1], versions[2]
+	if base.IsZero() {
+		return nil
+	}
+
+	merged := model.Timestamps{
+		CreatedAt:   mergeField(base.CreatedAt, ours.CreatedAt, theirs.CreatedAt),
+		StartedAt:   mergeField(base.StartedAt, ours.StartedAt, theirs.StartedAt),
+		CompletedAt: mergeField(base.CompletedAt, ours.CompletedAt, theirs.CompletedAt),
+	}
+	content, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return errcode.Wrap(err, errcode.FileWriteError, "failed to encode %s", oursPath)
+	}
+	if err := os.WriteFile(oursPath, append(content, '\n'), store.FilePerm()); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to write %s", oursPath)
+	}
+	return nil
+}
+
+// mergeField returns their value if only their side changed it from base,
+// and ours otherwise
+func mergeField(base, ours, theirs string) string {
+	if ours == base {
+		return theirs
+	}
+	return ours
+}
+
+// moveTheirFiles writes the context and timestamps files of a task
+// renumbered from oldID to newID with their versions of the old files, and
+// returns their paths. Files their side does not have are skipped, as are
+// all when git is not merging a commit.
+func (a *app) moveTheirFiles(ctx context.Context, store *storage.FileStorage, oldID, newID string) ([]string, error) {
+	repo, err := git.Open(ctx, store.DataDir())
+	if err != nil {
+		return nil, nil
+	}
+	var moved []string
+	if content := theirFile(ctx, repo, store.ContextFilePath(oldID)); content != "" {
+		idRegex := regexp.MustCompile(`\b` + regexp.QuoteMeta(oldID) + `\b`)
+		if err := store.WriteContextFile(model.Context{TaskID: newID, Content: idRegex.ReplaceAllString(content, newID)}); err != nil {
+			return nil, err
+		}
+		moved = append(moved, store.ContextFilePath(newID))
+	}
+	if content := theirFile(ctx, repo, store.TimestampsFilePath(oldID)); content != "" {
+		var t model.Timestamps
+		if err := json.Unmarshal([]byte(content), &t); err != nil {
+			return nil, errcode.Wrap(err, errcode.ParseError, "failed to parse their timestamps of %s", oldID)
+		}
+		if err := store.WriteTimestamps(newID, t); err != nil {
+			return nil, err
+		}
+		moved = append(moved, store.TimestampsFilePath(newID))
+	}
+	return moved, nil
+}
+
+// theirFile returns the content of the file at path in the commit being
+// merged in, "" if it has none
+func theirFile(ctx context.Context, repo *git.Repo, path string) string {
+	rel, err := repo.Rel(path)
+	if err != nil {
+		return ""
+	}
+	for _, ref := range theirCommits(ctx, repo) {
+		if out, err := repo.Run(ctx, "show", ref+":"+rel); err == nil {
+			return out
+		}
+	}
+	return ""
+}
+
+// theirCommits returns the candidates for the commit being merged in.
+// Git names the commits of a merge in GITHEAD_<commit> variables for its
+// merge drivers; ours is HEAD.
+func theirCommits(ctx context.Context, repo *git.Repo) []string {
+	head, _ := repo.Run(ctx, "rev-parse", "HEAD")
+	var commits []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if commit, ok := strings.CutPrefix(name, "GITHEAD_"); ok && commit != strings.TrimSpace(head) {
+			commits = append(commits, commit)
+		}
+	}
+	return append(commits, theirRefs...)
+}
+
+// path resolves a path given on the command line against -C
+func (a *app) path(p string) string {
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(a.dir, p)
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
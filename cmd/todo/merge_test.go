@@ -0,0 +1,223 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// asTodoEnv makes the test binary run as the todo command, so that git can call it
+const asTodoEnv = "TODO_TEST_RUN_AS_TODO"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(asTodoEnv) == "1" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+// gitRepo is a git repository for tests
+type gitRepo struct {
+	t   *testing.T
+	dir string
+}
+
+// newGitRepo initializes a repository, skipping the test if git is not installed
+func newGitRepo(t *testing.T) *gitRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	r := &gitRepo{t: t, dir: newWorkspace(t)}
+	r.git("init", "-q", "-b", "main")
+	r.git("config", "user.name", "Test")
+	r.git("config", "user.email", "test@example.com")
+	r.git("config", "commit.gpgsign", "false")
+	return r
+}
+
+// git runs git in the repository and returns its output
+func (r *gitRepo) git(args ...string) string {
+	r.t.Helper()
+	out, err := r.run(args...)
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return out
+}
+
+// run runs git in the repository and returns its output and error
+func (r *gitRepo) run(args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), asTodoEnv+"=1")
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+// write writes a file in the repository
+func (r *gitRepo) write(name, content string) {
+	r.t.Helper()
+	path := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// read reads a file in the repository
+func (r *gitRepo) read(name string) string {
+	r.t.Helper()
+	content, err := os.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return string(content)
+}
+
+// commit commits all changes
+func (r *gitRepo) commit(message string) {
+	r.t.Helper()
+	r.git("add", "-A")
+	r.git("commit", "-q", "-m", message)
+}
+
+func TestRun_MergeDriver(t *testing.T) {
+	r := newGitRepo(t)
+	self, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.git("config", "merge.todo.driver", self+" merge-driver %O %A %B %P")
+	r.write(".gitattributes", ".todo/task.md merge=todo\n.todo/context/*.md merge=todo\n.todo/meta/*.json merge=todo\n")
+	r.write(".todo/task.md", "# Task\n\n## Backend\n- [ ] Add login endpoint #T001\n\n")
+	r.write(".todo/context/T001.md", "# Context for T001\n")
+	r.write(".todo/meta/T001.json", "{\n  \"created_at\": \"2026-10-01T09:00:00Z\"\n}\n")
+	r.commit("base")
+
+	r.git("checkout", "-q", "-b", "feature")
+	r.write(".todo/task.md", "# Task\n\n## Backend\n- [-] Add login endpoint #T001\n- [ ] Write migrations #T002\n\n")
+	r.write(".todo/context/T002.md", "# Context for T002\n\nUse goose.\n")
+	r.write(".todo/meta/T001.json", "{\n  \"created_at\": \"2026-10-01T09:00:00Z\",\n  \"started_at\": \"2026-10-03T09:00:00Z\"\n}\n")
+	r.write(".todo/meta/T002.json", "{\n  \"created_at\": \"2026-10-02T09:00:00Z\"\n}\n")
+	r.commit("feature")
+
+	r.git("checkout", "-q", "main")
+	r.write(".todo/task.md", "# Task\n\n## Backend\n- [ ] Add login API #T001\n- [ ] Add logout endpoint #T002\n\n")
+	r.write(".todo/context/T002.md", "# Context for T002\n\nClear the session.\n")
+	r.write(".todo/meta/T001.json", "{\n  \"created_at\": \"2026-10-01T10:00:00Z\"\n}\n")
+	r.write(".todo/meta/T002.json", "{\n  \"created_at\": \"2026-10-02T10:00:00Z\"\n}\n")
+	r.commit("main")
+
+	// The merge stops for the files of the renumbered task to be added
+	out, err := r.run("merge", "--no-edit", "feature")
+	if err == nil || !strings.Contains(out, "their task is now T003") || !strings.Contains(out, "git add") {
+		t.Errorf("merge error = %v, output:\n%s", err, out)
+	}
+	status := r.git("status", "--porcelain")
+	for _, name := range []string{".todo/context/T003.md", ".todo/meta/T003.json"} {
+		if !strings.Contains(status, "?? "+name) {
+			t.Errorf("status after the merge = %q, want %s untracked", status, name)
+		}
+	}
+
+	want := map[string]string{
+		".todo/task.md": `# Task
+
+## Backend
+- [-] Add login API #T001
+- [ ] Add logout endpoint #T002
+- [ ] Write migrations #T003
+
+`,
+		".todo/context/T002.md": "# Context for T002\n\nClear the session.\n",
+		".todo/context/T003.md": "# Context for T003\n\nUse goose.\n",
+		".todo/meta/T001.json":  "{\n  \"created_at\": \"2026-10-01T10:00:00Z\",\n  \"started_at\": \"2026-10-03T09:00:00Z\"\n}\n",
+		".todo/meta/T002.json":  "{\n  \"created_at\": \"2026-10-02T10:00:00Z\"\n}\n",
+		".todo/meta/T003.json":  "{\n  \"created_at\": \"2026-10-02T09:00:00Z\"\n}\n",
+	}
+	got := map[string]string{}
+	for name := range want {
+		got[name] = r.read(name)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("merged files mismatch (-want +got):\n%s", diff)
+	}
+
+	r.git("add", ".todo")
+	r.git("commit", "-q", "--no-edit")
+	if status := r.git("status", "--porcelain"); status != "" {
+		t.Errorf("status after committing the merge = %q, want clean", status)
+	}
+
+	// Merges without renumbering complete on their own
+	r.git("checkout", "-q", "-b", "fix", "HEAD~1")
+	r.write(".todo/task.md", "# Task\n\n## Backend\n- [x] Add login API #T001\n- [ ] Add logout endpoint #T002\n\n")
+	r.commit("fix")
+	r.git("checkout", "-q", "main")
+	r.git("merge", "--no-edit", "fix")
+	if status := r.git("status", "--porcelain"); status != "" {
+		t.Errorf("status after a clean merge = %q, want clean", status)
+	}
+	if got := r.read(".todo/task.md"); !strings.Contains(got, "- [x] Add login API #T001\n") {
+		t.Errorf("task.md after a clean merge = %q", got)
+	}
+}
+
+func TestRun_MergeDriverConflict(t *testing.T) {
+	tests := []struct {
+		name   string
+		ours   string
+		theirs string
+		want   string
+	}{
+		{
+			name:   "title changed on both sides",
+			ours:   "## Backend\n- [ ] Add login API #T001\n  - [ ] Hash passwords\n- [ ] Set up database #T002\n",
+			theirs: "## Backend\n- [ ] Add sign-in endpoint #T001\n- [ ] Set up database #T002\n",
+			want: "# Task\n\n## Backend\n<<<<<<< ours\n- [ ] Add login API #T001\n  - [ ] Hash passwords\n=======\n" +
+				"- [ ] Add sign-in endpoint #T001\n  - [ ] Hash passwords\n>>>>>>> theirs\n- [ ] Set up database #T002\n\n",
+		},
+		{
+			name:   "changed on our side and deleted on theirs",
+			ours:   "## Backend\n- [x] Add login endpoint #T001\n- [ ] Set up database #T002\n",
+			theirs: "## Backend\n- [ ] Set up database #T002\n",
+			want: "# Task\n\n## Backend\n<<<<<<< ours\n- [x] Add login endpoint #T001\n=======\n>>>>>>> theirs\n" +
+				"- [ ] Set up database #T002\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := newWorkspace(t)
+			files := map[string]string{
+				"base":   "## Backend\n- [ ] Add login endpoint #T001\n- [ ] Set up database #T002\n",
+				"ours":   tt.ours,
+				"theirs": tt.theirs,
+			}
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			code, _, stderr := runTodo(t, dir, "", "merge-driver", "base", "ours", "theirs")
+			if code != exitError || !strings.Contains(stderr, "MERGE_CONFLICT") {
+				t.Errorf("merge-driver exit code = %d, stderr = %q", code, stderr)
+			}
+			content, err := os.ReadFile(filepath.Join(dir, "ours"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.want, string(content)); diff != "" {
+				t.Errorf("ours mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// subtask runs "todo subtask"
+func (a *app) subtask(ctx context.Context, args []string) error {
+	const synopsis = "subtask add|check|rename|move|rm ..."
+	if len(args) == 0 {
+		fmt.Fprintf(a.stderr, "Usage: todo %s\n", synopsis)
+		return errUsage
+	}
+	switch args[0] {
+	case "add":
+		return a.subtaskAdd(ctx, args[1:])
+	case "check":
+		return a.subtaskCheck(ctx, args[1:])
+	case "rename":
+		return a.subtaskRename(ctx, args[1:])
+	case "move":
+		return a.subtaskMove(ctx, args[1:])
+	case "rm":
+		return a.subtaskRemove(ctx, args[1:])
+	default:
+		fmt.Fprintf(a.stderr, "todo: unknown subtask command %q\nUsage: todo %s\n", args[0], synopsis)
+		return errUsage
+	}
+}
+
+// subtaskAdd runs "todo subtask add"
+func (a *app) subtaskAdd(ctx context.Context, args []string) error {
+	fs := a.flagSet("subtask add")
+	params := mcp.AddSubtaskParams{}
+	fs.StringVar(&params.Status, "status", "", "`status` of the subtask (default the first status of the workflow)")
+	fs.IntVar(&params.Position, "at", 0, "1-based `position` among the subtasks of the parent (default last)")
+	positional, err := a.parse(fs, args, "subtask add [--status status] [--at position] <task-id|path> <title>", 2, -1)
+	if err != nil {
+		return err
+	}
+	params.Parent = a.splitTaskID(positional[0])
+	params.Title = strings.Join(positional[1:], " ")
+	params.Project = a.project
+	return runSubtaskOp(ctx, a, "Added", params, (*mcp.ToolService).AddSubtask)
+}
+
+// subtaskCheck runs "todo subtask check"
+func (a *app) subtaskCheck(ctx context.Context, args []string) error {
+	fs := a.flagSet("subtask check")
+	params := mcp.CheckSubtaskParams{}
+	fs.StringVar(&params.Status, "status", "", "new `status` (default done)")
+	positional, err := a.parse(fs, args, "subtask check [--status status] <path>", 1, 1)
+	if err != nil {
+		return err
+	}
+	params.Path = a.splitTaskID(positional[0])
+	params.Project = a.project
+	return runSubtaskOp(ctx, a, "Checked", params, (*mcp.ToolService).CheckSubtask)
+}
+
+// subtaskRename runs "todo subtask rename"
+func (a *app) subtaskRename(ctx context.Context, args []string) error {
+	fs := a.flagSet("subtask rename")
+	positional, err := a.parse(fs, args, "subtask rename <path> <title>", 2, -1)
+	if err != nil {
+		return err
+	}
+	params := mcp.RenameSubtaskParams{
+		Path:    a.splitTaskID(positional[0]),
+		Title:   strings.Join(positional[1:], " "),
+		Project: a.project,
+	}
+	return runSubtaskOp(ctx, a, "Renamed", params, (*mcp.ToolService).RenameSubtask)
+}
+
+// subtaskMove runs "todo subtask move"
+func (a *app) subtaskMove(ctx context.Context, args []string) error {
+	fs := a.flagSet("subtask move")
+	params := mcp.MoveSubtaskParams{}
+	fs.StringVar(&params.Parent, "to", "", "task ID or subtask `path` to move it under (default its current parent)")
+	fs.IntVar(&params.Position, "at", 0, "1-based `position` among the subtasks of the parent after the move (default last)")
+	positional, err := a.parse(fs, args, "subtask move [--to task-id|path] [--at position] <path>", 1, 1)
+	if err != nil {
+		return err
+	}
+	params.Path = a.splitTaskID(positional[0])
+	params.Project = a.project
+	return runSubtaskOp(ctx, a, "Moved", params, (*mcp.ToolService).MoveSubtask)
+}
+
+// subtaskRemove runs "todo subtask rm"
+func (a *app) subtaskRemove(ctx context.Context, args []string) error {
+	fs := a.flagSet("subtask rm")
+	positional, err := a.parse(fs, args, "subtask rm <path>", 1, 1)
+	if err != nil {
+		return err
+	}
+	params := mcp.RemoveSubtaskParams{Path: a.splitTaskID(positional[0]), Project: a.project}
+	return runSubtaskOp(ctx, a, "Removed", params, (*mcp.ToolService).RemoveSubtask)
+}
+
+// runSubtaskOp runs a subtask operation and prints its result
+func runSubtaskOp[In any](
+	ctx context.Context, a *app, verb string, params In, op func(*mcp.ToolService, context.Context, In) (mcp.SubtaskResult, error),
+) error {
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := op(ts, ctx, params)
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		path := a.taskID(result.Project, result.Path)
+		if !result.Changed {
+			fmt.Fprintf(w, "Subtask %s is unchanged: [%s] %s\n", path, result.Status, result.Title)
+			return
+		}
+		fmt.Fprintf(w, "%s subtask %s: [%s] %s\n", verb, path, result.Status, result.Title)
+		a.printAutoTransitions(w, result.Project, result.AutoTransitions)
+	})
+}
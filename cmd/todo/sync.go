@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// sync runs "todo sync"
+func (a *app) sync(ctx context.Context, args []string) error {
+	fs := a.flagSet("sync")
+	dryRun := fs.Bool("dry-run", false, "print the changes without making them")
+	since := fs.String("since", "", "scan the commits after this `commit` instead of the last scanned one")
+	if _, err := a.parse(fs, args, "sync [--dry-run] [--since commit]", 0, 0); err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.SyncCommits(ctx, mcp.SyncCommitsParams{Since: *since, DryRun: *dryRun, Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		verb := "Updated"
+		if result.DryRun {
+			verb = "Would update"
+		}
+		for _, ref := range result.References {
+			change := ref.NewStatus
+			if ref.NewStatus != ref.OldStatus {
+				change = ref.OldStatus + " -> " + ref.NewStatus
+			}
+			fmt.Fprintf(w, "%s %s (%s): %s %s\n", verb, a.taskID(result.Project, ref.TaskID), change, ref.Commit, ref.Subject)
+		}
+		if len(result.UnknownTaskIDs) > 0 {
+			fmt.Fprintf(w, "Unknown tasks: %s\n", strings.Join(result.UnknownTaskIDs, ", "))
+		}
+		fmt.Fprintf(w, "Scanned %d commits\n", result.ScannedCommits)
+	})
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRun_Sync(t *testing.T) {
+	r := newGitRepo(t)
+	r.write(".todo/task.md", "# Task\n\n## Backend\n- [ ] Add login endpoint #T001\n- [ ] Add logout endpoint #T002\n")
+	r.commit("Add tasks")
+	r.write("main.go", "package main\n")
+	r.commit("Add login endpoint\n\nCloses #T001, refs #T002")
+	hash := strings.TrimSpace(r.git("rev-parse", "--short=7", "HEAD"))
+
+	code, stdout, stderr := runTodo(t, r.dir, "", "sync", "--dry-run")
+	want := "Would update T001 (todo -> done): " + hash + " Add login endpoint\n" +
+		"Would update T002 (todo -> in_progress): " + hash + " Add login endpoint\n" +
+		"Scanned 2 commits\n"
+	if code != exitOK || stdout != want {
+		t.Errorf("sync --dry-run = %d, %q, %q; want %q", code, stdout, stderr, want)
+	}
+	if strings.Contains(r.read(".todo/task.md"), "[x]") {
+		t.Error("dry run changed task.md")
+	}
+
+	if code, _, stderr := runTodo(t, r.dir, "", "sync"); code != exitOK {
+		t.Fatalf("sync = %d, %q", code, stderr)
+	}
+	if got := r.read(".todo/task.md"); !strings.Contains(got, "- [x] Add login endpoint #T001") || !strings.Contains(got, "- [-] Add logout endpoint #T002") {
+		t.Errorf("task.md after sync:\n%s", got)
+	}
+
+	code, stdout, _ = runTodo(t, r.dir, "", "sync")
+	if code != exitOK || stdout != "Scanned 0 commits\n" {
+		t.Errorf("second sync = %d, %q", code, stdout)
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// metadataFilter holds the metadata filter flags of "todo list" and "todo search"
+type metadataFilter struct {
+	assignee, tag, priority, dueBefore *string
+}
+
+// newMetadataFilter defines the metadata filter flags on fs
+func newMetadataFilter(fs *flag.FlagSet) metadataFilter {
+	return metadataFilter{
+		assignee:  fs.String("assignee", "", "only tasks assigned to `name`"),
+		tag:       fs.String("tag", "", "only tasks with this `tag`"),
+		priority:  fs.String("priority", "", "only tasks with this priority `label`: high, medium or low"),
+		dueBefore: fs.String("due-before", "", "only tasks due on or before this `date` (YYYY-MM-DD)"),
+	}
+}
+
+// create runs "todo create"
+func (a *app) create(ctx context.Context, args []string) error {
+	fs := a.flagSet("create")
+	category := fs.String("c", "", "task `category`")
+	description := fs.String("d", "", "task `description` written to the context file")
+	due := fs.String("due", "", "due `date` (YYYY-MM-DD)")
+	priority := fs.String("priority", "", "priority `label`: high, medium or low")
+	assignee := fs.String("assignee", "", "`name` of the person the task is assigned to")
+	var subtasks, tags stringList
+	fs.Var(&subtasks, "s", "subtask `title` (repeatable)")
+	fs.Var(&tags, "tag", "`tag` (repeatable)")
+	positional, err := a.parse(fs, args,
+		"create [-c category] [-d description] [-s subtask]... [--due date] [--priority label] [--assignee name] [--tag tag]... <title>", 1, -1)
+	if err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.CreateTask(ctx, mcp.CreateTaskParams{
+		Title:         strings.Join(positional, " "),
+		Category:      *category,
+		Description:   *description,
+		Subtasks:      subtasks,
+		Due:           *due,
+		PriorityLabel: *priority,
+		Assignee:      *assignee,
+		Tags:          tags,
+		Project:       a.project,
+	})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Created %s %s (%s)\n", a.taskID(result.Project, result.TaskID), result.Title, result.Category)
+	})
+}
+
+// update runs "todo update"
+func (a *app) update(ctx context.Context, args []string) error {
+	fs := a.flagSet("update")
+	title := fs.String("title", "", "new `title`")
+	status := fs.String("status", "", "new `status` of the project's workflow, by default todo, in_progress, done, blocked or cancelled")
+	reason := fs.String("reason", "", "why the task is blocked or cancelled")
+	category := fs.String("c", "", "new `category`; the task moves to the end of it")
+	due := fs.String("due", "", "new due `date` (YYYY-MM-DD)")
+	priority := fs.String("priority", "", "new priority `label`: high, medium or low")
+	assignee := fs.String("assignee", "", "`name` of the person the task is assigned to")
+	notes := fs.String("notes", "", "new `notes`, kept as lines indented under the task")
+	clearFields := fs.String("clear", "", "comma-separated `fields` to remove: due, priority_label, assignee, tags, notes")
+	var subtasks, tags stringList
+	fs.Var(&subtasks, "s", "subtask replacing the current ones, optionally prefixed with a checkbox such as [x] (repeatable)")
+	fs.Var(&tags, "tag", "`tag` replacing the current ones (repeatable)")
+	positional, err := a.parse(fs, args, "update [--title title] [--status status] [--reason reason] [-c category] [-s subtask]... "+
+		"[--due date] [--priority label] [--assignee name] [--tag tag]... [--notes notes] [--clear fields] <task-id>", 1, 1)
+	if err != nil {
+		return err
+	}
+
+	params := mcp.UpdateTaskParams{
+		TaskID:        a.splitTaskID(positional[0]),
+		Title:         *title,
+		Status:        *status,
+		Reason:        *reason,
+		Category:      *category,
+		Due:           *due,
+		PriorityLabel: *priority,
+		Assignee:      *assignee,
+		Tags:          tags,
+		Notes:         *notes,
+		Project:       a.project,
+	}
+	if *clearFields != "" {
+		params.Clear = strings.Split(*clearFields, ",")
+	}
+	if len(subtasks) > 0 {
+		workflow, err := a.workflow()
+		if err != nil {
+			return err
+		}
+		for _, s := range subtasks {
+			params.Subtasks = append(params.Subtasks, parseSubtask(s, workflow))
+		}
+	}
+	return a.runUpdate(ctx, params)
+}
+
+// done runs "todo done"
+func (a *app) done(ctx context.Context, args []string) error {
+	fs := a.flagSet("done")
+	positional, err := a.parse(fs, args, "done <task-id>", 1, 1)
+	if err != nil {
+		return err
+	}
+	taskID := a.splitTaskID(positional[0])
+	workflow, err := a.workflow()
+	if err != nil {
+		return err
+	}
+	return a.runUpdate(ctx, mcp.UpdateTaskParams{
+		TaskID:  taskID,
+		Status:  workflow.StageStatus(model.StageDone),
+		Project: a.project,
+	})
+}
+
+// block runs "todo block"
+func (a *app) block(ctx context.Context, args []string) error {
+	return a.setStatusWithReason(ctx, "block", model.StatusBlocked, args)
+}
+
+// cancel runs "todo cancel"
+func (a *app) cancel(ctx context.Context, args []string) error {
+	return a.setStatusWithReason(ctx, "cancel", model.StatusCancelled, args)
+}
+
+// setStatusWithReason moves a task to a status that needs a reason, given
+// as the words after the task ID
+func (a *app) setStatusWithReason(ctx context.Context, name, status string, args []string) error {
+	fs := a.flagSet(name)
+	positional, err := a.parse(fs, args, name+" <task-id> <reason>", 2, -1)
+	if err != nil {
+		return err
+	}
+	return a.runUpdate(ctx, mcp.UpdateTaskParams{
+		TaskID:  a.splitTaskID(positional[0]),
+		Status:  status,
+		Reason:  strings.Join(positional[1:], " "),
+		Project: a.project,
+	})
+}
+
+// runUpdate updates a task and prints the result
+func (a *app) runUpdate(ctx context.Context, params mcp.UpdateTaskParams) error {
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.UpdateTask(ctx, params)
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		id := a.taskID(result.Project, result.TaskID)
+		if len(result.UpdatedFields) == 0 {
+			fmt.Fprintf(w, "%s is unchanged\n", id)
+			return
+		}
+		fmt.Fprintf(w, "Updated %s: %s\n", id, strings.Join(result.UpdatedFields, ", "))
+		a.printAutoTransitions(w, result.Project, result.AutoTransitions)
+	})
+}
+
+// printAutoTransitions prints the status changes made by the rollup rules
+func (a *app) printAutoTransitions(w io.Writer, project string, transitions []mcp.AutoTransition) {
+	for _, t := range transitions {
+		fmt.Fprintf(w, "%s moved automatically: %s -> %s\n", a.taskID(project, t.Path), t.From, t.To)
+	}
+}
+
+// workflow returns the workflow of the selected project
+func (a *app) workflow() (model.Workflow, error) {
+	ts, err := a.toolService()
+	if err != nil {
+		return model.Workflow{}, err
+	}
+	store, err := ts.Storage(a.project)
+	if err != nil {
+		return model.Workflow{}, err
+	}
+	return store.Workflow(), nil
+}
+
+// parseSubtask reads a --subtask value such as "[x] Write tests", whose
+// checkbox is one of workflow
+func parseSubtask(s string, workflow model.Workflow) mcp.SubtaskInput {
+	for _, status := range workflow.Statuses {
+		if title, ok := strings.CutPrefix(s, "["+status.Marker+"] "); ok {
+			return mcp.SubtaskInput{Title: title, Status: status.Name}
+		}
+	}
+	return mcp.SubtaskInput{Title: s}
+}
+
+// list runs "todo list"
+func (a *app) list(ctx context.Context, args []string) error {
+	fs := a.flagSet("list")
+	status := fs.String("status", "", "only tasks with this `status`")
+	category := fs.String("c", "", "only tasks in this `category`")
+	filter := newMetadataFilter(fs)
+	long := fs.Bool("l", false, "show when each task was created, started and completed")
+	limit := fs.Int("n", 0, "show at most `n` tasks (default 50)")
+	if _, err := a.parse(fs, args, "list [--status status] [-c category] [--assignee name] [--tag tag] [--priority label] "+
+		"[--due-before date] [-l] [-n limit]", 0, 0); err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.ListTasks(ctx, mcp.ListTasksParams{
+		Status:        *status,
+		Category:      *category,
+		Assignee:      *filter.assignee,
+		Tag:           *filter.tag,
+		PriorityLabel: *filter.priority,
+		DueBefore:     *filter.dueBefore,
+		Project:       a.project,
+		Limit:         *limit,
+	})
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if result.TotalCount == 0 {
+			fmt.Fprintln(w, "No tasks found")
+			return
+		}
+		rows := make([][]string, 0, len(result.Tasks))
+		for _, task := range result.Tasks {
+			title := task.Title
+			if metadata := task.Metadata(); metadata != "" {
+				title += " " + metadata
+			}
+			subtasks := strconv.Itoa(task.SubtasksCount)
+			if task.SubtasksCount > 0 {
+				subtasks = fmt.Sprintf("%d/%d", task.SubtasksDone, task.SubtasksCount)
+			}
+			row := []string{a.taskID(task.Project, task.TaskID), task.Status, task.Category, title, subtasks}
+			if *long {
+				row = append(row, localTime(task.CreatedAt), localTime(task.StartedAt), localTime(task.CompletedAt))
+			}
+			rows = append(rows, row)
+		}
+		headers := []string{"ID", "STATUS", "CATEGORY", "TITLE", "SUBTASKS"}
+		if *long {
+			headers = append(headers, "CREATED", "STARTED", "COMPLETED")
+		}
+		table(w, headers, rows)
+		if len(result.Tasks) < result.TotalCount {
+			fmt.Fprintf(w, "(showing %d of %d)\n", len(result.Tasks), result.TotalCount)
+		}
+	})
+}
+
+// search runs "todo search"
+func (a *app) search(ctx context.Context, args []string) error {
+	fs := a.flagSet("search")
+	in := fs.String("in", "", "comma-separated `fields` to search: title, content, context (default title,content)")
+	filter := newMetadataFilter(fs)
+	archived := fs.Bool("archived", false, "also search archived tasks")
+	limit := fs.Int("n", 0, "show at most `n` results (default 20)")
+	positional, err := a.parse(fs, args, "search [--in fields] [--assignee name] [--tag tag] [--priority label] "+
+		"[--due-before date] [--archived] [-n limit] <query>", 1, -1)
+	if err != nil {
+		return err
+	}
+
+	params := mcp.SearchTasksParams{
+		Query:         strings.Join(positional, " "),
+		Assignee:      *filter.assignee,
+		Tag:           *filter.tag,
+		PriorityLabel: *filter.priority,
+		DueBefore:     *filter.dueBefore,
+		Archived:      *archived,
+		Project:       a.project,
+		Limit:         *limit,
+	}
+	if *in != "" {
+		params.SearchIn = strings.Split(*in, ",")
+	}
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	result, err := ts.SearchTasks(ctx, params)
+	if err != nil {
+		return err
+	}
+	return a.output(result, func(w io.Writer) {
+		if result.TotalMatches == 0 {
+			fmt.Fprintf(w, "No tasks match %q\n", params.Query)
+			return
+		}
+		header := []string{"ID", "STATUS", "SCORE", "TITLE", "MATCH"}
+		if *archived {
+			header = append(header, "ARCHIVE")
+		}
+		rows := make([][]string, 0, len(result.Results))
+		for _, r := range result.Results {
+			row := []string{
+				a.taskID(r.Project, r.TaskID), r.Status, strconv.FormatFloat(r.MatchScore, 'f', 1, 64), r.Title, r.MatchedContent,
+			}
+			if *archived {
+				row = append(row, r.Archive)
+			}
+			rows = append(rows, row)
+		}
+		table(w, header, rows)
+	})
+}
+
+// show runs "todo show"
+func (a *app) show(ctx context.Context, args []string) error {
+	fs := a.flagSet("show")
+	positional, err := a.parse(fs, args, "show <task-id>", 1, 1)
+	if err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	detail, err := ts.GetTask(ctx, mcp.GetTaskParams{TaskID: a.splitTaskID(positional[0]), Project: a.project})
+	if err != nil {
+		return err
+	}
+	return a.output(detail, func(w io.Writer) {
+		fmt.Fprintf(w, "%s  %s\n", a.taskID(detail.Project, detail.TaskID), detail.Title)
+		fmt.Fprintf(w, "Status:    %s\nCategory:  %s (priority %d)\n", detail.Status, detail.Category, detail.Priority)
+		for _, field := range []struct{ name, value string }{
+			{"Reason", detail.StatusReason},
+			{"Due", detail.Due},
+			{"Priority", detail.PriorityLabel},
+			{"Assignee", detail.Assignee},
+			{"Tags", strings.Join(detail.Tags, ", ")},
+			{"Created", localTime(detail.CreatedAt)},
+			{"Started", localTime(detail.StartedAt)},
+			{"Completed", localTime(detail.CompletedAt)},
+		} {
+			if field.value != "" {
+				fmt.Fprintf(w, "%-10s %s\n", field.name+":", field.value)
+			}
+		}
+		if detail.Notes != "" {
+			fmt.Fprintln(w, "Notes:")
+			printNotes(w, detail.Notes, "  ")
+		}
+		if len(detail.Subtasks) > 0 {
+			fmt.Fprintln(w, "Subtasks:")
+			for _, s := range detail.Subtasks {
+				indent := strings.Repeat("  ", strings.Count(s.Path, "."))
+				fmt.Fprintf(w, "%s[%s] %s %s\n", indent, s.Status, s.Path, s.Title)
+				printNotes(w, s.Notes, indent+"  ")
+			}
+		}
+		if detail.Context != "" {
+			fmt.Fprintf(w, "\n%s\n", strings.TrimRight(detail.Context, "\n"))
+		}
+	})
+}
+
+// printNotes prints the lines of notes with the given indentation
+func printNotes(w io.Writer, notes, indent string) {
+	for _, line := range model.NoteLines(notes) {
+		fmt.Fprintln(w, indent+line)
+	}
+}
+
+// localTime renders an RFC 3339 timestamp in the local time zone to the
+// minute; unparsable timestamps are shown as they are
+func localTime(stamp string) string {
+	t, err := time.Parse(time.RFC3339, stamp)
+	if err != nil {
+		return stamp
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
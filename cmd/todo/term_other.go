@@ -0,0 +1,22 @@
+//go:build !unix
+
+package main
+
+import (
+	"os"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// terminal is not available on this platform
+type terminal struct{}
+
+// openTerminal reports that the terminal UI is not supported
+func openTerminal(*os.File) (*terminal, error) {
+	return nil, errcode.New(errcode.Internal, "the terminal UI is not supported on this platform")
+}
+
+func (t *terminal) raw() error                { return nil }
+func (t *terminal) restore() error            { return nil }
+func (t *terminal) size() (width, height int) { return 80, 24 }
+func (t *terminal) resized() <-chan os.Signal { return nil }
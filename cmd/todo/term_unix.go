@@ -0,0 +1,76 @@
+//go:build unix
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// terminal switches a terminal between its own settings and the
+// unbuffered, silent input the board reads keys from. It drives stty so
+// that no terminal library is needed.
+type terminal struct {
+	in    *os.File
+	saved string
+}
+
+// openTerminal puts the terminal on in into key-at-a-time mode
+func openTerminal(in *os.File) (*terminal, error) {
+	if info, err := in.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil, errcode.New(errcode.Internal, "the terminal UI needs a terminal on standard input")
+	}
+	saved, err := stty(in, "-g")
+	if err != nil {
+		return nil, err
+	}
+	t := &terminal{in: in, saved: strings.TrimSpace(saved)}
+	return t, t.raw()
+}
+
+// raw turns off line buffering, echo and signal keys
+func (t *terminal) raw() error {
+	_, err := stty(t.in, "-icanon", "-echo", "-isig", "min", "1", "time", "0")
+	return err
+}
+
+// restore brings back the settings the terminal had when it was opened
+func (t *terminal) restore() error {
+	_, err := stty(t.in, t.saved)
+	return err
+}
+
+// size returns the number of columns and rows of the terminal
+func (t *terminal) size() (width, height int) {
+	out, err := stty(t.in, "size")
+	if err == nil {
+		if _, err := fmt.Sscan(out, &height, &width); err == nil && width > 0 && height > 0 {
+			return width, height
+		}
+	}
+	return 80, 24
+}
+
+// resized returns a channel notified when the terminal window changes size
+func (t *terminal) resized() <-chan os.Signal {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGWINCH)
+	return ch
+}
+
+// stty runs stty on the terminal
+func stty(in *os.File, args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errcode.Wrap(err, errcode.Internal, "stty %s failed", strings.Join(args, " "))
+	}
+	return string(out), nil
+}
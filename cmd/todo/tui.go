@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/mcp"
+)
+
+// ANSI escape sequences for taking over the screen
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+	clearScreen = "\x1b[H\x1b[2J"
+)
+
+// defaultEditor is used when $EDITOR is not set
+const defaultEditor = "vi"
+
+// fileStamp identifies a version of a file for change detection
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFile returns the stamp of a file; a missing file has the zero stamp
+func statFile(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// tui runs "todo tui"
+func (a *app) tui(ctx context.Context, args []string) error {
+	fs := a.flagSet("tui")
+	interval := fs.Duration("interval", time.Second, "how often to check task.md for changes")
+	if _, err := a.parse(fs, args, "tui [--interval duration]", 0, 0); err != nil {
+		return err
+	}
+
+	ts, err := a.toolService()
+	if err != nil {
+		return err
+	}
+	b := newBoard()
+	file, err := ts.ReadTaskFile(a.project)
+	if err != nil {
+		return err
+	}
+	b.setFile(file)
+
+	in, ok := a.stdin.(*os.File)
+	if !ok {
+		return errcode.New(errcode.Internal, "the terminal UI needs a terminal on standard input")
+	}
+	term, err := openTerminal(in)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(a.stdout, enterScreen)
+	defer func() {
+		fmt.Fprint(a.stdout, leaveScreen)
+		_ = term.restore()
+	}()
+
+	s := &session{app: a, ts: ts, board: b, term: term, in: in, stamp: statFile(file.Path)}
+	return s.loop(ctx, *interval)
+}
+
+// session is a running terminal UI
+type session struct {
+	app   *app
+	ts    *mcp.ToolService
+	board *board
+	term  *terminal
+	in    *os.File
+	stamp fileStamp
+}
+
+// loop draws the board and handles keys, file changes and resizes until the user quits
+func (s *session) loop(ctx context.Context, interval time.Duration) error {
+	keys := make(chan []string)
+	resume := make(chan struct{})
+	go readKeys(s.in, keys, resume)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	resized := s.term.resized()
+
+	for {
+		s.draw()
+		select {
+		case <-ctx.Done():
+			return nil
+		case batch, ok := <-keys:
+			if !ok {
+				return nil
+			}
+			for _, key := range batch {
+				if quit := s.handle(ctx, key); quit {
+					return nil
+				}
+			}
+			resume <- struct{}{}
+		case <-ticker.C:
+			if statFile(s.board.file.Path) != s.stamp {
+				s.reload()
+			}
+		case <-resized:
+		}
+	}
+}
+
+// readKeys reads terminal input and sends it as keys. After each batch it
+// waits on resume, so that no input is taken while an editor runs.
+func readKeys(in io.Reader, keys chan<- []string, resume <-chan struct{}) {
+	defer close(keys)
+	buf := make([]byte, 64)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			return
+		}
+		keys <- parseKeys(buf[:n])
+		<-resume
+	}
+}
+
+// handle applies one key and reports whether the user quit
+func (s *session) handle(ctx context.Context, key string) bool {
+	action := s.board.handleKey(key)
+	var err error
+	switch {
+	case action.quit:
+		return true
+	case action.reload:
+		s.reload()
+		return false
+	case action.update != nil:
+		_, err = s.ts.UpdateTask(ctx, *action.update)
+	case action.reorder != nil:
+		_, err = s.ts.ReorderTask(ctx, *action.reorder)
+	case action.check != nil:
+		_, err = s.ts.CheckSubtask(ctx, *action.check)
+	case action.edit != "":
+		err = s.edit(ctx, action.edit)
+	default:
+		return false
+	}
+
+	message := s.board.message
+	s.reload()
+	if err != nil {
+		message = errcode.As(err).Error()
+	}
+	s.board.message = message
+	return false
+}
+
+// reload reads task.md again
+func (s *session) reload() {
+	s.stamp = statFile(s.board.file.Path)
+	file, err := s.ts.ReadTaskFile(s.board.file.Project)
+	if err != nil {
+		s.board.message = errcode.As(err).Error()
+		return
+	}
+	s.board.setFile(file)
+}
+
+// edit opens the context file of a task in $EDITOR, creating it if needed
+func (s *session) edit(ctx context.Context, taskID string) error {
+	project := s.board.file.Project
+	detail, err := s.ts.GetTask(ctx, mcp.GetTaskParams{TaskID: taskID, Project: project})
+	if err != nil {
+		return err
+	}
+	if statFile(detail.ContextFile) == (fileStamp{}) {
+		if _, err := s.ts.UpdateContext(ctx, mcp.UpdateContextParams{
+			TaskID:  taskID,
+			Content: fmt.Sprintf("# Context for %s\n", taskID),
+			Project: project,
+		}); err != nil {
+			return err
+		}
+	}
+
+	value, _ := s.app.lookupEnv("EDITOR")
+	editor := strings.Fields(value)
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+	fmt.Fprint(s.app.stdout, leaveScreen)
+	if err := s.term.restore(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = s.term.raw()
+		fmt.Fprint(s.app.stdout, enterScreen)
+	}()
+
+	cmd := exec.CommandContext(ctx, editor[0], append(editor[1:], detail.ContextFile)...)
+	cmd.Stdin = s.in
+	cmd.Stdout = s.app.stdout
+	cmd.Stderr = s.app.stderr
+	if err := cmd.Run(); err != nil {
+		return errcode.Wrap(err, errcode.Internal, "editor %s failed", editor[0])
+	}
+	return nil
+}
+
+// draw renders the board to the whole screen
+func (s *session) draw() {
+	width, height := s.term.size()
+	lines := s.board.render(width, height)
+	fmt.Fprint(s.app.stdout, clearScreen+strings.Join(lines, "\r\n"))
+}
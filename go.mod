@@ -3,7 +3,8 @@ module github.com/jnst/agentic-todo-mcp
 go 1.24.3
 
 require (
-	github.com/google/go-cmp v0.7.0 // indirect
-	github.com/modelcontextprotocol/go-sdk v0.1.0 // indirect
-	go.uber.org/mock v0.5.2 // indirect
+	github.com/google/go-cmp v0.7.0
+	github.com/modelcontextprotocol/go-sdk v0.1.0
 )
+
+require go.uber.org/mock v0.5.2 // indirect
@@ -0,0 +1,212 @@
+// Package comments finds TODO, FIXME and HACK comments in the source files
+// of a project so they can be tracked as tasks.
+package comments
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/git"
+)
+
+// Comment kinds
+const (
+	KindTodo  = "TODO"
+	KindFixme = "FIXME"
+	KindHack  = "HACK"
+)
+
+// maxFileSize is the size of the largest file searched for comments
+const maxFileSize = 1 << 20
+
+// fingerprintLength is the number of hex digits in a fingerprint
+const fingerprintLength = 12
+
+// skipDirs are directories never searched outside a git repository
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Comment is one TODO, FIXME or HACK comment
+type Comment struct {
+	// Kind is TODO, FIXME or HACK
+	Kind string
+	// Text is the comment after the keyword, empty if there is none
+	Text string
+	// File is the path of the file, relative to the scanned directory with forward slashes
+	File string
+	// Line is the 1-based line number
+	Line int
+	// Fingerprint identifies the comment across scans. It depends on the
+	// file, kind and text but not the line, so moving a comment within its
+	// file keeps it.
+	Fingerprint string
+}
+
+// Scan returns the comments in the files below dir, in file and line order.
+// Inside a git working tree the files are those git tracks or would track,
+// so .gitignore is respected; otherwise hidden directories, node_modules and
+// vendor are skipped. Files below the exclude directories, binary files and
+// files larger than 1 MiB are not searched.
+func Scan(ctx context.Context, dir string, exclude []string) ([]Comment, error) {
+	files, err := listFiles(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []Comment
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if slices.ContainsFunc(exclude, func(ex string) bool { return within(ex, path) }) {
+			continue
+		}
+		found, err := scanFile(path, file)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, found...)
+	}
+	return comments, nil
+}
+
+// listFiles returns the files below dir relative to it, in lexical order
+func listFiles(ctx context.Context, dir string) ([]string, error) {
+	repo, err := git.Open(ctx, dir)
+	if err != nil {
+		return walkFiles(dir)
+	}
+	rel, err := repo.Rel(dir)
+	if err != nil {
+		return nil, err
+	}
+	out, err := repo.Run(ctx, "ls-files", "-z", "--cached", "--others", "--exclude-standard", "--", rel)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, file := range strings.Split(out, "\x00") {
+		if file == "" {
+			continue
+		}
+		if rel != "." {
+			file = strings.TrimPrefix(file, rel+"/")
+		}
+		if !slices.Contains(files, file) {
+			files = append(files, file)
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// walkFiles returns the files below dir relative to it, skipping hidden
+// directories, node_modules and vendor
+func walkFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to list files in %s", dir)
+	}
+	return files, nil
+}
+
+// scanFile returns the comments in one file, after the comment markers of
+// its language outside strings. Missing, binary and large files have none.
+func scanFile(path, name string) ([]Comment, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		// Deleted from the working tree but still in the index
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read %s", name)
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxFileSize {
+		return nil, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read %s", name)
+	}
+	if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+		return nil, nil
+	}
+
+	var comments []Comment
+	seen := make(map[string]int)
+	syntax := syntaxOf(name)
+	var state lineState
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, maxFileSize)
+	for line := 1; scanner.Scan(); line++ {
+		matches := syntax.match(scanner.Text(), &state)
+		if matches == nil {
+			continue
+		}
+		c := Comment{Kind: matches[1], Text: cleanText(matches[2]), File: name, Line: line}
+		// Identical comments in one file are told apart by their order
+		key := c.Kind + "\x00" + c.Text
+		c.Fingerprint = fingerprint(name, key, seen[key])
+		seen[key]++
+		comments = append(comments, c)
+	}
+	if err := scanner.Err(); err != nil {
+		// Lines too long to be code
+		return nil, nil
+	}
+	return comments, nil
+}
+
+// cleanText trims a comment's text and the end of a block comment
+func cleanText(text string) string {
+	text = strings.TrimSpace(text)
+	for _, end := range []string{"*/", "-->"} {
+		text = strings.TrimSpace(strings.TrimSuffix(text, end))
+	}
+	return strings.TrimSpace(strings.TrimLeft(text, ":-"))
+}
+
+// fingerprint returns the fingerprint of the n-th comment with key in file
+func fingerprint(file, key string, n int) string {
+	h := sha256.New()
+	h.Write([]byte(file + "\x00" + key))
+	if n > 0 {
+		h.Write([]byte{0, byte(n), byte(n >> 8)})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:fingerprintLength]
+}
+
+// within reports whether path is dir or below it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
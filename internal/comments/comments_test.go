@@ -0,0 +1,164 @@
+package comments
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+// writeFiles writes files below dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"main.go": `package main
+
+// TODO: handle signals
+func main() {
+	s := "TODO: not a comment"
+	_ = s // FIXME(alice): check the error
+}
+`,
+		"lib/db.py":             "# HACK retry until the pool is ready\n# TODOS are fine\n",
+		"lib/query.sql":         "-- TODO add an index\n/* TODO: vacuum */\n",
+		"web/index.html":        "<!-- TODO: dark mode -->\n",
+		".todo/task.md":         "# TODO list\n",
+		"node_modules/x/a.js":   "// TODO: not ours\n",
+		"excluded/generated.go": "// TODO: generated\n",
+		"image.png":             "\x89PNG\x00// TODO: binary\n",
+	})
+
+	got, err := Scan(context.Background(), dir, []string{filepath.Join(dir, "excluded")})
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []Comment{
+		{Kind: KindHack, Text: "retry until the pool is ready", File: "lib/db.py", Line: 1},
+		{Kind: KindTodo, Text: "add an index", File: "lib/query.sql", Line: 1},
+		{Kind: KindTodo, Text: "vacuum", File: "lib/query.sql", Line: 2},
+		{Kind: KindTodo, Text: "handle signals", File: "main.go", Line: 3},
+		{Kind: KindFixme, Text: "check the error", File: "main.go", Line: 6},
+		{Kind: KindTodo, Text: "dark mode", File: "web/index.html", Line: 1},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Comment{}, "Fingerprint")); diff != "" {
+		t.Errorf("Scan() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestScan_Syntax(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"TODO.md":     "# TODO: release plan\n\n## FIXME list\n<!-- TODO: link the board -->\n",
+		"notes.txt":   "# TODO: unknown files accept every marker\n",
+		"fixtures.go": "package fixtures\n\nvar a = \"// TODO: in a string\"\nvar b = '#' // FIXME: exit code\\n\nvar c = `\n// TODO: in a raw string\n` // HACK after a raw string\n",
+		"styles.css":  "a { content: \"/* TODO: in a string */\"; } /* TODO: contrast */\n",
+		"block.c":     "/*\n * TODO: in a block\n * it's `open\n */\nint x; // TODO: after the block\n",
+		"script.py":   "print(\"# TODO: in a string\")  # TODO: real\n// TODO: not a Python comment\n",
+	})
+
+	got, err := Scan(context.Background(), dir, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []Comment{
+		{Kind: KindTodo, Text: "link the board", File: "TODO.md", Line: 4},
+		{Kind: KindTodo, Text: "in a block", File: "block.c", Line: 2},
+		{Kind: KindTodo, Text: "after the block", File: "block.c", Line: 5},
+		{Kind: KindFixme, Text: "exit code\\n", File: "fixtures.go", Line: 4},
+		{Kind: KindHack, Text: "after a raw string", File: "fixtures.go", Line: 7},
+		{Kind: KindTodo, Text: "unknown files accept every marker", File: "notes.txt", Line: 1},
+		{Kind: KindTodo, Text: "real", File: "script.py", Line: 1},
+		{Kind: KindTodo, Text: "contrast", File: "styles.css", Line: 1},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Comment{}, "Fingerprint")); diff != "" {
+		t.Errorf("Scan() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestScan_Fingerprint(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a.go": "// TODO: one\n// TODO: one\n// TODO: two\n"})
+	before, err := Scan(context.Background(), dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Moving comments keeps their fingerprints
+	writeFiles(t, dir, map[string]string{"a.go": "package a\n\n// TODO: two\n// TODO: one\n// TODO: one\n"})
+	after, err := Scan(context.Background(), dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprints := func(comments []Comment) map[string]string {
+		m := make(map[string]string)
+		for _, c := range comments {
+			m[c.Fingerprint] = c.Text
+		}
+		return m
+	}
+	if diff := cmp.Diff(fingerprints(before), fingerprints(after)); diff != "" {
+		t.Errorf("fingerprints changed (-before +after):\n%s", diff)
+	}
+	if len(fingerprints(before)) != 3 {
+		t.Errorf("identical comments should have distinct fingerprints: %v", before)
+	}
+}
+
+func TestScan_GitIgnore(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	writeFiles(t, dir, map[string]string{
+		".gitignore":      "build/\n",
+		"build/gen.go":    "// TODO: ignored\n",
+		"svc/main.go":     "// TODO: untracked but not ignored\n",
+		"svc/.hidden/a.c": "// TODO: hidden directories count in git\n",
+		"other/main.go":   "// TODO: outside the scanned directory\n",
+	})
+
+	got, err := Scan(context.Background(), filepath.Join(dir, "svc"), nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	want := []Comment{
+		{Kind: KindTodo, Text: "hidden directories count in git", File: ".hidden/a.c", Line: 1},
+		{Kind: KindTodo, Text: "untracked but not ignored", File: "main.go", Line: 1},
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(Comment{}, "Fingerprint")); diff != "" {
+		t.Errorf("Scan() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = Scan(context.Background(), dir, nil)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	for _, c := range got {
+		if c.File == "build/gen.go" {
+			t.Errorf("Scan() found a comment in an ignored file: %+v", c)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package comments
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Comment markers, as regular expressions
+const (
+	slashMarker = `//+`
+	blockMarker = `/\*+`
+	starMarker  = `\*`
+	hashMarker  = `#+`
+	dashMarker  = `--`
+	semiMarker  = `;+`
+	htmlMarker  = `<!--`
+)
+
+// syntax describes the comments and strings of a language
+type syntax struct {
+	// comment matches a TODO, FIXME or HACK comment after a comment
+	// marker. The optional "(owner)" after the keyword is dropped.
+	comment *regexp.Regexp
+	// opener matches a comment marker at the start of the text
+	opener *regexp.Regexp
+	// blocks maps the markers opening block comments to those closing them
+	blocks map[string]string
+	// quotes delimit strings that end on the line they start on
+	quotes string
+	// rawQuotes delimit strings without escapes that may span lines
+	rawQuotes string
+}
+
+// newSyntax returns the syntax of a language with the given comment markers
+// and string delimiters
+func newSyntax(quotes, rawQuotes string, markers ...string) *syntax {
+	s := &syntax{quotes: quotes, rawQuotes: rawQuotes, blocks: map[string]string{}}
+	var openers []string
+	for _, marker := range markers {
+		switch marker {
+		case blockMarker:
+			s.blocks["/*"] = "*/"
+		case htmlMarker:
+			s.blocks["<!--"] = "-->"
+		case starMarker:
+			// Only continues a block comment
+			continue
+		}
+		openers = append(openers, marker)
+	}
+	s.comment = regexp.MustCompile(`(?:^|\s)(?:` + strings.Join(markers, "|") + `)\s*(TODO|FIXME|HACK)\b(?:\([^)]*\))?:?(.*)$`)
+	s.opener = regexp.MustCompile(`^(?:` + strings.Join(openers, "|") + `)`)
+	return s
+}
+
+var (
+	cSyntax      = newSyntax(`"'`, "", slashMarker, blockMarker, starMarker)
+	backtickC    = newSyntax(`"'`, "`", slashMarker, blockMarker, starMarker)
+	rustSyntax   = newSyntax(`"`, "", slashMarker, blockMarker, starMarker)
+	phpSyntax    = newSyntax(`"'`, "", slashMarker, blockMarker, starMarker, hashMarker)
+	hashSyntax   = newSyntax(`"'`, "", hashMarker)
+	iniSyntax    = newSyntax(`"'`, "", semiMarker, hashMarker)
+	sqlSyntax    = newSyntax(`"'`, "", dashMarker, blockMarker, starMarker)
+	luaSyntax    = newSyntax(`"'`, "", dashMarker)
+	haskell      = newSyntax(`"`, "", dashMarker)
+	lispSyntax   = newSyntax(`"`, "", semiMarker)
+	markupSyntax = newSyntax("", "", htmlMarker)
+	// anySyntax is used for unknown files, where an apostrophe is more
+	// likely prose than a string
+	anySyntax = newSyntax(`"`, "", slashMarker, hashMarker, blockMarker, starMarker, dashMarker, semiMarker, htmlMarker)
+)
+
+// extSyntaxes maps file extensions to the syntax of their language
+var extSyntaxes = map[string]*syntax{
+	".go": backtickC, ".js": backtickC, ".jsx": backtickC, ".mjs": backtickC, ".cjs": backtickC, ".ts": backtickC, ".tsx": backtickC,
+	".c": cSyntax, ".h": cSyntax, ".cc": cSyntax, ".cpp": cSyntax, ".hpp": cSyntax, ".cs": cSyntax, ".java": cSyntax,
+	".kt": cSyntax, ".kts": cSyntax, ".scala": cSyntax, ".swift": cSyntax, ".dart": cSyntax, ".proto": cSyntax,
+	".css": cSyntax, ".scss": cSyntax, ".less": cSyntax,
+	".rs":  rustSyntax,
+	".php": phpSyntax,
+	".py":  hashSyntax, ".rb": hashSyntax, ".sh": hashSyntax, ".bash": hashSyntax, ".zsh": hashSyntax, ".pl": hashSyntax,
+	".r": hashSyntax, ".yaml": hashSyntax, ".yml": hashSyntax, ".toml": hashSyntax, ".tf": hashSyntax, ".mk": hashSyntax,
+	".cmake": hashSyntax, ".conf": hashSyntax, ".ps1": hashSyntax,
+	".ini": iniSyntax,
+	".sql": sqlSyntax,
+	".lua": luaSyntax,
+	".hs":  haskell, ".elm": haskell,
+	".el": lispSyntax, ".lisp": lispSyntax, ".clj": lispSyntax, ".scm": lispSyntax,
+	".md": markupSyntax, ".markdown": markupSyntax, ".html": markupSyntax, ".htm": markupSyntax, ".xml": markupSyntax, ".svg": markupSyntax,
+}
+
+// nameSyntaxes maps the names of files without a telling extension to the
+// syntax of their language
+var nameSyntaxes = map[string]*syntax{
+	"Makefile":       hashSyntax,
+	"Dockerfile":     hashSyntax,
+	"CMakeLists.txt": hashSyntax,
+	"Gemfile":        hashSyntax,
+	"Rakefile":       hashSyntax,
+}
+
+// syntaxOf returns the syntax of a file, given as a slash-separated path
+func syntaxOf(name string) *syntax {
+	base := path.Base(name)
+	if s, ok := nameSyntaxes[base]; ok {
+		return s
+	}
+	if s, ok := extSyntaxes[strings.ToLower(path.Ext(base))]; ok {
+		return s
+	}
+	return anySyntax
+}
+
+// lineState is what is left open at the end of a line: a string that may
+// span lines, or a block comment
+type lineState struct {
+	rawQuote   byte
+	blockClose string
+}
+
+// match returns the submatches of the comment regex for the comment on a
+// line, or nil if there is none. Markers inside strings are not comments.
+// state carries strings and block comments across lines.
+func (s *syntax) match(line string, state *lineState) []string {
+	i := 0
+	if state.blockClose != "" {
+		end := strings.Index(line, state.blockClose)
+		if end < 0 {
+			return s.comment.FindStringSubmatch(line)
+		}
+		i = end + len(state.blockClose)
+		state.blockClose = ""
+		if matches := s.comment.FindStringSubmatch(line[:end]); matches != nil {
+			return matches
+		}
+	}
+	if state.rawQuote != 0 {
+		end := strings.IndexByte(line, state.rawQuote)
+		if end < 0 {
+			return nil
+		}
+		state.rawQuote = 0
+		i = end + 1
+	}
+
+	for i < len(line) {
+		c := line[i]
+		switch {
+		case strings.IndexByte(s.quotes, c) >= 0:
+			end := closingQuote(line, i+1, c)
+			if end < 0 {
+				return nil
+			}
+			i = end + 1
+			continue
+		case strings.IndexByte(s.rawQuotes, c) >= 0:
+			end := strings.IndexByte(line[i+1:], c)
+			if end < 0 {
+				state.rawQuote = c
+				return nil
+			}
+			i += end + 2
+			continue
+		}
+		if (i == 0 || line[i-1] == ' ' || line[i-1] == '\t') && s.opener.MatchString(line[i:]) {
+			rest := line[i:]
+			for open, end := range s.blocks {
+				if strings.HasPrefix(rest, open) && !strings.Contains(rest[len(open):], end) {
+					state.blockClose = end
+				}
+			}
+			// The rest of the line is a comment, whatever quotes it holds
+			return s.comment.FindStringSubmatch(rest)
+		}
+		i++
+	}
+	return nil
+}
+
+// closingQuote returns the index of the quote ending a string that starts
+// at from, skipping escaped characters, or -1 if it does not end on the line
+func closingQuote(line string, from int, quote byte) int {
+	for i := from; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,563 @@
+// Package config provides configuration loading for the agentic-todo-mcp server.
+// Settings come from built-in defaults, then .todo/config.toml (or YAML/JSON),
+// then environment variables, then command-line flags, each overriding the last.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+)
+
+const (
+	// TransportStdio serves a single client over stdin/stdout
+	TransportStdio = "stdio"
+	// TransportHTTP serves many clients over streamable HTTP and SSE
+	TransportHTTP = "http"
+
+	// DefaultAddr is the default listen address of the http transport
+	DefaultAddr = "127.0.0.1:8765"
+	// DefaultMaxSubtasks is the default limit of subtasks per task
+	DefaultMaxSubtasks = 20
+	// DefaultScanDepth is how many directory levels below the root are searched for projects
+	DefaultScanDepth = 3
+	// DefaultArchiveAfterDays is how long after completion done tasks are archived by default
+	DefaultArchiveAfterDays = 30
+
+	// EnvPrefix is the prefix of environment variables overriding the config
+	EnvPrefix = "AGENTIC_TODO_MCP_"
+)
+
+// configFiles lists the config file names looked up in the .todo directory, in order
+var configFiles = []string{"config.toml", "config.yaml", "config.yml", "config.json"}
+
+// Config holds the server configuration
+type Config struct {
+	// DataDir is the data directory, relative to the project root unless absolute
+	DataDir   string    `json:"data_dir"`
+	Tasks     Tasks     `json:"tasks"`
+	Templates Templates `json:"templates"`
+	Transport Transport `json:"transport"`
+	Storage   Storage   `json:"storage"`
+	Limits    Limits    `json:"limits"`
+	Workspace Workspace `json:"workspace"`
+	Git       Git       `json:"git"`
+	Workflow  Workflow  `json:"workflow"`
+	Archive   Archive   `json:"archive"`
+}
+
+// Tasks configures task creation
+type Tasks struct {
+	IDPrefix string `json:"id_prefix"`
+	// IDMode is model.IDModeSequential or model.IDModeTime
+	IDMode          string `json:"id_mode"`
+	DefaultCategory string `json:"default_category"`
+	IDDigits        int    `json:"id_digits"`
+}
+
+// Templates configures generated files
+type Templates struct {
+	// Context is the path of a text/template for new context files,
+	// relative to the data directory unless absolute. Empty uses the built-in template.
+	Context string `json:"context"`
+}
+
+// Transport configures how the server is reached
+type Transport struct {
+	Type           string   `json:"type"`
+	Addr           string   `json:"addr"`
+	Token          string   `json:"token"`
+	AllowedOrigins []string `json:"allowed_origins"`
+	AllowedHosts   []string `json:"allowed_hosts"`
+}
+
+// Storage configures file permissions
+type Storage struct {
+	DirPerm  os.FileMode `json:"dir_perm"`
+	FilePerm os.FileMode `json:"file_perm"`
+}
+
+// Limits bounds the amount of data the server accepts
+type Limits struct {
+	MaxTasks    int `json:"max_tasks"`
+	MaxSubtasks int `json:"max_subtasks"`
+}
+
+// Workspace configures which project roots one server manages
+type Workspace struct {
+	// Projects lists project directories, relative to the root unless absolute.
+	// Empty discovers directories holding the data directory.
+	Projects []string `json:"projects"`
+	// ScanDepth bounds project discovery below the root; 0 disables it
+	ScanDepth int `json:"scan_depth"`
+}
+
+// Git configures how changes are recorded in the repository holding a project
+type Git struct {
+	// AutoCommit commits the data directory after each change made by a tool
+	AutoCommit bool `json:"auto_commit"`
+}
+
+// Archive configures moving done tasks out of task.md
+type Archive struct {
+	// AfterDays is how many days after completion archive_tasks moves a
+	// done task unless told otherwise
+	AfterDays int `json:"after_days"`
+}
+
+// Workflow configures the statuses of tasks. Empty fields take the
+// built-in workflow's values.
+type Workflow struct {
+	// Statuses lists the statuses with their task.md checkbox, as in
+	// "review [r]", in order; the first is the status of new tasks
+	Statuses []string `json:"statuses"`
+	// Done lists the statuses in which a task counts as done
+	Done []string `json:"done"`
+	// Reason lists the statuses a task moves to only with a reason
+	Reason []string `json:"reason"`
+	// Transitions lists the allowed moves, as in "review -> done|in_progress".
+	// Statuses without an entry may move to any status.
+	Transitions []string `json:"transitions"`
+	// Rollup lists the rules moving a task along with its subtasks:
+	// model.RollupStart and model.RollupComplete
+	Rollup []string `json:"rollup"`
+}
+
+// statusEntryRegex matches a workflow status entry such as "review [r]"
+var statusEntryRegex = regexp.MustCompile(`^(\S+)\s*\[(.*)\]$`)
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		DataDir: storage.DefaultDataDir,
+		Tasks: Tasks{
+			IDPrefix:        model.DefaultIDPrefix,
+			IDMode:          model.IDModeSequential,
+			IDDigits:        model.DefaultIDDigits,
+			DefaultCategory: storage.DefaultCategory,
+		},
+		Transport: Transport{
+			Type: TransportStdio,
+			Addr: DefaultAddr,
+		},
+		Storage: Storage{
+			DirPerm:  storage.DefaultDirPerm,
+			FilePerm: storage.DefaultFilePerm,
+		},
+		Limits: Limits{
+			MaxTasks:    model.DefaultIDScheme.Max(),
+			MaxSubtasks: DefaultMaxSubtasks,
+		},
+		Workspace: Workspace{
+			ScanDepth: DefaultScanDepth,
+		},
+		Archive: Archive{
+			AfterDays: DefaultArchiveAfterDays,
+		},
+	}
+}
+
+// Load builds the configuration for the project at root.
+// If path is empty, the first of .todo/config.toml, config.yaml, config.yml
+// and config.json that exists is read; a missing file is not an error.
+// Environment variables
+// named EnvPrefix + the upper-cased key with dots replaced by underscores
+// (e.g. AGENTIC_TODO_MCP_TRANSPORT_ADDR) override the file.
+// lookupEnv is usually os.LookupEnv; nil skips the environment.
+func Load(root, path string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	if path == "" {
+		path = File(root)
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if lookupEnv != nil {
+		for _, key := range Keys() {
+			if value, ok := lookupEnv(EnvName(key)); ok {
+				if err := cfg.Set(key, value); err != nil {
+					return nil, fmt.Errorf("config: %s: %w", EnvName(key), err)
+				}
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
+// File returns the first of configFiles that exists in the .todo directory
+// of the project at root, or an empty string if there is none
+func File(root string) string {
+	for _, name := range configFiles {
+		candidate := filepath.Join(root, storage.DefaultDataDir, name)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// loadFile reads a TOML, YAML or JSON config file into cfg
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	var values map[string]string
+	switch filepath.Ext(path) {
+	case ".toml":
+		values, err = parseTOML(string(data))
+	case ".yaml", ".yml":
+		values, err = parseYAML(string(data))
+	case ".json":
+		values, err = parseJSON(data)
+	default:
+		return fmt.Errorf("config: %s: unsupported format (want .toml, .yaml or .json)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := c.Set(key, values[key]); err != nil {
+			return fmt.Errorf("config: %s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+// EnvName returns the environment variable overriding key
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// setters maps each config key to a function assigning its value
+var setters = map[string]func(c *Config, value string) error{
+	"data_dir":                  func(c *Config, v string) error { c.DataDir = v; return nil },
+	"tasks.id_prefix":           func(c *Config, v string) error { c.Tasks.IDPrefix = v; return nil },
+	"tasks.id_digits":           func(c *Config, v string) error { return setInt(&c.Tasks.IDDigits, v) },
+	"tasks.id_mode":             func(c *Config, v string) error { c.Tasks.IDMode = v; return nil },
+	"tasks.default_category":    func(c *Config, v string) error { c.Tasks.DefaultCategory = v; return nil },
+	"templates.context":         func(c *Config, v string) error { c.Templates.Context = v; return nil },
+	"transport.type":            func(c *Config, v string) error { c.Transport.Type = v; return nil },
+	"transport.addr":            func(c *Config, v string) error { c.Transport.Addr = v; return nil },
+	"transport.token":           func(c *Config, v string) error { c.Transport.Token = v; return nil },
+	"transport.allowed_origins": func(c *Config, v string) error { c.Transport.AllowedOrigins = SplitList(v); return nil },
+	"transport.allowed_hosts":   func(c *Config, v string) error { c.Transport.AllowedHosts = SplitList(v); return nil },
+	"storage.dir_perm":          func(c *Config, v string) error { return setPerm(&c.Storage.DirPerm, v) },
+	"storage.file_perm":         func(c *Config, v string) error { return setPerm(&c.Storage.FilePerm, v) },
+	"limits.max_tasks":          func(c *Config, v string) error { return setInt(&c.Limits.MaxTasks, v) },
+	"limits.max_subtasks":       func(c *Config, v string) error { return setInt(&c.Limits.MaxSubtasks, v) },
+	"workspace.projects":        func(c *Config, v string) error { c.Workspace.Projects = SplitList(v); return nil },
+	"workspace.scan_depth":      func(c *Config, v string) error { return setInt(&c.Workspace.ScanDepth, v) },
+	"git.auto_commit":           func(c *Config, v string) error { return setBool(&c.Git.AutoCommit, v) },
+	"workflow.statuses":         func(c *Config, v string) error { c.Workflow.Statuses = SplitList(v); return nil },
+	"workflow.done":             func(c *Config, v string) error { c.Workflow.Done = SplitList(v); return nil },
+	"workflow.reason":           func(c *Config, v string) error { c.Workflow.Reason = SplitList(v); return nil },
+	"workflow.transitions":      func(c *Config, v string) error { c.Workflow.Transitions = SplitList(v); return nil },
+	"workflow.rollup":           func(c *Config, v string) error { c.Workflow.Rollup = SplitList(v); return nil },
+	"archive.after_days":        func(c *Config, v string) error { return setInt(&c.Archive.AfterDays, v) },
+}
+
+// Keys returns all config keys in sorted order
+func Keys() []string {
+	keys := make([]string, 0, len(setters))
+	for key := range setters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Set assigns a value given as a string to the setting named by key,
+// e.g. Set("transport.addr", ":9000"). Lists are comma-separated.
+func (c *Config) Set(key, value string) error {
+	set, ok := setters[key]
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	return set(c, value)
+}
+
+func setInt(dst *int, value string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("not an integer: %q", value)
+	}
+	*dst = n
+	return nil
+}
+
+func setBool(dst *bool, value string) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return fmt.Errorf("not a boolean: %q", value)
+	}
+	*dst = b
+	return nil
+}
+
+func setPerm(dst *os.FileMode, value string) error {
+	n, err := strconv.ParseUint(strings.TrimSpace(value), 8, 32)
+	if err != nil {
+		return fmt.Errorf("not an octal permission: %q", value)
+	}
+	*dst = os.FileMode(n)
+	return nil
+}
+
+// SplitList splits a comma-separated list, dropping empty entries
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Validate checks the configuration and reports every problem found
+func (c *Config) Validate() error {
+	var errs []error
+	report := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("config: %s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.DataDir == "" {
+		report("data_dir", "must not be empty")
+	}
+
+	scheme := c.IDScheme()
+	if err := scheme.Validate(); err != nil {
+		report("tasks", "%v", err)
+	} else if c.Limits.MaxTasks < 1 || c.Limits.MaxTasks > scheme.Max() {
+		report("limits.max_tasks", "must be between 1 and %d for %d-digit IDs (got %d)",
+			scheme.Max(), scheme.Digits, c.Limits.MaxTasks)
+	}
+	if c.Tasks.DefaultCategory == "" || strings.ContainsAny(c.Tasks.DefaultCategory, "\r\n") {
+		report("tasks.default_category", "must be a non-empty single line (got %q)", c.Tasks.DefaultCategory)
+	}
+	if c.Limits.MaxSubtasks < 1 || c.Limits.MaxSubtasks > DefaultMaxSubtasks {
+		report("limits.max_subtasks", "must be between 1 and %d (got %d)", DefaultMaxSubtasks, c.Limits.MaxSubtasks)
+	}
+
+	switch c.Transport.Type {
+	case TransportStdio:
+	case TransportHTTP:
+		if _, _, err := net.SplitHostPort(c.Transport.Addr); err != nil {
+			report("transport.addr", "must be host:port (got %q)", c.Transport.Addr)
+		}
+	default:
+		report("transport.type", "must be %q or %q (got %q)", TransportStdio, TransportHTTP, c.Transport.Type)
+	}
+
+	if c.Storage.DirPerm&0o700 != 0o700 {
+		report("storage.dir_perm", "must grant the owner rwx (got %#o)", c.Storage.DirPerm)
+	}
+	if c.Storage.FilePerm&0o600 != 0o600 {
+		report("storage.file_perm", "must grant the owner rw (got %#o)", c.Storage.FilePerm)
+	}
+
+	c.validateWorkflow(report)
+
+	if c.Workspace.ScanDepth < 0 {
+		report("workspace.scan_depth", "must not be negative (got %d)", c.Workspace.ScanDepth)
+	}
+	if c.Archive.AfterDays < 1 {
+		report("archive.after_days", "must be at least 1 (got %d)", c.Archive.AfterDays)
+	}
+
+	// Relative template paths depend on the data directory and are checked when loaded
+	if filepath.IsAbs(c.Templates.Context) {
+		if _, err := c.ContextTemplate(""); err != nil {
+			report("templates.context", "%v", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IDScheme returns the task ID scheme described by the config
+func (c *Config) IDScheme() model.IDScheme {
+	return model.IDScheme{Prefix: c.Tasks.IDPrefix, Mode: c.Tasks.IDMode, Digits: c.Tasks.IDDigits}
+}
+
+// TaskWorkflow returns the workflow of task statuses described by the config.
+// Without statuses it is the built-in workflow; built-in statuses listed
+// without a checkbox keep their marker, and without done or reason statuses
+// those of the built-in workflow that the statuses include are used.
+func (c *Config) TaskWorkflow() model.Workflow {
+	entries := c.Workflow.Statuses
+	if len(entries) == 0 {
+		for _, s := range model.DefaultWorkflow.Statuses {
+			entries = append(entries, s.Name+" ["+s.Marker+"]")
+		}
+	}
+
+	var workflow model.Workflow
+	for _, entry := range entries {
+		status := model.Status{Name: entry}
+		if m := statusEntryRegex.FindStringSubmatch(entry); m != nil {
+			status = model.Status{Name: m[1], Marker: m[2]}
+		}
+		builtin, _ := model.DefaultWorkflow.Lookup(status.Name)
+		if status.Marker == "" {
+			status.Marker = builtin.Marker
+		}
+		status.Done = builtin.Done
+		if c.Workflow.Done != nil {
+			status.Done = slices.Contains(c.Workflow.Done, status.Name)
+		}
+		status.Reason = builtin.Reason
+		if c.Workflow.Reason != nil {
+			status.Reason = slices.Contains(c.Workflow.Reason, status.Name)
+		}
+		workflow.Statuses = append(workflow.Statuses, status)
+	}
+
+	for _, entry := range c.Workflow.Transitions {
+		from, to, ok := strings.Cut(entry, "->")
+		if !ok {
+			continue
+		}
+		if workflow.Transitions == nil {
+			workflow.Transitions = map[string][]string{}
+		}
+		from = strings.TrimSpace(from)
+		workflow.Transitions[from] = append(workflow.Transitions[from], splitStatuses(to)...)
+	}
+	workflow.Rollup = model.Rollup{
+		Start:    slices.Contains(c.Workflow.Rollup, model.RollupStart),
+		Complete: slices.Contains(c.Workflow.Rollup, model.RollupComplete),
+	}
+	return workflow
+}
+
+// validateWorkflow reports problems of the workflow settings
+func (c *Config) validateWorkflow(report func(key, format string, args ...any)) {
+	workflow := c.TaskWorkflow()
+	if err := workflow.Validate(); err != nil {
+		report("workflow", "%v", err)
+		return
+	}
+	for key, names := range map[string][]string{"workflow.done": c.Workflow.Done, "workflow.reason": c.Workflow.Reason} {
+		for _, name := range names {
+			if _, ok := workflow.Lookup(name); !ok {
+				report(key, "unknown status %q", name)
+			}
+		}
+	}
+	for _, entry := range c.Workflow.Transitions {
+		if _, _, ok := strings.Cut(entry, "->"); !ok {
+			report("workflow.transitions", "must be \"from -> to|to\" (got %q)", entry)
+		}
+	}
+	for _, rule := range c.Workflow.Rollup {
+		if rule != model.RollupStart && rule != model.RollupComplete {
+			report("workflow.rollup", "must list %q or %q (got %q)", model.RollupStart, model.RollupComplete, rule)
+		}
+	}
+}
+
+// splitStatuses splits a |-separated list of statuses, dropping empty entries
+func splitStatuses(s string) []string {
+	var statuses []string
+	for _, status := range strings.Split(s, "|") {
+		if status = strings.TrimSpace(status); status != "" {
+			statuses = append(statuses, status)
+		}
+	}
+	return statuses
+}
+
+// StorageOptions returns the storage options described by the config
+func (c *Config) StorageOptions() storage.Options {
+	return storage.Options{
+		DataDir:         c.DataDir,
+		DefaultCategory: c.Tasks.DefaultCategory,
+		IDScheme:        c.IDScheme(),
+		Workflow:        c.TaskWorkflow(),
+		DirPerm:         c.Storage.DirPerm,
+		FilePerm:        c.Storage.FilePerm,
+	}
+}
+
+// ContextTemplate parses the context file template, resolving a relative
+// template path against dataDir. It returns nil if no template is configured.
+func (c *Config) ContextTemplate(dataDir string) (*template.Template, error) {
+	if c.Templates.Context == "" {
+		return nil, nil
+	}
+	path := c.Templates.Context
+	if !filepath.IsAbs(path) && dataDir != "" {
+		path = filepath.Join(dataDir, path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(filepath.Base(path)).Parse(string(data))
+}
+
+// parseJSON flattens a JSON config object into dotted keys
+func parseJSON(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := flatten("", raw, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]any, values map[string]string) error {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch v := value.(type) {
+		case map[string]any:
+			if err := flatten(key, v, values); err != nil {
+				return err
+			}
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				items = append(items, fmt.Sprint(item))
+			}
+			values[key] = strings.Join(items, ",")
+		case string:
+			values[key] = v
+		case float64, bool:
+			values[key] = fmt.Sprint(v)
+		default:
+			return fmt.Errorf("%s: unsupported value %v", key, value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+// writeConfig writes a config file into root/.todo
+func writeConfig(t *testing.T, root, name, content string) {
+	t.Helper()
+	dir := filepath.Join(root, ".todo")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("Failed to create .todo dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+}
+
+func TestLoad_Default(t *testing.T) {
+	cfg, err := Load(t.TempDir(), "", nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if diff := cmp.Diff(Default(), cfg); diff != "" {
+		t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestLoad_Files(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name: "toml",
+			file: "config.toml",
+			content: `# project settings
+data_dir = "tasks"
+
+[tasks]
+id_prefix = "PRJ"
+id_digits = 4
+default_category = "Inbox"
+
+[transport]
+type = "http"
+addr = "127.0.0.1:9000"
+allowed_origins = ["https://a.example", "https://b.example"]
+allowed_hosts = ["todo.example"]
+
+[storage]
+dir_perm = 0o700
+file_perm = 0o644
+
+[limits]
+max_tasks = 5000
+max_subtasks = 10
+
+[git]
+auto_commit = true
+`,
+		},
+		{
+			name: "yaml",
+			file: "config.yaml",
+			content: `# project settings
+data_dir: tasks
+tasks:
+  id_prefix: PRJ
+  id_digits: 4
+  default_category: 'Inbox'
+transport:
+  type: http
+  addr: "127.0.0.1:9000"
+  allowed_origins:
+    - https://a.example
+    - https://b.example
+  allowed_hosts: [todo.example]
+storage:
+  dir_perm: 0o700
+  file_perm: 0644
+limits:
+  max_tasks: 5000
+  max_subtasks: 10
+git:
+  auto_commit: true
+`,
+		},
+		{
+			name: "json",
+			file: "config.json",
+			content: `{
+  "data_dir": "tasks",
+  "tasks": {"id_prefix": "PRJ", "id_digits": 4, "default_category": "Inbox"},
+  "transport": {"type": "http", "addr": "127.0.0.1:9000", "allowed_origins": ["https://a.example", "https://b.example"], "allowed_hosts": ["todo.example"]},
+  "storage": {"dir_perm": "0700", "file_perm": "0644"},
+  "limits": {"max_tasks": 5000, "max_subtasks": 10},
+  "git": {"auto_commit": true}
+}`,
+		},
+	}
+
+	want := Default()
+	want.DataDir = "tasks"
+	want.Tasks = Tasks{IDPrefix: "PRJ", IDMode: model.IDModeSequential, IDDigits: 4, DefaultCategory: "Inbox"}
+	want.Transport = Transport{
+		Type:           TransportHTTP,
+		Addr:           "127.0.0.1:9000",
+		AllowedOrigins: []string{"https://a.example", "https://b.example"},
+		AllowedHosts:   []string{"todo.example"},
+	}
+	want.Storage = Storage{DirPerm: 0o700, FilePerm: 0o644}
+	want.Limits = Limits{MaxTasks: 5000, MaxSubtasks: 10}
+	want.Git = Git{AutoCommit: true}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeConfig(t, root, tt.file, tt.content)
+
+			cfg, err := Load(root, "", nil)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if diff := cmp.Diff(want, cfg); diff != "" {
+				t.Errorf("Load() mismatch (-want +got):\n%s", diff)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	root := t.TempDir()
+	writeConfig(t, root, "config.toml", "[tasks]\ndefault_category = \"FromFile\"\n")
+
+	env := map[string]string{
+		"AGENTIC_TODO_MCP_TASKS_DEFAULT_CATEGORY": "FromEnv",
+		"AGENTIC_TODO_MCP_TRANSPORT_TOKEN":        "s3cret",
+	}
+	cfg, err := Load(root, "", func(key string) (string, bool) {
+		v, ok := env[key]
+		return v, ok
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if cfg.Tasks.DefaultCategory != "FromEnv" {
+		t.Errorf("DefaultCategory = %q, want FromEnv", cfg.Tasks.DefaultCategory)
+	}
+	if cfg.Transport.Token != "s3cret" {
+		t.Errorf("Token = %q, want s3cret", cfg.Transport.Token)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"unknown key", "[tasks]\nid_sufix = \"x\"\n", `unknown config key "tasks.id_sufix"`},
+		{"bad integer", "[limits]\nmax_tasks = \"many\"\n", "not an integer"},
+		{"bad boolean", "[git]\nauto_commit = \"sometimes\"\n", "not a boolean"},
+		{"syntax error", "[tasks\n", "malformed table header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeConfig(t, root, "config.toml", tt.content)
+
+			_, err := Load(root, "", nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantKey string
+	}{
+		{"empty data dir", func(c *Config) { c.DataDir = "" }, "data_dir"},
+		{"bad prefix", func(c *Config) { c.Tasks.IDPrefix = "T-" }, "tasks"},
+		{"too many digits", func(c *Config) { c.Tasks.IDDigits = 12 }, "tasks"},
+		{"unknown ID mode", func(c *Config) { c.Tasks.IDMode = "uuid" }, "tasks"},
+		{"max tasks beyond ID space", func(c *Config) { c.Limits.MaxTasks = 1000 }, "limits.max_tasks"},
+		{"multi-line category", func(c *Config) { c.Tasks.DefaultCategory = "a\nb" }, "tasks.default_category"},
+		{"unknown transport", func(c *Config) { c.Transport.Type = "grpc" }, "transport.type"},
+		{"bad addr", func(c *Config) { c.Transport.Type = TransportHTTP; c.Transport.Addr = "8765" }, "transport.addr"},
+		{"unwritable files", func(c *Config) { c.Storage.FilePerm = 0o444 }, "storage.file_perm"},
+		{"negative scan depth", func(c *Config) { c.Workspace.ScanDepth = -1 }, "workspace.scan_depth"},
+		{"archive after no days", func(c *Config) { c.Archive.AfterDays = 0 }, "archive.after_days"},
+		{"missing template", func(c *Config) { c.Templates.Context = "/nonexistent/context.tmpl" }, "templates.context"},
+		{"custom status without checkbox", func(c *Config) { c.Workflow.Statuses = []string{"todo", "review", "done"} }, "workflow"},
+		{"unknown done status", func(c *Config) { c.Workflow.Done = []string{"done", "shipped"} }, "workflow.done"},
+		{"malformed transition", func(c *Config) { c.Workflow.Transitions = []string{"todo: done"} }, "workflow.transitions"},
+		{"transition to unknown status", func(c *Config) { c.Workflow.Transitions = []string{"todo -> review"} }, "workflow"},
+		{"unknown rollup rule", func(c *Config) { c.Workflow.Rollup = []string{"start", "finish"} }, "workflow.rollup"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if err == nil || !strings.Contains(err.Error(), "config: "+tt.wantKey+":") {
+				t.Errorf("Validate() error = %v, want error for %s", err, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestTaskWorkflow(t *testing.T) {
+	if diff := cmp.Diff(model.DefaultWorkflow, Default().TaskWorkflow()); diff != "" {
+		t.Errorf("TaskWorkflow() of the default config mismatch (-want +got):\n%s", diff)
+	}
+
+	root := t.TempDir()
+	writeConfig(t, root, "config.toml", `[workflow]
+statuses = ["todo [ ]", "in_progress [-]", "review [r]", "qa [q]", "deployed [x]", "cancelled [~]"]
+done = ["deployed", "cancelled"]
+transitions = ["review -> qa|in_progress", "qa -> deployed|in_progress", "deployed ->"]
+rollup = ["start"]
+`)
+	cfg, err := Load(root, "", nil)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	want := model.Workflow{
+		Statuses: []model.Status{
+			{Name: "todo", Marker: " "},
+			{Name: "in_progress", Marker: "-"},
+			{Name: "review", Marker: "r"},
+			{Name: "qa", Marker: "q"},
+			{Name: "deployed", Marker: "x", Done: true},
+			// Reason is not configured, so cancelled keeps the built-in one
+			{Name: "cancelled", Marker: "~", Done: true, Reason: true},
+		},
+		Transitions: map[string][]string{
+			"review":   {"qa", "in_progress"},
+			"qa":       {"deployed", "in_progress"},
+			"deployed": nil,
+		},
+		Rollup: model.Rollup{Start: true},
+	}
+	if diff := cmp.Diff(want, cfg.TaskWorkflow()); diff != "" {
+		t.Errorf("TaskWorkflow() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want, cfg.StorageOptions().Workflow); diff != "" {
+		t.Errorf("StorageOptions().Workflow mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSet(t *testing.T) {
+	cfg := Default()
+	if err := cfg.Set("transport.allowed_origins", "https://a.example, ,https://b.example"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"https://a.example", "https://b.example"}, cfg.Transport.AllowedOrigins); diff != "" {
+		t.Errorf("AllowedOrigins mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := cfg.Set("workspace.projects", "services/api,services/web"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{"services/api", "services/web"}, cfg.Workspace.Projects); diff != "" {
+		t.Errorf("Projects mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := cfg.Set("storage.dir_perm", "0755"); err != nil || cfg.Storage.DirPerm != 0o755 {
+		t.Errorf("Set(storage.dir_perm) = %v, DirPerm = %#o", err, cfg.Storage.DirPerm)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	if got, want := EnvName("transport.allowed_origins"), "AGENTIC_TODO_MCP_TRANSPORT_ALLOWED_ORIGINS"; got != want {
+		t.Errorf("EnvName() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseTOML(t *testing.T) {
+	content := `
+title = "root # not a comment" # trailing comment
+count = 1_000
+enabled = true
+literal = 'C:\path'
+
+[section]
+escaped = "say \"hi\", #1"
+list = ["a", 'b', "c, d"]
+perm = 0o750
+
+[workflow]
+statuses = [
+  "todo",     # not started
+  "review",
+  "done", # trailing comma
+]
+transitions = [ "todo -> review",
+  "review -> done" ]
+`
+	want := map[string]string{
+		"title":                "root # not a comment",
+		"count":                "1000",
+		"enabled":              "true",
+		"literal":              `C:\path`,
+		"section.escaped":      `say "hi", #1`,
+		"section.list":         "a,b,c, d",
+		"section.perm":         "0750",
+		"workflow.statuses":    "todo,review,done",
+		"workflow.transitions": "todo -> review,review -> done",
+	}
+
+	got, err := parseTOML(content)
+	if err != nil {
+		t.Fatalf("parseTOML() error = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseTOML() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseTOML_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing equals", "key"},
+		{"missing value", "key ="},
+		{"duplicate key", "a = 1\na = 2"},
+		{"array of tables", "[[items]]"},
+		{"unterminated array", `list = ["a"`},
+		{"unterminated multi-line array", "list = [\n  \"a\",\n"},
+		{"bare word", "key = value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseTOML(tt.content); err == nil {
+				t.Error("parseTOML() should fail")
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML parses the subset of YAML used by config files into dotted keys:
+// nested mappings by indentation, plain, single- and double-quoted scalars,
+// block ("- item") and flow ("[a, b]") sequences of those, and # comments.
+// Sequence values are joined with commas, matching Config.Set.
+func parseYAML(content string) (map[string]string, error) {
+	values := make(map[string]string)
+	sequences := make(map[string][]string)
+
+	// parents holds the mappings enclosing the current line, innermost last
+	type parent struct {
+		indent int
+		key    string
+	}
+	var parents []parent
+	// open is the key without a value on the previous line, which a nested
+	// mapping or a block sequence follows
+	var open *parent
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		text := stripYAMLComment(scanner.Text())
+		line := strings.TrimLeft(text, " ")
+		if strings.TrimSpace(line) == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", lineNum)
+		}
+		indent := len(text) - len(line)
+		line = strings.TrimSpace(line)
+
+		if item, ok := strings.CutPrefix(line, "-"); ok && (item == "" || item[0] == ' ') {
+			if open == nil || indent < open.indent {
+				return nil, fmt.Errorf("line %d: sequence item without a key", lineNum)
+			}
+			value, err := parseYAMLScalar(strings.TrimSpace(item))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", lineNum, open.key, err)
+			}
+			sequences[open.key] = append(sequences[open.key], value)
+			continue
+		}
+
+		if open != nil && indent > open.indent {
+			if _, ok := sequences[open.key]; ok {
+				return nil, fmt.Errorf("line %d: %s: mapping inside a sequence", lineNum, open.key)
+			}
+			parents = append(parents, *open)
+		}
+		open = nil
+		for len(parents) > 0 && parents[len(parents)-1].indent >= indent {
+			parents = parents[:len(parents)-1]
+		}
+
+		key, raw, ok := strings.Cut(line, ":")
+		if !ok || (raw != "" && raw[0] != ' ') {
+			return nil, fmt.Errorf("line %d: expected key: value", lineNum)
+		}
+		key = strings.TrimSpace(key)
+		if len(parents) > 0 {
+			key = parents[len(parents)-1].key + "." + key
+		}
+		if _, dup := values[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %s", lineNum, key)
+		}
+		if _, dup := sequences[key]; dup {
+			return nil, fmt.Errorf("line %d: duplicate key %s", lineNum, key)
+		}
+
+		if raw = strings.TrimSpace(raw); raw == "" {
+			open = &parent{indent: indent, key: key}
+			continue
+		}
+		value, err := parseYAMLScalar(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", lineNum, key, err)
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for key, items := range sequences {
+		values[key] = strings.Join(items, ",")
+	}
+	return values, nil
+}
+
+// parseYAMLScalar converts a YAML scalar or flow sequence to its string form
+func parseYAMLScalar(raw string) (string, error) {
+	switch {
+	case raw == "":
+		return "", fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return "", fmt.Errorf("unterminated sequence")
+		}
+		var items []string
+		for _, item := range splitArray(raw[1 : len(raw)-1]) {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			value, err := parseYAMLScalar(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, value)
+		}
+		return strings.Join(items, ","), nil
+	case strings.HasPrefix(raw, "{"):
+		return "", fmt.Errorf("flow mappings are not supported")
+	case strings.HasPrefix(raw, `"`):
+		return strconv.Unquote(raw)
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("unterminated string")
+		}
+		return strings.ReplaceAll(raw[1:len(raw)-1], "''", "'"), nil
+	case strings.HasPrefix(raw, "0o"):
+		// YAML 1.2 octal, for permissions
+		if _, err := strconv.ParseUint(raw[2:], 8, 32); err != nil {
+			return "", fmt.Errorf("invalid octal %q", raw)
+		}
+		return "0" + raw[2:], nil
+	default:
+		return raw, nil
+	}
+}
+
+// stripYAMLComment removes a # comment, which starts a line or follows
+// whitespace, outside of strings
+func stripYAMLComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && r == '\\':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case (r == '"' || r == '\'') && (i == 0 || strings.IndexByte(" \t[,", line[i-1]) >= 0):
+			// Quotes open strings only at the start of a value
+			quote = r
+		case r == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return strings.TrimRight(line, " \t")
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseYAML(t *testing.T) {
+	content := `---
+title: "root # not a comment" # trailing comment
+plain: value#1
+enabled: true
+literal: 'it''s'
+
+section:
+  escaped: "say \"hi\", #1"
+  list: [a, 'b', "c, d"]
+  perm: 0o750
+  nested:
+    deep: 1
+workflow:
+  statuses:
+    - todo   # not started
+    - review
+    - done
+  transitions:
+  - todo -> review
+  - review -> done
+after: 2
+`
+	want := map[string]string{
+		"title":                "root # not a comment",
+		"plain":                "value#1",
+		"enabled":              "true",
+		"literal":              "it's",
+		"section.escaped":      `say "hi", #1`,
+		"section.list":         "a,b,c, d",
+		"section.perm":         "0750",
+		"section.nested.deep":  "1",
+		"workflow.statuses":    "todo,review,done",
+		"workflow.transitions": "todo -> review,review -> done",
+		"after":                "2",
+	}
+
+	got, err := parseYAML(content)
+	if err != nil {
+		t.Fatalf("parseYAML() error = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("parseYAML() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseYAML_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"missing colon", "key"},
+		{"duplicate key", "a: 1\na: 2"},
+		{"item without a key", "- a"},
+		{"mapping inside a sequence", "list:\n  - a\n  b: 1"},
+		{"tab indentation", "a:\n\tb: 1"},
+		{"flow mapping", "a: {b: 1}"},
+		{"unterminated sequence", "list: [a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseYAML(tt.content); err == nil {
+				t.Error("parseYAML() should fail")
+			}
+		})
+	}
+}
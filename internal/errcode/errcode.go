@@ -0,0 +1,173 @@
+// Package errcode provides typed errors for the agentic-todo-mcp system.
+// Every error carries one of the codes defined in doc/mcp-spec.md §6 so that
+// storage, parser and tool layers can report failures agents can branch on.
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// Code identifies a class of failure
+type Code string
+
+// File operation errors
+const (
+	// FileNotFound indicates that a file does not exist
+	FileNotFound Code = "FILE_NOT_FOUND"
+	// FileReadError indicates that a file could not be read
+	FileReadError Code = "FILE_READ_ERROR"
+	// FileWriteError indicates that a file could not be written
+	FileWriteError Code = "FILE_WRITE_ERROR"
+	// PermissionDenied indicates that file access was denied
+	PermissionDenied Code = "PERMISSION_DENIED"
+)
+
+// Validation errors
+const (
+	// ValidationError indicates that tool input does not match its schema
+	ValidationError Code = "VALIDATION_ERROR"
+	// InvalidTaskID indicates a malformed task ID
+	InvalidTaskID Code = "INVALID_TASK_ID"
+	// InvalidADRNumber indicates an ADR number out of range
+	InvalidADRNumber Code = "INVALID_ADR_NUMBER"
+	// InvalidStatus indicates an unknown status value
+	InvalidStatus Code = "INVALID_STATUS"
+	// InvalidCategory indicates an unusable category name
+	InvalidCategory Code = "INVALID_CATEGORY"
+	// ContentTooLong indicates content exceeding its maximum length
+	ContentTooLong Code = "CONTENT_TOO_LONG"
+	// ParseError indicates that a Markdown file could not be parsed
+	ParseError Code = "PARSE_ERROR"
+)
+
+// Business logic errors
+const (
+	// TaskNotFound indicates that no task has the given ID
+	TaskNotFound Code = "TASK_NOT_FOUND"
+	// ADRNotFound indicates that no ADR has the given number
+	ADRNotFound Code = "ADR_NOT_FOUND"
+	// CategoryNotFound indicates that no task.md header has the given category
+	CategoryNotFound Code = "CATEGORY_NOT_FOUND"
+	// CategoryNotEmpty indicates a category that still holds tasks
+	CategoryNotEmpty Code = "CATEGORY_NOT_EMPTY"
+	// ReferenceTaskNotFound indicates that a referenced task does not exist
+	ReferenceTaskNotFound Code = "REFERENCE_TASK_NOT_FOUND"
+	// InvalidPosition indicates an unusable reorder position
+	InvalidPosition Code = "INVALID_POSITION"
+	// TaskLimitExceeded indicates that no more task IDs can be allocated
+	TaskLimitExceeded Code = "TASK_LIMIT_EXCEEDED"
+	// ADRLimitExceeded indicates that no more ADR numbers can be allocated
+	ADRLimitExceeded Code = "ADR_LIMIT_EXCEEDED"
+	// ProjectNotFound indicates that no project has the given name
+	ProjectNotFound Code = "PROJECT_NOT_FOUND"
+	// ProjectRequired indicates that several projects are open and none was named
+	ProjectRequired Code = "PROJECT_REQUIRED"
+	// MergeConflict indicates changes that could not be merged automatically
+	MergeConflict Code = "MERGE_CONFLICT"
+)
+
+// Internal is used for errors that carry no code of their own
+const Internal Code = "INTERNAL_ERROR"
+
+// Error is an error annotated with a Code and optional details
+type Error struct {
+	Err     error          `json:"-"`
+	Details map[string]any `json:"details,omitempty"`
+	Code    Code           `json:"code"`
+	Message string         `json:"message"`
+}
+
+// New creates a new Error with a formatted message
+func New(code Code, format string, args ...any) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// Wrap annotates err with a code and a formatted message.
+// The wrapped error remains reachable through errors.Is and errors.As.
+func Wrap(err error, code Code, format string, args ...any) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+		Err:     err,
+	}
+}
+
+// WrapFS annotates a file system error, choosing FileNotFound or PermissionDenied
+// when the cause is known and falling back to the given code otherwise.
+func WrapFS(err error, fallback Code, format string, args ...any) *Error {
+	code := fallback
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = FileNotFound
+	case errors.Is(err, fs.ErrPermission):
+		code = PermissionDenied
+	}
+	return Wrap(err, code, format, args...)
+}
+
+// WithDetails records the field and value the error refers to, as in the spec's
+// error response format.
+func (e *Error) WithDetails(field string, value any) *Error {
+	return e.WithDetail("field", field).WithDetail("value", value)
+}
+
+// WithDetail records an arbitrary detail on the error
+func (e *Error) WithDetail(key string, value any) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]any)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is an *Error with the same code, so that
+// errors.Is(err, errcode.New(errcode.TaskNotFound, "")) matches by code.
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// CodeOf returns the code of the outermost *Error in err's chain,
+// or Internal if there is none.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return Internal
+}
+
+// HasCode reports whether err carries the given code
+func HasCode(err error, code Code) bool {
+	return err != nil && CodeOf(err) == code
+}
+
+// As returns err as an *Error, converting it to an Internal error if it carries no code
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Code: Internal, Message: err.Error()}
+}
@@ -0,0 +1,81 @@
+package errcode
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrapFS(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		fallback Code
+		want     Code
+	}{
+		{"not exist", fs.ErrNotExist, FileReadError, FileNotFound},
+		{"permission", fs.ErrPermission, FileWriteError, PermissionDenied},
+		{"wrapped not exist", fmt.Errorf("open: %w", fs.ErrNotExist), FileReadError, FileNotFound},
+		{"other", errors.New("disk full"), FileWriteError, FileWriteError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := WrapFS(tt.err, tt.fallback, "failed")
+			if got := CodeOf(err); got != tt.want {
+				t.Errorf("CodeOf() = %v, want %v", got, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("errors.Is(err, %v) = false, want true", tt.err)
+			}
+		})
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Code
+	}{
+		{"coded error", New(TaskNotFound, "task %s not found", "T001"), TaskNotFound},
+		{"wrapped coded error", fmt.Errorf("context: %w", New(InvalidStatus, "bad")), InvalidStatus},
+		{"plain error", errors.New("boom"), Internal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorIsMatchesCode(t *testing.T) {
+	err := fmt.Errorf("update: %w", New(TaskNotFound, "task T001 not found"))
+
+	if !errors.Is(err, New(TaskNotFound, "")) {
+		t.Error("errors.Is() should match an error with the same code")
+	}
+	if errors.Is(err, New(ADRNotFound, "")) {
+		t.Error("errors.Is() should not match an error with a different code")
+	}
+}
+
+func TestErrorMessageAndDetails(t *testing.T) {
+	err := Wrap(errors.New("permission denied"), FileWriteError, "failed to write %s", "task.md").
+		WithDetails("path", ".todo/task.md")
+
+	if got, want := err.Error(), "failed to write task.md: permission denied"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	want := map[string]any{"field": "path", "value": ".todo/task.md"}
+	if diff := cmp.Diff(want, err.Details); diff != "" {
+		t.Errorf("Details mismatch (-want +got):\n%s", diff)
+	}
+}
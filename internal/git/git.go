@@ -0,0 +1,104 @@
+// Package git runs the local git binary for the features that record task
+// changes in the repository holding a project.
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// Repo is a git working tree
+type Repo struct {
+	dir string
+}
+
+// Open returns the working tree containing dir
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	out, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	top, err := filepath.EvalSymlinks(strings.TrimSpace(out))
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileNotFound, "failed to resolve repository %s", out)
+	}
+	return &Repo{dir: top}, nil
+}
+
+// Dir returns the top-level directory of the working tree
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Run runs git in the top-level directory and returns its standard output
+func (r *Repo) Run(ctx context.Context, args ...string) (string, error) {
+	return run(ctx, r.dir, args...)
+}
+
+// CommitDir stages all changes below dir and commits them, and only them,
+// with message. Other staged changes stay staged. It reports whether a
+// commit was made; nothing is committed when dir has no changes.
+func (r *Repo) CommitDir(ctx context.Context, dir, message string) (bool, error) {
+	rel, err := r.Rel(dir)
+	if err != nil {
+		return false, err
+	}
+	if rel == "." {
+		return false, errcode.New(errcode.ValidationError, "%s is the whole repository", dir)
+	}
+	if _, err := r.Run(ctx, "add", "--all", "--", rel); err != nil {
+		return false, err
+	}
+	if _, err := r.Run(ctx, "diff", "--cached", "--quiet", "--", rel); err == nil {
+		return false, nil
+	} else if ExitCode(err) != 1 {
+		return false, err
+	}
+	if _, err := r.Run(ctx, "commit", "--quiet", "--no-verify", "--message", message, "--", rel); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Rel returns path relative to the top-level directory in the form git
+// uses, failing if it is outside the working tree. The parent directory
+// of path must exist.
+func (r *Repo) Rel(path string) (string, error) {
+	parent, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", errcode.WrapFS(err, errcode.FileNotFound, "failed to resolve %s", path)
+	}
+	rel, err := filepath.Rel(r.dir, filepath.Join(parent, filepath.Base(path)))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errcode.New(errcode.ValidationError, "%s is outside the repository at %s", path, r.dir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// run runs git in dir and returns its standard output
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", errcode.Wrap(err, errcode.Internal, "git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// ExitCode returns the status git exited with, or -1 if err is not an exit status
+func ExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,110 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// initRepo creates a repository with one commit, skipping the test if git is not installed
+func initRepo(t *testing.T) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	ctx := context.Background()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "Test"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "commit.gpgsign", "false"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		if _, err := run(ctx, dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo, err := Open(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// writeFile writes a file below the repository
+func writeFile(t *testing.T, repo *Repo, name, content string) {
+	t.Helper()
+	path := filepath.Join(repo.Dir(), name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRepo_CommitDir(t *testing.T) {
+	repo := initRepo(t)
+	ctx := context.Background()
+	dataDir := filepath.Join(repo.Dir(), ".todo")
+
+	writeFile(t, repo, ".todo/task.md", "# Task\n")
+	writeFile(t, repo, "main.go", "package main\n")
+	writeFile(t, repo, "staged.go", "package main\n")
+	if _, err := repo.Run(ctx, "add", "staged.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	committed, err := repo.CommitDir(ctx, dataDir, "todo: create T001 'First'")
+	if err != nil || !committed {
+		t.Fatalf("CommitDir() = %v, %v, want a commit", committed, err)
+	}
+	committed, err = repo.CommitDir(ctx, dataDir, "todo: nothing")
+	if err != nil || committed {
+		t.Errorf("CommitDir() without changes = %v, %v, want no commit", committed, err)
+	}
+
+	log, err := repo.Run(ctx, "log", "--format=%s", "--name-only", "-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("todo: create T001 'First'\n\n.todo/task.md\n", log); diff != "" {
+		t.Errorf("last commit mismatch (-want +got):\n%s", diff)
+	}
+	status, err := repo.Run(ctx, "status", "--porcelain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff("A  staged.go\n?? main.go\n", status); diff != "" {
+		t.Errorf("changes outside the directory were touched (-want +got):\n%s", diff)
+	}
+}
+
+func TestRepo_CommitDirOutside(t *testing.T) {
+	repo := initRepo(t)
+	ctx := context.Background()
+
+	for _, dir := range []string{t.TempDir(), repo.Dir()} {
+		if _, err := repo.CommitDir(ctx, dir, "todo: outside"); !errcode.HasCode(err, errcode.ValidationError) {
+			t.Errorf("CommitDir(%s) error = %v, want %s", dir, err, errcode.ValidationError)
+		}
+	}
+}
+
+func TestOpen_NotARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	_, err := Open(context.Background(), t.TempDir())
+	if err == nil || !strings.Contains(err.Error(), "rev-parse") {
+		t.Errorf("Open() error = %v, want a rev-parse failure", err)
+	}
+}
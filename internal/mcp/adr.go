@@ -0,0 +1,305 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+const (
+	// DefaultADRStatus is the status of new ADRs
+	DefaultADRStatus = "Proposed"
+	// DefaultADRListLimit is the default number of ADRs returned by list_adrs
+	DefaultADRListLimit = 50
+)
+
+// CreateADRParams defines the input parameters for create_adr tool
+type CreateADRParams struct {
+	Title        string `json:"title" description:"ADR title" schema:"minLength=1,maxLength=100"`
+	Context      string `json:"context" description:"Background of the decision" schema:"minLength=1,maxLength=2000"`
+	Decision     string `json:"decision" description:"The decision" schema:"minLength=1,maxLength=2000"`
+	Rationale    string `json:"rationale" description:"Why the decision was made" schema:"minLength=1,maxLength=2000"`
+	Consequences string `json:"consequences,omitempty" description:"Consequences of the decision (optional)" schema:"maxLength=2000"`
+	Status       string `json:"status,omitempty" description:"ADR status" schema:"enum=Proposed|Accepted|Deprecated,default=Proposed"`
+	Project      string `json:"project,omitempty" description:"Project to create the ADR in (optional; required when several projects are open)"`
+}
+
+// CreateADRResult defines the response from create_adr tool
+type CreateADRResult struct {
+	ADRID     string `json:"adr_id" description:"Generated ADR ID" schema:"pattern=^adr-[0-9]{3}-.*$"`
+	ADRNumber int    `json:"adr_number" description:"ADR number" schema:"minimum=1,maximum=999"`
+	Title     string `json:"title" description:"ADR title"`
+	Status    string `json:"status" description:"ADR status" schema:"enum=Proposed|Accepted|Deprecated"`
+	FilePath  string `json:"file_path" description:"Path of the created ADR file"`
+	CreatedAt string `json:"created_at" description:"Creation time" schema:"format=date-time"`
+	Project   string `json:"project" description:"Project the ADR was created in"`
+}
+
+// UpdateADRStatusParams defines the input parameters for update_adr_status tool
+type UpdateADRStatusParams struct {
+	ADRNumber int    `json:"adr_number" description:"Number of the ADR to update" schema:"minimum=1,maximum=999"`
+	Status    string `json:"status" description:"New status" schema:"enum=Proposed|Accepted|Deprecated"`
+	Reason    string `json:"reason,omitempty" description:"Reason for the change, kept in the status history" schema:"maxLength=500"`
+	Project   string `json:"project,omitempty" description:"Project of the ADR (optional; required when several projects are open)"`
+}
+
+// UpdateADRStatusResult defines the response from update_adr_status tool
+type UpdateADRStatusResult struct {
+	ADRNumber int    `json:"adr_number" description:"ADR number" schema:"minimum=1,maximum=999"`
+	Title     string `json:"title" description:"ADR title"`
+	OldStatus string `json:"old_status" description:"Status before the change"`
+	NewStatus string `json:"new_status" description:"Status after the change"`
+	UpdatedAt string `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project   string `json:"project" description:"Project the ADR belongs to"`
+}
+
+// ListADRsParams defines the input parameters for list_adrs tool
+type ListADRsParams struct {
+	Status  string `json:"status,omitempty" description:"Status filter" schema:"enum=Proposed|Accepted|Deprecated"`
+	Project string `json:"project,omitempty" description:"Project filter (optional; all projects when omitted)"`
+	Limit   int    `json:"limit,omitempty" description:"Maximum number of ADRs" schema:"minimum=1,maximum=100,default=50"`
+}
+
+// ADRSummary describes one ADR in list_adrs results
+type ADRSummary struct {
+	ADRNumber int    `json:"adr_number" description:"ADR number" schema:"minimum=1,maximum=999"`
+	Title     string `json:"title" description:"ADR title"`
+	Status    string `json:"status" description:"ADR status"`
+	CreatedAt string `json:"created_at" description:"Creation time" schema:"format=date-time"`
+	UpdatedAt string `json:"updated_at" description:"Last modification time" schema:"format=date-time"`
+	FilePath  string `json:"file_path" description:"Path of the ADR file"`
+	Project   string `json:"project" description:"Project the ADR belongs to"`
+}
+
+// ListADRsResult defines the response from list_adrs tool
+type ListADRsResult struct {
+	ADRs       []ADRSummary `json:"adrs" description:"Matching ADRs in number order"`
+	TotalCount int          `json:"total_count" description:"Number of matching ADRs before the limit"`
+}
+
+// CreateADRHandler handles the create_adr MCP tool
+func (ts *ToolService) CreateADRHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[CreateADRParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.CreateADR, ts.formatCreatedADR)(ctx, session, params)
+}
+
+// CreateADR writes a new ADR with the next free number
+func (ts *ToolService) CreateADR(ctx context.Context, args CreateADRParams) (CreateADRResult, error) {
+	if err := validateParams(args); err != nil {
+		return CreateADRResult{}, err
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return CreateADRResult{}, err
+	}
+	defer unlock()
+	number, err := p.storage.NextADRNumber()
+	if err != nil {
+		return CreateADRResult{}, err
+	}
+
+	createdAt := time.Now().Format(time.RFC3339)
+	adr := model.NewADR(number, args.Title, args.Context, args.Decision, args.Rationale)
+	adr.Consequences = args.Consequences
+	adr.Date = createdAt
+	if args.Status != "" {
+		adr.Status = args.Status
+	}
+	if err := adr.Validate(); err != nil {
+		return CreateADRResult{}, err
+	}
+
+	path, err := p.storage.WriteNewADR(adr)
+	if err != nil {
+		return CreateADRResult{}, err
+	}
+	ts.commit(ctx, p, "create ADR %d '%s'", number, adr.Title)
+	return CreateADRResult{
+		ADRID:     strings.TrimSuffix(filepath.Base(path), ".md"),
+		ADRNumber: number,
+		Title:     adr.Title,
+		Status:    adr.Status,
+		FilePath:  path,
+		CreatedAt: createdAt,
+		Project:   p.Name,
+	}, nil
+}
+
+// UpdateADRStatusHandler handles the update_adr_status MCP tool
+func (ts *ToolService) UpdateADRStatusHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[UpdateADRStatusParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.UpdateADRStatus, ts.formatUpdatedADR)(ctx, session, params)
+}
+
+// UpdateADRStatus changes the status of an ADR and records the change in its status history
+func (ts *ToolService) UpdateADRStatus(ctx context.Context, args UpdateADRStatusParams) (UpdateADRStatusResult, error) {
+	if err := validateParams(args); err != nil {
+		return UpdateADRStatusResult{}, err
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return UpdateADRStatusResult{}, err
+	}
+	defer unlock()
+	adr, _, err := p.storage.ReadADR(args.ADRNumber)
+	if err != nil {
+		return UpdateADRStatusResult{}, err
+	}
+	if adr.Status == args.Status {
+		return UpdateADRStatusResult{}, errcode.New(errcode.InvalidStatus, "ADR %d is already %s", args.ADRNumber, args.Status).
+			WithDetails("status", args.Status)
+	}
+
+	now := time.Now()
+	entry := fmt.Sprintf("%s: %s → %s", now.Format(time.DateOnly), adr.Status, args.Status)
+	if args.Reason != "" {
+		entry += ": " + args.Reason
+	}
+	oldStatus := adr.Status
+	if adr, err = p.storage.UpdateADRStatus(args.ADRNumber, args.Status, entry); err != nil {
+		return UpdateADRStatusResult{}, err
+	}
+	ts.commit(ctx, p, "mark ADR %d '%s' %s", adr.Number, adr.Title, adr.Status)
+	return UpdateADRStatusResult{
+		ADRNumber: adr.Number,
+		Title:     adr.Title,
+		OldStatus: oldStatus,
+		NewStatus: adr.Status,
+		UpdatedAt: now.Format(time.RFC3339),
+		Project:   p.Name,
+	}, nil
+}
+
+// ListADRsHandler handles the list_adrs MCP tool
+func (ts *ToolService) ListADRsHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[ListADRsParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.ListADRs, ts.formatADRList)(ctx, session, params)
+}
+
+// ListADRs lists the ADRs matching the filters across the selected projects
+func (ts *ToolService) ListADRs(ctx context.Context, args ListADRsParams) (ListADRsResult, error) {
+	if err := validateParams(args); err != nil {
+		return ListADRsResult{}, err
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = DefaultADRListLimit
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	projects, err := ts.selectProjects(ctx, args.Project)
+	if err != nil {
+		return ListADRsResult{}, err
+	}
+
+	result := ListADRsResult{ADRs: []ADRSummary{}}
+	for _, p := range projects {
+		files, err := p.storage.ADRFiles()
+		if err != nil {
+			return ListADRsResult{}, err
+		}
+		adrs, err := p.storage.ReadADRs()
+		if err != nil {
+			return ListADRsResult{}, err
+		}
+		for _, adr := range adrs {
+			if args.Status != "" && adr.Status != args.Status {
+				continue
+			}
+			result.TotalCount++
+			if len(result.ADRs) >= limit {
+				continue
+			}
+			path := files[adr.Number]
+			updatedAt := modTime(path)
+			createdAt := adr.Date
+			if _, err := time.Parse(time.RFC3339, createdAt); err != nil {
+				// ADRs written by hand may carry a plain date or none
+				createdAt = updatedAt
+				if d, err := time.ParseInLocation(time.DateOnly, adr.Date, time.Local); err == nil {
+					createdAt = d.Format(time.RFC3339)
+				}
+			}
+			result.ADRs = append(result.ADRs, ADRSummary{
+				ADRNumber: adr.Number,
+				Title:     adr.Title,
+				Status:    adr.Status,
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				FilePath:  path,
+				Project:   p.Name,
+			})
+		}
+	}
+	return result, nil
+}
+
+// formatCreatedADR renders a create_adr result as text
+func (ts *ToolService) formatCreatedADR(ctx context.Context, result CreateADRResult) string {
+	responseText := fmt.Sprintf("ADR created successfully:\n- ADR: %s\n- Title: %s\n- Status: %s\n- File: %s",
+		result.ADRID, result.Title, result.Status, ts.relativePath(ctx, result.Project, result.FilePath))
+	if ts.multiProject(ctx) {
+		responseText += "\n- Project: " + result.Project
+	}
+	return responseText
+}
+
+// formatUpdatedADR renders an update_adr_status result as text
+func (ts *ToolService) formatUpdatedADR(ctx context.Context, result UpdateADRStatusResult) string {
+	return fmt.Sprintf("ADR-%03d %s: %s → %s", result.ADRNumber, result.Title, result.OldStatus, result.NewStatus)
+}
+
+// formatADRList renders list_adrs results as text
+func (ts *ToolService) formatADRList(ctx context.Context, result ListADRsResult) string {
+	if result.TotalCount == 0 {
+		return "No ADRs found"
+	}
+
+	multi := ts.multiProject(ctx)
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d ADR(s)", result.TotalCount)
+	if len(result.ADRs) < result.TotalCount {
+		fmt.Fprintf(&sb, ", showing %d", len(result.ADRs))
+	}
+	sb.WriteString(":")
+	for _, adr := range result.ADRs {
+		sb.WriteString("\n- ")
+		if multi {
+			sb.WriteString(adr.Project + ":")
+		}
+		fmt.Fprintf(&sb, "ADR-%03d [%s] %s", adr.ADRNumber, adr.Status, adr.Title)
+	}
+	return sb.String()
+}
+
+// AddADRTools adds the create_adr, update_adr_status and list_adrs tools to the MCP server
+func AddADRTools(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[CreateADRParams, CreateADRResult]("create_adr",
+			"Create a new Architecture Decision Record with the next free number", toolService.CreateADRHandler),
+		newServerTool[UpdateADRStatusParams, UpdateADRStatusResult]("update_adr_status",
+			"Change the status of an ADR and record the change in its history", toolService.UpdateADRStatusHandler),
+		newServerTool[ListADRsParams, ListADRsResult]("list_adrs",
+			"List ADRs with an optional status filter", toolService.ListADRsHandler),
+	)
+}
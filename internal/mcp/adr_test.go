@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+func TestADRLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	toolService := NewToolService(dir)
+	ctx := context.Background()
+
+	created, err := toolService.CreateADR(ctx, CreateADRParams{
+		Title:     "Use Markdown files",
+		Context:   "Tasks must be readable without tools",
+		Decision:  "Store tasks in .todo/task.md",
+		Rationale: "Diffs stay reviewable",
+	})
+	if err != nil {
+		t.Fatalf("CreateADR() error = %v", err)
+	}
+	wantPath := filepath.Join(dir, ".todo", "adr", "adr-001-use-markdown-files.md")
+	if created.ADRID != "adr-001-use-markdown-files" || created.ADRNumber != 1 || created.Status != DefaultADRStatus || created.FilePath != wantPath {
+		t.Errorf("CreateADR() = %+v", created)
+	}
+
+	second, err := toolService.CreateADR(ctx, CreateADRParams{
+		Title: "Serve over stdio", Context: "c", Decision: "d", Rationale: "r", Status: "Accepted",
+	})
+	if err != nil || second.ADRNumber != 2 {
+		t.Fatalf("CreateADR() = %+v, %v", second, err)
+	}
+
+	updated, err := toolService.UpdateADRStatus(ctx, UpdateADRStatusParams{ADRNumber: 1, Status: "Accepted", Reason: "agreed in review"})
+	if err != nil {
+		t.Fatalf("UpdateADRStatus() error = %v", err)
+	}
+	if updated.OldStatus != "Proposed" || updated.NewStatus != "Accepted" {
+		t.Errorf("UpdateADRStatus() = %+v", updated)
+	}
+	content, err := os.ReadFile(wantPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Proposed → Accepted: agreed in review") {
+		t.Errorf("ADR file lacks the history entry:\n%s", content)
+	}
+
+	listed, err := toolService.ListADRs(ctx, ListADRsParams{Status: "Accepted"})
+	if err != nil {
+		t.Fatalf("ListADRs() error = %v", err)
+	}
+	var got []string
+	for _, adr := range listed.ADRs {
+		if adr.CreatedAt == "" || adr.UpdatedAt == "" {
+			t.Errorf("ListADRs() ADR %d lacks timestamps: %+v", adr.ADRNumber, adr)
+		}
+		got = append(got, adr.Title)
+	}
+	if diff := cmp.Diff([]string{"Use Markdown files", "Serve over stdio"}, got); diff != "" || listed.TotalCount != 2 {
+		t.Errorf("ListADRs() mismatch (-want +got):\n%s", diff)
+	}
+
+	listed, err = toolService.ListADRs(ctx, ListADRsParams{Limit: 1})
+	if err != nil || len(listed.ADRs) != 1 || listed.TotalCount != 2 {
+		t.Errorf("ListADRs(limit 1) = %+v, %v", listed, err)
+	}
+}
+
+func TestADRErrors(t *testing.T) {
+	dir := t.TempDir()
+	toolService := NewToolService(dir)
+	ctx := context.Background()
+	if _, err := toolService.CreateADR(ctx, CreateADRParams{Title: "A", Context: "c", Decision: "d", Rationale: "r"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		call     func() error
+		wantCode errcode.Code
+	}{
+		{
+			name: "missing rationale",
+			call: func() error {
+				_, err := toolService.CreateADR(ctx, CreateADRParams{Title: "B", Context: "c", Decision: "d"})
+				return err
+			},
+			wantCode: errcode.ValidationError,
+		},
+		{
+			name: "unknown ADR",
+			call: func() error {
+				_, err := toolService.UpdateADRStatus(ctx, UpdateADRStatusParams{ADRNumber: 7, Status: "Accepted"})
+				return err
+			},
+			wantCode: errcode.ADRNotFound,
+		},
+		{
+			name: "same status",
+			call: func() error {
+				_, err := toolService.UpdateADRStatus(ctx, UpdateADRStatusParams{ADRNumber: 1, Status: "Proposed"})
+				return err
+			},
+			wantCode: errcode.InvalidStatus,
+		},
+		{
+			name: "number out of range",
+			call: func() error {
+				_, err := toolService.UpdateADRStatus(ctx, UpdateADRStatusParams{ADRNumber: 1000, Status: "Accepted"})
+				return err
+			},
+			wantCode: errcode.ValidationError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); errcode.CodeOf(err) != tt.wantCode {
+				t.Errorf("error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+)
+
+// ArchiveTasksParams defines the input parameters for archive_tasks tool
+type ArchiveTasksParams struct {
+	TaskIDs       []string `json:"task_ids,omitempty" description:"Done tasks to archive regardless of age (optional)" schema:"maxItems=100,items.pattern=^[A-Za-z]+[0-9a-z]+$"`
+	OlderThanDays int      `json:"older_than_days,omitempty" description:"Archive done tasks completed at least this many days ago (default the archive.after_days setting, 30)" schema:"minimum=1"`
+	All           bool     `json:"all,omitempty" description:"Archive all done tasks regardless of age"`
+	DryRun        bool     `json:"dry_run,omitempty" description:"Report the tasks that would be archived without moving them"`
+	Project       string   `json:"project,omitempty" description:"Project of the tasks (optional; required when several projects are open)"`
+}
+
+// RestoreTasksParams defines the input parameters for restore_tasks tool
+type RestoreTasksParams struct {
+	TaskIDs []string `json:"task_ids" description:"Archived tasks to move back to task.md" schema:"minItems=1,maxItems=100,items.pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Project string   `json:"project,omitempty" description:"Project of the tasks (optional; required when several projects are open)"`
+}
+
+// ArchivedTask describes one task moved by archive_tasks or restore_tasks
+type ArchivedTask struct {
+	TaskID   string `json:"task_id" description:"Task ID"`
+	Title    string `json:"title" description:"Task title"`
+	Status   string `json:"status" description:"Task status"`
+	Category string `json:"category" description:"Task category"`
+	Archive  string `json:"archive" description:"Month of the archive file holding the task, as YYYY-MM"`
+}
+
+// ArchiveTasksResult defines the response from archive_tasks and restore_tasks tools
+type ArchiveTasksResult struct {
+	Tasks     []ArchivedTask `json:"tasks" description:"Tasks moved out of or back to task.md"`
+	DryRun    bool           `json:"dry_run" description:"Whether the tasks were only reported"`
+	UpdatedAt string         `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project   string         `json:"project" description:"Project the tasks belong to"`
+}
+
+// ArchiveTasksHandler handles the archive_tasks MCP tool
+func (ts *ToolService) ArchiveTasksHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[ArchiveTasksParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.ArchiveTasks, ts.formatArchivedTasks("Archived", "Would archive", "No tasks to archive"))(ctx, session, params)
+}
+
+// ArchiveTasks moves done tasks out of task.md into archive files named by
+// the month they were completed in. Context files stay where they are.
+func (ts *ToolService) ArchiveTasks(ctx context.Context, args ArchiveTasksParams) (ArchiveTasksResult, error) {
+	if err := validateParams(args); err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	if len(args.TaskIDs) > 0 && (args.All || args.OlderThanDays > 0) {
+		return ArchiveTasksResult{}, errcode.New(errcode.ValidationError, "task_ids cannot be combined with all or older_than_days").
+			WithDetails("task_ids", args.TaskIDs)
+	}
+	days := args.OlderThanDays
+	if days == 0 {
+		days = ts.archive.AfterDays
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	defer unlock()
+	tasks, err := readTasks(p)
+	if err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	times, err := p.storage.ReadTimestamps()
+	if err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	workflow := p.storage.Workflow()
+	for _, id := range args.TaskIDs {
+		i, err := findTask(tasks, id)
+		if err != nil {
+			return ArchiveTasksResult{}, err
+		}
+		if !workflow.IsDone(tasks[i].Task.Status) {
+			return ArchiveTasksResult{}, errcode.New(errcode.InvalidStatus, "task %s is %s; only done tasks can be archived", id, tasks[i].Task.Status).
+				WithDetails("task_id", id)
+		}
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -days)
+	archived := make(map[string][]parser.ParsedTask)
+	var kept []parser.ParsedTask
+	result := ArchiveTasksResult{Tasks: []ArchivedTask{}, DryRun: args.DryRun, UpdatedAt: now.Format(time.RFC3339), Project: p.Name}
+	for _, task := range tasks {
+		completed, err := time.Parse(time.RFC3339, times[task.Task.ID].CompletedAt)
+		recorded := err == nil
+		var archive bool
+		switch {
+		case len(args.TaskIDs) > 0:
+			archive = slices.Contains(args.TaskIDs, task.Task.ID)
+		case !workflow.IsDone(task.Task.Status):
+		case args.All:
+			archive = true
+		default:
+			// Tasks without a completion time are archived only when named or with all
+			archive = recorded && !completed.After(cutoff)
+		}
+		if !archive {
+			kept = append(kept, task)
+			continue
+		}
+
+		month := now.Format(storage.ArchiveMonthLayout)
+		if recorded {
+			month = completed.Format(storage.ArchiveMonthLayout)
+		}
+		archived[month] = append(archived[month], task)
+		result.Tasks = append(result.Tasks, archivedTask(task, month))
+	}
+	if len(result.Tasks) == 0 || args.DryRun {
+		return result, nil
+	}
+
+	// Archive files are written first so that a failure cannot lose the tasks
+	archives, err := p.storage.ReadArchives()
+	if err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	for _, month := range slices.Sorted(maps.Keys(archived)) {
+		if err := p.storage.WriteArchiveFile(month, append(archives[month], archived[month]...)); err != nil {
+			return ArchiveTasksResult{}, err
+		}
+	}
+	if err := p.storage.WriteTasksFile(kept); err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	ts.commit(ctx, p, "archive %s", archivedIDs(result.Tasks))
+	return result, nil
+}
+
+// RestoreTasksHandler handles the restore_tasks MCP tool
+func (ts *ToolService) RestoreTasksHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[RestoreTasksParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.RestoreTasks, ts.formatArchivedTasks("Restored", "", "No tasks to restore"))(ctx, session, params)
+}
+
+// RestoreTasks moves archived tasks back to the end of their categories in
+// task.md with their IDs and statuses
+func (ts *ToolService) RestoreTasks(ctx context.Context, args RestoreTasksParams) (ArchiveTasksResult, error) {
+	if err := validateParams(args); err != nil {
+		return ArchiveTasksResult{}, err
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	defer unlock()
+	tasks, err := readTasks(p)
+	if err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	archives, err := p.storage.ReadArchives()
+	if err != nil {
+		return ArchiveTasksResult{}, err
+	}
+
+	result := ArchiveTasksResult{Tasks: []ArchivedTask{}, UpdatedAt: time.Now().Format(time.RFC3339), Project: p.Name}
+	changed := make(map[string]bool)
+	for _, id := range args.TaskIDs {
+		if _, err := findTask(tasks, id); err == nil {
+			return ArchiveTasksResult{}, errcode.New(errcode.ValidationError, "task %s is already in task.md", id).WithDetails("task_id", id)
+		}
+		month, i := findArchivedTask(archives, id)
+		if i < 0 {
+			return ArchiveTasksResult{}, errcode.New(errcode.TaskNotFound, "archived task %s not found", id).WithDetails("task_id", id)
+		}
+		task := archives[month][i]
+		archives[month] = slices.Delete(archives[month], i, i+1)
+		changed[month] = true
+		tasks = append(tasks, task)
+		result.Tasks = append(result.Tasks, archivedTask(task, month))
+	}
+	if len(tasks) > ts.limits.MaxTasks {
+		return ArchiveTasksResult{}, errcode.New(errcode.TaskLimitExceeded, "task limit of %d reached", ts.limits.MaxTasks).
+			WithDetails("task_ids", args.TaskIDs)
+	}
+
+	// task.md is written first so that a failure cannot lose the tasks
+	if err := p.storage.WriteTasksFile(tasks); err != nil {
+		return ArchiveTasksResult{}, err
+	}
+	for _, month := range slices.Sorted(maps.Keys(changed)) {
+		if err := p.storage.WriteArchiveFile(month, archives[month]); err != nil {
+			return ArchiveTasksResult{}, err
+		}
+	}
+	ts.commit(ctx, p, "restore %s", archivedIDs(result.Tasks))
+	return result, nil
+}
+
+// usedTaskIDs returns the IDs of the tasks in task.md and in the archive,
+// which new tasks must not reuse
+func (ts *ToolService) usedTaskIDs(p *project, tasks []parser.ParsedTask) ([]string, error) {
+	ids := ts.extractTaskIDs(tasks)
+	archives, err := p.storage.ReadArchives()
+	if err != nil {
+		return nil, err
+	}
+	for _, archived := range archives {
+		ids = append(ids, ts.extractTaskIDs(archived)...)
+	}
+	return ids, nil
+}
+
+// findArchivedTask returns the month and index of an archived task, or an
+// index of -1 if it is not archived
+func findArchivedTask(archives map[string][]parser.ParsedTask, taskID string) (string, int) {
+	for _, month := range slices.Sorted(maps.Keys(archives)) {
+		if i := slices.IndexFunc(archives[month], func(t parser.ParsedTask) bool { return t.Task.ID == taskID }); i >= 0 {
+			return month, i
+		}
+	}
+	return "", -1
+}
+
+// archivedTask describes a task in an archive file of the given month
+func archivedTask(task parser.ParsedTask, month string) ArchivedTask {
+	return ArchivedTask{
+		TaskID:   task.Task.ID,
+		Title:    task.Task.Title,
+		Status:   task.Task.Status,
+		Category: task.Task.Category,
+		Archive:  month,
+	}
+}
+
+// archivedIDs lists the IDs of moved tasks for auto-commit messages
+func archivedIDs(tasks []ArchivedTask) string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.TaskID)
+	}
+	return strings.Join(ids, ", ")
+}
+
+// formatArchivedTasks returns a renderer of archive_tasks and restore_tasks
+// results as text
+func (ts *ToolService) formatArchivedTasks(verb, plannedVerb, none string) func(context.Context, ArchiveTasksResult) string {
+	return func(ctx context.Context, result ArchiveTasksResult) string {
+		if len(result.Tasks) == 0 {
+			return none
+		}
+		done := verb
+		if result.DryRun {
+			done = plannedVerb
+		}
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "%s %d task(s):", done, len(result.Tasks))
+		for _, task := range result.Tasks {
+			fmt.Fprintf(&sb, "\n- %s [%s] %s (%s, archive %s)", ts.qualifiedID(ctx, result.Project, task.TaskID),
+				task.Status, task.Title, task.Category, task.Archive)
+		}
+		return sb.String()
+	}
+}
+
+// AddArchiveTools adds the archive_tasks and restore_tasks tools to the MCP server
+func AddArchiveTools(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[ArchiveTasksParams, ArchiveTasksResult]("archive_tasks",
+			"Move done tasks completed long ago, or the given done tasks, out of task.md into monthly archive files", toolService.ArchiveTasksHandler),
+		newServerTool[RestoreTasksParams, ArchiveTasksResult]("restore_tasks",
+			"Move archived tasks back to task.md", toolService.RestoreTasksHandler),
+	)
+}
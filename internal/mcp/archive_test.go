@@ -0,0 +1,253 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+)
+
+const archiveTaskFile = `# Task
+
+## Backend
+- [x] Add login API #T001
+  - [x] Hash passwords
+- [x] Fix typo #T002
+- [ ] Add logout #T003
+
+## Docs
+- [x] Write guide #T004
+`
+
+// writeArchiveProject writes a project where T001 was completed in August,
+// T002 yesterday, and T004 has no recorded completion time
+func writeArchiveProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	writeTaskFile(t, dir, archiveTaskFile)
+	fs := storage.NewFileStorage(dir)
+	times := map[string]string{
+		"T001": "2026-08-10T18:00:00Z",
+		"T002": time.Now().AddDate(0, 0, -1).UTC().Format(time.RFC3339),
+	}
+	for id, completed := range times {
+		if err := fs.WriteTimestamps(id, model.Timestamps{CompletedAt: completed}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// taskIDsOf returns the IDs of the tasks in task.md
+func taskIDsOf(t *testing.T, ts *ToolService) []string {
+	t.Helper()
+	result, err := ts.ListTasks(context.Background(), ListTasksParams{})
+	if err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+	var ids []string
+	for _, task := range result.Tasks {
+		ids = append(ids, task.TaskID)
+	}
+	return ids
+}
+
+func TestArchiveTasks(t *testing.T) {
+	month := time.Now().Format(storage.ArchiveMonthLayout)
+	tests := []struct {
+		name      string
+		params    ArchiveTasksParams
+		want      []ArchivedTask
+		wantTasks []string
+	}{
+		{
+			name:   "older than the default",
+			params: ArchiveTasksParams{},
+			want: []ArchivedTask{
+				{TaskID: "T001", Title: "Add login API", Status: "done", Category: "Backend", Archive: "2026-08"},
+			},
+			wantTasks: []string{"T002", "T003", "T004"},
+		},
+		{
+			name:      "older than a day count",
+			params:    ArchiveTasksParams{OlderThanDays: 3650},
+			want:      []ArchivedTask{},
+			wantTasks: []string{"T001", "T002", "T003", "T004"},
+		},
+		{
+			name:   "all",
+			params: ArchiveTasksParams{All: true},
+			want: []ArchivedTask{
+				{TaskID: "T001", Title: "Add login API", Status: "done", Category: "Backend", Archive: "2026-08"},
+				{TaskID: "T002", Title: "Fix typo", Status: "done", Category: "Backend", Archive: month},
+				{TaskID: "T004", Title: "Write guide", Status: "done", Category: "Docs", Archive: month},
+			},
+			wantTasks: []string{"T003"},
+		},
+		{
+			name:   "named tasks",
+			params: ArchiveTasksParams{TaskIDs: []string{"T004"}},
+			want: []ArchivedTask{
+				{TaskID: "T004", Title: "Write guide", Status: "done", Category: "Docs", Archive: month},
+			},
+			wantTasks: []string{"T001", "T002", "T003"},
+		},
+		{
+			name:   "dry run",
+			params: ArchiveTasksParams{All: true, DryRun: true},
+			want: []ArchivedTask{
+				{TaskID: "T001", Title: "Add login API", Status: "done", Category: "Backend", Archive: "2026-08"},
+				{TaskID: "T002", Title: "Fix typo", Status: "done", Category: "Backend", Archive: month},
+				{TaskID: "T004", Title: "Write guide", Status: "done", Category: "Docs", Archive: month},
+			},
+			wantTasks: []string{"T001", "T002", "T003", "T004"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := NewToolService(writeArchiveProject(t))
+			result, err := ts.ArchiveTasks(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("ArchiveTasks() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, result.Tasks); diff != "" {
+				t.Errorf("ArchiveTasks() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantTasks, taskIDsOf(t, ts)); diff != "" {
+				t.Errorf("tasks left in task.md mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestArchiveTasks_Files(t *testing.T) {
+	dir := writeArchiveProject(t)
+	contextDir := filepath.Join(dir, ".todo", "context")
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(contextDir, "T001.md"), []byte("# Context for T001\n\nUse bcrypt\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ts := NewToolService(dir)
+	ctx := context.Background()
+
+	if _, err := ts.ArchiveTasks(ctx, ArchiveTasksParams{}); err != nil {
+		t.Fatalf("ArchiveTasks() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, ".todo", "archive", "2026-08.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Task\n\n## Backend\n- [x] Add login API #T001\n  - [x] Hash passwords\n\n"; string(content) != want {
+		t.Errorf("archive file = %q, want %q", content, want)
+	}
+	if _, err := os.Stat(filepath.Join(contextDir, "T001.md")); err != nil {
+		t.Errorf("context file of an archived task: %v", err)
+	}
+
+	// Archived tasks are still found by search, context included
+	found, err := ts.SearchTasks(ctx, SearchTasksParams{Query: "bcrypt", SearchIn: []string{"context"}, Archived: true})
+	if err != nil {
+		t.Fatalf("SearchTasks() error = %v", err)
+	}
+	if len(found.Results) != 1 || found.Results[0].TaskID != "T001" || found.Results[0].Archive != "2026-08" {
+		t.Errorf("SearchTasks() with archived = %+v", found.Results)
+	}
+	found, err = ts.SearchTasks(ctx, SearchTasksParams{Query: "login"})
+	if err != nil || len(found.Results) != 0 {
+		t.Errorf("SearchTasks() without archived = %+v, %v, want no results", found.Results, err)
+	}
+
+	// Archived IDs are never reused
+	if _, err := ts.ArchiveTasks(ctx, ArchiveTasksParams{TaskIDs: []string{"T004"}}); err != nil {
+		t.Fatalf("ArchiveTasks() error = %v", err)
+	}
+	created, err := ts.CreateTask(ctx, CreateTaskParams{Title: "Add signup"})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if created.TaskID != "T005" {
+		t.Errorf("CreateTask() ID = %s, want T005", created.TaskID)
+	}
+
+	// Restored tasks go back to the end of their categories and their
+	// archive files are removed once empty
+	restored, err := ts.RestoreTasks(ctx, RestoreTasksParams{TaskIDs: []string{"T001"}})
+	if err != nil {
+		t.Fatalf("RestoreTasks() error = %v", err)
+	}
+	want := []ArchivedTask{{TaskID: "T001", Title: "Add login API", Status: "done", Category: "Backend", Archive: "2026-08"}}
+	if diff := cmp.Diff(want, restored.Tasks); diff != "" {
+		t.Errorf("RestoreTasks() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"T002", "T003", "T001", "T005"}, taskIDsOf(t, ts)); diff != "" {
+		t.Errorf("tasks after restore mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".todo", "archive", "2026-08.md")); !os.IsNotExist(err) {
+		t.Errorf("empty archive file still exists: %v", err)
+	}
+	detail, err := ts.GetTask(ctx, GetTaskParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatalf("GetTask() error = %v", err)
+	}
+	if detail.CompletedAt != "2026-08-10T18:00:00Z" || !strings.Contains(detail.Context, "Use bcrypt") {
+		t.Errorf("restored task = %+v", detail)
+	}
+}
+
+func TestArchiveTasks_Errors(t *testing.T) {
+	ts := NewToolService(writeArchiveProject(t))
+	ctx := context.Background()
+	if _, err := ts.ArchiveTasks(ctx, ArchiveTasksParams{TaskIDs: []string{"T004"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		op       func() error
+		wantCode errcode.Code
+	}{
+		{"archive an open task", func() error {
+			_, err := ts.ArchiveTasks(ctx, ArchiveTasksParams{TaskIDs: []string{"T003"}})
+			return err
+		}, errcode.InvalidStatus},
+		{"archive an unknown task", func() error {
+			_, err := ts.ArchiveTasks(ctx, ArchiveTasksParams{TaskIDs: []string{"T009"}})
+			return err
+		}, errcode.TaskNotFound},
+		{"archive named tasks and all", func() error {
+			_, err := ts.ArchiveTasks(ctx, ArchiveTasksParams{TaskIDs: []string{"T001"}, All: true})
+			return err
+		}, errcode.ValidationError},
+		{"restore a task in task.md", func() error {
+			_, err := ts.RestoreTasks(ctx, RestoreTasksParams{TaskIDs: []string{"T001"}})
+			return err
+		}, errcode.ValidationError},
+		{"restore a task not archived", func() error {
+			_, err := ts.RestoreTasks(ctx, RestoreTasksParams{TaskIDs: []string{"T009"}})
+			return err
+		}, errcode.TaskNotFound},
+		{"restore nothing", func() error {
+			_, err := ts.RestoreTasks(ctx, RestoreTasksParams{})
+			return err
+		}, errcode.ValidationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := errcode.CodeOf(tt.op()); code != tt.wantCode {
+				t.Errorf("error code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
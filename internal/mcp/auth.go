@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"crypto/subtle"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// HTTPOptions configures access control for the HTTP transport
+type HTTPOptions struct {
+	// Token, if non-empty, must be presented as "Authorization: Bearer <token>"
+	Token string
+	// AllowedOrigins lists the browser origins (e.g. "https://app.example.com")
+	// allowed to call the server. Requests from loopback origins are always
+	// allowed; requests without an Origin header come from non-browser
+	// clients and are not checked.
+	AllowedOrigins []string
+	// AllowedHosts lists the host names (e.g. "todo.example.com" or
+	// "192.168.1.10") clients may call the server by, "*" allowing any.
+	// Requests must name a loopback host, the host of Addr or one of these
+	// in their Host header, which blocks DNS rebinding.
+	AllowedHosts []string
+	// Addr is the address the server listens on
+	Addr string
+	// Drain, if not nil, is called on shutdown and returns once the writes
+	// in flight have finished; open streams are ended after it returns
+	Drain func()
+}
+
+// withAccessControl wraps next with the origin and bearer token checks
+// described by opts. Denied requests are logged.
+func withAccessControl(next http.Handler, opts *HTTPOptions) http.Handler {
+	if opts == nil {
+		opts = &HTTPOptions{}
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Reject foreign hosts and origins first, to block DNS rebinding
+		if !hostAllowed(req.Host, opts) {
+			log.Printf("Denied request from %s: host %q is not allowed", req.RemoteAddr, req.Host)
+			http.Error(w, "host not allowed", http.StatusForbidden)
+			return
+		}
+		if origin := req.Header.Get("Origin"); origin != "" && !originAllowed(origin, opts.AllowedOrigins) {
+			log.Printf("Denied request from %s: origin %q is not allowed", req.RemoteAddr, origin)
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+
+		if opts.Token != "" && !tokenValid(req, opts.Token) {
+			log.Printf("Denied request from %s: missing or invalid bearer token", req.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+ServerName+`"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+// originAllowed reports whether origin is a loopback origin or in allowed
+func originAllowed(origin string, allowed []string) bool {
+	if slices.Contains(allowed, origin) || slices.Contains(allowed, "*") {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return IsLoopbackHost(u.Hostname())
+}
+
+// hostAllowed reports whether host, the Host header of a request, names a
+// loopback host, the listen address or an allowed host
+func hostAllowed(host string, opts *HTTPOptions) bool {
+	name := hostname(host)
+	if IsLoopbackHost(name) || slices.Contains(opts.AllowedHosts, "*") {
+		return true
+	}
+	if listen := hostname(opts.Addr); name != "" && strings.EqualFold(name, listen) {
+		return true
+	}
+	return slices.ContainsFunc(opts.AllowedHosts, func(allowed string) bool {
+		return strings.EqualFold(name, allowed)
+	})
+}
+
+// hostname returns the host of a "host:port" or "host" address, without
+// the brackets of an IPv6 address
+func hostname(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+}
+
+// tokenValid reports whether req carries the expected bearer token
+func tokenValid(req *http.Request, token string) bool {
+	scheme, credentials, ok := strings.Cut(req.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(credentials), []byte(token)) == 1
+}
+
+// IsLoopbackHost reports whether host names the local machine
+func IsLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
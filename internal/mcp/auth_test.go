@@ -0,0 +1,174 @@
+package mcp
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithAccessControl(t *testing.T) {
+	opts := &HTTPOptions{
+		Token:          "s3cret",
+		AllowedOrigins: []string{"https://app.example.com"},
+		AllowedHosts:   []string{"todo.example.com"},
+		Addr:           "192.168.1.10:8765",
+	}
+
+	tests := []struct {
+		name       string
+		host       string
+		origin     string
+		auth       string
+		wantStatus int
+	}{
+		{"valid token without origin", "", "", "Bearer s3cret", http.StatusOK},
+		{"valid token from allowed origin", "", "https://app.example.com", "Bearer s3cret", http.StatusOK},
+		{"valid token from loopback origin", "", "http://localhost:3000", "Bearer s3cret", http.StatusOK},
+		{"case-insensitive scheme", "", "", "bearer s3cret", http.StatusOK},
+		{"missing token", "", "", "", http.StatusUnauthorized},
+		{"wrong token", "", "", "Bearer nope", http.StatusUnauthorized},
+		{"wrong scheme", "", "", "Basic s3cret", http.StatusUnauthorized},
+		{"foreign origin", "", "https://evil.example", "Bearer s3cret", http.StatusForbidden},
+		{"rebinding origin", "", "http://attacker.test:8765", "Bearer s3cret", http.StatusForbidden},
+		{"localhost host", "localhost:8765", "", "Bearer s3cret", http.StatusOK},
+		{"listen address host", "192.168.1.10:8765", "", "Bearer s3cret", http.StatusOK},
+		{"allowed host", "todo.example.com:8765", "", "Bearer s3cret", http.StatusOK},
+		{"allowed origin host", "app.example.com", "", "Bearer s3cret", http.StatusForbidden},
+		{"spoofed host without origin", "attacker.test:8765", "", "Bearer s3cret", http.StatusForbidden},
+	}
+
+	handler := withAccessControl(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), opts)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, server.URL+StreamablePath, http.NoBody)
+			if err != nil {
+				t.Fatalf("http.NewRequest() error = %v", err)
+			}
+			if tt.host != "" {
+				req.Host = tt.host
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.auth != "" {
+				req.Header.Set("Authorization", tt.auth)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestWithAccessControl_NoToken(t *testing.T) {
+	handler := withAccessControl(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}), nil)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", http.NoBody)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestWithAccessControl_LogsDenied(t *testing.T) {
+	var buf bytes.Buffer
+	prev := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(prev)
+
+	handler := withAccessControl(http.NotFoundHandler(), &HTTPOptions{Token: "s3cret"})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL, "application/json", http.NoBody)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if !strings.Contains(buf.String(), "Denied request") {
+		t.Errorf("expected denied request to be logged, got %q", buf.String())
+	}
+}
+
+func TestNewHTTPHandler_RequiresToken(t *testing.T) {
+	server := httptest.NewServer(NewHTTPHandler(NewServer(), &HTTPOptions{Token: "s3cret"}))
+	defer server.Close()
+
+	body := strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+	resp, err := http.Post(server.URL+StreamablePath, "application/json", body)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+}
+
+func TestIsLoopbackHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want bool
+	}{
+		{"localhost", true},
+		{"127.0.0.1", true},
+		{"::1", true},
+		{"", false},
+		{"0.0.0.0", false},
+		{"example.com", false},
+	}
+
+	for _, tt := range tests {
+		if got := IsLoopbackHost(tt.host); got != tt.want {
+			t.Errorf("IsLoopbackHost(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestHostAllowed(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		opts HTTPOptions
+		want bool
+	}{
+		{"loopback on a wildcard address", "127.0.0.1:8765", HTTPOptions{Addr: ":8765"}, true},
+		{"LAN address on a wildcard address", "192.168.1.10:8765", HTTPOptions{Addr: ":8765"}, false},
+		{"LAN address allowed", "192.168.1.10:8765", HTTPOptions{Addr: "0.0.0.0:8765", AllowedHosts: []string{"192.168.1.10"}}, true},
+		{"any host allowed", "todo.lan:8765", HTTPOptions{Addr: ":8765", AllowedHosts: []string{"*"}}, true},
+		{"IPv6 listen address", "[fd00::1]:8765", HTTPOptions{Addr: "[fd00::1]:8765"}, true},
+		{"host name case", "TODO.example.com", HTTPOptions{AllowedHosts: []string{"todo.example.com"}}, true},
+		{"no host", "", HTTPOptions{Addr: ":8765"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hostAllowed(tt.host, &tt.opts); got != tt.want {
+				t.Errorf("hostAllowed(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/jnst/agentic-todo-mcp/internal/git"
+)
+
+// commitPrefix starts the messages of automatic commits
+const commitPrefix = "todo: "
+
+// commit records the changes a tool made to the data directory of p in a
+// git commit when git.auto_commit is on. Nothing outside the data
+// directory is committed, and nothing is when it did not change. A failed
+// commit is logged rather than returned, since the change itself was made.
+// The caller holds ts.mu.
+func (ts *ToolService) commit(ctx context.Context, p *project, format string, args ...any) {
+	if !ts.autoCommit {
+		return
+	}
+	dataDir := p.storage.DataDir()
+	repo, err := git.Open(ctx, dataDir)
+	if err == nil {
+		_, err = repo.CommitDir(ctx, dataDir, commitPrefix+fmt.Sprintf(format, args...))
+	}
+	if err != nil {
+		log.Printf("Auto-commit of %s failed: %v", dataDir, err)
+	}
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+// gitOutput runs git in dir, failing the test on errors
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func TestAutoCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "-q", "-b", "main")
+	gitOutput(t, dir, "config", "user.name", "Test")
+	gitOutput(t, dir, "config", "user.email", "test@example.com")
+	gitOutput(t, dir, "config", "commit.gpgsign", "false")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Default()
+	cfg.Git.AutoCommit = true
+	ts, err := NewToolServiceWithConfig(workspace.Root{Path: dir, Source: workspace.SourceGit}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := ts.CreateTask(ctx, CreateTaskParams{Title: "Add rate limiter"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.CreateTask(ctx, CreateTaskParams{Title: "Write docs"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Status: "done"}); err != nil {
+		t.Fatal(err)
+	}
+	// Unchanged: no commit
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Status: "done"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.ReorderTask(ctx, ReorderTaskParams{TaskID: "T002", Position: PositionFirst}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.UpdateContext(ctx, UpdateContextParams{TaskID: "T002", Content: "Outline first", Append: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.CreateADR(ctx, CreateADRParams{Title: "Use tokens", Context: "c", Decision: "d", Rationale: "r"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.UpdateADRStatus(ctx, UpdateADRStatusParams{ADRNumber: 1, Status: "Accepted"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"todo: mark ADR 1 'Use tokens' Accepted",
+		"todo: create ADR 1 'Use tokens'",
+		"todo: update context of T002",
+		"todo: move T002 'Write docs' first",
+		"todo: update T001 'Add rate limiter': status",
+		"todo: create T002 'Write docs'",
+		"todo: create T001 'Add rate limiter'",
+	}
+	got := strings.Split(strings.TrimSpace(gitOutput(t, dir, "log", "--format=%s")), "\n")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("commits mismatch (-want +got):\n%s", diff)
+	}
+	if status := gitOutput(t, dir, "status", "--porcelain"); status != "?? main.go\n" {
+		t.Errorf("git status = %q, want only main.go left out", status)
+	}
+}
+
+func TestAutoCommit_NotARepository(t *testing.T) {
+	dir := t.TempDir()
+	cfg := config.Default()
+	cfg.Git.AutoCommit = true
+	ts, err := NewToolServiceWithConfig(workspace.Root{Path: dir, Source: workspace.SourceWorkingDir}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The change is made even though it cannot be committed
+	if _, err := ts.CreateTask(context.Background(), CreateTaskParams{Title: "Outside git"}); err != nil {
+		t.Errorf("CreateTask() error = %v", err)
+	}
+}
@@ -0,0 +1,533 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// ListCategoriesParams defines the input parameters for list_categories tool
+type ListCategoriesParams struct {
+	Project string `json:"project,omitempty" description:"Project to list the categories of (optional; required when several projects are open)"`
+}
+
+// CategoryInfo describes one category in list_categories results
+type CategoryInfo struct {
+	Name     string   `json:"name" description:"Category name"`
+	Position int      `json:"position" description:"Position of the category in task.md, 1 is the first"`
+	TaskIDs  []string `json:"task_ids" description:"IDs of the main-tasks in the category, in priority order"`
+}
+
+// ListCategoriesResult defines the response from list_categories tool
+type ListCategoriesResult struct {
+	Categories []CategoryInfo `json:"categories" description:"Categories in task.md order, including headers without tasks"`
+	Project    string         `json:"project" description:"Project the categories belong to"`
+}
+
+// RenameCategoryParams defines the input parameters for rename_category tool
+type RenameCategoryParams struct {
+	Category string `json:"category" description:"Category to rename" schema:"minLength=1,maxLength=50"`
+	NewName  string `json:"new_name" description:"New category name, not used by another category" schema:"minLength=1,maxLength=50"`
+	Project  string `json:"project,omitempty" description:"Project of the category (optional; required when several projects are open)"`
+}
+
+// RenameCategoryResult defines the response from rename_category tool
+type RenameCategoryResult struct {
+	Category  string   `json:"category" description:"Category name before the change"`
+	NewName   string   `json:"new_name" description:"Category name after the change"`
+	TaskIDs   []string `json:"task_ids" description:"IDs of the tasks whose category changed"`
+	UpdatedAt string   `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project   string   `json:"project" description:"Project the category belongs to"`
+}
+
+// MergeCategoriesParams defines the input parameters for merge_categories tool
+type MergeCategoriesParams struct {
+	Categories []string `json:"categories" description:"Categories whose tasks move to the end of the target, in this order" schema:"minItems=1,maxItems=20,items.minLength=1,items.maxLength=50"`
+	Target     string   `json:"target" description:"Category to merge into; created in place of the first merged category if it does not exist" schema:"minLength=1,maxLength=50"`
+	Project    string   `json:"project,omitempty" description:"Project of the categories (optional; required when several projects are open)"`
+}
+
+// MergeCategoriesResult defines the response from merge_categories tool
+type MergeCategoriesResult struct {
+	Categories []string `json:"categories" description:"Categories merged into the target and removed"`
+	Target     string   `json:"target" description:"Category the tasks moved to"`
+	TaskIDs    []string `json:"task_ids" description:"IDs of the tasks whose category changed"`
+	UpdatedAt  string   `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project    string   `json:"project" description:"Project the categories belong to"`
+}
+
+// ReorderCategoryParams defines the input parameters for reorder_category tool
+type ReorderCategoryParams struct {
+	Category          string `json:"category" description:"Category to move" schema:"minLength=1,maxLength=50"`
+	Position          string `json:"position" description:"Where to move the category in task.md" schema:"enum=first|last|before|after"`
+	ReferenceCategory string `json:"reference_category,omitempty" description:"Category to move before or after (required for before and after)" schema:"maxLength=50"`
+	Project           string `json:"project,omitempty" description:"Project of the category (optional; required when several projects are open)"`
+}
+
+// ReorderCategoryResult defines the response from reorder_category tool
+type ReorderCategoryResult struct {
+	Category    string   `json:"category" description:"Moved category"`
+	OldPosition int      `json:"old_position" description:"Position of the category in task.md before the move, 1 is the first"`
+	NewPosition int      `json:"new_position" description:"Position of the category in task.md after the move"`
+	TaskIDs     []string `json:"task_ids" description:"IDs of the tasks that moved with the category; empty if it kept its position"`
+	UpdatedAt   string   `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project     string   `json:"project" description:"Project the category belongs to"`
+}
+
+// DeleteCategoryParams defines the input parameters for delete_category tool
+type DeleteCategoryParams struct {
+	Category string `json:"category" description:"Category to delete" schema:"minLength=1,maxLength=50"`
+	MoveTo   string `json:"move_to,omitempty" description:"Category to move the tasks of the deleted category to (required unless it has no tasks); created in its place if it does not exist" schema:"maxLength=50"`
+	Project  string `json:"project,omitempty" description:"Project of the category (optional; required when several projects are open)"`
+}
+
+// DeleteCategoryResult defines the response from delete_category tool
+type DeleteCategoryResult struct {
+	Category  string   `json:"category" description:"Deleted category"`
+	MovedTo   string   `json:"moved_to,omitempty" description:"Category the tasks moved to, if there were any"`
+	TaskIDs   []string `json:"task_ids" description:"IDs of the tasks whose category changed"`
+	UpdatedAt string   `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project   string   `json:"project" description:"Project the category belonged to"`
+}
+
+// categoryGroup is a category of task.md with its tasks in priority order
+type categoryGroup struct {
+	name  string
+	tasks []parser.ParsedTask
+}
+
+// categoryEdit changes the categories of task.md and returns the auto-commit
+// message, or an empty message if nothing changed
+type categoryEdit func(groups []categoryGroup) ([]categoryGroup, string, error)
+
+// ListCategoriesHandler handles the list_categories MCP tool
+func (ts *ToolService) ListCategoriesHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[ListCategoriesParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.ListCategories, ts.formatCategoryList)(ctx, session, params)
+}
+
+// ListCategories lists the categories of a project with their tasks
+func (ts *ToolService) ListCategories(ctx context.Context, args ListCategoriesParams) (ListCategoriesResult, error) {
+	if err := validateParams(args); err != nil {
+		return ListCategoriesResult{}, err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	p, err := ts.project(ctx, args.Project)
+	if err != nil {
+		return ListCategoriesResult{}, err
+	}
+	groups, err := ts.readCategories(p)
+	if err != nil {
+		return ListCategoriesResult{}, err
+	}
+
+	result := ListCategoriesResult{Categories: []CategoryInfo{}, Project: p.Name}
+	for i, group := range groups {
+		result.Categories = append(result.Categories, CategoryInfo{Name: group.name, Position: i + 1, TaskIDs: categoryTaskIDs(group.tasks)})
+	}
+	return result, nil
+}
+
+// RenameCategoryHandler handles the rename_category MCP tool
+func (ts *ToolService) RenameCategoryHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[RenameCategoryParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.RenameCategory, ts.formatRenamedCategory)(ctx, session, params)
+}
+
+// RenameCategory renames a category, keeping its place and the order of its tasks
+func (ts *ToolService) RenameCategory(ctx context.Context, args RenameCategoryParams) (RenameCategoryResult, error) {
+	if err := validateParams(args); err != nil {
+		return RenameCategoryResult{}, err
+	}
+	if err := checkCategory(args.NewName); err != nil {
+		return RenameCategoryResult{}, err
+	}
+
+	result := RenameCategoryResult{Category: args.Category, NewName: args.NewName, TaskIDs: []string{}}
+	project, updatedAt, err := ts.editCategories(ctx, args.Project, func(groups []categoryGroup) ([]categoryGroup, string, error) {
+		i, err := findCategory(groups, args.Category, "category")
+		if err != nil || args.NewName == args.Category {
+			return groups, "", err
+		}
+		if slices.ContainsFunc(groups, func(g categoryGroup) bool { return g.name == args.NewName }) {
+			return nil, "", errcode.New(errcode.InvalidCategory, "category %q already exists; merge the categories instead", args.NewName).
+				WithDetails("new_name", args.NewName)
+		}
+		groups[i].name = args.NewName
+		for j := range groups[i].tasks {
+			groups[i].tasks[j].Task.Category = args.NewName
+		}
+		result.TaskIDs = categoryTaskIDs(groups[i].tasks)
+		return groups, fmt.Sprintf("rename category '%s' to '%s'", args.Category, args.NewName), nil
+	})
+	if err != nil {
+		return RenameCategoryResult{}, err
+	}
+	result.UpdatedAt, result.Project = updatedAt, project
+	return result, nil
+}
+
+// MergeCategoriesHandler handles the merge_categories MCP tool
+func (ts *ToolService) MergeCategoriesHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[MergeCategoriesParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.MergeCategories, ts.formatMergedCategories)(ctx, session, params)
+}
+
+// MergeCategories moves the tasks of categories to the end of a target
+// category and removes them
+func (ts *ToolService) MergeCategories(ctx context.Context, args MergeCategoriesParams) (MergeCategoriesResult, error) {
+	if err := validateParams(args); err != nil {
+		return MergeCategoriesResult{}, err
+	}
+	if err := checkCategory(args.Target); err != nil {
+		return MergeCategoriesResult{}, err
+	}
+
+	result := MergeCategoriesResult{Categories: []string{}, Target: args.Target, TaskIDs: []string{}}
+	project, updatedAt, err := ts.editCategories(ctx, args.Project, func(groups []categoryGroup) ([]categoryGroup, string, error) {
+		var merged []int
+		for _, name := range args.Categories {
+			i, err := findCategory(groups, name, "categories")
+			if err != nil {
+				return nil, "", err
+			}
+			if name != args.Target && !slices.Contains(merged, i) {
+				merged = append(merged, i)
+			}
+		}
+		if len(merged) == 0 {
+			return groups, "", nil
+		}
+
+		var tasks []parser.ParsedTask
+		for _, i := range merged {
+			result.Categories = append(result.Categories, groups[i].name)
+			tasks = append(tasks, groups[i].tasks...)
+		}
+		// A new target takes the place of the first merged category
+		at := slices.Min(merged)
+		slices.Sort(merged)
+		for _, i := range slices.Backward(merged) {
+			groups = slices.Delete(groups, i, i+1)
+		}
+		groups, result.TaskIDs = moveToCategory(groups, tasks, args.Target, at)
+		return groups, fmt.Sprintf("merge categories '%s' into '%s'", strings.Join(result.Categories, "', '"), args.Target), nil
+	})
+	if err != nil {
+		return MergeCategoriesResult{}, err
+	}
+	result.UpdatedAt, result.Project = updatedAt, project
+	return result, nil
+}
+
+// ReorderCategoryHandler handles the reorder_category MCP tool
+func (ts *ToolService) ReorderCategoryHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[ReorderCategoryParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.ReorderCategory, ts.formatReorderedCategory)(ctx, session, params)
+}
+
+// ReorderCategory moves a category with its tasks to another place in task.md
+func (ts *ToolService) ReorderCategory(ctx context.Context, args ReorderCategoryParams) (ReorderCategoryResult, error) {
+	if err := validateParams(args); err != nil {
+		return ReorderCategoryResult{}, err
+	}
+	needsReference := args.Position == PositionBefore || args.Position == PositionAfter
+	if needsReference != (args.ReferenceCategory != "") {
+		return ReorderCategoryResult{}, errcode.New(errcode.InvalidPosition,
+			"reference_category is required for before and after, and only for them").WithDetails("position", args.Position)
+	}
+	if args.ReferenceCategory == args.Category {
+		return ReorderCategoryResult{}, errcode.New(errcode.InvalidPosition, "a category cannot be moved relative to itself").
+			WithDetails("reference_category", args.ReferenceCategory)
+	}
+
+	result := ReorderCategoryResult{Category: args.Category, TaskIDs: []string{}}
+	project, updatedAt, err := ts.editCategories(ctx, args.Project, func(groups []categoryGroup) ([]categoryGroup, string, error) {
+		i, err := findCategory(groups, args.Category, "category")
+		if err != nil {
+			return nil, "", err
+		}
+		moved := groups[i]
+		rest := slices.Delete(slices.Clone(groups), i, i+1)
+		var at int
+		switch args.Position {
+		case PositionFirst:
+			at = 0
+		case PositionLast:
+			at = len(rest)
+		default:
+			if at, err = findCategory(rest, args.ReferenceCategory, "reference_category"); err != nil {
+				return nil, "", err
+			}
+			if args.Position == PositionAfter {
+				at++
+			}
+		}
+
+		result.OldPosition, result.NewPosition = i+1, at+1
+		if at == i {
+			return groups, "", nil
+		}
+		result.TaskIDs = categoryTaskIDs(moved.tasks)
+		return slices.Insert(rest, at, moved),
+			fmt.Sprintf("move category '%s' %s", args.Category, strings.TrimSpace(args.Position+" "+args.ReferenceCategory)), nil
+	})
+	if err != nil {
+		return ReorderCategoryResult{}, err
+	}
+	result.UpdatedAt, result.Project = updatedAt, project
+	return result, nil
+}
+
+// DeleteCategoryHandler handles the delete_category MCP tool
+func (ts *ToolService) DeleteCategoryHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[DeleteCategoryParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.DeleteCategory, ts.formatDeletedCategory)(ctx, session, params)
+}
+
+// DeleteCategory removes a category. A category with tasks can only be
+// deleted by moving its tasks to another category.
+func (ts *ToolService) DeleteCategory(ctx context.Context, args DeleteCategoryParams) (DeleteCategoryResult, error) {
+	if err := validateParams(args); err != nil {
+		return DeleteCategoryResult{}, err
+	}
+	if args.MoveTo == args.Category {
+		return DeleteCategoryResult{}, errcode.New(errcode.InvalidCategory, "tasks cannot move to the deleted category").
+			WithDetails("move_to", args.MoveTo)
+	}
+	if err := checkCategory(args.MoveTo); err != nil {
+		return DeleteCategoryResult{}, err
+	}
+
+	result := DeleteCategoryResult{Category: args.Category, TaskIDs: []string{}}
+	project, updatedAt, err := ts.editCategories(ctx, args.Project, func(groups []categoryGroup) ([]categoryGroup, string, error) {
+		i, err := findCategory(groups, args.Category, "category")
+		if err != nil {
+			return nil, "", err
+		}
+		tasks := groups[i].tasks
+		groups = slices.Delete(groups, i, i+1)
+		if len(tasks) == 0 {
+			return groups, fmt.Sprintf("delete category '%s'", args.Category), nil
+		}
+		if args.MoveTo == "" {
+			return nil, "", errcode.New(errcode.CategoryNotEmpty, "category %q has %d task(s); give move_to to move them to another category",
+				args.Category, len(tasks)).WithDetails("task_ids", categoryTaskIDs(tasks))
+		}
+		result.MovedTo = args.MoveTo
+		groups, result.TaskIDs = moveToCategory(groups, tasks, args.MoveTo, i)
+		return groups, fmt.Sprintf("delete category '%s', moving its tasks to '%s'", args.Category, args.MoveTo), nil
+	})
+	if err != nil {
+		return DeleteCategoryResult{}, err
+	}
+	result.UpdatedAt, result.Project = updatedAt, project
+	return result, nil
+}
+
+// editCategories applies edit to the categories of a project and writes
+// task.md if they changed. It returns the project name and the update time.
+func (ts *ToolService) editCategories(ctx context.Context, projectName string, edit categoryEdit) (string, string, error) {
+	p, unlock, err := ts.lockProject(ctx, projectName)
+	if err != nil {
+		return "", "", err
+	}
+	defer unlock()
+	groups, err := ts.readCategories(p)
+	if err != nil {
+		return "", "", err
+	}
+	groups, message, err := edit(groups)
+	if err != nil {
+		return "", "", err
+	}
+	if message != "" {
+		var names []string
+		var tasks []parser.ParsedTask
+		for _, group := range groups {
+			names = append(names, group.name)
+			tasks = append(tasks, group.tasks...)
+		}
+		// Headers without tasks are written back in their places too
+		if err := p.storage.WriteTasksFileWithCategories(names, tasks); err != nil {
+			return "", "", err
+		}
+		ts.commit(ctx, p, "%s", message)
+	}
+	return p.Name, time.Now().Format(time.RFC3339), nil
+}
+
+// readCategories reads the tasks of a project grouped by category, with the
+// categories in task.md order. Headers without tasks give empty groups;
+// tasks above the first header belong to the default category.
+func (ts *ToolService) readCategories(p *project) ([]categoryGroup, error) {
+	tasks, err := readTasks(p)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := p.storage.ReadCategories()
+	if err != nil && !errcode.HasCode(err, errcode.FileNotFound) {
+		return nil, err
+	}
+
+	var groups []categoryGroup
+	group := func(name string) *categoryGroup {
+		i := slices.IndexFunc(groups, func(g categoryGroup) bool { return g.name == name })
+		if i < 0 {
+			groups = append(groups, categoryGroup{name: name})
+			i = len(groups) - 1
+		}
+		return &groups[i]
+	}
+	if slices.ContainsFunc(tasks, func(t parser.ParsedTask) bool { return t.Task.Category == "" }) {
+		group(ts.defaultCategory)
+	}
+	for _, name := range headers {
+		group(name)
+	}
+	for _, task := range tasks {
+		if task.Task.Category == "" {
+			task.Task.Category = ts.defaultCategory
+		}
+		g := group(task.Task.Category)
+		g.tasks = append(g.tasks, task)
+	}
+	return groups, nil
+}
+
+// findCategory returns the index of the category with the given name; key
+// names the parameter it was given in
+func findCategory(groups []categoryGroup, name, key string) (int, error) {
+	i := slices.IndexFunc(groups, func(g categoryGroup) bool { return g.name == name })
+	if i < 0 {
+		return -1, errcode.New(errcode.CategoryNotFound, "category %q not found", name).WithDetails(key, name)
+	}
+	return i, nil
+}
+
+// moveToCategory moves tasks to the end of the category target, creating it
+// at index at if it does not exist, and returns the groups with the IDs of
+// the moved tasks
+func moveToCategory(groups []categoryGroup, tasks []parser.ParsedTask, target string, at int) ([]categoryGroup, []string) {
+	i := slices.IndexFunc(groups, func(g categoryGroup) bool { return g.name == target })
+	if i < 0 {
+		groups = slices.Insert(groups, at, categoryGroup{name: target})
+		i = at
+	}
+	for _, task := range tasks {
+		task.Task.Category = target
+		groups[i].tasks = append(groups[i].tasks, task)
+	}
+	return groups, categoryTaskIDs(tasks)
+}
+
+// categoryTaskIDs returns the IDs of tasks
+func categoryTaskIDs(tasks []parser.ParsedTask) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.Task.ID)
+	}
+	return ids
+}
+
+// formatTaskIDs renders task IDs as a comma-separated list
+func (ts *ToolService) formatTaskIDs(ctx context.Context, project string, ids []string) string {
+	if len(ids) == 0 {
+		return "none"
+	}
+	qualified := make([]string, 0, len(ids))
+	for _, id := range ids {
+		qualified = append(qualified, ts.qualifiedID(ctx, project, id))
+	}
+	return strings.Join(qualified, ", ")
+}
+
+// formatCategoryList renders a list_categories result as text
+func (ts *ToolService) formatCategoryList(ctx context.Context, result ListCategoriesResult) string {
+	if len(result.Categories) == 0 {
+		return "No categories found"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d category(ies):", len(result.Categories))
+	for _, category := range result.Categories {
+		fmt.Fprintf(&sb, "\n%d. %s (%d task(s)): %s", category.Position, category.Name, len(category.TaskIDs),
+			ts.formatTaskIDs(ctx, result.Project, category.TaskIDs))
+	}
+	return sb.String()
+}
+
+// formatRenamedCategory renders a rename_category result as text
+func (ts *ToolService) formatRenamedCategory(ctx context.Context, result RenameCategoryResult) string {
+	if result.Category == result.NewName {
+		return fmt.Sprintf("Category %s is unchanged", result.Category)
+	}
+	return fmt.Sprintf("Renamed category %s to %s; tasks: %s", result.Category, result.NewName,
+		ts.formatTaskIDs(ctx, result.Project, result.TaskIDs))
+}
+
+// formatMergedCategories renders a merge_categories result as text
+func (ts *ToolService) formatMergedCategories(ctx context.Context, result MergeCategoriesResult) string {
+	if len(result.Categories) == 0 {
+		return fmt.Sprintf("Category %s is unchanged", result.Target)
+	}
+	return fmt.Sprintf("Merged %s into %s; tasks moved: %s", strings.Join(result.Categories, ", "), result.Target,
+		ts.formatTaskIDs(ctx, result.Project, result.TaskIDs))
+}
+
+// formatReorderedCategory renders a reorder_category result as text
+func (ts *ToolService) formatReorderedCategory(ctx context.Context, result ReorderCategoryResult) string {
+	return fmt.Sprintf("Category %s moved from position %d to %d; tasks: %s", result.Category,
+		result.OldPosition, result.NewPosition, ts.formatTaskIDs(ctx, result.Project, result.TaskIDs))
+}
+
+// formatDeletedCategory renders a delete_category result as text
+func (ts *ToolService) formatDeletedCategory(ctx context.Context, result DeleteCategoryResult) string {
+	if result.MovedTo == "" {
+		return fmt.Sprintf("Deleted category %s", result.Category)
+	}
+	return fmt.Sprintf("Deleted category %s; tasks moved to %s: %s", result.Category, result.MovedTo,
+		ts.formatTaskIDs(ctx, result.Project, result.TaskIDs))
+}
+
+// AddCategoryTools adds the list_categories, rename_category,
+// merge_categories, reorder_category and delete_category tools to the MCP server
+func AddCategoryTools(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[ListCategoriesParams, ListCategoriesResult]("list_categories",
+			"List the categories of task.md in order with the IDs of their main-tasks", toolService.ListCategoriesHandler),
+		newServerTool[RenameCategoryParams, RenameCategoryResult]("rename_category",
+			"Rename a category in place", toolService.RenameCategoryHandler),
+		newServerTool[MergeCategoriesParams, MergeCategoriesResult]("merge_categories",
+			"Move the main-tasks of categories into a target category and remove them", toolService.MergeCategoriesHandler),
+		newServerTool[ReorderCategoryParams, ReorderCategoryResult]("reorder_category",
+			"Move a category with its main-tasks to another place in task.md", toolService.ReorderCategoryHandler),
+		newServerTool[DeleteCategoryParams, DeleteCategoryResult]("delete_category",
+			"Delete a category that has no main-tasks, or move its main-tasks to another category first", toolService.DeleteCategoryHandler),
+	)
+}
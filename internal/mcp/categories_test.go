@@ -0,0 +1,305 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+const categoryTaskFile = `# Task
+
+## Backend
+- [ ] First #T001
+- [ ] Second #T002
+
+## Frontend
+- [ ] Form #T003
+
+## Ops
+
+## Docs
+- [ ] Guide #T004
+`
+
+// categoryLayout returns the categories of a project as "name: IDs" lines
+func categoryLayout(t *testing.T, toolService *ToolService) []string {
+	t.Helper()
+	result, err := toolService.ListCategories(context.Background(), ListCategoriesParams{})
+	if err != nil {
+		t.Fatalf("ListCategories() error = %v", err)
+	}
+	var layout []string
+	for _, category := range result.Categories {
+		layout = append(layout, category.Name+": "+strings.Join(category.TaskIDs, " "))
+	}
+	return layout
+}
+
+func TestListCategories(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n- [ ] Loose #T005\n\n"+strings.TrimPrefix(categoryTaskFile, "# Task\n\n"))
+	toolService := NewToolService(dir)
+
+	want := []string{"Default: T005", "Backend: T001 T002", "Frontend: T003", "Ops: ", "Docs: T004"}
+	if diff := cmp.Diff(want, categoryLayout(t, toolService)); diff != "" {
+		t.Errorf("ListCategories() mismatch (-want +got):\n%s", diff)
+	}
+
+	result, err := NewToolService(t.TempDir()).ListCategories(context.Background(), ListCategoriesParams{})
+	if err != nil || len(result.Categories) != 0 {
+		t.Errorf("ListCategories() without task.md = %+v, %v, want no categories", result, err)
+	}
+}
+
+func TestCategoryTools(t *testing.T) {
+	tests := []struct {
+		name       string
+		op         func(*ToolService) ([]string, error)
+		wantIDs    []string
+		wantLayout []string
+	}{
+		{
+			name: "rename",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.RenameCategory(context.Background(), RenameCategoryParams{Category: "Frontend", NewName: "Web"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{"T003"},
+			wantLayout: []string{"Backend: T001 T002", "Web: T003", "Ops: ", "Docs: T004"},
+		},
+		{
+			name: "merge into an existing category",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.MergeCategories(context.Background(), MergeCategoriesParams{Categories: []string{"Docs", "Frontend"}, Target: "Backend"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{"T004", "T003"},
+			wantLayout: []string{"Backend: T001 T002 T004 T003", "Ops: "},
+		},
+		{
+			name: "merge into a new category",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.MergeCategories(context.Background(), MergeCategoriesParams{Categories: []string{"Frontend", "Backend", "Ops"}, Target: "App"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{"T003", "T001", "T002"},
+			wantLayout: []string{"App: T003 T001 T002", "Docs: T004"},
+		},
+		{
+			name: "rename an empty category",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.RenameCategory(context.Background(), RenameCategoryParams{Category: "Ops", NewName: "Infra"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{},
+			wantLayout: []string{"Backend: T001 T002", "Frontend: T003", "Infra: ", "Docs: T004"},
+		},
+		{
+			name: "reorder first",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.ReorderCategory(context.Background(), ReorderCategoryParams{Category: "Docs", Position: PositionFirst})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{"T004"},
+			wantLayout: []string{"Docs: T004", "Backend: T001 T002", "Frontend: T003", "Ops: "},
+		},
+		{
+			name: "reorder after",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.ReorderCategory(context.Background(), ReorderCategoryParams{Category: "Backend", Position: PositionAfter, ReferenceCategory: "Frontend"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{"T001", "T002"},
+			wantLayout: []string{"Frontend: T003", "Backend: T001 T002", "Ops: ", "Docs: T004"},
+		},
+		{
+			name: "reorder an empty category",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.ReorderCategory(context.Background(), ReorderCategoryParams{Category: "Ops", Position: PositionFirst})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{},
+			wantLayout: []string{"Ops: ", "Backend: T001 T002", "Frontend: T003", "Docs: T004"},
+		},
+		{
+			name: "delete an empty category",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.DeleteCategory(context.Background(), DeleteCategoryParams{Category: "Ops"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{},
+			wantLayout: []string{"Backend: T001 T002", "Frontend: T003", "Docs: T004"},
+		},
+		{
+			name: "delete moving tasks to an existing category",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.DeleteCategory(context.Background(), DeleteCategoryParams{Category: "Frontend", MoveTo: "Docs"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{"T003"},
+			wantLayout: []string{"Backend: T001 T002", "Ops: ", "Docs: T004 T003"},
+		},
+		{
+			name: "delete moving tasks to a new category",
+			op: func(ts *ToolService) ([]string, error) {
+				result, err := ts.DeleteCategory(context.Background(), DeleteCategoryParams{Category: "Backend", MoveTo: "Core"})
+				return result.TaskIDs, err
+			},
+			wantIDs:    []string{"T001", "T002"},
+			wantLayout: []string{"Core: T001 T002", "Frontend: T003", "Ops: ", "Docs: T004"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTaskFile(t, dir, categoryTaskFile)
+			toolService := NewToolService(dir)
+
+			ids, err := tt.op(toolService)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantIDs, ids); diff != "" {
+				t.Errorf("task IDs mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantLayout, categoryLayout(t, toolService)); diff != "" {
+				t.Errorf("categories mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCategoryTools_Unchanged(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, categoryTaskFile)
+	toolService := NewToolService(dir)
+	ctx := context.Background()
+
+	renamed, err := toolService.RenameCategory(ctx, RenameCategoryParams{Category: "Backend", NewName: "Backend"})
+	if err != nil || len(renamed.TaskIDs) != 0 {
+		t.Errorf("RenameCategory() to the same name = %+v, %v", renamed, err)
+	}
+	reordered, err := toolService.ReorderCategory(ctx, ReorderCategoryParams{Category: "Backend", Position: PositionFirst})
+	if err != nil || len(reordered.TaskIDs) != 0 || reordered.OldPosition != 1 || reordered.NewPosition != 1 {
+		t.Errorf("ReorderCategory() to the same place = %+v, %v", reordered, err)
+	}
+	merged, err := toolService.MergeCategories(ctx, MergeCategoriesParams{Categories: []string{"Docs"}, Target: "Docs"})
+	if err != nil || len(merged.Categories) != 0 {
+		t.Errorf("MergeCategories() into itself = %+v, %v", merged, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != categoryTaskFile {
+		t.Errorf("task.md changed:\n%s", content)
+	}
+}
+
+func TestCategoryTools_KeepsEmptyCategories(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Empty\n\n## Backend\n- [ ] API #T001\n\n## Frontend\n- [ ] Form #T002\n\n## Later\n")
+	toolService := NewToolService(dir)
+
+	if _, err := toolService.RenameCategory(context.Background(), RenameCategoryParams{Category: "Backend", NewName: "Server"}); err != nil {
+		t.Fatalf("RenameCategory() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Task\n\n## Empty\n\n## Server\n- [ ] API #T001\n\n## Frontend\n- [ ] Form #T002\n\n## Later\n\n"
+	if string(content) != want {
+		t.Errorf("task.md = %q, want %q", content, want)
+	}
+
+	// Task edits keep the headers too, including those they leave empty
+	ctx := context.Background()
+	if _, err := toolService.CreateTask(ctx, CreateTaskParams{Title: "Docs", Category: "Frontend"}); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if _, err := toolService.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Status: "done"}); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if _, err := toolService.ArchiveTasks(ctx, ArchiveTasksParams{All: true}); err != nil {
+		t.Fatalf("ArchiveTasks() error = %v", err)
+	}
+	content, err = os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "# Task\n\n## Empty\n\n## Server\n\n## Frontend\n- [ ] Form #T002\n- [ ] Docs #T003\n\n## Later\n\n"
+	if string(content) != want {
+		t.Errorf("task.md after task edits = %q, want %q", content, want)
+	}
+}
+
+func TestCategoryTools_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, categoryTaskFile)
+	toolService := NewToolService(dir)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		op       func() error
+		wantCode errcode.Code
+	}{
+		{"rename unknown category", func() error {
+			_, err := toolService.RenameCategory(ctx, RenameCategoryParams{Category: "Mobile", NewName: "App"})
+			return err
+		}, errcode.CategoryNotFound},
+		{"rename to an existing category", func() error {
+			_, err := toolService.RenameCategory(ctx, RenameCategoryParams{Category: "Frontend", NewName: "Docs"})
+			return err
+		}, errcode.InvalidCategory},
+		{"rename to several lines", func() error {
+			_, err := toolService.RenameCategory(ctx, RenameCategoryParams{Category: "Frontend", NewName: "Web\n## Backend"})
+			return err
+		}, errcode.InvalidCategory},
+		{"merge unknown category", func() error {
+			_, err := toolService.MergeCategories(ctx, MergeCategoriesParams{Categories: []string{"Docs", "Mobile"}, Target: "Backend"})
+			return err
+		}, errcode.CategoryNotFound},
+		{"merge nothing", func() error {
+			_, err := toolService.MergeCategories(ctx, MergeCategoriesParams{Target: "Backend"})
+			return err
+		}, errcode.ValidationError},
+		{"reorder without reference", func() error {
+			_, err := toolService.ReorderCategory(ctx, ReorderCategoryParams{Category: "Docs", Position: PositionBefore})
+			return err
+		}, errcode.InvalidPosition},
+		{"reorder relative to itself", func() error {
+			_, err := toolService.ReorderCategory(ctx, ReorderCategoryParams{Category: "Docs", Position: PositionBefore, ReferenceCategory: "Docs"})
+			return err
+		}, errcode.InvalidPosition},
+		{"reorder relative to unknown category", func() error {
+			_, err := toolService.ReorderCategory(ctx, ReorderCategoryParams{Category: "Docs", Position: PositionBefore, ReferenceCategory: "Mobile"})
+			return err
+		}, errcode.CategoryNotFound},
+		{"delete a category with tasks", func() error {
+			_, err := toolService.DeleteCategory(ctx, DeleteCategoryParams{Category: "Backend"})
+			return err
+		}, errcode.CategoryNotEmpty},
+		{"delete moving tasks to itself", func() error {
+			_, err := toolService.DeleteCategory(ctx, DeleteCategoryParams{Category: "Backend", MoveTo: "Backend"})
+			return err
+		}, errcode.InvalidCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code := errcode.CodeOf(tt.op()); code != tt.wantCode {
+				t.Errorf("error code = %s, want %s", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,344 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/git"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+)
+
+// commitsSection is the context file section commits referencing a task are listed in
+const commitsSection = "Commits"
+
+// shortHashLength is the length commit hashes are abbreviated to
+const shortHashLength = 7
+
+// commitKeywords maps the keywords of task references in commit messages
+// to the workflow stage they move a task to
+var commitKeywords = map[string]int{
+	"fix":        model.StageDone,
+	"fixes":      model.StageDone,
+	"fixed":      model.StageDone,
+	"close":      model.StageDone,
+	"closes":     model.StageDone,
+	"closed":     model.StageDone,
+	"resolve":    model.StageDone,
+	"resolves":   model.StageDone,
+	"resolved":   model.StageDone,
+	"ref":        model.StageActive,
+	"refs":       model.StageActive,
+	"references": model.StageActive,
+}
+
+// SyncCommitsParams defines the input parameters for sync_commits tool
+type SyncCommitsParams struct {
+	Since   string `json:"since,omitempty" description:"Commit to scan after (optional; default the last scanned commit, or all history)"`
+	DryRun  bool   `json:"dry_run,omitempty" description:"Report the changes without making them" schema:"default=false"`
+	Project string `json:"project,omitempty" description:"Project to update (optional; required when several projects are open)"`
+}
+
+// SyncCommitsResult defines the response from sync_commits tool
+type SyncCommitsResult struct {
+	References     []CommitReference `json:"references" description:"Task references found, oldest commit first"`
+	UnknownTaskIDs []string          `json:"unknown_task_ids" description:"Referenced IDs that match no task"`
+	ScannedCommits int               `json:"scanned_commits" description:"Number of commits scanned"`
+	Marker         string            `json:"marker" description:"Last scanned commit; the next scan starts after it unless this was a dry run"`
+	DryRun         bool              `json:"dry_run" description:"Whether the changes were only reported"`
+	Project        string            `json:"project" description:"Project the tasks belong to"`
+}
+
+// CommitReference describes one task referenced by a commit message
+type CommitReference struct {
+	TaskID    string `json:"task_id" description:"Referenced task"`
+	Commit    string `json:"commit" description:"Abbreviated commit hash"`
+	Subject   string `json:"subject" description:"Commit subject"`
+	Keyword   string `json:"keyword" description:"Keyword before the reference, such as fixes or refs"`
+	OldStatus string `json:"old_status" description:"Task status before the commit"`
+	NewStatus string `json:"new_status" description:"Task status after the commit; the same when unchanged"`
+}
+
+// commitMessage is a commit read from git log
+type commitMessage struct {
+	Hash    string
+	Subject string
+	Body    string
+}
+
+// SyncCommitsHandler handles the sync_commits MCP tool
+func (ts *ToolService) SyncCommitsHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[SyncCommitsParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.SyncCommits, ts.formatSyncedCommits)(ctx, session, params)
+}
+
+// SyncCommits scans the commits made since the last scan for task
+// references such as "fixes #T012" or "refs #T013". Fixing, closing and
+// resolving keywords mark a task done; referring ones start a task that is
+// still todo. Each commit is listed in the context file of the tasks it
+// references, and the last commit is stored for the next scan.
+func (ts *ToolService) SyncCommits(ctx context.Context, args SyncCommitsParams) (SyncCommitsResult, error) {
+	if err := validateParams(args); err != nil {
+		return SyncCommitsResult{}, err
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return SyncCommitsResult{}, err
+	}
+	defer unlock()
+	repo, err := git.Open(ctx, p.Root.Path)
+	if err != nil {
+		return SyncCommitsResult{}, err
+	}
+	since := args.Since
+	if since == "" {
+		if since, err = p.storage.ReadCommitMarker(); err != nil {
+			return SyncCommitsResult{}, err
+		}
+	}
+	commits, err := readCommits(ctx, repo, p.Root.Path, since, args.Since != "")
+	if err != nil {
+		return SyncCommitsResult{}, err
+	}
+	tasks, err := readTasks(p)
+	if err != nil {
+		return SyncCommitsResult{}, err
+	}
+	before := taskStatuses(tasks)
+
+	result := SyncCommitsResult{
+		References:     []CommitReference{},
+		UnknownTaskIDs: []string{},
+		ScannedCommits: len(commits),
+		Marker:         since,
+		DryRun:         args.DryRun,
+		Project:        p.Name,
+	}
+	if len(commits) > 0 {
+		result.Marker = commits[len(commits)-1].Hash
+	}
+
+	referenceRegex := taskReferenceRegex(p.storage.IDScheme())
+	idRegex := referencedIDRegex(p.storage.IDScheme())
+	contexts := make(map[string][]string)
+	for _, c := range commits {
+		for _, r := range commitReferences(c, referenceRegex, idRegex) {
+			taskID, keyword := r.TaskID, r.Keyword
+			i, err := findTask(tasks, taskID)
+			if err != nil {
+				if !slices.Contains(result.UnknownTaskIDs, taskID) {
+					result.UnknownTaskIDs = append(result.UnknownTaskIDs, taskID)
+				}
+				continue
+			}
+			ref := CommitReference{
+				TaskID:    taskID,
+				Commit:    c.Hash[:min(shortHashLength, len(c.Hash))],
+				Subject:   c.Subject,
+				Keyword:   keyword,
+				OldStatus: tasks[i].Task.Status,
+				NewStatus: referencedStatus(p.storage.Workflow(), tasks[i].Task.Status, commitKeywords[keyword]),
+			}
+			tasks[i].Task.Status = ref.NewStatus
+			result.References = append(result.References, ref)
+			contexts[taskID] = append(contexts[taskID], fmt.Sprintf("- %s %s (%s)", ref.Commit, ref.Subject, keyword))
+		}
+	}
+	if args.DryRun || len(commits) == 0 {
+		return result, nil
+	}
+
+	if slices.ContainsFunc(result.References, func(r CommitReference) bool { return r.NewStatus != r.OldStatus }) {
+		if err := p.storage.WriteTasksFile(tasks); err != nil {
+			return SyncCommitsResult{}, err
+		}
+		if err := recordTimestamps(p, before, tasks, time.Now()); err != nil {
+			return SyncCommitsResult{}, err
+		}
+	}
+	for _, taskID := range sortedTaskIDs(contexts) {
+		if err := appendCommits(p.storage, taskID, contexts[taskID]); err != nil {
+			return SyncCommitsResult{}, err
+		}
+	}
+	if err := p.storage.WriteCommitMarker(result.Marker); err != nil {
+		return SyncCommitsResult{}, err
+	}
+	ts.commit(ctx, p, "sync tasks with commits up to %s", result.Marker[:min(shortHashLength, len(result.Marker))])
+	return result, nil
+}
+
+// readCommits returns the commits after since that touch dir, oldest first.
+// An empty since reads the whole history. A since that is not a commit,
+// for example a marker left by history that was rewritten, is an error
+// when given explicitly and otherwise also reads the whole history.
+func readCommits(ctx context.Context, repo *git.Repo, dir, since string, explicit bool) ([]commitMessage, error) {
+	if _, err := repo.Run(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// No commits yet
+		return nil, nil
+	}
+	revs := "HEAD"
+	if since != "" {
+		if _, err := repo.Run(ctx, "rev-parse", "--verify", "--quiet", since+"^{commit}"); err == nil {
+			revs = since + "..HEAD"
+		} else if explicit {
+			return nil, errcode.New(errcode.ValidationError, "%s is not a commit", since).WithDetails("since", since)
+		}
+	}
+	args := []string{"log", "--reverse", "--format=%H%x1f%s%x1f%b%x1e", revs}
+	if rel, err := repo.Rel(dir); err != nil {
+		return nil, err
+	} else if rel != "." {
+		// Only commits touching the project reference its tasks
+		args = append(args, "--", rel)
+	}
+	out, err := repo.Run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var commits []commitMessage
+	for _, record := range strings.Split(out, "\x1e") {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), "\x1f", 3)
+		if len(fields) < 3 {
+			continue
+		}
+		commits = append(commits, commitMessage{Hash: fields[0], Subject: fields[1], Body: fields[2]})
+	}
+	return commits, nil
+}
+
+// taskReferenceRegex returns the regular expression matching a keyword
+// followed by one or more task references, as in "Closes #T012, #T013"
+func taskReferenceRegex(ids model.IDScheme) *regexp.Regexp {
+	keywords := make([]string, 0, len(commitKeywords))
+	for keyword := range commitKeywords {
+		keywords = append(keywords, keyword)
+	}
+	// Longest first, so "fixes" is not matched as "fix"
+	slices.SortFunc(keywords, func(a, b string) int { return len(b) - len(a) })
+	id := `#` + ids.Pattern() + `\b`
+	return regexp.MustCompile(`\b((?i:` + strings.Join(keywords, "|") + `)):?\s+(` + id + `(?:(?:\s*,\s*|\s+and\s+|\s+)` + id + `)*)`)
+}
+
+// referencedIDRegex returns the regular expression matching one "#T012"
+// reference, capturing the task ID
+func referencedIDRegex(ids model.IDScheme) *regexp.Regexp {
+	return regexp.MustCompile(`#(` + ids.Pattern() + `)\b`)
+}
+
+// taskReference is a task referenced in a commit message
+type taskReference struct {
+	TaskID  string
+	Keyword string
+}
+
+// commitReferences returns the tasks a commit message references with the
+// keyword of each, in order of appearance. A task referenced with several
+// keywords gets the one moving it furthest.
+func commitReferences(c commitMessage, referenceRegex, idRegex *regexp.Regexp) []taskReference {
+	var refs []taskReference
+	for _, match := range referenceRegex.FindAllStringSubmatch(c.Subject+"\n"+c.Body, -1) {
+		keyword := strings.ToLower(match[1])
+		for _, id := range idRegex.FindAllStringSubmatch(match[2], -1) {
+			i := slices.IndexFunc(refs, func(r taskReference) bool { return r.TaskID == id[1] })
+			switch {
+			case i < 0:
+				refs = append(refs, taskReference{TaskID: id[1], Keyword: keyword})
+			case commitKeywords[refs[i].Keyword] != model.StageDone:
+				refs[i].Keyword = keyword
+			}
+		}
+	}
+	return refs
+}
+
+// referencedStatus returns the status a task moves to when a commit
+// references it with a keyword for stage: the first status of the stage
+// that needs no reason. Tasks never move backwards or against the
+// workflow's transitions, so done and cancelled tasks stay as they are.
+func referencedStatus(workflow model.Workflow, status string, stage int) string {
+	target := workflow.StageStatus(stage)
+	if target == "" || workflow.Stage(status) >= stage || !workflow.CanTransition(status, target) {
+		return status
+	}
+	return target
+}
+
+// appendCommits adds commit lines to the Commits section of a task's
+// context file, skipping commits it already lists
+func appendCommits(fs *storage.FileStorage, taskID string, lines []string) error {
+	current, err := fs.ReadContextFile(taskID)
+	if errcode.HasCode(err, errcode.FileNotFound) {
+		current = model.NewContext(taskID, fmt.Sprintf("# Context for %s\n", taskID))
+	} else if err != nil {
+		return err
+	}
+
+	content := current.Content
+	for _, line := range lines {
+		hash, _, _ := strings.Cut(strings.TrimPrefix(line, "- "), " ")
+		if strings.Contains(content, "- "+hash+" ") {
+			continue
+		}
+		content = parser.SetSection(content, commitsSection, line, true)
+	}
+	if content == current.Content {
+		return nil
+	}
+	return fs.WriteContextFile(model.NewContext(taskID, content))
+}
+
+// sortedTaskIDs returns the keys of m in order
+func sortedTaskIDs(m map[string][]string) []string {
+	ids := make([]string, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// formatSyncedCommits renders a sync_commits result as text
+func (ts *ToolService) formatSyncedCommits(ctx context.Context, result SyncCommitsResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Scanned %d commits", result.ScannedCommits)
+	if result.DryRun {
+		sb.WriteString(" (dry run, nothing changed)")
+	}
+	if len(result.References) == 0 {
+		sb.WriteString("; no task references found")
+	}
+	for _, ref := range result.References {
+		change := ref.NewStatus
+		if ref.NewStatus != ref.OldStatus {
+			change = ref.OldStatus + " -> " + ref.NewStatus
+		}
+		fmt.Fprintf(&sb, "\n%s %s: %s %s (%s)", ts.qualifiedID(ctx, result.Project, ref.TaskID), change, ref.Commit, ref.Subject, ref.Keyword)
+	}
+	if len(result.UnknownTaskIDs) > 0 {
+		fmt.Fprintf(&sb, "\nUnknown tasks: %s", strings.Join(result.UnknownTaskIDs, ", "))
+	}
+	return sb.String()
+}
+
+// AddSyncCommitsTool adds the sync_commits tool to the MCP server
+func AddSyncCommitsTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[SyncCommitsParams, SyncCommitsResult]("sync_commits",
+			"Update tasks referenced as \"fixes #T012\" or \"refs #T013\" in commit messages since the last scan",
+			toolService.SyncCommitsHandler),
+	)
+}
@@ -0,0 +1,228 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+// commitRepo creates a git repository with tasks T001 to T003 and returns
+// its directory and a function committing a file change with a message
+func commitRepo(t *testing.T) (string, func(message string)) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	gitOutput(t, dir, "init", "-q", "-b", "main")
+	gitOutput(t, dir, "config", "user.name", "Test")
+	gitOutput(t, dir, "config", "user.email", "test@example.com")
+	gitOutput(t, dir, "config", "commit.gpgsign", "false")
+	writeTaskFile(t, dir, `# Task
+
+## Auth
+- [ ] Add login endpoint #T001
+- [-] Hash passwords #T002
+- [ ] Rate limit login #T003
+`)
+
+	n := 0
+	return dir, func(message string) {
+		t.Helper()
+		n++
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(strings.Repeat("//\n", n)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		gitOutput(t, dir, "add", "main.go")
+		gitOutput(t, dir, "commit", "-q", "-m", message)
+	}
+}
+
+func TestSyncCommits(t *testing.T) {
+	dir, commit := commitRepo(t)
+	commit("Add login endpoint\n\nFixes #T001, refs #T003 and #T999")
+	commit("Start hashing (refs #T002)")
+	commit("Unrelated change mentioning T002")
+
+	ts := NewToolService(dir)
+	ctx := context.Background()
+
+	dry, err := ts.SyncCommits(ctx, SyncCommitsParams{DryRun: true})
+	if err != nil {
+		t.Fatalf("SyncCommits(dry_run) error = %v", err)
+	}
+	want := SyncCommitsResult{
+		References: []CommitReference{
+			{TaskID: "T001", Subject: "Add login endpoint", Keyword: "fixes", OldStatus: "todo", NewStatus: "done"},
+			{TaskID: "T003", Subject: "Add login endpoint", Keyword: "refs", OldStatus: "todo", NewStatus: "in_progress"},
+			{TaskID: "T002", Subject: "Start hashing (refs #T002)", Keyword: "refs", OldStatus: "in_progress", NewStatus: "in_progress"},
+		},
+		UnknownTaskIDs: []string{"T999"},
+		ScannedCommits: 3,
+		DryRun:         true,
+		Project:        ts.Projects()[0].Name,
+	}
+	ignoreHashes := cmpopts.IgnoreFields(CommitReference{}, "Commit")
+	if diff := cmp.Diff(want, dry, ignoreHashes, cmpopts.IgnoreFields(SyncCommitsResult{}, "Marker")); diff != "" {
+		t.Errorf("SyncCommits(dry_run) mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".todo", "last-commit")); !os.IsNotExist(err) {
+		t.Errorf("dry run should not store a marker, stat error = %v", err)
+	}
+
+	result, err := ts.SyncCommits(ctx, SyncCommitsParams{})
+	if err != nil {
+		t.Fatalf("SyncCommits() error = %v", err)
+	}
+	head := strings.TrimSpace(gitOutput(t, dir, "rev-parse", "HEAD"))
+	if result.Marker != head {
+		t.Errorf("Marker = %q, want HEAD %q", result.Marker, head)
+	}
+	list, err := ts.ListTasks(ctx, ListTasksParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, task := range list.Tasks {
+		statuses = append(statuses, task.TaskID+" "+task.Status)
+	}
+	if diff := cmp.Diff([]string{"T001 done", "T002 in_progress", "T003 in_progress"}, statuses); diff != "" {
+		t.Errorf("statuses mismatch (-want +got):\n%s", diff)
+	}
+	context, err := ts.GetContext(ctx, GetContextParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantContext := "# Context for T001\n\n## Commits\n- " + result.References[0].Commit + " Add login endpoint (fixes)\n"
+	if context.Content != wantContext {
+		t.Errorf("context = %q, want %q", context.Content, wantContext)
+	}
+
+	// The next scan starts after the stored marker
+	commit("Close #T003")
+	result, err = ts.SyncCommits(ctx, SyncCommitsParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ScannedCommits != 1 || len(result.References) != 1 || result.References[0].NewStatus != "done" {
+		t.Errorf("second scan = %+v, want T003 done from one commit", result)
+	}
+
+	// Rescanning from the start lists no commit twice in a context file
+	root := strings.TrimSpace(gitOutput(t, dir, "rev-list", "--max-parents=0", "HEAD"))
+	if _, err := ts.SyncCommits(ctx, SyncCommitsParams{Since: root}); err != nil {
+		t.Fatal(err)
+	}
+	context, err = ts.GetContext(ctx, GetContextParams{TaskID: "T003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(context.Content, "\n- "); got != 2 {
+		t.Errorf("context of T003 lists %d commits, want 2:\n%s", got, context.Content)
+	}
+}
+
+func TestSyncCommits_Errors(t *testing.T) {
+	dir, commit := commitRepo(t)
+	commit("Initial commit")
+	ts := NewToolService(dir)
+
+	_, err := ts.SyncCommits(context.Background(), SyncCommitsParams{Since: "no-such-commit"})
+	if got := errcode.CodeOf(err); got != errcode.ValidationError {
+		t.Errorf("SyncCommits(bad since) code = %v, want %v", got, errcode.ValidationError)
+	}
+
+	plain := t.TempDir()
+	writeTaskFile(t, plain, "# Task\n\n## Default\n- [ ] Task #T001\n")
+	_, err = NewToolService(plain).SyncCommits(context.Background(), SyncCommitsParams{})
+	if err == nil {
+		t.Error("SyncCommits() outside a repository should fail")
+	}
+}
+
+func TestTaskReferenceRegex(t *testing.T) {
+	referenceRegex := taskReferenceRegex(model.DefaultIDScheme)
+	idRegex := referencedIDRegex(model.DefaultIDScheme)
+
+	tests := []struct {
+		message string
+		want    []taskReference
+	}{
+		{"Fix #T001", []taskReference{{"T001", "fix"}}},
+		{"RESOLVED: #T001 #T002", []taskReference{{"T001", "resolved"}, {"T002", "resolved"}}},
+		{"refs #T001 then closes #T001", []taskReference{{"T001", "closes"}}},
+		{"closes #T001 then refs #T001", []taskReference{{"T001", "closes"}}},
+		{"prefix fixes #T0012", nil},
+		{"Mention T001 without a keyword", nil},
+		{"hotfixes #T001", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			got := commitReferences(commitMessage{Subject: tt.message}, referenceRegex, idRegex)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("commitReferences() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSyncCommitsTool(t *testing.T) {
+	dir, commit := commitRepo(t)
+	commit("Fixes #T001")
+	session := connectTestClient(t, dir)
+
+	result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{
+		Name:      "sync_commits",
+		Arguments: map[string]any{"dry_run": true},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	text := result.Content[0].(*mcpsdk.TextContent).Text
+	if !strings.HasPrefix(text, "Scanned 1 commits (dry run, nothing changed)\nT001 todo -> done: ") {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestReferencedStatus(t *testing.T) {
+	review := model.Workflow{
+		Statuses: []model.Status{
+			{Name: "todo", Marker: " "},
+			{Name: "in_progress", Marker: "-"},
+			{Name: "review", Marker: "r"},
+			{Name: "done", Marker: "x", Done: true},
+		},
+		Transitions: map[string][]string{"in_progress": {"review"}},
+	}
+
+	tests := []struct {
+		workflow     model.Workflow
+		status, want string
+		stage        int
+	}{
+		{model.DefaultWorkflow, "todo", "in_progress", model.StageActive},
+		{model.DefaultWorkflow, "done", "done", model.StageActive},
+		{model.DefaultWorkflow, "blocked", "blocked", model.StageActive},
+		{model.DefaultWorkflow, "blocked", "done", model.StageDone},
+		{model.DefaultWorkflow, "cancelled", "cancelled", model.StageDone},
+		{model.DefaultWorkflow, "in_progress", "done", model.StageDone},
+		{review, "review", "done", model.StageDone},
+		// The workflow requires a review before done
+		{review, "in_progress", "in_progress", model.StageDone},
+	}
+	for _, tt := range tests {
+		if got := referencedStatus(tt.workflow, tt.status, tt.stage); got != tt.want {
+			t.Errorf("referencedStatus(%s, %d) = %s, want %s", tt.status, tt.stage, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// UpdateContextParams defines the input parameters for update_context tool
+type UpdateContextParams struct {
+	TaskID  string `json:"task_id" description:"Task ID" schema:"pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Content string `json:"content" description:"Context content" schema:"minLength=1,maxLength=10000"`
+	Append  bool   `json:"append,omitempty" description:"Add to the existing content instead of replacing it" schema:"default=false"`
+	Section string `json:"section,omitempty" description:"Section (## heading) to write to; created if missing" schema:"maxLength=50"`
+	Project string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// UpdateContextResult defines the response from update_context tool
+type UpdateContextResult struct {
+	TaskID    string `json:"task_id" description:"Task ID"`
+	FilePath  string `json:"file_path" description:"Path of the updated context file"`
+	UpdatedAt string `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project   string `json:"project" description:"Project the task belongs to"`
+}
+
+// GetContextParams defines the input parameters for get_context tool
+type GetContextParams struct {
+	TaskID  string `json:"task_id" description:"Task ID" schema:"pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Project string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// GetContextResult defines the response from get_context tool
+type GetContextResult struct {
+	TaskID    string `json:"task_id" description:"Task ID"`
+	Content   string `json:"content" description:"Context content"`
+	FilePath  string `json:"file_path" description:"Path of the context file"`
+	UpdatedAt string `json:"updated_at" description:"Last modification time" schema:"format=date-time"`
+	Project   string `json:"project" description:"Project the task belongs to"`
+}
+
+// UpdateContextHandler handles the update_context MCP tool
+func (ts *ToolService) UpdateContextHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[UpdateContextParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.UpdateContext, ts.formatUpdatedContext)(ctx, session, params)
+}
+
+// UpdateContext replaces or appends to the context file of a main task.
+// With a section, only the body of that section is replaced or appended to.
+func (ts *ToolService) UpdateContext(ctx context.Context, args UpdateContextParams) (UpdateContextResult, error) {
+	if err := validateParams(args); err != nil {
+		return UpdateContextResult{}, err
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return UpdateContextResult{}, err
+	}
+	defer unlock()
+	tasks, err := readTasks(p)
+	if err != nil {
+		return UpdateContextResult{}, err
+	}
+	if _, err := findTask(tasks, args.TaskID); err != nil {
+		return UpdateContextResult{}, err
+	}
+
+	current, err := p.storage.ReadContextFile(args.TaskID)
+	if errcode.HasCode(err, errcode.FileNotFound) {
+		current = model.NewContext(args.TaskID, fmt.Sprintf("# Context for %s\n", args.TaskID))
+	} else if err != nil {
+		return UpdateContextResult{}, err
+	}
+
+	var content string
+	switch {
+	case args.Section != "":
+		content = parser.SetSection(current.Content, args.Section, args.Content, args.Append)
+	case args.Append:
+		content = parser.AppendText(current.Content, args.Content)
+	default:
+		content = args.Content
+	}
+	if err := p.storage.WriteContextFile(model.NewContext(args.TaskID, content)); err != nil {
+		return UpdateContextResult{}, err
+	}
+	ts.commit(ctx, p, "update context of %s", args.TaskID)
+
+	return UpdateContextResult{
+		TaskID:    args.TaskID,
+		FilePath:  p.storage.ContextFilePath(args.TaskID),
+		UpdatedAt: time.Now().Format(time.RFC3339),
+		Project:   p.Name,
+	}, nil
+}
+
+// GetContextHandler handles the get_context MCP tool
+func (ts *ToolService) GetContextHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[GetContextParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.GetContext, func(_ context.Context, result GetContextResult) string { return result.Content })(ctx, session, params)
+}
+
+// GetContext returns the context file of a main task
+func (ts *ToolService) GetContext(ctx context.Context, args GetContextParams) (GetContextResult, error) {
+	if err := validateParams(args); err != nil {
+		return GetContextResult{}, err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	p, err := ts.project(ctx, args.Project)
+	if err != nil {
+		return GetContextResult{}, err
+	}
+	context, err := p.storage.ReadContextFile(args.TaskID)
+	if errcode.HasCode(err, errcode.FileNotFound) {
+		// Tell a missing task apart from a task without a context file
+		tasks, readErr := readTasks(p)
+		if readErr != nil {
+			return GetContextResult{}, readErr
+		}
+		if _, findErr := findTask(tasks, args.TaskID); findErr != nil {
+			return GetContextResult{}, findErr
+		}
+	}
+	if err != nil {
+		return GetContextResult{}, err
+	}
+
+	path := p.storage.ContextFilePath(args.TaskID)
+	return GetContextResult{
+		TaskID:    args.TaskID,
+		Content:   context.Content,
+		FilePath:  path,
+		UpdatedAt: modTime(path),
+		Project:   p.Name,
+	}, nil
+}
+
+// formatUpdatedContext renders an update_context result as text
+func (ts *ToolService) formatUpdatedContext(ctx context.Context, result UpdateContextResult) string {
+	return fmt.Sprintf("Context of %s updated: %s", ts.qualifiedID(ctx, result.Project, result.TaskID),
+		ts.relativePath(ctx, result.Project, result.FilePath))
+}
+
+// AddContextTools adds the update_context and get_context tools to the MCP server
+func AddContextTools(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[UpdateContextParams, UpdateContextResult]("update_context",
+			"Replace or append to the context of a main-task, optionally within one section", toolService.UpdateContextHandler),
+		newServerTool[GetContextParams, GetContextResult]("get_context",
+			"Get the context of a main-task", toolService.GetContextHandler),
+	)
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+func TestUpdateContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		params UpdateContextParams
+		want   string
+	}{
+		{
+			name:   "replace",
+			params: UpdateContextParams{Content: "# Context for T001\n\nRewritten\n"},
+			want:   "# Context for T001\n\nRewritten\n",
+		},
+		{
+			name:   "append",
+			params: UpdateContextParams{Content: "Found the cause", Append: true},
+			want:   "# Context for T001\n\n## Notes\nFirst note\n\nFound the cause\n",
+		},
+		{
+			name:   "append to section",
+			params: UpdateContextParams{Content: "Second note", Section: "Notes", Append: true},
+			want:   "# Context for T001\n\n## Notes\nFirst note\nSecond note\n",
+		},
+		{
+			name:   "new section",
+			params: UpdateContextParams{Content: "Ship it", Section: "Decision"},
+			want:   "# Context for T001\n\n## Notes\nFirst note\n\n## Decision\nShip it\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Task #T001\n")
+			toolService := NewToolService(dir)
+			ctx := context.Background()
+			if _, err := toolService.UpdateContext(ctx, UpdateContextParams{TaskID: "T001", Content: "## Notes\nFirst note", Append: true}); err != nil {
+				t.Fatalf("UpdateContext() error = %v", err)
+			}
+
+			tt.params.TaskID = "T001"
+			if _, err := toolService.UpdateContext(ctx, tt.params); err != nil {
+				t.Fatalf("UpdateContext() error = %v", err)
+			}
+			got, err := toolService.GetContext(ctx, GetContextParams{TaskID: "T001"})
+			if err != nil {
+				t.Fatalf("GetContext() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got.Content); diff != "" {
+				t.Errorf("context mismatch (-want +got):\n%s", diff)
+			}
+			if got.UpdatedAt == "" {
+				t.Error("GetContext() updated_at is empty")
+			}
+		})
+	}
+}
+
+func TestContextErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Task #T001\n")
+	toolService := NewToolService(dir)
+	ctx := context.Background()
+
+	if _, err := toolService.UpdateContext(ctx, UpdateContextParams{TaskID: "T002", Content: "x"}); !errcode.HasCode(err, errcode.TaskNotFound) {
+		t.Errorf("UpdateContext() error = %v, want TASK_NOT_FOUND", err)
+	}
+	if _, err := toolService.GetContext(ctx, GetContextParams{TaskID: "T002"}); !errcode.HasCode(err, errcode.TaskNotFound) {
+		t.Errorf("GetContext() error = %v, want TASK_NOT_FOUND", err)
+	}
+	if _, err := toolService.GetContext(ctx, GetContextParams{TaskID: "T001"}); !errcode.HasCode(err, errcode.FileNotFound) {
+		t.Errorf("GetContext() error = %v, want FILE_NOT_FOUND", err)
+	}
+}
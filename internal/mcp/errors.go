@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"fmt"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// ErrorResponse is the structured content of a failed tool call (doc/mcp-spec.md §6.2)
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes a single tool error
+type ErrorBody struct {
+	Details map[string]any `json:"details,omitempty"`
+	Code    errcode.Code   `json:"code"`
+	Message string         `json:"message"`
+}
+
+// NewErrorResponse converts err into the spec error response format.
+// Errors that carry no code are reported as INTERNAL_ERROR.
+func NewErrorResponse(err error) ErrorResponse {
+	e := errcode.As(err)
+	return ErrorResponse{
+		Error: ErrorBody{
+			Code:    e.Code,
+			Message: e.Error(),
+			Details: e.Details,
+		},
+	}
+}
+
+// toolError converts err into an MCP tool error result.
+// The text content carries the code for clients that only read text,
+// and the structured content carries the full error response.
+func toolError(err error) *mcpsdk.CallToolResultFor[any] {
+	response := NewErrorResponse(err)
+	return &mcpsdk.CallToolResultFor[any]{
+		IsError:           true,
+		Content:           []mcpsdk.Content{&mcpsdk.TextContent{Text: fmt.Sprintf("%s: %s", response.Error.Code, response.Error.Message)}},
+		StructuredContent: response,
+	}
+}
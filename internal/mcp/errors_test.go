@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+func TestNewErrorResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorResponse
+	}{
+		{
+			name: "coded error with details",
+			err:  errcode.New(errcode.TaskNotFound, "task T009 not found").WithDetails("task_id", "T009"),
+			want: ErrorResponse{Error: ErrorBody{
+				Code:    errcode.TaskNotFound,
+				Message: "task T009 not found",
+				Details: map[string]any{"field": "task_id", "value": "T009"},
+			}},
+		},
+		{
+			name: "plain error",
+			err:  errors.New("boom"),
+			want: ErrorResponse{Error: ErrorBody{
+				Code:    errcode.Internal,
+				Message: "boom",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewErrorResponse(tt.err)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NewErrorResponse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToolError(t *testing.T) {
+	result := toolError(errcode.New(errcode.InvalidStatus, "invalid status: foo"))
+
+	if !result.IsError {
+		t.Error("Expected IsError to be true")
+	}
+
+	textContent, ok := result.Content[0].(*mcpsdk.TextContent)
+	if !ok {
+		t.Fatal("Expected TextContent in result")
+	}
+	if want := "INVALID_STATUS: invalid status: foo"; textContent.Text != want {
+		t.Errorf("Text = %q, want %q", textContent.Text, want)
+	}
+
+	response, ok := result.StructuredContent.(ErrorResponse)
+	if !ok {
+		t.Fatalf("StructuredContent = %T, want ErrorResponse", result.StructuredContent)
+	}
+	if response.Error.Code != errcode.InvalidStatus {
+		t.Errorf("Code = %v, want %v", response.Error.Code, errcode.InvalidStatus)
+	}
+}
@@ -0,0 +1,268 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+const (
+	// DefaultListLimit is the default number of tasks returned by list_tasks
+	DefaultListLimit = 50
+)
+
+// ListTasksParams defines the input parameters for list_tasks tool
+type ListTasksParams struct {
+	Status        string `json:"status,omitempty" description:"Status filter, one of the project's workflow statuses"`
+	Category      string `json:"category,omitempty" description:"Category filter"`
+	Assignee      string `json:"assignee,omitempty" description:"Assignee filter"`
+	Tag           string `json:"tag,omitempty" description:"Tag filter"`
+	PriorityLabel string `json:"priority_label,omitempty" description:"Priority label filter" schema:"enum=high|medium|low"`
+	DueBefore     string `json:"due_before,omitempty" description:"Only tasks due on or before this date, as YYYY-MM-DD" schema:"format=date"`
+	Project       string `json:"project,omitempty" description:"Project filter (optional; all projects when omitted)"`
+	Limit         int    `json:"limit,omitempty" description:"Maximum number of tasks" schema:"minimum=1,maximum=100,default=50"`
+}
+
+// TaskSummary describes one task in list_tasks results
+type TaskSummary struct {
+	TaskID        string   `json:"task_id" description:"Task ID"`
+	Title         string   `json:"title" description:"Task title"`
+	Status        string   `json:"status" description:"Task status"`
+	Category      string   `json:"category" description:"Task category"`
+	StatusReason  string   `json:"status_reason,omitempty" description:"Why a blocked or cancelled task is in its status"`
+	SubtasksCount int      `json:"subtasks_count" description:"Number of subtasks, including nested ones"`
+	SubtasksDone  int      `json:"subtasks_done" description:"Number of done subtasks, including nested ones"`
+	Priority      int      `json:"priority" description:"Position within the category, 1 is the highest priority"`
+	Due           string   `json:"due,omitempty" description:"Due date as YYYY-MM-DD" schema:"format=date"`
+	PriorityLabel string   `json:"priority_label,omitempty" description:"Priority label" schema:"enum=high|medium|low"`
+	Assignee      string   `json:"assignee,omitempty" description:"Person the task is assigned to"`
+	Tags          []string `json:"tags,omitempty" description:"Tags"`
+	CreatedAt     string   `json:"created_at,omitempty" description:"Creation time, if recorded" schema:"format=date-time"`
+	StartedAt     string   `json:"started_at,omitempty" description:"Time of the first move to in_progress, if recorded" schema:"format=date-time"`
+	CompletedAt   string   `json:"completed_at,omitempty" description:"Completion time of a done task, if recorded" schema:"format=date-time"`
+	Project       string   `json:"project" description:"Project the task belongs to"`
+}
+
+// metadataFilter selects tasks by their metadata; empty fields match all tasks
+type metadataFilter struct {
+	assignee  string
+	tag       string
+	label     string
+	dueBefore string
+}
+
+// ListTasksResult defines the response from list_tasks tool
+type ListTasksResult struct {
+	Tasks      []TaskSummary `json:"tasks" description:"Matching tasks"`
+	TotalCount int           `json:"total_count" description:"Number of matching tasks before the limit"`
+}
+
+// ListTasksHandler handles the list_tasks MCP tool
+func (ts *ToolService) ListTasksHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[ListTasksParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.ListTasks, ts.formatTaskList)(ctx, session, params)
+}
+
+// ListTasks lists the tasks matching the filters across the selected projects
+func (ts *ToolService) ListTasks(ctx context.Context, args ListTasksParams) (ListTasksResult, error) {
+	if err := validateParams(args); err != nil {
+		return ListTasksResult{}, err
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = DefaultListLimit
+	}
+
+	filter, err := newMetadataFilter(args.Assignee, args.Tag, args.PriorityLabel, args.DueBefore)
+	if err != nil {
+		return ListTasksResult{}, err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	projects, err := ts.selectProjects(ctx, args.Project)
+	if err != nil {
+		return ListTasksResult{}, err
+	}
+	if err := checkStatusFilter(projects, args.Status); err != nil {
+		return ListTasksResult{}, err
+	}
+
+	result := ListTasksResult{Tasks: []TaskSummary{}}
+	for _, p := range projects {
+		tasks, err := readTasks(p)
+		if err != nil {
+			return ListTasksResult{}, err
+		}
+		times, err := p.storage.ReadTimestamps()
+		if err != nil {
+			return ListTasksResult{}, err
+		}
+		for _, task := range summarizeTasks(p, tasks, times) {
+			if args.Status != "" && task.Status != args.Status {
+				continue
+			}
+			if args.Category != "" && task.Category != args.Category {
+				continue
+			}
+			if !filter.matches(task.Due, task.PriorityLabel, task.Assignee, task.Tags) {
+				continue
+			}
+			result.TotalCount++
+			if len(result.Tasks) >= limit {
+				continue
+			}
+			task.StatusReason, err = statusReason(p.storage, model.Task{ID: task.TaskID, Status: task.Status})
+			if err != nil {
+				return ListTasksResult{}, err
+			}
+			result.Tasks = append(result.Tasks, task)
+		}
+	}
+
+	return result, nil
+}
+
+// readTasks reads the tasks of a project; a missing task file has no tasks
+func readTasks(p *project) ([]parser.ParsedTask, error) {
+	tasks, err := p.storage.ReadTasksFile()
+	if errcode.HasCode(err, errcode.FileNotFound) {
+		return nil, nil
+	}
+	return tasks, err
+}
+
+// summarizeTasks converts parsed tasks to summaries with their position in
+// the category and their timestamps, if times holds them
+func summarizeTasks(p *project, tasks []parser.ParsedTask, times map[string]model.Timestamps) []TaskSummary {
+	workflow := p.storage.Workflow()
+	positions := make(map[string]int)
+	summaries := make([]TaskSummary, 0, len(tasks))
+	for _, parsed := range tasks {
+		task := parsed.Task
+		positions[task.Category]++
+		summaries = append(summaries, TaskSummary{
+			TaskID:        task.ID,
+			Title:         task.Title,
+			Status:        task.Status,
+			Category:      task.Category,
+			SubtasksCount: model.CountSubtasks(parsed.SubTasks),
+			SubtasksDone:  model.CountDoneSubtasks(workflow, parsed.SubTasks),
+			Priority:      positions[task.Category],
+			Due:           task.Due,
+			PriorityLabel: task.Priority,
+			Assignee:      task.Assignee,
+			Tags:          slices.Clone(task.Tags),
+			CreatedAt:     times[task.ID].CreatedAt,
+			StartedAt:     times[task.ID].StartedAt,
+			CompletedAt:   times[task.ID].CompletedAt,
+			Project:       p.Name,
+		})
+	}
+	return summaries
+}
+
+// newMetadataFilter checks the metadata filters of list_tasks and search_tasks
+func newMetadataFilter(assignee, tag, label, dueBefore string) (metadataFilter, error) {
+	// Validate the filter values as the metadata of a task
+	probe := model.Task{ID: "-", Title: "-", Status: model.DefaultWorkflow.Initial(), Due: dueBefore, Priority: label, Assignee: assignee}
+	if tag != "" {
+		probe.Tags = []string{tag}
+	}
+	if err := probe.Validate(model.DefaultWorkflow); err != nil {
+		return metadataFilter{}, err
+	}
+	return metadataFilter{assignee: assignee, tag: tag, label: label, dueBefore: dueBefore}, nil
+}
+
+// matches reports whether a task with the given metadata passes the filter.
+// Tasks without a due date never pass a due_before filter.
+func (f metadataFilter) matches(due, label, assignee string, tags []string) bool {
+	switch {
+	case f.assignee != "" && assignee != f.assignee:
+		return false
+	case f.tag != "" && !slices.Contains(tags, f.tag):
+		return false
+	case f.label != "" && label != f.label:
+		return false
+	case f.dueBefore != "" && (due == "" || due > f.dueBefore):
+		return false
+	}
+	return true
+}
+
+// Metadata returns the inline form of the task's metadata, empty if it has none
+func (s TaskSummary) Metadata() string {
+	return model.Task{Due: s.Due, Priority: s.PriorityLabel, Assignee: s.Assignee, Tags: s.Tags}.Metadata()
+}
+
+// formatStatus renders a status with the reason of a blocked or cancelled task
+func formatStatus(status, reason string) string {
+	if reason == "" {
+		return status
+	}
+	return status + ": " + reason
+}
+
+// formatTimestamps renders the dates of the known timestamps of a task as
+// ", created 2026-10-19, started 2026-10-20", empty if none are known
+func formatTimestamps(created, started, completed string) string {
+	var sb strings.Builder
+	for _, stamp := range []struct{ name, value string }{
+		{"created", created},
+		{"started", started},
+		{"completed", completed},
+	} {
+		if stamp.value != "" {
+			date, _, _ := strings.Cut(stamp.value, "T")
+			fmt.Fprintf(&sb, ", %s %s", stamp.name, date)
+		}
+	}
+	return sb.String()
+}
+
+// formatTaskList renders list_tasks results as text.
+// Task IDs are qualified with the project when several projects are open.
+func (ts *ToolService) formatTaskList(ctx context.Context, result ListTasksResult) string {
+	if result.TotalCount == 0 {
+		return "No tasks found"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d task(s)", result.TotalCount)
+	if len(result.Tasks) < result.TotalCount {
+		fmt.Fprintf(&sb, ", showing %d", len(result.Tasks))
+	}
+	sb.WriteString(":")
+	for _, task := range result.Tasks {
+		fmt.Fprintf(&sb, "\n- %s [%s] %s (%s%s)", ts.qualifiedID(ctx, task.Project, task.TaskID),
+			formatStatus(task.Status, task.StatusReason), task.Title,
+			task.Category, formatTimestamps(task.CreatedAt, task.StartedAt, task.CompletedAt))
+		if task.SubtasksCount > 0 {
+			fmt.Fprintf(&sb, " [%d/%d]", task.SubtasksDone, task.SubtasksCount)
+		}
+		if metadata := task.Metadata(); metadata != "" {
+			sb.WriteString(" " + metadata)
+		}
+	}
+	return sb.String()
+}
+
+// AddListTasksTool adds the list_tasks tool to the MCP server
+func AddListTasksTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[ListTasksParams, ListTasksResult]("list_tasks",
+			"List tasks with optional status, category and project filters", toolService.ListTasksHandler),
+	)
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+func TestListTasksHandler(t *testing.T) {
+	root := writeMonorepo(t)
+	toolService := NewToolService(root)
+
+	apiLogin := TaskSummary{TaskID: "T001", Title: "Add login endpoint", Status: "todo", Category: "Backend", SubtasksCount: 1, Priority: 1, Project: "api"}
+	apiDB := TaskSummary{TaskID: "T002", Title: "Set up database", Status: "done", Category: "Backend", Priority: 2, Project: "api"}
+	webForm := TaskSummary{TaskID: "T001", Title: "Build login form", Status: "in_progress", Category: "Frontend", Priority: 1, Project: "web"}
+
+	tests := []struct {
+		name   string
+		params ListTasksParams
+		want   ListTasksResult
+	}{
+		{
+			name:   "all projects",
+			params: ListTasksParams{},
+			want:   ListTasksResult{Tasks: []TaskSummary{apiLogin, apiDB, webForm}, TotalCount: 3},
+		},
+		{
+			name:   "one project",
+			params: ListTasksParams{Project: "web"},
+			want:   ListTasksResult{Tasks: []TaskSummary{webForm}, TotalCount: 1},
+		},
+		{
+			name:   "status filter",
+			params: ListTasksParams{Status: "done"},
+			want:   ListTasksResult{Tasks: []TaskSummary{apiDB}, TotalCount: 1},
+		},
+		{
+			name:   "category filter",
+			params: ListTasksParams{Category: "Frontend"},
+			want:   ListTasksResult{Tasks: []TaskSummary{webForm}, TotalCount: 1},
+		},
+		{
+			name:   "limit",
+			params: ListTasksParams{Limit: 2},
+			want:   ListTasksResult{Tasks: []TaskSummary{apiLogin, apiDB}, TotalCount: 3},
+		},
+		{
+			name:   "no match",
+			params: ListTasksParams{Category: "Docs"},
+			want:   ListTasksResult{Tasks: []TaskSummary{}, TotalCount: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := toolService.ListTasksHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[ListTasksParams]{Arguments: tt.params})
+			if err != nil || result.IsError {
+				t.Fatalf("ListTasksHandler() error = %v, result = %+v", err, result)
+			}
+			if diff := cmp.Diff(tt.want, result.StructuredContent); diff != "" {
+				t.Errorf("ListTasksHandler() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListTasksTool_Text(t *testing.T) {
+	root := writeMonorepo(t)
+	session := connectTestClient(t, root)
+
+	result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{
+		Name:      "list_tasks",
+		Arguments: map[string]any{"status": "in_progress"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	text := result.Content[0].(*mcpsdk.TextContent).Text
+	if want := "1 task(s):\n- web:T001 [in_progress] Build login form (Frontend)"; text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+
+	// Unknown statuses are rejected by the project's workflow
+	result, err = session.CallTool(context.Background(), &mcpsdk.CallToolParams{
+		Name:      "list_tasks",
+		Arguments: map[string]any{"status": "waiting"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("list_tasks should reject an unknown status")
+	}
+}
+
+func TestListTasks_MetadataFilters(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, `# Task
+
+## Default
+- [ ] Add login @alice +auth due:2026-11-01 !high #T001
+- [ ] Write docs @bob +docs due:2026-12-01 #T002
+- [ ] Set up CI +ops +auth !low #T003
+`)
+	toolService := NewToolService(dir)
+
+	tests := []struct {
+		name    string
+		params  ListTasksParams
+		wantIDs []string
+	}{
+		{"assignee", ListTasksParams{Assignee: "alice"}, []string{"T001"}},
+		{"tag", ListTasksParams{Tag: "auth"}, []string{"T001", "T003"}},
+		{"priority label", ListTasksParams{PriorityLabel: "low"}, []string{"T003"}},
+		{"due before includes the day", ListTasksParams{DueBefore: "2026-12-01"}, []string{"T001", "T002"}},
+		{"due before excludes tasks without a due date", ListTasksParams{DueBefore: "2026-11-15"}, []string{"T001"}},
+		{"combined", ListTasksParams{Tag: "auth", PriorityLabel: "high"}, []string{"T001"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := toolService.ListTasks(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("ListTasks() error = %v", err)
+			}
+			ids := []string{}
+			for _, task := range result.Tasks {
+				ids = append(ids, task.TaskID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, ids); diff != "" {
+				t.Errorf("ListTasks() IDs mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	if _, err := toolService.ListTasks(context.Background(), ListTasksParams{DueBefore: "next week"}); errcode.CodeOf(err) != errcode.ValidationError {
+		t.Errorf("ListTasks() with an invalid due_before error = %v, want %s", err, errcode.ValidationError)
+	}
+	result, err := toolService.SearchTasks(context.Background(), SearchTasksParams{Query: "docs", Tag: "auth"})
+	if err != nil || result.TotalMatches != 0 {
+		t.Errorf("SearchTasks() with a tag filter = %+v, %v; want no matches", result, err)
+	}
+}
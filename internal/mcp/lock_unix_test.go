@@ -0,0 +1,45 @@
+//go:build unix
+
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestCreateTask_ConcurrentServices(t *testing.T) {
+	// Two services on one project stand for the server and the CLI, which
+	// only the lock on the data directory serializes
+	tempDir := t.TempDir()
+	services := []*ToolService{NewToolService(tempDir), NewToolService(tempDir)}
+
+	const tasks = 100
+	var wg sync.WaitGroup
+	for i := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := services[i%2].CreateTask(context.Background(), CreateTaskParams{Title: fmt.Sprintf("Task %d", i)}); err != nil {
+				t.Errorf("CreateTask() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	file, err := services[0].ReadTaskFile("")
+	if err != nil {
+		t.Fatalf("ReadTaskFile() error = %v", err)
+	}
+	if len(file.Tasks) != tasks {
+		t.Fatalf("got %d tasks, want %d", len(file.Tasks), tasks)
+	}
+	seen := map[string]bool{}
+	for _, task := range file.Tasks {
+		if seen[task.Task.ID] {
+			t.Errorf("duplicate task ID %s", task.Task.ID)
+		}
+		seen[task.Task.ID] = true
+	}
+}
@@ -0,0 +1,253 @@
+package mcp
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+// project is one project root with its own storage
+type project struct {
+	workspace.Project
+	storage *storage.FileStorage
+}
+
+// view is the workspace as tools see it: the root that selects the default
+// project, and the projects. Sessions following their client's roots have
+// their own; the others share one.
+type view struct {
+	home     workspace.Root
+	projects []*project
+}
+
+// Root returns the workspace root the service was started on or moved to
+func (ts *ToolService) Root() workspace.Root {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.shared.home
+}
+
+// Projects returns the projects the service manages
+func (ts *ToolService) Projects() []workspace.Project {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	projects := make([]workspace.Project, len(ts.shared.projects))
+	for i, p := range ts.shared.projects {
+		projects[i] = p.Project
+	}
+	return projects
+}
+
+// Storage returns the storage of the named project, for commands that work
+// on its files directly
+func (ts *ToolService) Storage(projectName string) (*storage.FileStorage, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	p, err := ts.shared.project(projectName)
+	if err != nil {
+		return nil, err
+	}
+	return p.storage, nil
+}
+
+// SetRoots moves the service to the given workspace roots, the first of
+// which becomes the default. The projects are the directories holding the
+// data directory within the roots, or the roots themselves if there are
+// none. Configured projects are kept. It waits for in-flight writes.
+func (ts *ToolService) SetRoots(roots ...workspace.Root) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if v := ts.newView(roots); v != nil {
+		ts.shared = *v
+	}
+}
+
+// newView returns the view of the given workspace roots, as described for
+// SetRoots, or nil if there are none or the projects are configured.
+// The caller holds ts.mu.
+func (ts *ToolService) newView(roots []workspace.Root) *view {
+	if len(roots) == 0 || ts.fixedProjects {
+		return nil
+	}
+
+	var projectRoots []workspace.Root
+	for _, root := range roots {
+		for _, r := range ts.discover(root) {
+			if !slices.ContainsFunc(projectRoots, func(p workspace.Root) bool { return p.Path == r.Path }) {
+				projectRoots = append(projectRoots, r)
+			}
+		}
+	}
+	return &view{home: roots[0], projects: ts.newProjects(roots[0], projectRoots)}
+}
+
+// discover returns the project roots within root
+func (ts *ToolService) discover(root workspace.Root) []workspace.Root {
+	// An absolute data directory is shared, so there is only one project
+	if filepath.IsAbs(ts.storageOpts.DataDir) || ts.scanDepth == 0 {
+		return []workspace.Root{root}
+	}
+	found, err := workspace.Discover(root.Path, ts.storageOpts.DataDir, ts.scanDepth)
+	if err != nil || len(found) == 0 {
+		return []workspace.Root{root}
+	}
+	if found[0].Path == root.Path {
+		// Keep how the root itself was found
+		found[0] = root
+	}
+	return found
+}
+
+// newProjects returns projects with storages for roots, in the workspace
+// at home
+func (ts *ToolService) newProjects(home workspace.Root, roots []workspace.Root) []*project {
+	var projects []*project
+	for _, p := range workspace.NameProjects(roots) {
+		opts := ts.storageOpts
+		opts.Workflow = ts.projectWorkflow(home, p.Path)
+		projects = append(projects, &project{
+			Project: p,
+			storage: storage.NewFileStorageWithOptions(p.Path, opts),
+		})
+	}
+	return projects
+}
+
+// projectWorkflow returns the workflow of the project at path: the one in
+// its own config file if it has one, otherwise the server's. The project at
+// the workspace root uses the server's config.
+func (ts *ToolService) projectWorkflow(home workspace.Root, path string) model.Workflow {
+	file := config.File(path)
+	if file == "" || path == home.Path {
+		return ts.storageOpts.Workflow
+	}
+	cfg, err := config.Load(path, file, nil)
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		log.Printf("Ignoring the workflow of project %s: %v", path, err)
+		return ts.storageOpts.Workflow
+	}
+	return cfg.TaskWorkflow()
+}
+
+// viewOf returns the view of the session calling a tool, or the shared one.
+// The caller holds ts.mu.
+func (ts *ToolService) viewOf(ctx context.Context) *view {
+	if session, ok := ctx.Value(sessionKey{}).(*mcpsdk.ServerSession); ok {
+		if v, ok := ts.sessions[session]; ok {
+			return v
+		}
+	}
+	return &ts.shared
+}
+
+// project returns the project called name in the caller's view.
+// The caller holds ts.mu.
+func (ts *ToolService) project(ctx context.Context, name string) (*project, error) {
+	return ts.viewOf(ctx).project(name)
+}
+
+// findProject returns the project called name in the caller's view, or nil.
+// The caller holds ts.mu.
+func (ts *ToolService) findProject(ctx context.Context, name string) *project {
+	return ts.viewOf(ctx).findProject(name)
+}
+
+// selectProjects returns the project called name in the caller's view, or
+// all its projects if name is empty.
+// The caller holds ts.mu.
+func (ts *ToolService) selectProjects(ctx context.Context, name string) ([]*project, error) {
+	v := ts.viewOf(ctx)
+	if name == "" {
+		return v.projects, nil
+	}
+	p, err := v.project(name)
+	if err != nil {
+		return nil, err
+	}
+	return []*project{p}, nil
+}
+
+// project returns the project called name. An empty name selects the
+// project at the workspace root, or the only project.
+func (v *view) project(name string) (*project, error) {
+	if name != "" {
+		if p := v.findProject(name); p != nil {
+			return p, nil
+		}
+		return nil, errcode.New(errcode.ProjectNotFound, "project %q not found (projects: %s)", name, v.projectNames()).
+			WithDetails("project", name)
+	}
+
+	if len(v.projects) == 1 {
+		return v.projects[0], nil
+	}
+	for _, p := range v.projects {
+		if p.Path == v.home.Path {
+			return p, nil
+		}
+	}
+	return nil, errcode.New(errcode.ProjectRequired, "several projects are open; choose one of: %s", v.projectNames()).
+		WithDetails("project", name)
+}
+
+// findProject returns the project called name, or nil
+func (v *view) findProject(name string) *project {
+	for _, p := range v.projects {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// projectNames lists the project names for messages
+func (v *view) projectNames() string {
+	names := make([]string, len(v.projects))
+	for i, p := range v.projects {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// multiProject reports whether the caller has several projects open
+func (ts *ToolService) multiProject(ctx context.Context) bool {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return len(ts.viewOf(ctx).projects) > 1
+}
+
+// qualifiedID prefixes a task ID with its project when the caller has
+// several projects open
+func (ts *ToolService) qualifiedID(ctx context.Context, projectName, taskID string) string {
+	if ts.multiProject(ctx) {
+		return projectName + ":" + taskID
+	}
+	return taskID
+}
+
+// relativePath returns path relative to the root of the named project, for display
+func (ts *ToolService) relativePath(ctx context.Context, projectName, path string) string {
+	ts.mu.Lock()
+	p := ts.findProject(ctx, projectName)
+	ts.mu.Unlock()
+	if p == nil {
+		return path
+	}
+	if rel, err := filepath.Rel(p.Path, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+// writeMonorepo creates a workspace with the api and web projects and
+// returns its root
+func writeMonorepo(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	writeTaskFile(t, filepath.Join(root, "services", "api"), `# Task
+
+## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+- [x] Set up database #T002
+`)
+	writeTaskFile(t, filepath.Join(root, "services", "web"), `# Task
+
+## Frontend
+- [-] Build login form #T001
+`)
+	return root
+}
+
+func TestToolService_Projects(t *testing.T) {
+	root := writeMonorepo(t)
+	toolService := NewToolService(root)
+
+	var names []string
+	for _, p := range toolService.Projects() {
+		names = append(names, p.Name)
+	}
+	if diff := cmp.Diff([]string{"api", "web"}, names); diff != "" {
+		t.Errorf("Projects() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestCreateTaskHandler_Projects(t *testing.T) {
+	root := writeMonorepo(t)
+	toolService := NewToolService(root)
+
+	tests := []struct {
+		name     string
+		project  string
+		wantCode errcode.Code
+		wantID   string
+	}{
+		{"project required", "", errcode.ProjectRequired, ""},
+		{"unknown project", "mobile", errcode.ProjectNotFound, ""},
+		{"api project", "api", "", "T003"},
+		{"web project", "web", "", "T002"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := toolService.CreateTaskHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[CreateTaskParams]{
+				Arguments: CreateTaskParams{Title: "New task", Project: tt.project},
+			})
+			if err != nil {
+				t.Fatalf("CreateTaskHandler() error = %v", err)
+			}
+
+			if tt.wantCode != "" {
+				response, ok := result.StructuredContent.(ErrorResponse)
+				if !ok || response.Error.Code != tt.wantCode {
+					t.Errorf("StructuredContent = %+v, want %v", result.StructuredContent, tt.wantCode)
+				}
+				return
+			}
+
+			created, ok := result.StructuredContent.(CreateTaskResult)
+			if !ok {
+				t.Fatalf("StructuredContent = %+v, want CreateTaskResult", result.StructuredContent)
+			}
+			want := filepath.Join(root, "services", tt.project, ".todo", "context", tt.wantID+".md")
+			if created.TaskID != tt.wantID || created.Project != tt.project || created.FilePath != want {
+				t.Errorf("created = %+v, want %s in %s", created, tt.wantID, tt.project)
+			}
+		})
+	}
+}
+
+func TestCreateTaskHandler_DefaultProject(t *testing.T) {
+	// A workspace root holding its own data directory is the default project
+	root := writeMonorepo(t)
+	if err := os.MkdirAll(filepath.Join(root, ".todo"), 0755); err != nil {
+		t.Fatalf("Failed to create .todo: %v", err)
+	}
+	toolService := NewToolService(root)
+
+	result, err := toolService.CreateTaskHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[CreateTaskParams]{
+		Arguments: CreateTaskParams{Title: "Root task"},
+	})
+	if err != nil || result.IsError {
+		t.Fatalf("CreateTaskHandler() error = %v, result = %+v", err, result)
+	}
+	if created := result.StructuredContent.(CreateTaskResult); created.Project != filepath.Base(root) {
+		t.Errorf("Project = %q, want %q", created.Project, filepath.Base(root))
+	}
+}
+
+func TestNewToolServiceWithConfig_Projects(t *testing.T) {
+	root := writeMonorepo(t)
+	cfg := config.Default()
+	cfg.Workspace.Projects = []string{"services/web"}
+
+	toolService, err := NewToolServiceWithConfig(workspace.Root{Path: root, Source: workspace.SourceGit}, cfg)
+	if err != nil {
+		t.Fatalf("NewToolServiceWithConfig() error = %v", err)
+	}
+	want := []workspace.Project{
+		{Name: "web", Root: workspace.Root{Path: filepath.Join(root, "services", "web"), Source: workspace.SourceConfig}},
+	}
+	if diff := cmp.Diff(want, toolService.Projects()); diff != "" {
+		t.Errorf("Projects() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Configured projects do not follow client roots
+	toolService.SetRoots(workspace.Root{Path: t.TempDir(), Source: workspace.SourceClient})
+	if diff := cmp.Diff(want, toolService.Projects()); diff != "" {
+		t.Errorf("Projects() after SetRoots mismatch (-want +got):\n%s", diff)
+	}
+
+	cfg.Workspace.Projects = []string{"services/missing"}
+	if _, err := NewToolServiceWithConfig(workspace.Root{Path: root}, cfg); err == nil {
+		t.Error("NewToolServiceWithConfig() should fail for a missing project")
+	}
+}
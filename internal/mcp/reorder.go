@@ -0,0 +1,202 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// Positions accepted by reorder_task
+const (
+	PositionFirst  = "first"
+	PositionLast   = "last"
+	PositionBefore = "before"
+	PositionAfter  = "after"
+)
+
+// ReorderTaskParams defines the input parameters for reorder_task tool
+type ReorderTaskParams struct {
+	TaskID          string `json:"task_id" description:"ID of the task to move" schema:"pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Position        string `json:"position" description:"Where to move the task within its category" schema:"enum=first|last|before|after"`
+	ReferenceTaskID string `json:"reference_task_id,omitempty" description:"Task in the same category to move before or after (required for before and after)" schema:"pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Project         string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// ReorderTaskResult defines the response from reorder_task tool
+type ReorderTaskResult struct {
+	TaskID      string `json:"task_id" description:"ID of the moved task"`
+	OldPosition int    `json:"old_position" description:"Position within the category before the move, 1 is the highest priority"`
+	NewPosition int    `json:"new_position" description:"Position within the category after the move"`
+	UpdatedAt   string `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project     string `json:"project" description:"Project the task belongs to"`
+}
+
+// TaskFile is the content of a project's task.md
+type TaskFile struct {
+	Project  string
+	Path     string
+	Tasks    []parser.ParsedTask
+	Workflow model.Workflow
+}
+
+// ReorderTaskHandler handles the reorder_task MCP tool
+func (ts *ToolService) ReorderTaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[ReorderTaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.ReorderTask, ts.formatReorderedTask)(ctx, session, params)
+}
+
+// ReorderTask moves a main task within its category, changing its priority
+func (ts *ToolService) ReorderTask(ctx context.Context, args ReorderTaskParams) (ReorderTaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return ReorderTaskResult{}, err
+	}
+	needsReference := args.Position == PositionBefore || args.Position == PositionAfter
+	if needsReference != (args.ReferenceTaskID != "") {
+		return ReorderTaskResult{}, errcode.New(errcode.InvalidPosition,
+			"reference_task_id is required for before and after, and only for them").WithDetails("position", args.Position)
+	}
+	if args.ReferenceTaskID == args.TaskID {
+		return ReorderTaskResult{}, errcode.New(errcode.InvalidPosition, "a task cannot be moved relative to itself").
+			WithDetails("reference_task_id", args.ReferenceTaskID)
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return ReorderTaskResult{}, err
+	}
+	defer unlock()
+	tasks, err := readTasks(p)
+	if err != nil {
+		return ReorderTaskResult{}, err
+	}
+	// Work on the order task.md is written in, so categories keep their places
+	tasks = groupByCategory(tasks)
+	i, err := findTask(tasks, args.TaskID)
+	if err != nil {
+		return ReorderTaskResult{}, err
+	}
+	moved := tasks[i]
+	category := moved.Task.Category
+	oldPosition := summarizeTasks(p, tasks, nil)[i].Priority
+
+	rest := slices.Delete(slices.Clone(tasks), i, i+1)
+	var at int
+	switch args.Position {
+	case PositionFirst, PositionLast:
+		at = categoryBounds(rest, category, args.Position == PositionLast, i)
+	default:
+		ref := slices.IndexFunc(rest, func(t parser.ParsedTask) bool { return t.Task.ID == args.ReferenceTaskID })
+		if ref < 0 {
+			return ReorderTaskResult{}, errcode.New(errcode.ReferenceTaskNotFound, "reference task %s not found", args.ReferenceTaskID).
+				WithDetails("reference_task_id", args.ReferenceTaskID)
+		}
+		if rest[ref].Task.Category != category {
+			return ReorderTaskResult{}, errcode.New(errcode.InvalidPosition, "reference task %s is in category %q, not %q",
+				args.ReferenceTaskID, rest[ref].Task.Category, category).WithDetails("reference_task_id", args.ReferenceTaskID)
+		}
+		at = ref
+		if args.Position == PositionAfter {
+			at++
+		}
+	}
+	tasks = slices.Insert(rest, at, moved)
+
+	result := ReorderTaskResult{
+		TaskID:      args.TaskID,
+		OldPosition: oldPosition,
+		NewPosition: summarizeTasks(p, tasks, nil)[at].Priority,
+		UpdatedAt:   time.Now().Format(time.RFC3339),
+		Project:     p.Name,
+	}
+	if result.NewPosition != result.OldPosition {
+		if err := p.storage.WriteTasksFile(tasks); err != nil {
+			return ReorderTaskResult{}, err
+		}
+		ts.commit(ctx, p, "move %s '%s' %s", args.TaskID, moved.Task.Title, strings.TrimSpace(args.Position+" "+args.ReferenceTaskID))
+	}
+	return result, nil
+}
+
+// ReadTaskFile returns all tasks of a project with the path of its task.md.
+// An empty name selects the default project.
+func (ts *ToolService) ReadTaskFile(projectName string) (TaskFile, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	p, err := ts.shared.project(projectName)
+	if err != nil {
+		return TaskFile{}, err
+	}
+	tasks, err := readTasks(p)
+	if err != nil {
+		return TaskFile{}, err
+	}
+	return TaskFile{Project: p.Name, Path: p.storage.TaskFilePath(), Tasks: groupByCategory(tasks), Workflow: p.storage.Workflow()}, nil
+}
+
+// groupByCategory orders tasks as task.md is written: grouped by category,
+// categories in order of first appearance
+func groupByCategory(tasks []parser.ParsedTask) []parser.ParsedTask {
+	var order []string
+	groups := make(map[string][]parser.ParsedTask)
+	for _, task := range tasks {
+		category := task.Task.Category
+		if _, ok := groups[category]; !ok {
+			order = append(order, category)
+		}
+		groups[category] = append(groups[category], task)
+	}
+
+	grouped := make([]parser.ParsedTask, 0, len(tasks))
+	for _, category := range order {
+		grouped = append(grouped, groups[category]...)
+	}
+	return grouped
+}
+
+// categoryBounds returns the index of the first task of a category, or the
+// index after its last task. A category with no tasks yields fallback.
+func categoryBounds(tasks []parser.ParsedTask, category string, end bool, fallback int) int {
+	first, last := -1, -1
+	for i, task := range tasks {
+		if task.Task.Category == category {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	switch {
+	case first < 0:
+		return fallback
+	case end:
+		return last + 1
+	default:
+		return first
+	}
+}
+
+// formatReorderedTask renders a reorder_task result as text
+func (ts *ToolService) formatReorderedTask(ctx context.Context, result ReorderTaskResult) string {
+	return fmt.Sprintf("Task %s moved from position %d to %d", ts.qualifiedID(ctx, result.Project, result.TaskID),
+		result.OldPosition, result.NewPosition)
+}
+
+// AddReorderTaskTool adds the reorder_task tool to the MCP server
+func AddReorderTaskTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[ReorderTaskParams, ReorderTaskResult]("reorder_task",
+			"Move a main-task within its category to change its priority", toolService.ReorderTaskHandler),
+	)
+}
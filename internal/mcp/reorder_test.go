@@ -0,0 +1,127 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+const reorderTaskFile = `# Task
+
+## Backend
+- [ ] First #T001
+- [ ] Second #T002
+
+## Frontend
+- [ ] Form #T003
+
+## Backend
+- [ ] Third #T004
+`
+
+func TestReorderTask(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  ReorderTaskParams
+		wantIDs []string
+		wantOld int
+		wantNew int
+	}{
+		{
+			name:    "first",
+			params:  ReorderTaskParams{TaskID: "T004", Position: PositionFirst},
+			wantIDs: []string{"T004", "T001", "T002", "T003"},
+			wantOld: 3,
+			wantNew: 1,
+		},
+		{
+			name:    "last",
+			params:  ReorderTaskParams{TaskID: "T001", Position: PositionLast},
+			wantIDs: []string{"T002", "T004", "T001", "T003"},
+			wantOld: 1,
+			wantNew: 3,
+		},
+		{
+			name:    "before",
+			params:  ReorderTaskParams{TaskID: "T004", Position: PositionBefore, ReferenceTaskID: "T002"},
+			wantIDs: []string{"T001", "T004", "T002", "T003"},
+			wantOld: 3,
+			wantNew: 2,
+		},
+		{
+			name:    "after",
+			params:  ReorderTaskParams{TaskID: "T001", Position: PositionAfter, ReferenceTaskID: "T002"},
+			wantIDs: []string{"T002", "T001", "T004", "T003"},
+			wantOld: 1,
+			wantNew: 2,
+		},
+		{
+			name:    "only task in its category",
+			params:  ReorderTaskParams{TaskID: "T003", Position: PositionFirst},
+			wantIDs: []string{"T001", "T002", "T004", "T003"},
+			wantOld: 1,
+			wantNew: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTaskFile(t, dir, reorderTaskFile)
+			toolService := NewToolService(dir)
+
+			result, err := toolService.ReorderTask(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("ReorderTask() error = %v", err)
+			}
+			if result.OldPosition != tt.wantOld || result.NewPosition != tt.wantNew {
+				t.Errorf("ReorderTask() positions = %d → %d, want %d → %d",
+					result.OldPosition, result.NewPosition, tt.wantOld, tt.wantNew)
+			}
+
+			file, err := toolService.ReadTaskFile("")
+			if err != nil {
+				t.Fatalf("ReadTaskFile() error = %v", err)
+			}
+			var ids []string
+			for _, task := range file.Tasks {
+				ids = append(ids, task.Task.ID)
+			}
+			if diff := cmp.Diff(tt.wantIDs, ids); diff != "" {
+				t.Errorf("task order mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReorderTask_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, reorderTaskFile)
+	toolService := NewToolService(dir)
+
+	tests := []struct {
+		name     string
+		params   ReorderTaskParams
+		wantCode errcode.Code
+	}{
+		{"unknown task", ReorderTaskParams{TaskID: "T009", Position: PositionFirst}, errcode.TaskNotFound},
+		{"unknown reference", ReorderTaskParams{TaskID: "T001", Position: PositionAfter, ReferenceTaskID: "T009"}, errcode.ReferenceTaskNotFound},
+		{"missing reference", ReorderTaskParams{TaskID: "T001", Position: PositionBefore}, errcode.InvalidPosition},
+		{"reference with first", ReorderTaskParams{TaskID: "T001", Position: PositionFirst, ReferenceTaskID: "T002"}, errcode.InvalidPosition},
+		{"reference is the task", ReorderTaskParams{TaskID: "T001", Position: PositionAfter, ReferenceTaskID: "T001"}, errcode.InvalidPosition},
+		{"other category", ReorderTaskParams{TaskID: "T001", Position: PositionAfter, ReferenceTaskID: "T003"}, errcode.InvalidPosition},
+		{"unknown position", ReorderTaskParams{TaskID: "T001", Position: "middle"}, errcode.ValidationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toolService.ReorderTask(context.Background(), tt.params)
+			if code := errcode.CodeOf(err); code != tt.wantCode {
+				t.Errorf("ReorderTask() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
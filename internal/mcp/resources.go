@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+const (
+	// ResourceScheme is the URI scheme of project-scoped resources, e.g.
+	// todo:///api/task.md or todo:///api/context/T001.md
+	ResourceScheme = "todo"
+
+	taskFileName     = "task.md"
+	contextDirName   = "context"
+	markdownMIMEType = "text/markdown"
+)
+
+// contextFileRegex guards context resource names against path traversal
+var contextFileRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+\.md$`)
+
+// TaskFileURI returns the resource URI of a project's task.md
+func TaskFileURI(projectName string) string {
+	return ResourceScheme + ":///" + escapeProject(projectName) + "/" + taskFileName
+}
+
+// ContextFileURI returns the resource URI of a task's context file
+func ContextFileURI(projectName, taskID string) string {
+	return ResourceScheme + ":///" + escapeProject(projectName) + "/" + contextDirName + "/" + url.PathEscape(taskID) + ".md"
+}
+
+// escapeProject escapes each element of a project name for use in a URI path
+func escapeProject(name string) string {
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// AddResources adds the task and context files of all projects as resources.
+// They are addressed as todo:///{project}/task.md and
+// todo:///{project}/context/{task_id}.md, or by file:// URIs within a
+// project's data directory.
+func AddResources(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddResourceTemplates(
+		&mcpsdk.ServerResourceTemplate{
+			ResourceTemplate: &mcpsdk.ResourceTemplate{
+				Name:        "task-file",
+				Title:       "Task file",
+				Description: "task.md of a project",
+				URITemplate: ResourceScheme + ":///{+project}/" + taskFileName,
+				MIMEType:    markdownMIMEType,
+			},
+			Handler: toolService.readResource,
+		},
+		&mcpsdk.ServerResourceTemplate{
+			ResourceTemplate: &mcpsdk.ResourceTemplate{
+				Name:        "context-file",
+				Title:       "Context file",
+				Description: "Context file of a task",
+				URITemplate: ResourceScheme + ":///{+project}/" + contextDirName + "/{task_id}.md",
+				MIMEType:    markdownMIMEType,
+			},
+			Handler: toolService.readResource,
+		},
+		&mcpsdk.ServerResourceTemplate{
+			ResourceTemplate: &mcpsdk.ResourceTemplate{
+				Name:        "project-file",
+				Title:       "Project file",
+				Description: "Markdown file within a project's data directory",
+				URITemplate: "file:///{+path}",
+				MIMEType:    markdownMIMEType,
+			},
+			Handler: toolService.readResource,
+		},
+	)
+}
+
+// readResource reads a task or context file resource
+func (ts *ToolService) readResource(ctx context.Context, _ *mcpsdk.ServerSession, params *mcpsdk.ReadResourceParams) (*mcpsdk.ReadResourceResult, error) {
+	path, ok := ts.resourcePath(ctx, params.URI)
+	if !ok {
+		return nil, mcpsdk.ResourceNotFoundError(params.URI)
+	}
+
+	ts.mu.Lock()
+	data, err := os.ReadFile(path)
+	ts.mu.Unlock()
+	if err != nil {
+		return nil, mcpsdk.ResourceNotFoundError(params.URI)
+	}
+
+	return &mcpsdk.ReadResourceResult{Contents: []*mcpsdk.ResourceContents{
+		{URI: params.URI, MIMEType: markdownMIMEType, Text: string(data)},
+	}}, nil
+}
+
+// resourcePath maps a resource URI to a Markdown file in a project's data directory
+func (ts *ToolService) resourcePath(ctx context.Context, uri string) (string, bool) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", false
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	switch u.Scheme {
+	case ResourceScheme:
+		rest := strings.TrimPrefix(u.Path, "/")
+		if name, ok := strings.CutSuffix(rest, "/"+taskFileName); ok {
+			if p := ts.findProject(ctx, name); p != nil {
+				return p.storage.TaskFilePath(), true
+			}
+			return "", false
+		}
+		i := strings.LastIndex(rest, "/"+contextDirName+"/")
+		if i < 0 {
+			return "", false
+		}
+		file := rest[i+len(contextDirName)+2:]
+		p := ts.findProject(ctx, rest[:i])
+		if p == nil || !contextFileRegex.MatchString(file) {
+			return "", false
+		}
+		return filepath.Join(p.storage.DataDir(), contextDirName, file), true
+
+	case "file":
+		path, err := workspace.PathFromURI(uri)
+		if err != nil || filepath.Ext(path) != ".md" {
+			return "", false
+		}
+		for _, p := range ts.viewOf(ctx).projects {
+			if workspace.Contains(p.storage.DataDir(), path) {
+				return path, true
+			}
+		}
+	}
+	return "", false
+}
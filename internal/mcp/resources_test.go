@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReadResource(t *testing.T) {
+	root := writeMonorepo(t)
+	toolService := NewToolService(root)
+	session := connectToolService(t, toolService)
+
+	_, err := toolService.CreateTaskHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[CreateTaskParams]{
+		Arguments: CreateTaskParams{Title: "Style login form", Description: "Match the design", Project: "web"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTaskHandler() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		uri      string
+		contains string
+		wantErr  bool
+	}{
+		{"task file", TaskFileURI("api"), "Add login endpoint #T001", false},
+		{"context file", ContextFileURI("web", "T002"), "Match the design", false},
+		{"file URI", "file://" + filepath.ToSlash(filepath.Join(root, "services", "web", ".todo", "task.md")), "Style login form #T002", false},
+		{"unknown project", TaskFileURI("mobile"), "", true},
+		{"missing context", ContextFileURI("api", "T009"), "", true},
+		{"outside data dir", "file://" + filepath.ToSlash(filepath.Join(root, "services", "README.md")), "", true},
+		{"traversal", "todo:///api/context/..%2F..%2Fsecret.md", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := session.ReadResource(context.Background(), &mcpsdk.ReadResourceParams{URI: tt.uri})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadResource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := result.Contents[0].Text; !strings.Contains(got, tt.contains) {
+				t.Errorf("ReadResource() = %q, want containing %q", got, tt.contains)
+			}
+		})
+	}
+}
+
+func TestResourceURIs(t *testing.T) {
+	if got, want := TaskFileURI("services/api"), "todo:///services/api/task.md"; got != want {
+		t.Errorf("TaskFileURI() = %q, want %q", got, want)
+	}
+	if got, want := ContextFileURI("my app", "T001"), "todo:///my%20app/context/T001.md"; got != want {
+		t.Errorf("ContextFileURI() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// AutoTransition describes a status change the rollup rules made
+type AutoTransition struct {
+	Path string `json:"path" description:"Task ID or subtask path whose status changed"`
+	From string `json:"from" description:"Status before the change"`
+	To   string `json:"to" description:"Status after the change"`
+}
+
+// rollupTask applies the workflow's rollup rules to the subtasks of a task
+// and, if includeTask is set, to the task itself
+func rollupTask(workflow model.Workflow, task *parser.ParsedTask, includeTask bool) []AutoTransition {
+	var changes []model.StatusChange
+	if includeTask {
+		changes = workflow.RollupTask(&task.Task, task.SubTasks)
+	} else {
+		changes = workflow.RollupSubtasks(model.SubtaskPath{TaskID: task.Task.ID}, task.SubTasks)
+	}
+
+	var transitions []AutoTransition
+	for _, c := range changes {
+		transitions = append(transitions, AutoTransition{Path: c.Path.String(), From: c.From, To: c.To})
+	}
+	return transitions
+}
+
+// formatAutoTransitions renders the status changes made by the rollup rules
+// as text lines following a tool result
+func (ts *ToolService) formatAutoTransitions(ctx context.Context, project string, transitions []AutoTransition) string {
+	var sb strings.Builder
+	for _, t := range transitions {
+		fmt.Fprintf(&sb, "\n%s moved automatically: %s -> %s", ts.qualifiedID(ctx, project, t.Path), t.From, t.To)
+	}
+	return sb.String()
+}
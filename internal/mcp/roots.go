@@ -0,0 +1,192 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+const (
+	methodInitialized      = "notifications/initialized"
+	methodRootsListChanged = "notifications/roots/list_changed"
+	// rootsTimeout bounds how long the server waits for a client's roots
+	rootsTimeout = 5 * time.Second
+)
+
+// ServerInfoParams defines the input parameters for server_info tool
+type ServerInfoParams struct{}
+
+// ServerInfoResult defines the response from server_info tool
+type ServerInfoResult struct {
+	Name       string        `json:"name" description:"Server name"`
+	Version    string        `json:"version" description:"Server version"`
+	Root       string        `json:"root" description:"Workspace root directory"`
+	RootSource string        `json:"root_source" description:"How the workspace root was found" schema:"enum=data_dir|git|working_dir|client|config"`
+	Projects   []ProjectInfo `json:"projects" description:"Projects managed by the server"`
+}
+
+// ProjectInfo describes one project in server_info results
+type ProjectInfo struct {
+	Name    string `json:"name" description:"Project name, accepted by the project argument of tools"`
+	Root    string `json:"root" description:"Project root directory"`
+	DataDir string `json:"data_dir" description:"Directory holding task.md and context files"`
+	Default bool   `json:"default" description:"Whether tools use this project when none is given"`
+	// Statuses lets agents discover a custom workflow, which the tool schemas cannot list
+	Statuses []string `json:"statuses" description:"Task statuses of the project's workflow; new tasks get the first"`
+}
+
+// ServerInfoHandler handles the server_info MCP tool
+func (ts *ToolService) ServerInfoHandler(
+	ctx context.Context,
+	_ *mcpsdk.ServerSession,
+	_ *mcpsdk.CallToolParamsFor[ServerInfoParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	ts.mu.Lock()
+	v := ts.viewOf(ctx)
+	result := ServerInfoResult{
+		Name:       ServerName,
+		Version:    ServerVersion,
+		Root:       v.home.Path,
+		RootSource: string(v.home.Source),
+		Projects:   []ProjectInfo{},
+	}
+	defaultProject, _ := v.project("")
+	for _, p := range v.projects {
+		result.Projects = append(result.Projects, ProjectInfo{
+			Name:     p.Name,
+			Root:     p.Path,
+			DataDir:  p.storage.DataDir(),
+			Default:  p == defaultProject,
+			Statuses: p.storage.Workflow().Names(),
+		})
+	}
+	ts.mu.Unlock()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s %s\n- Workspace root: %s (%s)", result.Name, result.Version, result.Root, result.RootSource)
+	for _, p := range result.Projects {
+		fmt.Fprintf(&sb, "\n- Project %s: %s", p.Name, p.DataDir)
+		if p.Default {
+			sb.WriteString(" (default)")
+		}
+		fmt.Fprintf(&sb, ", statuses: %s", strings.Join(p.Statuses, ", "))
+	}
+	return toolResult(sb.String(), result), nil
+}
+
+// AddServerInfoTool adds the server_info tool to the MCP server
+func AddServerInfoTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[ServerInfoParams, ServerInfoResult]("server_info",
+			"Show the server version and the project root it works on", toolService.ServerInfoHandler),
+	)
+}
+
+// sessionKey is the context key of the session calling a tool
+type sessionKey struct{}
+
+// UseClientRoots makes toolService follow the roots of connected clients.
+// When a client starts or changes its roots, its session moves to them;
+// the current root stays the default if it lies within a client root.
+// Each session has its own workspace, so clients sharing the server over
+// HTTP do not move each other. Clients without roots support use the
+// workspace the server started on.
+func UseClientRoots(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddReceivingMiddleware(func(next mcpsdk.MethodHandler[*mcpsdk.ServerSession]) mcpsdk.MethodHandler[*mcpsdk.ServerSession] {
+		return func(ctx context.Context, session *mcpsdk.ServerSession, method string, params mcpsdk.Params) (mcpsdk.Result, error) {
+			// Tools look up the session's workspace from the context
+			ctx = context.WithValue(ctx, sessionKey{}, session)
+			result, err := next(ctx, session, method, params)
+			if method == methodInitialized || method == methodRootsListChanged {
+				// Ask outside the notification handler, which must not block on the client
+				go toolService.syncClientRoots(context.WithoutCancel(ctx), session)
+			}
+			return result, err
+		}
+	})
+}
+
+// syncClientRoots asks the client for its roots and moves its session to them
+func (ts *ToolService) syncClientRoots(ctx context.Context, session *mcpsdk.ServerSession) {
+	ctx, cancel := context.WithTimeout(ctx, rootsTimeout)
+	defer cancel()
+
+	res, err := session.ListRoots(ctx, nil)
+	if err != nil {
+		// Most likely the client does not support roots
+		return
+	}
+
+	var paths []string
+	for _, root := range res.Roots {
+		path, err := workspace.PathFromURI(root.URI)
+		if err != nil {
+			log.Printf("Ignoring client root: %v", err)
+			continue
+		}
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	v := ts.newView(ts.clientRoots(ts.viewOf(ctx).home, paths))
+	if v == nil {
+		return
+	}
+	if _, ok := ts.sessions[session]; !ok {
+		go func() {
+			_ = session.Wait()
+			ts.mu.Lock()
+			delete(ts.sessions, session)
+			ts.mu.Unlock()
+		}()
+	}
+	ts.sessions[session] = v
+	for _, p := range v.projects {
+		log.Printf("Using project %s at %s from client roots", p.Name, p.Path)
+	}
+}
+
+// clientRoots returns the workspace roots for the client root paths, given
+// the current root. A client may have opened a subdirectory of a project, so
+// each path resolves to the nearest directory holding the data directory or
+// .git.
+func (ts *ToolService) clientRoots(current workspace.Root, paths []string) []workspace.Root {
+	dataDir := ts.storageOpts.DataDir
+	if filepath.IsAbs(dataDir) {
+		dataDir = filepath.Base(dataDir)
+	}
+
+	var roots []workspace.Root
+	add := func(root workspace.Root) {
+		if !slices.ContainsFunc(roots, func(r workspace.Root) bool { return r.Path == root.Path }) {
+			roots = append(roots, root)
+		}
+	}
+	for _, path := range paths {
+		if workspace.Contains(path, current.Path) {
+			// Keep the current root as the default
+			add(current)
+			break
+		}
+	}
+	for _, path := range paths {
+		root, err := workspace.Find(path, dataDir)
+		if err != nil || root.Source == workspace.SourceWorkingDir {
+			root = workspace.Root{Path: path, Source: workspace.SourceClient}
+		}
+		add(root)
+	}
+	return roots
+}
@@ -0,0 +1,229 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+// newRootsServer returns a server that follows client roots
+func newRootsServer(toolService *ToolService) *mcpsdk.Server {
+	server := NewServer()
+	AddServerInfoTool(server, toolService)
+	AddCreateTaskTool(server, toolService)
+	UseClientRoots(server, toolService)
+	return server
+}
+
+// connectRootsClient connects a client announcing roots to a server that
+// follows client roots, and returns the client session
+func connectRootsClient(t *testing.T, toolService *ToolService, roots ...*mcpsdk.Root) *mcpsdk.ClientSession {
+	t.Helper()
+	return connectClient(t, newRootsServer(toolService), roots...)
+}
+
+// connectClient connects a client announcing roots to server, and returns
+// the client session
+func connectClient(t *testing.T, server *mcpsdk.Server, roots ...*mcpsdk.Root) *mcpsdk.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+
+	clientTransport, serverTransport := mcpsdk.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("server.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcpsdk.NewClient("test-client", "0.0.1", nil)
+	client.AddRoots(roots...)
+	clientSession, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("client.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+// sessionRoot returns the workspace root server_info reports to session
+func sessionRoot(t *testing.T, session *mcpsdk.ClientSession) workspace.Root {
+	t.Helper()
+	result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: "server_info"})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	info, _ := result.StructuredContent.(map[string]any)
+	path, _ := info["root"].(string)
+	source, _ := info["root_source"].(string)
+	return workspace.Root{Path: path, Source: workspace.Source(source)}
+}
+
+// waitForRoot polls until session uses the wanted root
+func waitForRoot(t *testing.T, session *mcpsdk.ClientSession, want workspace.Root) {
+	t.Helper()
+	deadline := time.Now().Add(rootsTimeout)
+	for got := sessionRoot(t, session); got != want; got = sessionRoot(t, session) {
+		if time.Now().After(deadline) {
+			t.Fatalf("session root = %+v, want %+v", got, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServerInfoTool(t *testing.T) {
+	tempDir := t.TempDir()
+	toolService := NewToolService(tempDir)
+	session := connectRootsClient(t, toolService)
+
+	result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: "server_info"})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("CallTool() returned error result: %+v", result.Content)
+	}
+
+	want := map[string]any{
+		"name":        ServerName,
+		"version":     ServerVersion,
+		"root":        tempDir,
+		"root_source": "working_dir",
+		"projects": []any{
+			map[string]any{
+				"name":     filepath.Base(tempDir),
+				"root":     tempDir,
+				"data_dir": filepath.Join(tempDir, ".todo"),
+				"default":  true,
+				"statuses": []any{"todo", "in_progress", "done", "blocked", "cancelled"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, result.StructuredContent); diff != "" {
+		t.Errorf("StructuredContent mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUseClientRoots(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "project")
+	for _, dir := range []string{".todo", "src"} {
+		if err := os.MkdirAll(filepath.Join(project, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+	other := filepath.Join(base, "other")
+	if err := os.MkdirAll(other, 0755); err != nil {
+		t.Fatalf("Failed to create other: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		start workspace.Root
+		uri   string
+		want  workspace.Root
+	}{
+		{
+			name:  "moves to client root",
+			start: workspace.Root{Path: base, Source: workspace.SourceWorkingDir},
+			uri:   "file://" + filepath.ToSlash(other),
+			want:  workspace.Root{Path: other, Source: workspace.SourceClient},
+		},
+		{
+			name:  "walks up from a client subdirectory",
+			start: workspace.Root{Path: other, Source: workspace.SourceWorkingDir},
+			uri:   "file://" + filepath.ToSlash(filepath.Join(project, "src")),
+			want:  workspace.Root{Path: project, Source: workspace.SourceDataDir},
+		},
+		{
+			name:  "keeps a root inside the client root",
+			start: workspace.Root{Path: project, Source: workspace.SourceDataDir},
+			uri:   "file://" + filepath.ToSlash(base),
+			want:  workspace.Root{Path: project, Source: workspace.SourceDataDir},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toolService := NewToolService(tt.start.Path)
+			toolService.SetRoots(tt.start)
+
+			session := connectRootsClient(t, toolService, &mcpsdk.Root{URI: tt.uri})
+			waitForRoot(t, session, tt.want)
+		})
+	}
+}
+
+func TestUseClientRoots_NoRoots(t *testing.T) {
+	tempDir := t.TempDir()
+	toolService := NewToolService(tempDir)
+	session := connectRootsClient(t, toolService)
+
+	// A round trip lets the server handle the initialized notification
+	if _, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: "server_info"}); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	want := workspace.Root{Path: tempDir, Source: workspace.SourceWorkingDir}
+	if got := toolService.Root(); got != want {
+		t.Errorf("Root() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUseClientRoots_PerSession(t *testing.T) {
+	base := t.TempDir()
+	var roots []workspace.Root
+	for _, name := range []string{"alpha", "beta"} {
+		dir := filepath.Join(base, name)
+		if err := os.MkdirAll(filepath.Join(dir, ".todo"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, workspace.Root{Path: dir, Source: workspace.SourceDataDir})
+	}
+	toolService := NewToolService(base)
+	server := newRootsServer(toolService)
+
+	// Two clients share the server, each with its own root
+	var sessions []*mcpsdk.ClientSession
+	for _, root := range roots {
+		session := connectClient(t, server, &mcpsdk.Root{URI: "file://" + filepath.ToSlash(root.Path)})
+		waitForRoot(t, session, root)
+		sessions = append(sessions, session)
+	}
+	for i, session := range sessions {
+		if got := sessionRoot(t, session); got != roots[i] {
+			t.Errorf("session %d root = %+v, want %+v", i, got, roots[i])
+		}
+	}
+	if got, want := toolService.Root(), (workspace.Root{Path: base, Source: workspace.SourceWorkingDir}); got != want {
+		t.Errorf("shared Root() = %+v, want %+v", got, want)
+	}
+
+	// Each session's tasks go to its own project
+	for i, session := range sessions {
+		result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{
+			Name:      "create_task",
+			Arguments: CreateTaskParams{Title: "Task of " + filepath.Base(roots[i].Path)},
+		})
+		if err != nil || result.IsError {
+			t.Fatalf("create_task in session %d: %v, %+v", i, err, result)
+		}
+	}
+	for _, root := range roots {
+		content, err := os.ReadFile(filepath.Join(root.Path, ".todo", "task.md"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "- [ ] Task of " + filepath.Base(root.Path) + " #T001\n"
+		if !strings.Contains(string(content), want) || strings.Count(string(content), "#T") != 1 {
+			t.Errorf("task.md of %s = %q, want only %q", filepath.Base(root.Path), content, want)
+		}
+	}
+}
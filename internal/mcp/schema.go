@@ -0,0 +1,347 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// Struct tags read by schemaFor in addition to the json tag:
+//
+//	description:"Task title"
+//	schema:"minLength=1,maxLength=100"
+//
+// The schema tag accepts minLength, maxLength, minimum, maximum, minItems,
+// maxItems, enum (values separated by |), default, format and pattern. Keywords
+// prefixed with "items." apply to the element schema of a slice field.
+// pattern consumes the rest of the tag, so it must come last.
+const (
+	descriptionTag = "description"
+	schemaTag      = "schema"
+)
+
+// schemaFor generates a JSON Schema for T, including the constraints
+// declared in its struct tags
+func schemaFor[T any]() (*jsonschema.Schema, error) {
+	schema, err := jsonschema.For[T]()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyTags(reflect.TypeFor[T](), schema); err != nil {
+		return nil, fmt.Errorf("schemaFor[%v]: %w", reflect.TypeFor[T](), err)
+	}
+	return schema, nil
+}
+
+// applyTags walks t alongside its inferred schema and applies struct tags
+func applyTags(t reflect.Type, schema *jsonschema.Schema) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return applyTags(t.Elem(), schema.Items)
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		prop, ok := schema.Properties[name]
+		if !ok {
+			continue
+		}
+		if err := applyTags(field.Type, prop); err != nil {
+			return err
+		}
+		if desc, ok := field.Tag.Lookup(descriptionTag); ok {
+			prop.Description = desc
+		}
+		if tag, ok := field.Tag.Lookup(schemaTag); ok {
+			if err := applyKeywords(prop, tag); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// applyKeywords applies the comma-separated keywords of a schema tag
+func applyKeywords(schema *jsonschema.Schema, tag string) error {
+	for tag != "" {
+		var keyword string
+		if strings.HasPrefix(tag, "pattern=") || strings.HasPrefix(tag, "items.pattern=") {
+			keyword, tag = tag, ""
+		} else {
+			keyword, tag, _ = strings.Cut(tag, ",")
+		}
+
+		key, value, ok := strings.Cut(keyword, "=")
+		if !ok {
+			return fmt.Errorf("malformed schema keyword %q", keyword)
+		}
+
+		target := schema
+		if rest, found := strings.CutPrefix(key, "items."); found {
+			if schema.Items == nil {
+				return fmt.Errorf("%q used on a non-array field", key)
+			}
+			target, key = schema.Items, rest
+		}
+		if err := applyKeyword(target, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyKeyword sets a single schema keyword from its tag value
+func applyKeyword(schema *jsonschema.Schema, key, value string) error {
+	switch key {
+	case "minLength", "maxLength", "minItems", "maxItems":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		switch key {
+		case "minLength":
+			schema.MinLength = &n
+		case "maxLength":
+			schema.MaxLength = &n
+		case "minItems":
+			schema.MinItems = &n
+		case "maxItems":
+			schema.MaxItems = &n
+		}
+	case "minimum", "maximum":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if key == "minimum" {
+			schema.Minimum = &f
+		} else {
+			schema.Maximum = &f
+		}
+	case "enum":
+		schema.Enum = nil
+		for _, v := range strings.Split(value, "|") {
+			schema.Enum = append(schema.Enum, v)
+		}
+	case "pattern":
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+		schema.Pattern = value
+	case "format":
+		schema.Format = value
+	case "default":
+		if !json.Valid([]byte(value)) {
+			value = strconv.Quote(value)
+		}
+		schema.Default = json.RawMessage(value)
+	default:
+		return fmt.Errorf("unknown schema keyword %q", key)
+	}
+	return nil
+}
+
+// toolSchemas holds the input schema of every tool created by newServerTool,
+// keyed by tool name, for use by validateToolInput.
+var toolSchemas sync.Map
+
+// paramSchemas caches input schemas by parameter type for validateParams
+var paramSchemas sync.Map
+
+// validateParams checks tool arguments given as a Go value against the
+// schema generated for In. Tool operations call it so that callers outside
+// MCP, such as the CLI, get the same validation as MCP clients.
+func validateParams[In any](args In) error {
+	t := reflect.TypeFor[In]()
+	schema, ok := paramSchemas.Load(t)
+	if !ok {
+		s, err := schemaFor[In]()
+		if err != nil {
+			return errcode.Wrap(err, errcode.Internal, "invalid schema for %v", t)
+		}
+		schema, _ = paramSchemas.LoadOrStore(t, s)
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return errcode.Wrap(err, errcode.ValidationError, "invalid arguments")
+	}
+	var instance any
+	if err := json.Unmarshal(data, &instance); err != nil {
+		return errcode.Wrap(err, errcode.ValidationError, "invalid arguments")
+	}
+	return validateInput(schema.(*jsonschema.Schema), instance)
+}
+
+// validateInput checks a decoded JSON value against schema and returns a
+// VALIDATION_ERROR whose details carry a JSON pointer to the first violation.
+func validateInput(schema *jsonschema.Schema, instance any) error {
+	return validateValue(schema, instance, "")
+}
+
+func validateValue(schema *jsonschema.Schema, instance any, pointer string) error {
+	if schema == nil {
+		return nil
+	}
+	if err := validateType(schema, instance, pointer); err != nil {
+		return err
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(v any) bool { return jsonschema.Equal(v, instance) }) {
+		return violation(pointer, instance, "must be one of %v", schema.Enum)
+	}
+
+	switch v := instance.(type) {
+	case string:
+		return validateString(schema, v, pointer)
+	case float64:
+		return validateNumber(schema, v, pointer)
+	case []any:
+		return validateArray(schema, v, pointer)
+	case map[string]any:
+		return validateObject(schema, v, pointer)
+	}
+	return nil
+}
+
+func validateType(schema *jsonschema.Schema, instance any, pointer string) error {
+	types := schema.Types
+	if schema.Type != "" {
+		types = []string{schema.Type}
+	}
+	if len(types) == 0 {
+		return nil
+	}
+	for _, t := range types {
+		if matchesType(t, instance) {
+			return nil
+		}
+	}
+	return violation(pointer, instance, "must be of type %s", strings.Join(types, " or "))
+}
+
+func matchesType(typ string, instance any) bool {
+	switch v := instance.(type) {
+	case nil:
+		return typ == "null"
+	case bool:
+		return typ == "boolean"
+	case string:
+		return typ == "string"
+	case float64:
+		return typ == "number" || (typ == "integer" && v == float64(int64(v)))
+	case []any:
+		return typ == "array"
+	case map[string]any:
+		return typ == "object"
+	}
+	return false
+}
+
+func validateString(schema *jsonschema.Schema, s, pointer string) error {
+	length := utf8.RuneCountInString(s)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return violation(pointer, s, "must be at least %d characters", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return violation(pointer, s, "must be at most %d characters", *schema.MaxLength)
+	}
+	if schema.Pattern != "" {
+		re, err := regexp.Compile(schema.Pattern)
+		if err == nil && !re.MatchString(s) {
+			return violation(pointer, s, "must match pattern %s", schema.Pattern)
+		}
+	}
+	return nil
+}
+
+func validateNumber(schema *jsonschema.Schema, n float64, pointer string) error {
+	if schema.Minimum != nil && n < *schema.Minimum {
+		return violation(pointer, n, "must be at least %v", *schema.Minimum)
+	}
+	if schema.Maximum != nil && n > *schema.Maximum {
+		return violation(pointer, n, "must be at most %v", *schema.Maximum)
+	}
+	return nil
+}
+
+func validateArray(schema *jsonschema.Schema, items []any, pointer string) error {
+	if schema.MinItems != nil && len(items) < *schema.MinItems {
+		return violation(pointer, items, "must have at least %d items", *schema.MinItems)
+	}
+	if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+		return violation(pointer, items, "must have at most %d items", *schema.MaxItems)
+	}
+	for i, item := range items {
+		if err := validateValue(schema.Items, item, pointer+"/"+strconv.Itoa(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateObject(schema *jsonschema.Schema, object map[string]any, pointer string) error {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			return violation(pointer+"/"+escapePointer(name), nil, "is required")
+		}
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		child := pointer + "/" + escapePointer(name)
+		prop, ok := schema.Properties[name]
+		if !ok {
+			prop = schema.AdditionalProperties
+			if prop != nil && prop.Not != nil {
+				return violation(child, object[name], "is not an allowed property")
+			}
+		}
+		if err := validateValue(prop, object[name], child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// violation builds a VALIDATION_ERROR for the value at pointer
+func violation(pointer string, value any, format string, args ...any) error {
+	field := strings.TrimPrefix(pointer, "/")
+	if field == "" {
+		field = "arguments"
+	}
+	return errcode.New(errcode.ValidationError, "%s %s", field, fmt.Sprintf(format, args...)).
+		WithDetails(field, value).
+		WithDetail("pointer", pointer)
+}
+
+// escapePointer escapes a property name for use in a JSON pointer (RFC 6901)
+func escapePointer(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+func TestSchemaFor(t *testing.T) {
+	type item struct {
+		Status string `json:"status,omitempty" schema:"enum=todo|in_progress|done|blocked|cancelled"`
+	}
+	type params struct {
+		ID    string   `json:"id" description:"Task ID" schema:"pattern=^T[0-9]{3}$"`
+		Limit int      `json:"limit,omitempty" schema:"minimum=1,maximum=100,default=50"`
+		Tags  []string `json:"tags,omitempty" schema:"maxItems=3,items.maxLength=10"`
+		Items []item   `json:"items,omitempty"`
+	}
+
+	schema, err := schemaFor[params]()
+	if err != nil {
+		t.Fatalf("schemaFor() error = %v", err)
+	}
+
+	id := schema.Properties["id"]
+	if id.Description != "Task ID" || id.Pattern != "^T[0-9]{3}$" {
+		t.Errorf("id schema = %+v", id)
+	}
+	if diff := cmp.Diff([]string{"id"}, schema.Required); diff != "" {
+		t.Errorf("Required mismatch (-want +got):\n%s", diff)
+	}
+
+	limit := schema.Properties["limit"]
+	if *limit.Minimum != 1 || *limit.Maximum != 100 || string(limit.Default) != "50" {
+		t.Errorf("limit schema = %+v", limit)
+	}
+
+	tags := schema.Properties["tags"]
+	if *tags.MaxItems != 3 || *tags.Items.MaxLength != 10 {
+		t.Errorf("tags schema = %+v", tags)
+	}
+
+	status := schema.Properties["items"].Items.Properties["status"]
+	if diff := cmp.Diff([]any{"todo", "in_progress", "done", "blocked", "cancelled"}, status.Enum); diff != "" {
+		t.Errorf("status enum mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchemaFor_InvalidTag(t *testing.T) {
+	type params struct {
+		Title string `json:"title" schema:"maxLenght=10"`
+	}
+
+	if _, err := schemaFor[params](); err == nil {
+		t.Error("schemaFor() should reject unknown keywords")
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	schema, err := schemaFor[CreateTaskParams]()
+	if err != nil {
+		t.Fatalf("schemaFor() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		args        map[string]any
+		wantPointer string
+	}{
+		{"valid", map[string]any{"title": "Task", "subtasks": []any{"a", "b"}}, ""},
+		{"missing title", map[string]any{}, "/title"},
+		{"empty title", map[string]any{"title": ""}, "/title"},
+		{"title too long", map[string]any{"title": strings.Repeat("あ", 101)}, "/title"},
+		{"category too long", map[string]any{"title": "Task", "category": strings.Repeat("c", 51)}, "/category"},
+		{"wrong type", map[string]any{"title": 42.0}, "/title"},
+		{"too many subtasks", map[string]any{"title": "Task", "subtasks": make([]any, 21)}, "/subtasks"},
+		{"subtask too long", map[string]any{"title": "Task", "subtasks": []any{"a", strings.Repeat("s", 101)}}, "/subtasks/1"},
+		{"unknown property", map[string]any{"title": "Task", "priority": "high"}, "/priority"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateInput(schema, tt.args)
+			if tt.wantPointer == "" {
+				if err != nil {
+					t.Errorf("validateInput() error = %v, want nil", err)
+				}
+				return
+			}
+
+			if got := errcode.CodeOf(err); got != errcode.ValidationError {
+				t.Fatalf("validateInput() code = %v, want %v", got, errcode.ValidationError)
+			}
+			if got := errcode.As(err).Details["pointer"]; got != tt.wantPointer {
+				t.Errorf("pointer = %v, want %v", got, tt.wantPointer)
+			}
+		})
+	}
+}
+
+func TestValidateInput_Enum(t *testing.T) {
+	schema := &jsonschema.Schema{Type: "string", Enum: []any{"todo", "done"}}
+
+	if err := validateInput(schema, "done"); err != nil {
+		t.Errorf("validateInput() error = %v, want nil", err)
+	}
+	if err := validateInput(schema, "blocked"); errcode.CodeOf(err) != errcode.ValidationError {
+		t.Errorf("validateInput() error = %v, want VALIDATION_ERROR", err)
+	}
+}
+
+func TestValidateParams(t *testing.T) {
+	if err := validateParams(ListTasksParams{Status: "done", Limit: 10}); err != nil {
+		t.Errorf("validateParams() error = %v, want nil", err)
+	}
+	err := validateParams(ListTasksParams{Limit: 500})
+	if errcode.CodeOf(err) != errcode.ValidationError || errcode.As(err).Details["pointer"] != "/limit" {
+		t.Errorf("validateParams() error = %v, want VALIDATION_ERROR at /limit", err)
+	}
+}
+
+func TestValidateToolInput(t *testing.T) {
+	ctx := context.Background()
+	session := connectTestClient(t, t.TempDir())
+
+	result, err := session.CallTool(ctx, &mcpsdk.CallToolParams{
+		Name:      "create_task",
+		Arguments: map[string]any{"title": strings.Repeat("x", 101)},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected IsError to be true")
+	}
+
+	structured, ok := result.StructuredContent.(map[string]any)
+	if !ok {
+		t.Fatalf("StructuredContent = %T, want map", result.StructuredContent)
+	}
+	body, _ := structured["error"].(map[string]any)
+	if body["code"] != string(errcode.ValidationError) {
+		t.Errorf("code = %v, want %v", body["code"], errcode.ValidationError)
+	}
+	details, _ := body["details"].(map[string]any)
+	if details["pointer"] != "/title" {
+		t.Errorf("pointer = %v, want /title", details["pointer"])
+	}
+}
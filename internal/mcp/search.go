@@ -0,0 +1,244 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+const (
+	// DefaultSearchLimit is the default number of results returned by search_tasks
+	DefaultSearchLimit = 20
+	// maxExcerptLength bounds matched_content excerpts, in characters
+	maxExcerptLength = 100
+)
+
+// Fields searched by search_tasks
+const (
+	SearchInTitle   = "title"
+	SearchInContent = "content"
+	SearchInContext = "context"
+)
+
+// searchScores weights a match by the field it was found in
+var searchScores = map[string]float64{
+	SearchInTitle:   1.0,
+	SearchInContent: 0.7,
+	SearchInContext: 0.5,
+}
+
+// SearchTasksParams defines the input parameters for search_tasks tool
+type SearchTasksParams struct {
+	Query         string   `json:"query" description:"Search query, matched case-insensitively" schema:"minLength=1,maxLength=200"`
+	SearchIn      []string `json:"search_in,omitempty" description:"Fields to search: title, content (subtasks and notes) and context (default title and content)" schema:"items.enum=title|content|context"`
+	Assignee      string   `json:"assignee,omitempty" description:"Assignee filter"`
+	Tag           string   `json:"tag,omitempty" description:"Tag filter"`
+	PriorityLabel string   `json:"priority_label,omitempty" description:"Priority label filter" schema:"enum=high|medium|low"`
+	DueBefore     string   `json:"due_before,omitempty" description:"Only tasks due on or before this date, as YYYY-MM-DD" schema:"format=date"`
+	Archived      bool     `json:"archived,omitempty" description:"Also search tasks moved to the archive by archive_tasks"`
+	Project       string   `json:"project,omitempty" description:"Project filter (optional; all projects when omitted)"`
+	Limit         int      `json:"limit,omitempty" description:"Maximum number of results" schema:"minimum=1,maximum=50,default=20"`
+}
+
+// SearchResult describes one task in search_tasks results
+type SearchResult struct {
+	TaskID         string  `json:"task_id" description:"Task ID"`
+	Title          string  `json:"title" description:"Task title"`
+	Status         string  `json:"status" description:"Task status"`
+	Category       string  `json:"category" description:"Task category"`
+	MatchScore     float64 `json:"match_score" description:"Relevance score" schema:"minimum=0,maximum=1"`
+	MatchedContent string  `json:"matched_content" description:"Excerpt of the matched content"`
+	Archive        string  `json:"archive,omitempty" description:"Month of the archive file holding an archived task, as YYYY-MM"`
+	Project        string  `json:"project" description:"Project the task belongs to"`
+}
+
+// SearchTasksResult defines the response from search_tasks tool
+type SearchTasksResult struct {
+	Results      []SearchResult `json:"results" description:"Matching tasks, best first"`
+	TotalMatches int            `json:"total_matches" description:"Number of matching tasks before the limit"`
+}
+
+// SearchTasksHandler handles the search_tasks MCP tool
+func (ts *ToolService) SearchTasksHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[SearchTasksParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	format := func(ctx context.Context, result SearchTasksResult) string {
+		return ts.formatSearchResults(ctx, params.Arguments.Query, result)
+	}
+	return toolHandler(ts.SearchTasks, format)(ctx, session, params)
+}
+
+// SearchTasks searches tasks across the selected projects, best matches first
+func (ts *ToolService) SearchTasks(ctx context.Context, args SearchTasksParams) (SearchTasksResult, error) {
+	if err := validateParams(args); err != nil {
+		return SearchTasksResult{}, err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return SearchTasksResult{}, errcode.New(errcode.ValidationError, "query is required").WithDetails("query", args.Query)
+	}
+	searchIn := args.SearchIn
+	if len(searchIn) == 0 {
+		searchIn = []string{SearchInTitle, SearchInContent}
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+	filter, err := newMetadataFilter(args.Assignee, args.Tag, args.PriorityLabel, args.DueBefore)
+	if err != nil {
+		return SearchTasksResult{}, err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	projects, err := ts.selectProjects(ctx, args.Project)
+	if err != nil {
+		return SearchTasksResult{}, err
+	}
+
+	query := strings.ToLower(args.Query)
+	results := []SearchResult{}
+	for _, p := range projects {
+		tasks, err := readTasks(p)
+		if err != nil {
+			return SearchTasksResult{}, err
+		}
+		// Archived tasks follow the tasks of task.md, most recent archive first
+		months := map[string]string{}
+		if args.Archived {
+			archives, err := p.storage.ReadArchives()
+			if err != nil {
+				return SearchTasksResult{}, err
+			}
+			for _, month := range slices.Backward(slices.Sorted(maps.Keys(archives))) {
+				for _, task := range archives[month] {
+					months[task.Task.ID] = month
+				}
+				tasks = append(tasks, archives[month]...)
+			}
+		}
+		for _, task := range tasks {
+			if !filter.matches(task.Task.Due, task.Task.Priority, task.Task.Assignee, task.Task.Tags) {
+				continue
+			}
+			result, ok, err := matchTask(p, task, query, searchIn)
+			if err != nil {
+				return SearchTasksResult{}, err
+			}
+			if ok {
+				result.Archive = months[task.Task.ID]
+				results = append(results, result)
+			}
+		}
+	}
+
+	// Best matches first; ties keep file order
+	sort.SliceStable(results, func(i, j int) bool { return results[i].MatchScore > results[j].MatchScore })
+	response := SearchTasksResult{Results: results, TotalMatches: len(results)}
+	if len(response.Results) > limit {
+		response.Results = response.Results[:limit]
+	}
+
+	return response, nil
+}
+
+// matchTask searches the given fields of a task for the lower-cased query
+// and returns a result for its best match
+func matchTask(p *project, parsed parser.ParsedTask, query string, searchIn []string) (SearchResult, bool, error) {
+	task := parsed.Task
+	result := SearchResult{
+		TaskID:   task.ID,
+		Title:    task.Title,
+		Status:   task.Status,
+		Category: task.Category,
+		Project:  p.Name,
+	}
+
+	found := func(field, excerpt string) {
+		if score := searchScores[field]; score > result.MatchScore {
+			result.MatchScore = score
+			result.MatchedContent = truncate(excerpt, maxExcerptLength)
+		}
+	}
+
+	if slices.Contains(searchIn, SearchInTitle) && strings.Contains(strings.ToLower(task.Title), query) {
+		found(SearchInTitle, task.Title)
+	}
+	if slices.Contains(searchIn, SearchInContent) {
+		content := model.NoteLines(task.Notes)
+		model.WalkSubtasks(parsed.SubTasks, func(_ []int, subtask model.SubTask) {
+			content = append(append(content, subtask.Title), model.NoteLines(subtask.Notes)...)
+		})
+		for _, line := range content {
+			if strings.Contains(strings.ToLower(line), query) {
+				found(SearchInContent, line)
+				break
+			}
+		}
+	}
+	if slices.Contains(searchIn, SearchInContext) && result.MatchScore < searchScores[SearchInContext] {
+		context, err := p.storage.ReadContextFile(task.ID)
+		if err != nil && !errcode.HasCode(err, errcode.FileNotFound) {
+			return SearchResult{}, false, err
+		}
+		for _, line := range strings.Split(context.Content, "\n") {
+			if strings.Contains(strings.ToLower(line), query) {
+				found(SearchInContext, strings.TrimSpace(line))
+				break
+			}
+		}
+	}
+
+	return result, result.MatchScore > 0, nil
+}
+
+// truncate shortens s to at most n characters, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:n-1]) + "…"
+}
+
+// formatSearchResults renders search_tasks results as text
+func (ts *ToolService) formatSearchResults(ctx context.Context, query string, response SearchTasksResult) string {
+	if response.TotalMatches == 0 {
+		return fmt.Sprintf("No tasks match %q", query)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d task(s) match %q", response.TotalMatches, query)
+	if len(response.Results) < response.TotalMatches {
+		fmt.Fprintf(&sb, ", showing %d", len(response.Results))
+	}
+	sb.WriteString(":")
+	for _, r := range response.Results {
+		fmt.Fprintf(&sb, "\n- %s [%s] %s: %s", ts.qualifiedID(ctx, r.Project, r.TaskID), r.Status, r.Title, r.MatchedContent)
+		if r.Archive != "" {
+			fmt.Fprintf(&sb, " (archive %s)", r.Archive)
+		}
+	}
+	return sb.String()
+}
+
+// AddSearchTasksTool adds the search_tasks tool to the MCP server
+func AddSearchTasksTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[SearchTasksParams, SearchTasksResult]("search_tasks",
+			"Search tasks by title, subtasks and context files across projects", toolService.SearchTasksHandler),
+	)
+}
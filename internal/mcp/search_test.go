@@ -0,0 +1,87 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSearchTasksHandler(t *testing.T) {
+	root := writeMonorepo(t)
+	contextDir := filepath.Join(root, "services", "api", ".todo", "context")
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		t.Fatalf("Failed to create context dir: %v", err)
+	}
+	content := "# Context for T002\n\nUse PostgreSQL with bcrypt for password storage\n"
+	if err := os.WriteFile(filepath.Join(contextDir, "T002.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write context file: %v", err)
+	}
+	toolService := NewToolService(root)
+
+	tests := []struct {
+		name   string
+		params SearchTasksParams
+		want   SearchTasksResult
+	}{
+		{
+			name:   "title across projects",
+			params: SearchTasksParams{Query: "LOGIN"},
+			want: SearchTasksResult{Results: []SearchResult{
+				{TaskID: "T001", Title: "Add login endpoint", Status: "todo", Category: "Backend", MatchScore: 1, MatchedContent: "Add login endpoint", Project: "api"},
+				{TaskID: "T001", Title: "Build login form", Status: "in_progress", Category: "Frontend", MatchScore: 1, MatchedContent: "Build login form", Project: "web"},
+			}, TotalMatches: 2},
+		},
+		{
+			name:   "subtask content",
+			params: SearchTasksParams{Query: "password"},
+			want: SearchTasksResult{Results: []SearchResult{
+				{TaskID: "T001", Title: "Add login endpoint", Status: "todo", Category: "Backend", MatchScore: 0.7, MatchedContent: "Hash passwords", Project: "api"},
+			}, TotalMatches: 1},
+		},
+		{
+			name:   "context ranks below content",
+			params: SearchTasksParams{Query: "password", SearchIn: []string{"content", "context"}},
+			want: SearchTasksResult{Results: []SearchResult{
+				{TaskID: "T001", Title: "Add login endpoint", Status: "todo", Category: "Backend", MatchScore: 0.7, MatchedContent: "Hash passwords", Project: "api"},
+				{TaskID: "T002", Title: "Set up database", Status: "done", Category: "Backend", MatchScore: 0.5, MatchedContent: "Use PostgreSQL with bcrypt for password storage", Project: "api"},
+			}, TotalMatches: 2},
+		},
+		{
+			name:   "project filter and limit",
+			params: SearchTasksParams{Query: "login", Project: "web", Limit: 1},
+			want: SearchTasksResult{Results: []SearchResult{
+				{TaskID: "T001", Title: "Build login form", Status: "in_progress", Category: "Frontend", MatchScore: 1, MatchedContent: "Build login form", Project: "web"},
+			}, TotalMatches: 1},
+		},
+		{
+			name:   "no match",
+			params: SearchTasksParams{Query: "deploy"},
+			want:   SearchTasksResult{Results: []SearchResult{}, TotalMatches: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := toolService.SearchTasksHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[SearchTasksParams]{Arguments: tt.params})
+			if err != nil || result.IsError {
+				t.Fatalf("SearchTasksHandler() error = %v, result = %+v", err, result)
+			}
+			if diff := cmp.Diff(tt.want, result.StructuredContent); diff != "" {
+				t.Errorf("SearchTasksHandler() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("タスク管理システム", 5); got != "タスク管…" {
+		t.Errorf("truncate() = %q", got)
+	}
+	if got := truncate("short", 10); got != "short" {
+		t.Errorf("truncate() = %q", got)
+	}
+}
@@ -4,6 +4,11 @@ package mcp
 
 import (
 	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
 
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
@@ -15,9 +20,24 @@ const (
 	ServerVersion = "0.1.0"
 )
 
-// NewServer creates a new MCP server instance
+const (
+	// StreamablePath is the HTTP path of the streamable HTTP transport
+	StreamablePath = "/mcp"
+	// SSEPath is the HTTP path of the legacy SSE transport
+	SSEPath = "/sse"
+	// ShutdownTimeout bounds how long the HTTP server waits for open requests on shutdown
+	ShutdownTimeout = 10 * time.Second
+	// readHeaderTimeout guards the HTTP server against slow clients
+	readHeaderTimeout = 10 * time.Second
+)
+
+// NewServer creates a new MCP server instance.
+// Tool arguments are validated against each tool's input schema before
+// its handler runs.
 func NewServer() *mcpsdk.Server {
-	return mcpsdk.NewServer(ServerName, ServerVersion, nil)
+	server := mcpsdk.NewServer(ServerName, ServerVersion, nil)
+	server.AddReceivingMiddleware(validateToolInput)
+	return server
 }
 
 // RunServer runs the MCP server over stdio transport
@@ -25,3 +45,103 @@ func RunServer(ctx context.Context, server *mcpsdk.Server) error {
 	transport := mcpsdk.NewStdioTransport()
 	return server.Run(ctx, transport)
 }
+
+// NewHTTPHandler returns an HTTP handler serving the streamable HTTP transport
+// at StreamablePath and the legacy SSE transport at SSEPath.
+// All sessions share the given server. Requests are subject to the access
+// control described by opts, which may be nil.
+func NewHTTPHandler(server *mcpsdk.Server, opts *HTTPOptions) http.Handler {
+	getServer := func(*http.Request) *mcpsdk.Server { return server }
+
+	mux := http.NewServeMux()
+	mux.Handle(StreamablePath, mcpsdk.NewStreamableHTTPHandler(getServer, nil))
+	mux.Handle(SSEPath, mcpsdk.NewSSEHandler(getServer))
+	return withAccessControl(mux, opts)
+}
+
+// RunHTTPServer serves the MCP server over HTTP on addr until ctx is done,
+// then shuts down gracefully: it stops accepting connections, lets in-flight
+// writes finish, then ends open streams, waiting up to ShutdownTimeout in all.
+func RunHTTPServer(ctx context.Context, server *mcpsdk.Server, addr string, opts *HTTPOptions) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if host, _, _ := net.SplitHostPort(addr); !IsLoopbackHost(host) && (opts == nil || opts.Token == "") {
+		log.Printf("Warning: serving on non-loopback address %s without a bearer token", addr)
+	}
+	if ip := net.ParseIP(hostname(addr)); (hostname(addr) == "" || ip != nil && ip.IsUnspecified()) && (opts == nil || len(opts.AllowedHosts) == 0) {
+		log.Printf("Serving on %s; only loopback hosts are accepted unless allowed_hosts names others", addr)
+	}
+	withAddr := HTTPOptions{}
+	if opts != nil {
+		withAddr = *opts
+	}
+	if withAddr.Addr == "" {
+		withAddr.Addr = addr
+	}
+	return serveHTTP(ctx, listener, NewHTTPHandler(server, &withAddr), withAddr.Drain)
+}
+
+// serveHTTP serves handler on listener until ctx is done. On shutdown, drain
+// (if not nil) is called to wait for in-flight writes.
+func serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler, drain func()) error {
+	// Streams hang on their request context, so requests get a context
+	// that is canceled once in-flight writes are done.
+	baseCtx, cancelBase := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelBase()
+
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("MCP server listening on http://%s", listener.Addr())
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down MCP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	shutdownErr := make(chan error, 1)
+	go func() { shutdownErr <- httpServer.Shutdown(shutdownCtx) }()
+
+	// Open streams never end on their own, so they are ended once the
+	// writes in flight have finished, Shutdown has returned or the timeout
+	// has expired, whichever comes first
+	drained := make(chan struct{})
+	go func() {
+		if drain != nil {
+			drain()
+		}
+		close(drained)
+	}()
+	var err error
+	select {
+	case <-drained:
+		cancelBase()
+		err = <-shutdownErr
+	case err = <-shutdownErr:
+		cancelBase()
+	case <-shutdownCtx.Done():
+		cancelBase()
+		err = <-shutdownErr
+	}
+	if err != nil {
+		_ = httpServer.Close()
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package mcp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+	"github.com/jnst/agentic-todo-mcp/internal/storage"
+)
+
+// statusSection is the context file section recording why a task was
+// blocked or cancelled
+const statusSection = "Status"
+
+// statusReasonRegex matches a line of the Status section, as in
+// "- 2026-10-19T09:30:00Z blocked: waiting for API keys"
+var statusReasonRegex = regexp.MustCompile(`(?m)^- (\S+) ([a-z0-9_]+): (.+)$`)
+
+// checkReason checks the reason given for a task moving to status. Statuses
+// the workflow marks as needing a reason, such as blocked and cancelled,
+// need one, and other statuses take none.
+func checkReason(workflow model.Workflow, taskID, status string, moved bool, reason string) error {
+	switch {
+	case moved && workflow.RequiresReason(status) && reason == "":
+		return errcode.New(errcode.ValidationError, "a reason is required to mark %s %s", taskID, status).
+			WithDetails("status", status)
+	case reason != "" && !workflow.RequiresReason(status):
+		var statuses []string
+		for _, s := range workflow.Statuses {
+			if s.Reason {
+				statuses = append(statuses, s.Name)
+			}
+		}
+		return errcode.New(errcode.ValidationError, "a reason is only recorded for tasks that are %s, %s is %s",
+			strings.Join(statuses, " or "), taskID, status).WithDetails("status", status)
+	}
+	return nil
+}
+
+// checkStatusFilter checks that a status filter names a status of the
+// workflow of at least one of the projects
+func checkStatusFilter(projects []*project, status string) error {
+	if status == "" {
+		return nil
+	}
+	var err error
+	for _, p := range projects {
+		if err = p.storage.Workflow().CheckStatus(status); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// appendStatusReason records in a task's context file why it moved to status
+func appendStatusReason(fs *storage.FileStorage, taskID, status, reason string, now time.Time) error {
+	current, err := fs.ReadContextFile(taskID)
+	if errcode.HasCode(err, errcode.FileNotFound) {
+		current = model.NewContext(taskID, fmt.Sprintf("# Context for %s\n", taskID))
+	} else if err != nil {
+		return err
+	}
+	line := fmt.Sprintf("- %s %s: %s", now.Format(time.RFC3339), status, strings.Join(strings.Fields(reason), " "))
+	return fs.WriteContextFile(model.NewContext(taskID, parser.SetSection(current.Content, statusSection, line, true)))
+}
+
+// statusReason returns the last reason recorded for the status of a task
+// whose status needs a reason, empty for other tasks
+func statusReason(fs *storage.FileStorage, task model.Task) (string, error) {
+	if !fs.Workflow().RequiresReason(task.Status) {
+		return "", nil
+	}
+	context, err := fs.ReadContextFile(task.ID)
+	if errcode.HasCode(err, errcode.FileNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	reason := ""
+	for _, m := range statusReasonRegex.FindAllStringSubmatch(context.Content, -1) {
+		if m[2] == task.Status {
+			reason = m[3]
+		}
+	}
+	return reason, nil
+}
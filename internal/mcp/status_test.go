@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+func TestUpdateTask_BlockedAndCancelled(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, updateTaskFile)
+	ts := NewToolService(dir)
+	ctx := context.Background()
+
+	result, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Status: "blocked", Reason: "waiting for\nAPI keys"})
+	if err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{FieldStatus, FieldReason}, result.UpdatedFields); diff != "" {
+		t.Errorf("UpdateTask() updated fields mismatch (-want +got):\n%s", diff)
+	}
+	// A blocked task can be given a newer reason without a status change
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Reason: "waiting for the security review"}); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: "T002", Status: "cancelled", Reason: "using the hosted database"}); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+
+	detail, err := ts.GetTask(ctx, GetTaskParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Status != "blocked" || detail.StatusReason != "waiting for the security review" {
+		t.Errorf("GetTask() status = %s (%s)", detail.Status, detail.StatusReason)
+	}
+	if !strings.Contains(detail.Context, "## Status\n- ") || !strings.Contains(detail.Context, " blocked: waiting for API keys\n- ") {
+		t.Errorf("context of T001 does not record the reasons:\n%s", detail.Context)
+	}
+
+	list, err := ts.ListTasks(ctx, ListTasksParams{Status: "cancelled"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].TaskID != "T002" || list.Tasks[0].StatusReason != "using the hosted database" {
+		t.Errorf("ListTasks(cancelled) = %+v", list.Tasks)
+	}
+
+	// Unblocking keeps the history but no longer reports a reason
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Status: "in_progress"}); err != nil {
+		t.Fatal(err)
+	}
+	detail, err = ts.GetTask(ctx, GetTaskParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.StatusReason != "" || !strings.Contains(detail.Context, "blocked: waiting for API keys") {
+		t.Errorf("GetTask() after unblocking = %q, context:\n%s", detail.StatusReason, detail.Context)
+	}
+}
+
+func TestListTasksTool_StatusReason(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [!] Deploy #T001\n- [~] Migrate #T002\n")
+	writeSource(t, dir, ".todo/context/T001.md", "# Context for T001\n\n## Status\n- 2026-10-19T09:30:00Z blocked: no staging cluster\n")
+	session := connectTestClient(t, dir)
+
+	result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: "list_tasks", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	text := result.Content[0].(*mcpsdk.TextContent).Text
+	want := "2 task(s):\n- T001 [blocked: no staging cluster] Deploy (Default)\n- T002 [cancelled] Migrate (Default)"
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestCustomWorkflow(t *testing.T) {
+	root := writeMonorepo(t)
+	web := filepath.Join(root, "services", "web")
+	if err := os.WriteFile(filepath.Join(web, ".todo", "config.toml"), []byte(`[workflow]
+statuses = ["todo [ ]", "in_progress [-]", "review [r]", "deployed [x]"]
+done = ["deployed"]
+transitions = ["in_progress -> review|todo", "review -> deployed|in_progress"]
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ts := NewToolService(root)
+	ctx := context.Background()
+
+	// The api project keeps the default workflow
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{Project: "api", TaskID: "T001", Status: "review"}); errcode.CodeOf(err) != errcode.InvalidStatus {
+		t.Errorf("UpdateTask(api, review) error = %v, want %s", err, errcode.InvalidStatus)
+	}
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{Project: "web", TaskID: "T001", Status: "deployed"}); errcode.CodeOf(err) != errcode.InvalidStatus {
+		t.Errorf("UpdateTask() skipping review error = %v, want %s", err, errcode.InvalidStatus)
+	}
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{Project: "web", TaskID: "T001", Status: "review"}); err != nil {
+		t.Fatalf("UpdateTask(web, review) error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(web, ".todo", "task.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "- [r] Build login form #T001\n") {
+		t.Errorf("task.md lacks the review checkbox:\n%s", content)
+	}
+
+	list, err := ts.ListTasks(ctx, ListTasksParams{Status: "review"})
+	if err != nil {
+		t.Fatalf("ListTasks(review) error = %v", err)
+	}
+	if len(list.Tasks) != 1 || list.Tasks[0].Project != "web" {
+		t.Errorf("ListTasks(review) = %+v", list.Tasks)
+	}
+	if _, err := ts.ListTasks(ctx, ListTasksParams{Project: "web", Status: "blocked"}); errcode.CodeOf(err) != errcode.InvalidStatus {
+		t.Errorf("ListTasks(web, blocked) error = %v, want %s", err, errcode.InvalidStatus)
+	}
+
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{Project: "web", TaskID: "T001", Status: "deployed"}); err != nil {
+		t.Fatalf("UpdateTask(web, deployed) error = %v", err)
+	}
+	detail, err := ts.GetTask(ctx, GetTaskParams{Project: "web", TaskID: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.CompletedAt == "" {
+		t.Error("a deployed task has no completion time")
+	}
+}
@@ -0,0 +1,377 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// AddSubtaskParams defines the input parameters for add_subtask tool
+type AddSubtaskParams struct {
+	Parent   string `json:"parent" description:"Task ID or subtask path to add the subtask under, e.g. T003 or T003.2" schema:"pattern=^[A-Za-z]+[0-9a-z]+([.][1-9][0-9]*)*$"`
+	Title    string `json:"title" description:"Subtask title" schema:"minLength=1,maxLength=100"`
+	Status   string `json:"status,omitempty" description:"Subtask status (default the workflow's first status, todo)"`
+	Position int    `json:"position,omitempty" description:"1-based position among the subtasks of the parent (default last)" schema:"minimum=1"`
+	Project  string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// CheckSubtaskParams defines the input parameters for check_subtask tool
+type CheckSubtaskParams struct {
+	Path    string `json:"path" description:"Path of the subtask, e.g. T003.2.1" schema:"pattern=^[A-Za-z]+[0-9a-z]+([.][1-9][0-9]*)+$"`
+	Status  string `json:"status,omitempty" description:"New status (default the workflow's done status, done)"`
+	Project string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// RenameSubtaskParams defines the input parameters for rename_subtask tool
+type RenameSubtaskParams struct {
+	Path    string `json:"path" description:"Path of the subtask, e.g. T003.2.1" schema:"pattern=^[A-Za-z]+[0-9a-z]+([.][1-9][0-9]*)+$"`
+	Title   string `json:"title" description:"New title" schema:"minLength=1,maxLength=100"`
+	Project string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// MoveSubtaskParams defines the input parameters for move_subtask tool
+type MoveSubtaskParams struct {
+	Path     string `json:"path" description:"Path of the subtask, e.g. T003.2.1" schema:"pattern=^[A-Za-z]+[0-9a-z]+([.][1-9][0-9]*)+$"`
+	Parent   string `json:"parent,omitempty" description:"Task ID or subtask path of the same task to move it under, as paths read before the move (default its current parent)" schema:"pattern=^[A-Za-z]+[0-9a-z]+([.][1-9][0-9]*)*$"`
+	Position int    `json:"position,omitempty" description:"1-based position among the subtasks of the parent after the move (default last)" schema:"minimum=1"`
+	Project  string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// RemoveSubtaskParams defines the input parameters for remove_subtask tool
+type RemoveSubtaskParams struct {
+	Path    string `json:"path" description:"Path of the subtask to remove with the subtasks nested under it" schema:"pattern=^[A-Za-z]+[0-9a-z]+([.][1-9][0-9]*)+$"`
+	Project string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// SubtaskResult defines the response from the subtask tools
+type SubtaskResult struct {
+	Path            string           `json:"path" description:"Path of the subtask after the change; for remove_subtask, the path it had"`
+	Title           string           `json:"title" description:"Subtask title"`
+	Status          string           `json:"status" description:"Subtask status"`
+	Changed         bool             `json:"changed" description:"Whether task.md changed"`
+	UpdatedAt       string           `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project         string           `json:"project" description:"Project the task belongs to"`
+	AutoTransitions []AutoTransition `json:"auto_transitions,omitempty" description:"Status changes of the task and its subtasks made by the workflow's rollup rules"`
+}
+
+// subtaskEdit changes the subtasks of a task and reports the subtask it
+// changed and whether anything changed
+type subtaskEdit func(task *parser.ParsedTask, workflow model.Workflow) (SubtaskResult, bool, error)
+
+// AddSubtaskHandler handles the add_subtask MCP tool
+func (ts *ToolService) AddSubtaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[AddSubtaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.AddSubtask, ts.formatSubtask("Added"))(ctx, session, params)
+}
+
+// AddSubtask adds a subtask under a task or another subtask
+func (ts *ToolService) AddSubtask(ctx context.Context, args AddSubtaskParams) (SubtaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return SubtaskResult{}, err
+	}
+	parent, err := model.ParseSubtaskPath(args.Parent)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	return ts.editSubtasks(ctx, args.Project, parent.TaskID, "add", func(task *parser.ParsedTask, workflow model.Workflow) (SubtaskResult, bool, error) {
+		status := args.Status
+		if status == "" {
+			status = workflow.Initial()
+		}
+		if err := workflow.CheckStatus(status); err != nil {
+			return SubtaskResult{}, false, err
+		}
+		list, ok := model.SubtaskList(&task.SubTasks, parent.Indexes)
+		if !ok {
+			return SubtaskResult{}, false, subtaskNotFound(parent)
+		}
+		at, err := subtaskPosition(args.Position, len(*list), parent)
+		if err != nil {
+			return SubtaskResult{}, false, err
+		}
+		if model.CountSubtasks(task.SubTasks) >= ts.limits.MaxSubtasks {
+			return SubtaskResult{}, false, errcode.New(errcode.ValidationError, "at most %d subtasks are allowed", ts.limits.MaxSubtasks).
+				WithDetails("task_id", parent.TaskID)
+		}
+		*list = slices.Insert(*list, at, model.NewSubTask(args.Title, status))
+		return SubtaskResult{Path: parent.Child(at).String(), Title: args.Title, Status: status}, true, nil
+	})
+}
+
+// CheckSubtaskHandler handles the check_subtask MCP tool
+func (ts *ToolService) CheckSubtaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[CheckSubtaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.CheckSubtask, ts.formatSubtask("Checked"))(ctx, session, params)
+}
+
+// CheckSubtask changes the status of a subtask, by default to done
+func (ts *ToolService) CheckSubtask(ctx context.Context, args CheckSubtaskParams) (SubtaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return SubtaskResult{}, err
+	}
+	path, err := model.ParseSubtaskPath(args.Path)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	return ts.editSubtasks(ctx, args.Project, path.TaskID, "check", func(task *parser.ParsedTask, workflow model.Workflow) (SubtaskResult, bool, error) {
+		status := args.Status
+		if status == "" {
+			status = workflow.StageStatus(model.StageDone)
+		}
+		if err := workflow.CheckStatus(status); err != nil {
+			return SubtaskResult{}, false, err
+		}
+		list, i, err := subtaskAt(task, path)
+		if err != nil {
+			return SubtaskResult{}, false, err
+		}
+		subtask := &(*list)[i]
+		changed := subtask.Status != status
+		subtask.Status = status
+		return SubtaskResult{Path: args.Path, Title: subtask.Title, Status: status}, changed, nil
+	})
+}
+
+// RenameSubtaskHandler handles the rename_subtask MCP tool
+func (ts *ToolService) RenameSubtaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[RenameSubtaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.RenameSubtask, ts.formatSubtask("Renamed"))(ctx, session, params)
+}
+
+// RenameSubtask changes the title of a subtask
+func (ts *ToolService) RenameSubtask(ctx context.Context, args RenameSubtaskParams) (SubtaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return SubtaskResult{}, err
+	}
+	path, err := model.ParseSubtaskPath(args.Path)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	return ts.editSubtasks(ctx, args.Project, path.TaskID, "rename", func(task *parser.ParsedTask, _ model.Workflow) (SubtaskResult, bool, error) {
+		list, i, err := subtaskAt(task, path)
+		if err != nil {
+			return SubtaskResult{}, false, err
+		}
+		subtask := &(*list)[i]
+		changed := subtask.Title != args.Title
+		subtask.Title = args.Title
+		return SubtaskResult{Path: args.Path, Title: subtask.Title, Status: subtask.Status}, changed, nil
+	})
+}
+
+// MoveSubtaskHandler handles the move_subtask MCP tool
+func (ts *ToolService) MoveSubtaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[MoveSubtaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.MoveSubtask, ts.formatSubtask("Moved"))(ctx, session, params)
+}
+
+// MoveSubtask moves a subtask with the subtasks nested under it to another
+// position or parent within its task
+func (ts *ToolService) MoveSubtask(ctx context.Context, args MoveSubtaskParams) (SubtaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return SubtaskResult{}, err
+	}
+	path, err := model.ParseSubtaskPath(args.Path)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	parent := path.Parent()
+	if args.Parent != "" {
+		if parent, err = model.ParseSubtaskPath(args.Parent); err != nil {
+			return SubtaskResult{}, err
+		}
+	}
+	if parent.TaskID != path.TaskID {
+		return SubtaskResult{}, errcode.New(errcode.InvalidPosition, "subtask %s can only move within task %s", args.Path, path.TaskID).
+			WithDetails("parent", args.Parent)
+	}
+	if path.Contains(parent) {
+		return SubtaskResult{}, errcode.New(errcode.InvalidPosition, "subtask %s cannot move under itself", args.Path).
+			WithDetails("parent", args.Parent)
+	}
+
+	return ts.editSubtasks(ctx, args.Project, path.TaskID, "move", func(task *parser.ParsedTask, _ model.Workflow) (SubtaskResult, bool, error) {
+		list, i, err := subtaskAt(task, path)
+		if err != nil {
+			return SubtaskResult{}, false, err
+		}
+		if _, ok := model.SubtaskList(&task.SubTasks, parent.Indexes); !ok {
+			return SubtaskResult{}, false, subtaskNotFound(parent)
+		}
+		moved := (*list)[i]
+		*list = slices.Delete(*list, i, i+1)
+
+		// Paths after the moved subtask in its list shift up by one
+		target := model.SubtaskPath{TaskID: parent.TaskID, Indexes: slices.Clone(parent.Indexes)}
+		if level := len(path.Indexes) - 1; path.Parent().Contains(target) && len(target.Indexes) > level && target.Indexes[level] > i {
+			target.Indexes[level]--
+		}
+		targetList, _ := model.SubtaskList(&task.SubTasks, target.Indexes)
+		at, err := subtaskPosition(args.Position, len(*targetList), parent)
+		if err != nil {
+			return SubtaskResult{}, false, err
+		}
+		*targetList = slices.Insert(*targetList, at, moved)
+
+		newPath := target.Child(at)
+		changed := newPath.String() != path.String()
+		return SubtaskResult{Path: newPath.String(), Title: moved.Title, Status: moved.Status}, changed, nil
+	})
+}
+
+// RemoveSubtaskHandler handles the remove_subtask MCP tool
+func (ts *ToolService) RemoveSubtaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[RemoveSubtaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.RemoveSubtask, ts.formatSubtask("Removed"))(ctx, session, params)
+}
+
+// RemoveSubtask removes a subtask with the subtasks nested under it
+func (ts *ToolService) RemoveSubtask(ctx context.Context, args RemoveSubtaskParams) (SubtaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return SubtaskResult{}, err
+	}
+	path, err := model.ParseSubtaskPath(args.Path)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	return ts.editSubtasks(ctx, args.Project, path.TaskID, "remove", func(task *parser.ParsedTask, _ model.Workflow) (SubtaskResult, bool, error) {
+		list, i, err := subtaskAt(task, path)
+		if err != nil {
+			return SubtaskResult{}, false, err
+		}
+		removed := (*list)[i]
+		*list = slices.Delete(*list, i, i+1)
+		return SubtaskResult{Path: args.Path, Title: removed.Title, Status: removed.Status}, true, nil
+	})
+}
+
+// editSubtasks applies edit to the subtasks of a task, then the workflow's
+// rollup rules, and writes task.md if they changed. verb names the change
+// in the auto-commit message.
+func (ts *ToolService) editSubtasks(ctx context.Context, projectName, taskID, verb string, edit subtaskEdit) (SubtaskResult, error) {
+	p, unlock, err := ts.lockProject(ctx, projectName)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	defer unlock()
+	tasks, err := readTasks(p)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	i, err := findTask(tasks, taskID)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+
+	before := taskStatuses(tasks)
+	workflow := p.storage.Workflow()
+	result, changed, err := edit(&tasks[i], workflow)
+	if err != nil {
+		return SubtaskResult{}, err
+	}
+	now := time.Now()
+	result.Changed = changed
+	result.UpdatedAt = now.Format(time.RFC3339)
+	result.Project = p.Name
+	if !changed {
+		return result, nil
+	}
+	result.AutoTransitions = rollupTask(workflow, &tasks[i], true)
+	if err := p.storage.WriteTasksFile(tasks); err != nil {
+		return SubtaskResult{}, err
+	}
+	if tasks[i].Task.Status != before[taskID] {
+		if err := recordTimestamps(p, before, tasks, now); err != nil {
+			return SubtaskResult{}, err
+		}
+	}
+	ts.commit(ctx, p, "%s subtask %s '%s'", verb, result.Path, result.Title)
+	return result, nil
+}
+
+// subtaskAt returns the list holding the subtask at path and its index in it
+func subtaskAt(task *parser.ParsedTask, path model.SubtaskPath) (*[]model.SubTask, int, error) {
+	list, ok := model.SubtaskList(&task.SubTasks, path.Parent().Indexes)
+	i := path.Indexes[len(path.Indexes)-1]
+	if !ok || i >= len(*list) {
+		return nil, 0, subtaskNotFound(path)
+	}
+	return list, i, nil
+}
+
+// subtaskPosition converts a 1-based position in a list of n subtasks to an
+// index to insert at; 0 means the end
+func subtaskPosition(position, n int, parent model.SubtaskPath) (int, error) {
+	switch {
+	case position == 0:
+		return n, nil
+	case position > n+1:
+		return 0, errcode.New(errcode.InvalidPosition, "position %d is past the end of the %d subtask(s) of %s", position, n, parent).
+			WithDetails("position", position)
+	default:
+		return position - 1, nil
+	}
+}
+
+// subtaskNotFound returns the error for a path that addresses no subtask
+func subtaskNotFound(path model.SubtaskPath) error {
+	return errcode.New(errcode.TaskNotFound, "subtask %s not found", path).WithDetails("path", path.String())
+}
+
+// subtaskIndent returns the indentation of a subtask path in text output,
+// two spaces per level
+func subtaskIndent(path string) string {
+	return strings.Repeat("  ", strings.Count(path, "."))
+}
+
+// formatSubtask returns a renderer of subtask tool results as text
+func (ts *ToolService) formatSubtask(verb string) func(context.Context, SubtaskResult) string {
+	return func(ctx context.Context, result SubtaskResult) string {
+		path := ts.qualifiedID(ctx, result.Project, result.Path)
+		if !result.Changed {
+			return fmt.Sprintf("Subtask %s is unchanged: [%s] %s", path, result.Status, result.Title)
+		}
+		return fmt.Sprintf("%s subtask %s: [%s] %s", verb, path, result.Status, result.Title) +
+			ts.formatAutoTransitions(ctx, result.Project, result.AutoTransitions)
+	}
+}
+
+// AddSubtaskTools adds the add_subtask, check_subtask, rename_subtask,
+// move_subtask and remove_subtask tools to the MCP server
+func AddSubtaskTools(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[AddSubtaskParams, SubtaskResult]("add_subtask",
+			"Add a subtask under a main-task or under another subtask, addressed by a path like T003.2", toolService.AddSubtaskHandler),
+		newServerTool[CheckSubtaskParams, SubtaskResult]("check_subtask",
+			"Change the status of a subtask, by default to done", toolService.CheckSubtaskHandler),
+		newServerTool[RenameSubtaskParams, SubtaskResult]("rename_subtask",
+			"Change the title of a subtask", toolService.RenameSubtaskHandler),
+		newServerTool[MoveSubtaskParams, SubtaskResult]("move_subtask",
+			"Move a subtask with its nested subtasks to another position or parent within its main-task", toolService.MoveSubtaskHandler),
+		newServerTool[RemoveSubtaskParams, SubtaskResult]("remove_subtask",
+			"Remove a subtask with its nested subtasks", toolService.RemoveSubtaskHandler),
+	)
+}
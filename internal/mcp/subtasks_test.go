@@ -0,0 +1,279 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+const subtaskTaskFile = `# Task
+
+## Ops
+- [ ] Deploy #T001
+  - [ ] Build image
+    - [x] Pin base image
+    - [ ] Scan
+  - [ ] Tag release
+- [ ] Monitor #T002
+
+`
+
+func TestSubtaskTools(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		op   func(ts *ToolService) (SubtaskResult, error)
+		want SubtaskResult
+		// wantSubtasks lists the subtasks of T001 after the change
+		wantSubtasks string
+	}{
+		{
+			name: "add to a task",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.AddSubtask(ctx, AddSubtaskParams{Parent: "T001", Title: "Announce"})
+			},
+			want: SubtaskResult{Path: "T001.3", Title: "Announce", Status: "todo", Changed: true},
+			wantSubtasks: `  - [ ] Build image
+    - [x] Pin base image
+    - [ ] Scan
+  - [ ] Tag release
+  - [ ] Announce
+`,
+		},
+		{
+			name: "add under a subtask at a position",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.AddSubtask(ctx, AddSubtaskParams{Parent: "T001.1.2", Title: "Fix CVEs", Status: "in_progress", Position: 1})
+			},
+			want: SubtaskResult{Path: "T001.1.2.1", Title: "Fix CVEs", Status: "in_progress", Changed: true},
+			wantSubtasks: `  - [ ] Build image
+    - [x] Pin base image
+    - [ ] Scan
+      - [-] Fix CVEs
+  - [ ] Tag release
+`,
+		},
+		{
+			name: "check",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.CheckSubtask(ctx, CheckSubtaskParams{Path: "T001.1.2"})
+			},
+			want: SubtaskResult{Path: "T001.1.2", Title: "Scan", Status: "done", Changed: true},
+			wantSubtasks: `  - [ ] Build image
+    - [x] Pin base image
+    - [x] Scan
+  - [ ] Tag release
+`,
+		},
+		{
+			name: "uncheck",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.CheckSubtask(ctx, CheckSubtaskParams{Path: "T001.1.1", Status: "todo"})
+			},
+			want: SubtaskResult{Path: "T001.1.1", Title: "Pin base image", Status: "todo", Changed: true},
+			wantSubtasks: `  - [ ] Build image
+    - [ ] Pin base image
+    - [ ] Scan
+  - [ ] Tag release
+`,
+		},
+		{
+			name: "rename",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.RenameSubtask(ctx, RenameSubtaskParams{Path: "T001.2", Title: "Tag v1.0"})
+			},
+			want: SubtaskResult{Path: "T001.2", Title: "Tag v1.0", Status: "todo", Changed: true},
+			wantSubtasks: `  - [ ] Build image
+    - [x] Pin base image
+    - [ ] Scan
+  - [ ] Tag v1.0
+`,
+		},
+		{
+			name: "move with nested subtasks under a later sibling",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.MoveSubtask(ctx, MoveSubtaskParams{Path: "T001.1", Parent: "T001.2"})
+			},
+			want: SubtaskResult{Path: "T001.1.1", Title: "Build image", Status: "todo", Changed: true},
+			wantSubtasks: `  - [ ] Tag release
+    - [ ] Build image
+      - [x] Pin base image
+      - [ ] Scan
+`,
+		},
+		{
+			name: "move up a level",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.MoveSubtask(ctx, MoveSubtaskParams{Path: "T001.1.2", Parent: "T001", Position: 1})
+			},
+			want: SubtaskResult{Path: "T001.1", Title: "Scan", Status: "todo", Changed: true},
+			wantSubtasks: `  - [ ] Scan
+  - [ ] Build image
+    - [x] Pin base image
+  - [ ] Tag release
+`,
+		},
+		{
+			name: "move within its list",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.MoveSubtask(ctx, MoveSubtaskParams{Path: "T001.1.2", Position: 1})
+			},
+			want: SubtaskResult{Path: "T001.1.1", Title: "Scan", Status: "todo", Changed: true},
+			wantSubtasks: `  - [ ] Build image
+    - [ ] Scan
+    - [x] Pin base image
+  - [ ] Tag release
+`,
+		},
+		{
+			name: "move to where it is",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.MoveSubtask(ctx, MoveSubtaskParams{Path: "T001.2"})
+			},
+			want: SubtaskResult{Path: "T001.2", Title: "Tag release", Status: "todo", Changed: false},
+			wantSubtasks: `  - [ ] Build image
+    - [x] Pin base image
+    - [ ] Scan
+  - [ ] Tag release
+`,
+		},
+		{
+			name: "remove with nested subtasks",
+			op: func(ts *ToolService) (SubtaskResult, error) {
+				return ts.RemoveSubtask(ctx, RemoveSubtaskParams{Path: "T001.1"})
+			},
+			want: SubtaskResult{Path: "T001.1", Title: "Build image", Status: "todo", Changed: true},
+			wantSubtasks: `  - [ ] Tag release
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTaskFile(t, dir, subtaskTaskFile)
+			toolService := NewToolService(dir)
+
+			result, err := tt.op(toolService)
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			tt.want.UpdatedAt, tt.want.Project = result.UpdatedAt, filepath.Base(dir)
+			if diff := cmp.Diff(tt.want, result); diff != "" {
+				t.Errorf("result mismatch (-want +got):\n%s", diff)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := "# Task\n\n## Ops\n- [ ] Deploy #T001\n" + tt.wantSubtasks + "- [ ] Monitor #T002\n\n"
+			if diff := cmp.Diff(want, string(content)); diff != "" {
+				t.Errorf("task.md mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSubtaskTools_Errors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTaskFile(t, dir, subtaskTaskFile)
+	toolService := NewToolService(dir)
+	toolService.limits.MaxSubtasks = 4
+
+	tests := []struct {
+		name     string
+		op       func() (SubtaskResult, error)
+		wantCode errcode.Code
+	}{
+		{"unknown task", func() (SubtaskResult, error) {
+			return toolService.AddSubtask(ctx, AddSubtaskParams{Parent: "T009", Title: "x"})
+		}, errcode.TaskNotFound},
+		{"unknown parent", func() (SubtaskResult, error) {
+			return toolService.AddSubtask(ctx, AddSubtaskParams{Parent: "T001.5", Title: "x"})
+		}, errcode.TaskNotFound},
+		{"unknown subtask", func() (SubtaskResult, error) {
+			return toolService.CheckSubtask(ctx, CheckSubtaskParams{Path: "T001.1.3"})
+		}, errcode.TaskNotFound},
+		{"task ID as path", func() (SubtaskResult, error) {
+			return toolService.RemoveSubtask(ctx, RemoveSubtaskParams{Path: "T001"})
+		}, errcode.ValidationError},
+		{"zero in path", func() (SubtaskResult, error) {
+			return toolService.RenameSubtask(ctx, RenameSubtaskParams{Path: "T001.0", Title: "x"})
+		}, errcode.ValidationError},
+		{"invalid status", func() (SubtaskResult, error) {
+			return toolService.CheckSubtask(ctx, CheckSubtaskParams{Path: "T001.1", Status: "shipped"})
+		}, errcode.InvalidStatus},
+		{"position past the end", func() (SubtaskResult, error) {
+			return toolService.AddSubtask(ctx, AddSubtaskParams{Parent: "T001", Title: "x", Position: 4})
+		}, errcode.InvalidPosition},
+		{"move under itself", func() (SubtaskResult, error) {
+			return toolService.MoveSubtask(ctx, MoveSubtaskParams{Path: "T001.1", Parent: "T001.1.2"})
+		}, errcode.InvalidPosition},
+		{"move to another task", func() (SubtaskResult, error) {
+			return toolService.MoveSubtask(ctx, MoveSubtaskParams{Path: "T001.1", Parent: "T002"})
+		}, errcode.InvalidPosition},
+		{"too many subtasks", func() (SubtaskResult, error) {
+			return toolService.AddSubtask(ctx, AddSubtaskParams{Parent: "T001", Title: "x"})
+		}, errcode.ValidationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.op()
+			if code := errcode.CodeOf(err); code != tt.wantCode {
+				t.Errorf("error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestSubtaskTools_Rollup(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTaskFile(t, dir, subtaskTaskFile)
+	cfg := config.Default()
+	cfg.Workflow.Rollup = []string{model.RollupStart, model.RollupComplete}
+	toolService, err := NewToolServiceWithConfig(workspace.Root{Path: dir, Source: workspace.SourceWorkingDir}, cfg)
+	if err != nil {
+		t.Fatalf("NewToolServiceWithConfig() error = %v", err)
+	}
+
+	result, err := toolService.CheckSubtask(ctx, CheckSubtaskParams{Path: "T001.1.2"})
+	if err != nil {
+		t.Fatalf("CheckSubtask() error = %v", err)
+	}
+	want := []AutoTransition{
+		{Path: "T001.1", From: "todo", To: "done"},
+		{Path: "T001", From: "todo", To: "in_progress"},
+	}
+	if diff := cmp.Diff(want, result.AutoTransitions); diff != "" {
+		t.Errorf("auto transitions mismatch (-want +got):\n%s", diff)
+	}
+
+	result, err = toolService.CheckSubtask(ctx, CheckSubtaskParams{Path: "T001.2"})
+	if err != nil {
+		t.Fatalf("CheckSubtask() error = %v", err)
+	}
+	want = []AutoTransition{{Path: "T001", From: "in_progress", To: "done"}}
+	if diff := cmp.Diff(want, result.AutoTransitions); diff != "" {
+		t.Errorf("auto transitions mismatch (-want +got):\n%s", diff)
+	}
+
+	detail, err := toolService.GetTask(ctx, GetTaskParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatalf("GetTask() error = %v", err)
+	}
+	if detail.Status != "done" || detail.StartedAt == "" || detail.CompletedAt == "" {
+		t.Errorf("task after rollup = %s, started %q, completed %q", detail.Status, detail.StartedAt, detail.CompletedAt)
+	}
+}
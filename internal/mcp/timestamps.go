@@ -0,0 +1,43 @@
+package mcp
+
+import (
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// taskStatuses returns the status of each task by ID
+func taskStatuses(tasks []parser.ParsedTask) map[string]string {
+	statuses := make(map[string]string, len(tasks))
+	for _, task := range tasks {
+		statuses[task.Task.ID] = task.Task.Status
+	}
+	return statuses
+}
+
+// recordTimestamps updates the timestamps of the tasks created or moved to
+// another status since before, the statuses read from task.md at the start
+// of the change
+func recordTimestamps(p *project, before map[string]string, tasks []parser.ParsedTask, now time.Time) error {
+	times, err := p.storage.ReadTimestamps()
+	if err != nil {
+		return err
+	}
+	workflow := p.storage.Workflow()
+	for _, task := range tasks {
+		id := task.Task.ID
+		t := times[id]
+		from, existed := before[id]
+		if !existed {
+			from = workflow.Initial()
+			t.CreatedAt = now.Format(model.TimestampFormat)
+		}
+		if t.Transition(workflow, from, task.Task.Status, now) || !existed {
+			if err := p.storage.WriteTimestamps(id, t); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
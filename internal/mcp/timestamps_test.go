@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTaskTimestamps(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Written by hand #T001\n")
+	ts := NewToolService(dir)
+	ctx := context.Background()
+
+	created, err := ts.CreateTask(ctx, CreateTaskParams{Title: "Add login"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	times := func(taskID string) TaskDetail {
+		t.Helper()
+		detail, err := ts.GetTask(ctx, GetTaskParams{TaskID: taskID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return TaskDetail{CreatedAt: detail.CreatedAt, StartedAt: detail.StartedAt, CompletedAt: detail.CompletedAt}
+	}
+	if diff := cmp.Diff(TaskDetail{CreatedAt: created.CreatedAt}, times(created.TaskID)); diff != "" {
+		t.Errorf("timestamps after create_task mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, status := range []string{"in_progress", "todo", "in_progress", "done"} {
+		if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: created.TaskID, Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got := times(created.TaskID)
+	if got.CreatedAt != created.CreatedAt || got.StartedAt == "" || got.CompletedAt == "" {
+		t.Fatalf("timestamps after completion = %+v", got)
+	}
+	started, _ := time.Parse(time.RFC3339, got.StartedAt)
+	completed, _ := time.Parse(time.RFC3339, got.CompletedAt)
+	if completed.Before(started) {
+		t.Errorf("completed_at %s is before started_at %s", got.CompletedAt, got.StartedAt)
+	}
+
+	// Reopening clears the completion but keeps the first start
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: created.TaskID, Status: "in_progress"}); err != nil {
+		t.Fatal(err)
+	}
+	if reopened := times(created.TaskID); reopened.StartedAt != got.StartedAt || reopened.CompletedAt != "" {
+		t.Errorf("timestamps after reopening = %+v", reopened)
+	}
+
+	// Tasks written by hand have no creation time but record later changes
+	if _, err := ts.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Status: "done"}); err != nil {
+		t.Fatal(err)
+	}
+	if hand := times("T001"); hand.CreatedAt != "" || hand.StartedAt != "" || hand.CompletedAt == "" {
+		t.Errorf("timestamps of a hand-written task = %+v", hand)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".todo", "meta", created.TaskID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"created_at": "`+created.CreatedAt+`"`) {
+		t.Errorf("timestamps file = %s", content)
+	}
+}
+
+func TestListTasksTool_Timestamps(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [x] Add login #T001\n- [ ] Write docs #T002\n")
+	writeSource(t, dir, ".todo/meta/T001.json",
+		`{"created_at": "2026-10-01T09:00:00Z", "started_at": "2026-10-02T09:00:00Z", "completed_at": "2026-10-05T18:00:00Z"}`)
+	session := connectTestClient(t, dir)
+
+	result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: "list_tasks", Arguments: map[string]any{}})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	text := result.Content[0].(*mcpsdk.TextContent).Text
+	want := "2 task(s):\n" +
+		"- T001 [done] Add login (Default, created 2026-10-01, started 2026-10-02, completed 2026-10-05)\n" +
+		"- T002 [todo] Write docs (Default)"
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
@@ -0,0 +1,283 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/comments"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// DefaultImportCategory is the category of tasks imported from comments unless another is given
+const DefaultImportCategory = "Code"
+
+// sourceSection is the context file section recording the comment a task was imported from
+const sourceSection = "Source"
+
+// maxTitleLength is the length of the longest task title
+const maxTitleLength = 100
+
+var (
+	// fingerprintRegex finds the fingerprint in the Source section of a context file
+	fingerprintRegex = regexp.MustCompile("(?m)^Fingerprint: ([0-9a-f]+)$")
+	// locationRegex finds the file:line reference in the Source section of a context file
+	locationRegex = regexp.MustCompile("(?m)^`([^`\n]+:[0-9]+)`")
+)
+
+// ImportTodosParams defines the input parameters for import_todos tool
+type ImportTodosParams struct {
+	Category string `json:"category,omitempty" description:"Category of the created tasks (default Code)" schema:"maxLength=50"`
+	DryRun   bool   `json:"dry_run,omitempty" description:"Report the changes without making them" schema:"default=false"`
+	Project  string `json:"project,omitempty" description:"Project to scan (optional; required when several projects are open)"`
+}
+
+// ImportTodosResult defines the response from import_todos tool
+type ImportTodosResult struct {
+	Created   []ImportedTodo `json:"created" description:"Tasks created for new comments"`
+	Moved     []ImportedTodo `json:"moved" description:"Tasks whose comment moved to another line"`
+	Completed []ImportedTodo `json:"completed" description:"Tasks marked done because their comment is gone"`
+	Comments  int            `json:"comments" description:"Number of TODO, FIXME and HACK comments found"`
+	DryRun    bool           `json:"dry_run" description:"Whether the changes were only reported"`
+	Project   string         `json:"project" description:"Project that was scanned"`
+}
+
+// ImportedTodo describes a task tracking a comment
+type ImportedTodo struct {
+	TaskID   string `json:"task_id" description:"Task ID"`
+	Title    string `json:"title" description:"Task title"`
+	Location string `json:"location" description:"file:line of the comment, relative to the project root (last known for removed comments)"`
+}
+
+// importedTask is a task imported from a comment by an earlier scan
+type importedTask struct {
+	index    int
+	location string
+	context  string
+}
+
+// ImportTodosHandler handles the import_todos MCP tool
+func (ts *ToolService) ImportTodosHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[ImportTodosParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.ImportTodos, ts.formatImportedTodos)(ctx, session, params)
+}
+
+// ImportTodos creates a task for each TODO, FIXME and HACK comment in the
+// project's source files that has none yet. Each task's context file
+// records the comment's file:line and a fingerprint, which ties the comment
+// to the task on later runs. Tasks whose comment disappeared are marked
+// done, and a moved comment updates the file:line.
+func (ts *ToolService) ImportTodos(ctx context.Context, args ImportTodosParams) (ImportTodosResult, error) {
+	if err := validateParams(args); err != nil {
+		return ImportTodosResult{}, err
+	}
+	category := args.Category
+	if category == "" {
+		category = DefaultImportCategory
+	}
+	if err := checkCategory(category); err != nil {
+		return ImportTodosResult{}, err
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return ImportTodosResult{}, err
+	}
+	defer unlock()
+	workflow := p.storage.Workflow()
+	// Other projects below this one track their own comments
+	exclude := []string{p.storage.DataDir()}
+	for _, other := range ts.viewOf(ctx).projects {
+		if rel, err := filepath.Rel(p.Path, other.Path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+			exclude = append(exclude, other.Path)
+		}
+	}
+	found, err := comments.Scan(ctx, p.Path, exclude)
+	if err != nil {
+		return ImportTodosResult{}, err
+	}
+	tasks, err := readTasks(p)
+	if err != nil {
+		return ImportTodosResult{}, err
+	}
+	imported, err := importedTasks(p, tasks)
+	if err != nil {
+		return ImportTodosResult{}, err
+	}
+	before := taskStatuses(tasks)
+
+	result := ImportTodosResult{
+		Created:   []ImportedTodo{},
+		Moved:     []ImportedTodo{},
+		Completed: []ImportedTodo{},
+		Comments:  len(found),
+		DryRun:    args.DryRun,
+		Project:   p.Name,
+	}
+	contexts := make(map[string]string)
+	seen := make(map[string]bool)
+	ids, err := ts.usedTaskIDs(p, tasks)
+	if err != nil {
+		return ImportTodosResult{}, err
+	}
+	now := time.Now().Format(time.RFC3339)
+	for _, c := range found {
+		seen[c.Fingerprint] = true
+		location := fmt.Sprintf("%s:%d", c.File, c.Line)
+		if known, ok := imported[c.Fingerprint]; ok {
+			if known.location != location {
+				task := tasks[known.index].Task
+				result.Moved = append(result.Moved, ImportedTodo{TaskID: task.ID, Title: task.Title, Location: location})
+				contexts[task.ID] = parser.SetSection(known.context, sourceSection, sourceBody(c), false)
+			}
+			continue
+		}
+
+		if len(tasks) >= ts.limits.MaxTasks {
+			return ImportTodosResult{}, errcode.New(errcode.TaskLimitExceeded, "task limit of %d reached", ts.limits.MaxTasks).
+				WithDetails("location", location)
+		}
+		id, err := ts.ids.Next(ids)
+		if err != nil {
+			return ImportTodosResult{}, err
+		}
+		ids = append(ids, id)
+		title := commentTitle(c)
+		task := ts.createTask(id, title, category, workflow.Initial())
+		if err := task.Validate(workflow); err != nil {
+			return ImportTodosResult{}, errcode.As(err).WithDetail("location", location)
+		}
+		tasks = append(tasks, parser.ParsedTask{Task: task})
+		content, err := ts.renderContext(contextTemplateData{
+			TaskID:      id,
+			Title:       title,
+			Category:    category,
+			Description: fmt.Sprintf("Imported from a %s comment.", c.Kind),
+			CreatedAt:   now,
+		})
+		if err != nil {
+			return ImportTodosResult{}, err
+		}
+		contexts[id] = parser.SetSection(content, sourceSection, sourceBody(c), false)
+		result.Created = append(result.Created, ImportedTodo{TaskID: id, Title: title, Location: location})
+	}
+	done := workflow.StageStatus(model.StageDone)
+	for fp, known := range imported {
+		task := &tasks[known.index].Task
+		if seen[fp] || done == "" || workflow.IsDone(task.Status) || !workflow.CanTransition(task.Status, done) {
+			continue
+		}
+		task.Status = done
+		result.Completed = append(result.Completed, ImportedTodo{TaskID: task.ID, Title: task.Title, Location: known.location})
+	}
+	slices.SortFunc(result.Completed, func(a, b ImportedTodo) int { return strings.Compare(a.TaskID, b.TaskID) })
+
+	if args.DryRun || len(result.Created)+len(result.Moved)+len(result.Completed) == 0 {
+		return result, nil
+	}
+	if len(result.Created)+len(result.Completed) > 0 {
+		if err := p.storage.WriteTasksFile(tasks); err != nil {
+			return ImportTodosResult{}, err
+		}
+		if err := recordTimestamps(p, before, tasks, time.Now()); err != nil {
+			return ImportTodosResult{}, err
+		}
+	}
+	for id, content := range contexts {
+		if err := p.storage.WriteContextFile(model.NewContext(id, content)); err != nil {
+			return ImportTodosResult{}, err
+		}
+	}
+	ts.commit(ctx, p, "import code comments: %d created, %d moved, %d done",
+		len(result.Created), len(result.Moved), len(result.Completed))
+	return result, nil
+}
+
+// importedTasks returns the tasks imported from comments, by fingerprint
+func importedTasks(p *project, tasks []parser.ParsedTask) (map[string]importedTask, error) {
+	imported := make(map[string]importedTask)
+	for i, task := range tasks {
+		context, err := p.storage.ReadContextFile(task.Task.ID)
+		if errcode.HasCode(err, errcode.FileNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		fp := fingerprintRegex.FindStringSubmatch(context.Content)
+		if fp == nil {
+			continue
+		}
+		known := importedTask{index: i, context: context.Content}
+		if location := locationRegex.FindStringSubmatch(context.Content); location != nil {
+			known.location = location[1]
+		}
+		imported[fp[1]] = known
+	}
+	return imported, nil
+}
+
+// sourceBody returns the Source section of the context file of a task imported from c
+func sourceBody(c comments.Comment) string {
+	comment := c.Kind
+	if c.Text != "" {
+		comment += ": " + c.Text
+	}
+	return fmt.Sprintf("`%s:%d` %s\nFingerprint: %s", c.File, c.Line, comment, c.Fingerprint)
+}
+
+// commentTitle returns the title of the task for a comment. TODO comments
+// are titled with their text alone, others keep their kind. Words that
+// would be read as metadata are quoted; task IDs the comment mentions stay
+// as references, since the task's own ID is written last.
+func commentTitle(c comments.Comment) string {
+	title := model.QuoteMetadata(c.Text)
+	switch {
+	case title == "":
+		title = fmt.Sprintf("%s in %s", c.Kind, filepath.Base(c.File))
+	case c.Kind != comments.KindTodo:
+		title = c.Kind + ": " + title
+	}
+	if runes := []rune(title); len(runes) > maxTitleLength {
+		title = strings.TrimSpace(string(runes[:maxTitleLength-3])) + "..."
+	}
+	return title
+}
+
+// formatImportedTodos renders an import_todos result as text
+func (ts *ToolService) formatImportedTodos(ctx context.Context, result ImportTodosResult) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Found %d comments: %d new, %d moved, %d gone", result.Comments,
+		len(result.Created), len(result.Moved), len(result.Completed))
+	if result.DryRun {
+		sb.WriteString(" (dry run, nothing changed)")
+	}
+	for _, group := range []struct {
+		verb  string
+		todos []ImportedTodo
+	}{{"created", result.Created}, {"moved", result.Moved}, {"done", result.Completed}} {
+		for _, todo := range group.todos {
+			fmt.Fprintf(&sb, "\n%s %s %s (%s)", ts.qualifiedID(ctx, result.Project, todo.TaskID), group.verb, todo.Title, todo.Location)
+		}
+	}
+	return sb.String()
+}
+
+// AddImportTodosTool adds the import_todos tool to the MCP server
+func AddImportTodosTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[ImportTodosParams, ImportTodosResult]("import_todos",
+			"Create tasks for TODO, FIXME and HACK comments in the source code and complete those whose comment is gone",
+			toolService.ImportTodosHandler),
+	)
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// writeSource writes a source file below dir
+func writeSource(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportTodos(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Existing task #T001\n")
+	writeSource(t, dir, "main.go", "package main\n\n// TODO: handle signals\nfunc main() {} // FIXME: exit code\n")
+	writeSource(t, dir, "db/pool.go", "package db\n\n// HACK\n")
+
+	ts := NewToolService(dir)
+	ctx := context.Background()
+
+	dry, err := ts.ImportTodos(ctx, ImportTodosParams{DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportTodos(dry_run) error = %v", err)
+	}
+	wantCreated := []ImportedTodo{
+		{TaskID: "T002", Title: "HACK in pool.go", Location: "db/pool.go:3"},
+		{TaskID: "T003", Title: "handle signals", Location: "main.go:3"},
+		{TaskID: "T004", Title: "FIXME: exit code", Location: "main.go:4"},
+	}
+	if diff := cmp.Diff(wantCreated, dry.Created); diff != "" {
+		t.Errorf("Created mismatch (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".todo", "context", "T002.md")); !os.IsNotExist(err) {
+		t.Errorf("dry run should not write context files, stat error = %v", err)
+	}
+
+	result, err := ts.ImportTodos(ctx, ImportTodosParams{Category: "Tech debt"})
+	if err != nil {
+		t.Fatalf("ImportTodos() error = %v", err)
+	}
+	if diff := cmp.Diff(wantCreated, result.Created); diff != "" {
+		t.Errorf("Created mismatch (-want +got):\n%s", diff)
+	}
+	detail, err := ts.GetTask(ctx, GetTaskParams{TaskID: "T003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Category != "Tech debt" || !strings.Contains(detail.Context, "## Source\n`main.go:3` TODO: handle signals\nFingerprint: ") {
+		t.Errorf("T003 = %s, context:\n%s", detail.Category, detail.Context)
+	}
+
+	// Re-running creates no duplicates, follows moved comments and
+	// completes tasks whose comment is gone
+	writeSource(t, dir, "main.go", "package main\n\n// Entry point\n// TODO: handle signals\nfunc main() {}\n")
+	result, err = ts.ImportTodos(ctx, ImportTodosParams{})
+	if err != nil {
+		t.Fatalf("ImportTodos() error = %v", err)
+	}
+	want := ImportTodosResult{
+		Created:   []ImportedTodo{},
+		Moved:     []ImportedTodo{{TaskID: "T003", Title: "handle signals", Location: "main.go:4"}},
+		Completed: []ImportedTodo{{TaskID: "T004", Title: "FIXME: exit code", Location: "main.go:4"}},
+		Comments:  2,
+		Project:   result.Project,
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("ImportTodos() mismatch (-want +got):\n%s", diff)
+	}
+	detail, err = ts.GetTask(ctx, GetTaskParams{TaskID: "T004"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Status != "done" {
+		t.Errorf("T004 status = %s, want done", detail.Status)
+	}
+	context, err := ts.GetContext(ctx, GetContextParams{TaskID: "T003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(context.Content, "`main.go:4` TODO: handle signals") {
+		t.Errorf("context of T003 keeps the old location:\n%s", context.Content)
+	}
+}
+
+func TestImportTodosTool(t *testing.T) {
+	dir := t.TempDir()
+	writeSource(t, dir, "main.go", "// TODO: write tests\n")
+	session := connectTestClient(t, dir)
+
+	result, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{
+		Name:      "import_todos",
+		Arguments: map[string]any{"dry_run": true},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	text := result.Content[0].(*mcpsdk.TextContent).Text
+	want := "Found 1 comments: 1 new, 0 moved, 0 gone (dry run, nothing changed)\nT001 created write tests (main.go:1)"
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestImportTodos_MetadataWords(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Existing task #T001\n")
+	writeSource(t, dir, "main.go", "package main\n\n// TODO: ask @bob about +retry after #T001 lands\n")
+	ctx := context.Background()
+
+	// Each run reads the file written by the one before
+	for i, wantCreated := range [][]ImportedTodo{
+		{{TaskID: "T002", Title: "ask `@bob` about `+retry` after #T001 lands", Location: "main.go:3"}},
+		{},
+	} {
+		result, err := NewToolService(dir).ImportTodos(ctx, ImportTodosParams{})
+		if err != nil {
+			t.Fatalf("ImportTodos() run %d error = %v", i+1, err)
+		}
+		if diff := cmp.Diff(wantCreated, result.Created); diff != "" {
+			t.Errorf("run %d Created mismatch (-want +got):\n%s", i+1, diff)
+		}
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Task\n\n## Default\n- [ ] Existing task #T001\n\n## Code\n- [ ] ask `@bob` about `+retry` after #T001 lands #T002\n\n"
+	if diff := cmp.Diff(want, string(content)); diff != "" {
+		t.Errorf("task.md mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// methodCallTool is the MCP method name for tool calls
+const methodCallTool = "tools/call"
+
+// newServerTool binds a typed handler to a tool definition.
+// The input and output schemas are generated from In and Out, including the
+// constraints declared in their struct tags (see schemaFor); the input schema
+// is enforced by validateToolInput. Successful results should carry an Out
+// value as structured content.
+// Unlike mcpsdk.NewServerTool, the handler's result is passed to the client
+// unchanged, so structured content (including structured errors) is preserved.
+func newServerTool[In, Out any](name, description string, handler mcpsdk.ToolHandlerFor[In, any]) *mcpsdk.ServerTool {
+	inputSchema, err := schemaFor[In]()
+	if err != nil {
+		panic(fmt.Errorf("newServerTool(%q): %w", name, err))
+	}
+	outputSchema, err := schemaFor[Out]()
+	if err != nil {
+		panic(fmt.Errorf("newServerTool(%q): %w", name, err))
+	}
+	toolSchemas.Store(name, inputSchema)
+
+	return &mcpsdk.ServerTool{
+		Tool: &mcpsdk.Tool{
+			Name:         name,
+			Description:  description,
+			InputSchema:  inputSchema,
+			OutputSchema: outputSchema,
+		},
+		Handler: func(
+			ctx context.Context,
+			session *mcpsdk.ServerSession,
+			params *mcpsdk.CallToolParamsFor[map[string]any],
+		) (*mcpsdk.CallToolResult, error) {
+			var args In
+			if err := decodeArguments(params.Arguments, &args); err != nil {
+				return toolError(err), nil
+			}
+			return handler(ctx, session, &mcpsdk.CallToolParamsFor[In]{
+				Meta:      params.Meta,
+				Name:      params.Name,
+				Arguments: args,
+			})
+		},
+	}
+}
+
+// toolHandler adapts a tool operation to an MCP tool handler. Errors become
+// structured error results; results are returned as structured content with
+// the text rendered by format.
+func toolHandler[In, Out any](op func(context.Context, In) (Out, error), format func(context.Context, Out) string) mcpsdk.ToolHandlerFor[In, any] {
+	return func(ctx context.Context, _ *mcpsdk.ServerSession, params *mcpsdk.CallToolParamsFor[In]) (*mcpsdk.CallToolResultFor[any], error) {
+		result, err := op(ctx, params.Arguments)
+		if err != nil {
+			return toolError(err), nil
+		}
+		return toolResult(format(ctx, result), result), nil
+	}
+}
+
+// toolResult creates a successful tool result carrying structured content,
+// with a short text summary for clients that cannot read structured results
+func toolResult(summary string, structured any) *mcpsdk.CallToolResultFor[any] {
+	return &mcpsdk.CallToolResultFor[any]{
+		Content:           []mcpsdk.Content{&mcpsdk.TextContent{Text: summary}},
+		StructuredContent: structured,
+	}
+}
+
+// validateToolInput is a receiving middleware that validates tool arguments
+// against the tool's input schema before any handler runs. Violations are
+// returned as VALIDATION_ERROR tool results rather than protocol errors, so
+// agents can see which field to fix.
+func validateToolInput(next mcpsdk.MethodHandler[*mcpsdk.ServerSession]) mcpsdk.MethodHandler[*mcpsdk.ServerSession] {
+	return func(ctx context.Context, session *mcpsdk.ServerSession, method string, params mcpsdk.Params) (mcpsdk.Result, error) {
+		if method != methodCallTool {
+			return next(ctx, session, method, params)
+		}
+		p, ok := params.(*mcpsdk.CallToolParamsFor[json.RawMessage])
+		if !ok {
+			return next(ctx, session, method, params)
+		}
+		schema, ok := toolSchemas.Load(p.Name)
+		if !ok {
+			return next(ctx, session, method, params)
+		}
+
+		var args any = map[string]any{}
+		if len(p.Arguments) > 0 {
+			if err := json.Unmarshal(p.Arguments, &args); err != nil {
+				return toolError(errcode.Wrap(err, errcode.ValidationError, "arguments are not valid JSON")), nil
+			}
+		}
+		if err := validateInput(schema.(*jsonschema.Schema), args); err != nil {
+			return toolError(err), nil
+		}
+		return next(ctx, session, method, params)
+	}
+}
+
+// decodeArguments converts generic tool arguments into the handler's input type
+func decodeArguments(arguments map[string]any, v any) error {
+	if arguments == nil {
+		return nil
+	}
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return errcode.Wrap(err, errcode.ValidationError, "invalid arguments")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errcode.Wrap(err, errcode.ValidationError, "invalid arguments")
+	}
+	return nil
+}
@@ -5,135 +5,268 @@ package mcp
 import (
 	"context"
 	"fmt"
-	"regexp"
-	"sort"
-	"strconv"
+	"strings"
+	"sync"
+	"text/template"
 	"time"
 
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
 	"github.com/jnst/agentic-todo-mcp/internal/model"
 	"github.com/jnst/agentic-todo-mcp/internal/parser"
 	"github.com/jnst/agentic-todo-mcp/internal/storage"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
 )
 
-const (
-	// DefaultTaskID is the default task ID for first task
-	DefaultTaskID = "T001"
-	// DefaultStatus is the default status for new tasks
-	DefaultStatus = "todo"
-)
+// defaultContextTemplate is the context file template used unless the config provides one
+var defaultContextTemplate = template.Must(template.New("context").Parse(
+	"# Context for {{.TaskID}}\n\n## Task Description\n{{.Description}}\n\n## Created\n{{.CreatedAt}}\n"))
+
+// contextTemplateData is the data available to context file templates
+type contextTemplateData struct {
+	TaskID      string
+	Title       string
+	Category    string
+	Description string
+	CreatedAt   string
+}
 
 // CreateTaskParams defines the input parameters for create_task tool
 type CreateTaskParams struct {
-	Title       string   `json:"title"`
-	Category    string   `json:"category,omitempty"`
-	Description string   `json:"description,omitempty"`
-	Subtasks    []string `json:"subtasks,omitempty"`
+	Title         string   `json:"title" description:"Task title" schema:"minLength=1,maxLength=100"`
+	Category      string   `json:"category,omitempty" description:"Task category (optional)" schema:"maxLength=50"`
+	Description   string   `json:"description,omitempty" description:"Task description (optional)" schema:"maxLength=500"`
+	Subtasks      []string `json:"subtasks,omitempty" description:"List of subtask titles (optional)" schema:"maxItems=20,items.maxLength=100"`
+	Due           string   `json:"due,omitempty" description:"Due date as YYYY-MM-DD (optional)" schema:"format=date"`
+	PriorityLabel string   `json:"priority_label,omitempty" description:"Priority label (optional)" schema:"enum=high|medium|low"`
+	Assignee      string   `json:"assignee,omitempty" description:"Person the task is assigned to (optional)" schema:"maxLength=50"`
+	Tags          []string `json:"tags,omitempty" description:"Tags (optional)" schema:"maxItems=20,items.maxLength=50"`
+	Project       string   `json:"project,omitempty" description:"Project to create the task in (optional; required when several projects are open)"`
 }
 
 // CreateTaskResult defines the response from create_task tool
 type CreateTaskResult struct {
-	TaskID    string `json:"task_id"`
-	FilePath  string `json:"file_path"`
-	CreatedAt string `json:"created_at"`
+	TaskID    string `json:"task_id" description:"Generated task ID" schema:"pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Title     string `json:"title" description:"Task title"`
+	Category  string `json:"category" description:"Task category"`
+	FilePath  string `json:"file_path" description:"Path of the created context file"`
+	CreatedAt string `json:"created_at" description:"Creation time" schema:"format=date-time"`
+	Project   string `json:"project" description:"Project the task was created in"`
 }
 
-// ToolService provides MCP tool implementations
+// ToolService provides MCP tool implementations.
+// It is safe for concurrent use: mutating operations are serialized so that
+// clients sharing one server never interleave read-modify-write cycles.
 type ToolService struct {
-	storage *storage.FileStorage
+	shared          view
+	sessions        map[*mcpsdk.ServerSession]*view
+	fixedProjects   bool
+	scanDepth       int
+	storageOpts     storage.Options
+	contextTemplate *template.Template
+	ids             model.IDScheme
+	defaultCategory string
+	limits          config.Limits
+	archive         config.Archive
+	autoCommit      bool
+	mu              sync.Mutex
+	closed          bool
 }
 
-// NewToolService creates a new ToolService instance
+// NewToolService creates a new ToolService instance with the default configuration
 func NewToolService(basePath string) *ToolService {
+	root := workspace.Root{Path: basePath, Source: workspace.SourceWorkingDir}
+	ts := newToolService(config.Default(), defaultContextTemplate)
+	ts.SetRoots(root)
+	return ts
+}
+
+// NewToolServiceWithConfig creates a new ToolService instance for the workspace root and configuration.
+// The configuration should have been validated with config.Config.Validate.
+func NewToolServiceWithConfig(root workspace.Root, cfg *config.Config) (*ToolService, error) {
+	ts := newToolService(cfg, defaultContextTemplate)
+	if len(cfg.Workspace.Projects) > 0 {
+		roots, err := workspace.Resolve(root.Path, cfg.Workspace.Projects)
+		if err != nil {
+			return nil, errcode.WrapFS(err, errcode.FileNotFound, "config: workspace.projects")
+		}
+		ts.shared = view{home: root, projects: ts.newProjects(root, roots)}
+		ts.fixedProjects = true
+	} else {
+		ts.SetRoots(root)
+	}
+
+	dataDir := storage.NewFileStorageWithOptions(root.Path, ts.storageOpts).DataDir()
+	tmpl, err := cfg.ContextTemplate(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl != nil {
+		ts.contextTemplate = tmpl
+	}
+	return ts, nil
+}
+
+func newToolService(cfg *config.Config, contextTemplate *template.Template) *ToolService {
 	return &ToolService{
-		storage: storage.NewFileStorage(basePath),
+		scanDepth:       cfg.Workspace.ScanDepth,
+		storageOpts:     cfg.StorageOptions(),
+		contextTemplate: contextTemplate,
+		ids:             cfg.IDScheme(),
+		defaultCategory: cfg.Tasks.DefaultCategory,
+		limits:          cfg.Limits,
+		archive:         cfg.Archive,
+		autoCommit:      cfg.Git.AutoCommit,
+		sessions:        map[*mcpsdk.ServerSession]*view{},
 	}
 }
 
 // CreateTaskHandler handles the create_task MCP tool
 func (ts *ToolService) CreateTaskHandler(
-	_ context.Context,
-	_ *mcpsdk.ServerSession,
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
 	params *mcpsdk.CallToolParamsFor[CreateTaskParams],
 ) (*mcpsdk.CallToolResultFor[any], error) {
-	args := params.Arguments
+	return toolHandler(ts.CreateTask, ts.formatCreatedTask)(ctx, session, params)
+}
+
+// CreateTask creates a main task with an auto-generated task ID and its context file
+func (ts *ToolService) CreateTask(ctx context.Context, args CreateTaskParams) (CreateTaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return CreateTaskResult{}, err
+	}
+
+	if len(args.Subtasks) > ts.limits.MaxSubtasks {
+		return CreateTaskResult{}, errcode.New(errcode.ValidationError, "at most %d subtasks are allowed", ts.limits.MaxSubtasks).
+			WithDetails("subtasks", len(args.Subtasks))
+	}
 
-	// Validate required fields
-	if args.Title == "" {
-		return ts.createErrorResponse("Title is required"), nil
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return CreateTaskResult{}, err
 	}
+	defer unlock()
 
 	// Read existing tasks to generate next ID
-	existingTasks, err := ts.storage.ReadTasksFile()
+	existingTasks, err := readTasks(p)
 	if err != nil {
-		// If file doesn't exist, start with empty list
-		existingTasks = []parser.ParsedTask{}
+		return CreateTaskResult{}, err
 	}
 
 	// Extract existing task IDs and generate next task ID
-	existingIDs := ts.extractTaskIDs(existingTasks)
-	newTaskID := GenerateNextTaskID(existingIDs)
+	if len(existingTasks) >= ts.limits.MaxTasks {
+		return CreateTaskResult{}, errcode.New(errcode.TaskLimitExceeded, "task limit of %d reached", ts.limits.MaxTasks)
+	}
+	existingIDs, err := ts.usedTaskIDs(p, existingTasks)
+	if err != nil {
+		return CreateTaskResult{}, err
+	}
+	newTaskID, err := ts.ids.Next(existingIDs)
+	if err != nil {
+		return CreateTaskResult{}, err
+	}
 
 	// Set default category if not provided
 	category := args.Category
 	if category == "" {
-		category = "Default"
+		category = ts.defaultCategory
+	}
+	if err := checkCategory(category); err != nil {
+		return CreateTaskResult{}, err
 	}
 
 	// Create new main task and subtasks
-	newTask := ts.createTask(newTaskID, args.Title, category)
-	subtasks := ts.createSubtasks(args.Subtasks)
+	workflow := p.storage.Workflow()
+	newTask := ts.createTask(newTaskID, args.Title, category, workflow.Initial())
+	newTask.Due = args.Due
+	newTask.Priority = args.PriorityLabel
+	newTask.Assignee = args.Assignee
+	newTask.Tags = args.Tags
+	if err := newTask.Validate(workflow); err != nil {
+		return CreateTaskResult{}, err
+	}
+	subtasks := ts.createSubtasks(args.Subtasks, workflow.Initial())
 
 	// Create parsed task and add to existing tasks
 	parsedTask := parser.ParsedTask{
 		Task:     newTask,
 		SubTasks: subtasks,
 	}
+	before := taskStatuses(existingTasks)
 	existingTasks = append(existingTasks, parsedTask)
 
 	// Write updated tasks to file
-	if err := ts.storage.WriteTasksFile(existingTasks); err != nil {
-		return ts.createErrorResponse(fmt.Sprintf("Failed to write tasks: %v", err)), nil
+	if err := p.storage.WriteTasksFile(existingTasks); err != nil {
+		return CreateTaskResult{}, err
 	}
 
 	// Create and write context file
-	if err := ts.createContextFile(newTaskID, args.Description); err != nil {
-		return ts.createErrorResponse(fmt.Sprintf("Failed to write context: %v", err)), nil
+	createdAt := time.Now()
+	if err := ts.createContextFile(p.storage, contextTemplateData{
+		TaskID:      newTaskID,
+		Title:       args.Title,
+		Category:    category,
+		Description: args.Description,
+		CreatedAt:   createdAt.Format(time.RFC3339),
+	}); err != nil {
+		return CreateTaskResult{}, err
+	}
+	if err := recordTimestamps(p, before, existingTasks, createdAt); err != nil {
+		return CreateTaskResult{}, err
 	}
+	ts.commit(ctx, p, "create %s '%s'", newTaskID, args.Title)
+
+	return CreateTaskResult{
+		TaskID:    newTaskID,
+		Title:     args.Title,
+		Category:  category,
+		FilePath:  p.storage.ContextFilePath(newTaskID),
+		CreatedAt: createdAt.Format(time.RFC3339),
+		Project:   p.Name,
+	}, nil
+}
 
-	// Create success response
-	return ts.createSuccessResponse(newTaskID, args.Title, category), nil
+// Close waits for in-flight writes to finish and rejects any later ones
+func (ts *ToolService) Close() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.closed = true
+	return nil
 }
 
-// GenerateNextTaskID generates the next sequential task ID
-func GenerateNextTaskID(existingIDs []string) string {
-	if len(existingIDs) == 0 {
-		return DefaultTaskID
+// lock serializes a mutating operation, failing once the service is closed
+func (ts *ToolService) lock() (unlock func(), err error) {
+	ts.mu.Lock()
+	if ts.closed {
+		ts.mu.Unlock()
+		return nil, errcode.New(errcode.Internal, "server is shutting down")
 	}
+	return ts.mu.Unlock, nil
+}
 
-	// Extract numeric parts and find the maximum
-	var numbers []int
-	taskIDRegex := regexp.MustCompile(`^T(\d{3})$`)
-
-	for _, id := range existingIDs {
-		matches := taskIDRegex.FindStringSubmatch(id)
-		if len(matches) > 1 {
-			if num, err := strconv.Atoi(matches[1]); err == nil {
-				numbers = append(numbers, num)
-			}
-		}
+// lockProject serializes a mutating operation on the named project, with
+// other processes too, and returns the project
+func (ts *ToolService) lockProject(ctx context.Context, name string) (p *project, unlock func(), err error) {
+	unlockService, err := ts.lock()
+	if err != nil {
+		return nil, nil, err
 	}
-
-	if len(numbers) == 0 {
-		return DefaultTaskID
+	p, err = ts.project(ctx, name)
+	if err != nil {
+		unlockService()
+		return nil, nil, err
 	}
-
-	sort.Ints(numbers)
-	maxNum := numbers[len(numbers)-1]
-	nextNum := maxNum + 1
-
-	return fmt.Sprintf("T%03d", nextNum)
+	unlockFiles, err := p.storage.Lock()
+	if err != nil {
+		unlockService()
+		return nil, nil, err
+	}
+	return p, func() {
+		unlockFiles()
+		unlockService()
+	}, nil
 }
 
 // Helper methods for CreateTaskHandler
@@ -148,72 +281,62 @@ func (ts *ToolService) extractTaskIDs(tasks []parser.ParsedTask) []string {
 }
 
 // createTask creates a new Task with the given parameters
-func (ts *ToolService) createTask(id, title, category string) model.Task {
+func (ts *ToolService) createTask(id, title, category, status string) model.Task {
 	return model.Task{
 		ID:       id,
 		Title:    title,
-		Status:   DefaultStatus,
+		Status:   status,
 		Category: category,
 	}
 }
 
 // createSubtasks creates subtasks from a list of titles
-func (ts *ToolService) createSubtasks(titles []string) []model.Task {
-	var subtasks []model.Task
+func (ts *ToolService) createSubtasks(titles []string, status string) []model.SubTask {
+	var subtasks []model.SubTask
 	for _, title := range titles {
-		subtask := model.Task{
-			Title:  title,
-			Status: DefaultStatus,
-		}
-		subtasks = append(subtasks, subtask)
+		subtasks = append(subtasks, model.NewSubTask(title, status))
 	}
 	return subtasks
 }
 
-// createContextFile creates and writes a context file
-func (ts *ToolService) createContextFile(taskID, description string) error {
-	contextContent := fmt.Sprintf("# Context for %s\n\n## Task Description\n%s\n\n## Created\n%s\n",
-		taskID, description, time.Now().Format(time.RFC3339))
+// createContextFile renders the context template and writes the context file
+func (ts *ToolService) createContextFile(fs *storage.FileStorage, data contextTemplateData) error {
+	content, err := ts.renderContext(data)
+	if err != nil {
+		return err
+	}
 
 	context := model.Context{
-		TaskID:  taskID,
-		Content: contextContent,
+		TaskID:  data.TaskID,
+		Content: content,
 	}
 
-	return ts.storage.WriteContextFile(context)
+	return fs.WriteContextFile(context)
 }
 
-// createErrorResponse creates an error response
-func (ts *ToolService) createErrorResponse(message string) *mcpsdk.CallToolResultFor[any] {
-	return &mcpsdk.CallToolResultFor[any]{
-		IsError: true,
-		Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: message}},
+// renderContext renders the context template for a new task
+func (ts *ToolService) renderContext(data contextTemplateData) (string, error) {
+	var sb strings.Builder
+	if err := ts.contextTemplate.Execute(&sb, data); err != nil {
+		return "", errcode.Wrap(err, errcode.Internal, "failed to render context template")
 	}
+	return sb.String(), nil
 }
 
-// createSuccessResponse creates a success response
-func (ts *ToolService) createSuccessResponse(taskID, title, category string) *mcpsdk.CallToolResultFor[any] {
-	filePath := fmt.Sprintf(".todo/context/%s.md", taskID)
-
+// formatCreatedTask renders a create_task result as text
+func (ts *ToolService) formatCreatedTask(ctx context.Context, result CreateTaskResult) string {
 	responseText := fmt.Sprintf("Task created successfully:\n- Task ID: %s\n- Title: %s\n- Category: %s\n- Context file: %s",
-		taskID, title, category, filePath)
-
-	return &mcpsdk.CallToolResultFor[any]{
-		Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: responseText}},
+		result.TaskID, result.Title, result.Category, ts.relativePath(ctx, result.Project, result.FilePath))
+	if ts.multiProject(ctx) {
+		responseText += "\n- Project: " + result.Project
 	}
+	return responseText
 }
 
 // AddCreateTaskTool adds the create_task tool to the MCP server
 func AddCreateTaskTool(server *mcpsdk.Server, toolService *ToolService) {
 	server.AddTools(
-		mcpsdk.NewServerTool("create_task", "Create new main-task with auto-generated task-id",
-			toolService.CreateTaskHandler,
-			mcpsdk.Input(
-				mcpsdk.Property("title", mcpsdk.Description("Task title")),
-				mcpsdk.Property("category", mcpsdk.Description("Task category (optional)")),
-				mcpsdk.Property("description", mcpsdk.Description("Task description (optional)")),
-				mcpsdk.Property("subtasks", mcpsdk.Description("List of subtask titles (optional)")),
-			),
-		),
+		newServerTool[CreateTaskParams, CreateTaskResult]("create_task", "Create new main-task with auto-generated task-id",
+			toolService.CreateTaskHandler),
 	)
 }
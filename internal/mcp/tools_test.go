@@ -2,11 +2,21 @@ package mcp
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sync"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
 )
 
 func TestCreateTaskHandler(t *testing.T) {
@@ -43,6 +53,8 @@ func TestCreateTaskHandler(t *testing.T) {
 			},
 			expected: CreateTaskResult{
 				TaskID:    "T002",
+				Title:     "New test task",
+				Category:  "SPEC",
 				FilePath:  filepath.Join(tempDir, ".todo", "context", "T002.md"),
 				CreatedAt: "", // We'll check this is not empty
 			},
@@ -57,6 +69,8 @@ func TestCreateTaskHandler(t *testing.T) {
 			},
 			expected: CreateTaskResult{
 				TaskID:    "T003",
+				Title:     "Complex task",
+				Category:  "Frontend",
 				FilePath:  filepath.Join(tempDir, ".todo", "context", "T003.md"),
 				CreatedAt: "", // We'll check this is not empty
 			},
@@ -96,6 +110,20 @@ func TestCreateTaskHandler(t *testing.T) {
 				t.Error("Expected non-empty text content")
 			}
 
+			// The structured content should match the create_task output schema
+			created, ok := result.StructuredContent.(CreateTaskResult)
+			if !ok {
+				t.Fatalf("StructuredContent = %T, want CreateTaskResult", result.StructuredContent)
+			}
+			if created.CreatedAt == "" {
+				t.Error("Expected non-empty created_at")
+			}
+			tt.expected.CreatedAt = created.CreatedAt
+			tt.expected.Project = filepath.Base(tempDir)
+			if diff := cmp.Diff(tt.expected, created); diff != "" {
+				t.Errorf("CreateTaskHandler() result mismatch (-want +got):\n%s", diff)
+			}
+
 			// Verify the task file was updated
 			updatedContent, err := os.ReadFile(taskFilePath)
 			if err != nil {
@@ -117,52 +145,325 @@ func TestCreateTaskHandler(t *testing.T) {
 	}
 }
 
-func TestGenerateNextTaskID(t *testing.T) {
+// Helper function to check if string contains substring
+func contains(s, substr string) bool {
+	return len(s) >= len(substr) &&
+		(s == substr || len(s) > len(substr) &&
+			(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
+				indexOf(s, substr) >= 0))
+}
+
+func indexOf(s, substr string) int {
+	for i := 0; i <= len(s)-len(substr); i++ {
+		if s[i:i+len(substr)] == substr {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestCreateTaskHandler_Errors(t *testing.T) {
 	tests := []struct {
-		name           string
-		existingTasks  []string
-		expectedTaskID string
+		name     string
+		setup    func(t *testing.T, dir string)
+		params   CreateTaskParams
+		wantCode errcode.Code
 	}{
 		{
-			name:           "first task",
-			existingTasks:  []string{},
-			expectedTaskID: "T001",
+			name:     "missing title",
+			params:   CreateTaskParams{Category: "SPEC"},
+			wantCode: errcode.ValidationError,
 		},
 		{
-			name:           "sequential task IDs",
-			existingTasks:  []string{"T001", "T002"},
-			expectedTaskID: "T003",
+			name:     "multi-line category",
+			params:   CreateTaskParams{Title: "Task", Category: "SPEC\n## Other"},
+			wantCode: errcode.InvalidCategory,
 		},
 		{
-			name:           "with gaps",
-			existingTasks:  []string{"T001", "T003", "T005"},
-			expectedTaskID: "T006",
+			name: "task limit reached",
+			setup: func(t *testing.T, dir string) {
+				writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Last task #T999\n")
+			},
+			params:   CreateTaskParams{Title: "One too many"},
+			wantCode: errcode.TaskLimitExceeded,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := GenerateNextTaskID(tt.existingTasks)
-			if result != tt.expectedTaskID {
-				t.Errorf("GenerateNextTaskID() = %v, want %v", result, tt.expectedTaskID)
+			tempDir := t.TempDir()
+			if tt.setup != nil {
+				tt.setup(t, tempDir)
+			}
+
+			service := NewToolService(tempDir)
+			result, err := service.CreateTaskHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[CreateTaskParams]{
+				Name:      "create_task",
+				Arguments: tt.params,
+			})
+			if err != nil {
+				t.Fatalf("CreateTaskHandler() error = %v", err)
+			}
+			if !result.IsError {
+				t.Fatal("Expected IsError to be true")
+			}
+
+			response, ok := result.StructuredContent.(ErrorResponse)
+			if !ok {
+				t.Fatalf("StructuredContent = %T, want ErrorResponse", result.StructuredContent)
+			}
+			if response.Error.Code != tt.wantCode {
+				t.Errorf("Code = %v, want %v", response.Error.Code, tt.wantCode)
 			}
 		})
 	}
 }
 
-// Helper function to check if string contains substring
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) &&
-		(s == substr || len(s) > len(substr) &&
-			(s[:len(substr)] == substr || s[len(s)-len(substr):] == substr ||
-				indexOf(s, substr) >= 0))
+func TestCreateTaskTool_StructuredError(t *testing.T) {
+	ctx := context.Background()
+	session := connectTestClient(t, t.TempDir())
+
+	result, err := session.CallTool(ctx, &mcpsdk.CallToolParams{
+		Name:      "create_task",
+		Arguments: map[string]any{"title": "Task", "category": "A\nB"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected IsError to be true")
+	}
+
+	structured, ok := result.StructuredContent.(map[string]any)
+	if !ok {
+		t.Fatalf("StructuredContent = %T, want map", result.StructuredContent)
+	}
+	body, _ := structured["error"].(map[string]any)
+	if body["code"] != string(errcode.InvalidCategory) {
+		t.Errorf("code = %v, want %v", body["code"], errcode.InvalidCategory)
+	}
 }
 
-func indexOf(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
+func TestCreateTaskTool_StructuredOutput(t *testing.T) {
+	ctx := context.Background()
+	session := connectTestClient(t, t.TempDir())
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	var outputSchema *jsonschema.Schema
+	for _, tool := range tools.Tools {
+		if tool.Name == "create_task" {
+			outputSchema = tool.OutputSchema
 		}
 	}
-	return -1
+	if outputSchema == nil {
+		t.Fatal("create_task should declare an output schema")
+	}
+
+	result, err := session.CallTool(ctx, &mcpsdk.CallToolParams{
+		Name:      "create_task",
+		Arguments: map[string]any{"title": "Structured task"},
+	})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("CallTool() returned error result: %v", result.Content)
+	}
+	if err := validateInput(outputSchema, result.StructuredContent); err != nil {
+		t.Errorf("structured content does not match output schema: %v", err)
+	}
+	structured, _ := result.StructuredContent.(map[string]any)
+	if structured["task_id"] != "T001" {
+		t.Errorf("task_id = %v, want T001", structured["task_id"])
+	}
+}
+
+func TestCreateTaskHandler_Concurrent(t *testing.T) {
+	tempDir := t.TempDir()
+	service := NewToolService(tempDir)
+
+	const clients = 10
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = service.CreateTaskHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[CreateTaskParams]{
+				Name:      "create_task",
+				Arguments: CreateTaskParams{Title: fmt.Sprintf("Task %d", i)},
+			})
+		}()
+	}
+	wg.Wait()
+
+	tasks, err := service.shared.projects[0].storage.ReadTasksFile()
+	if err != nil {
+		t.Fatalf("ReadTasksFile() error = %v", err)
+	}
+	if len(tasks) != clients {
+		t.Errorf("got %d tasks, want %d", len(tasks), clients)
+	}
+}
+
+// writeTaskFile writes content to .todo/task.md under dir
+func writeTaskFile(t *testing.T, dir, content string) {
+	t.Helper()
+	todoDir := filepath.Join(dir, ".todo")
+	if err := os.MkdirAll(todoDir, 0755); err != nil {
+		t.Fatalf("Failed to create .todo dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(todoDir, "task.md"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write task file: %v", err)
+	}
+}
+
+// connectTestClient starts a server with all tools over in-memory transports
+// and returns a connected client session
+func connectTestClient(t *testing.T, basePath string) *mcpsdk.ClientSession {
+	t.Helper()
+	return connectToolService(t, NewToolService(basePath))
+}
+
+// connectToolService starts a server with all tools and resources of
+// toolService over in-memory transports and returns a connected client session
+func connectToolService(t *testing.T, toolService *ToolService) *mcpsdk.ClientSession {
+	t.Helper()
+	ctx := context.Background()
+
+	server := NewServer()
+	AddCreateTaskTool(server, toolService)
+	AddListTasksTool(server, toolService)
+	AddSearchTasksTool(server, toolService)
+	AddUpdateTaskTool(server, toolService)
+	AddGetTaskTool(server, toolService)
+	AddReorderTaskTool(server, toolService)
+	AddSubtaskTools(server, toolService)
+	AddCategoryTools(server, toolService)
+	AddArchiveTools(server, toolService)
+	AddADRTools(server, toolService)
+	AddContextTools(server, toolService)
+	AddSyncCommitsTool(server, toolService)
+	AddImportTodosTool(server, toolService)
+	AddServerInfoTool(server, toolService)
+	AddResources(server, toolService)
+
+	clientTransport, serverTransport := mcpsdk.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport)
+	if err != nil {
+		t.Fatalf("server.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcpsdk.NewClient("test-client", "0.0.1", nil)
+	clientSession, err := client.Connect(ctx, clientTransport)
+	if err != nil {
+		t.Fatalf("client.Connect() error = %v", err)
+	}
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+func TestNewToolServiceWithConfig(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, "tasks")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatalf("Failed to create data dir: %v", err)
+	}
+	tmpl := "# {{.TaskID}}: {{.Title}} ({{.Category}})\n{{.Description}}\n"
+	if err := os.WriteFile(filepath.Join(dataDir, "context.tmpl"), []byte(tmpl), 0644); err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.DataDir = "tasks"
+	cfg.Tasks = config.Tasks{IDPrefix: "PRJ", IDDigits: 4, DefaultCategory: "Inbox"}
+	cfg.Templates.Context = "context.tmpl"
+	cfg.Limits.MaxTasks = 1
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	toolService, err := NewToolServiceWithConfig(workspace.Root{Path: tempDir, Source: workspace.SourceWorkingDir}, cfg)
+	if err != nil {
+		t.Fatalf("NewToolServiceWithConfig() error = %v", err)
+	}
+
+	params := &mcpsdk.CallToolParamsFor[CreateTaskParams]{
+		Arguments: CreateTaskParams{Title: "Configured", Description: "From template"},
+	}
+	result, err := toolService.CreateTaskHandler(context.Background(), nil, params)
+	if err != nil || result.IsError {
+		t.Fatalf("CreateTaskHandler() error = %v, result = %+v", err, result)
+	}
+	created, ok := result.StructuredContent.(CreateTaskResult)
+	if !ok || created.TaskID != "PRJ0001" {
+		t.Errorf("StructuredContent = %+v, want task PRJ0001", result.StructuredContent)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dataDir, "context", "PRJ0001.md"))
+	if err != nil {
+		t.Fatalf("Failed to read context file: %v", err)
+	}
+	if diff := cmp.Diff("# PRJ0001: Configured (Inbox)\nFrom template\n", string(content)); diff != "" {
+		t.Errorf("context file mismatch (-want +got):\n%s", diff)
+	}
+
+	// The configured task limit applies
+	result, err = toolService.CreateTaskHandler(context.Background(), nil, params)
+	if err != nil {
+		t.Fatalf("CreateTaskHandler() error = %v", err)
+	}
+	response, ok := result.StructuredContent.(ErrorResponse)
+	if !ok || response.Error.Code != errcode.TaskLimitExceeded {
+		t.Errorf("StructuredContent = %+v, want %v", result.StructuredContent, errcode.TaskLimitExceeded)
+	}
+}
+
+func TestCreateTask_TimeIDs(t *testing.T) {
+	tempDir := t.TempDir()
+	dataDir := filepath.Join(tempDir, ".todo")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatalf("Failed to create data dir: %v", err)
+	}
+	existing := "# Task\n\n## Default\n- [ ] Sequential task #T001\n\n"
+	if err := os.WriteFile(filepath.Join(dataDir, "task.md"), []byte(existing), 0644); err != nil {
+		t.Fatalf("Failed to write task.md: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Tasks.IDMode = model.IDModeTime
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	toolService, err := NewToolServiceWithConfig(workspace.Root{Path: tempDir, Source: workspace.SourceWorkingDir}, cfg)
+	if err != nil {
+		t.Fatalf("NewToolServiceWithConfig() error = %v", err)
+	}
+
+	ctx := context.Background()
+	created, err := toolService.CreateTask(ctx, CreateTaskParams{Title: "Time task"})
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if !regexp.MustCompile(`^T[0-9a-z]{8}$`).MatchString(created.TaskID) {
+		t.Errorf("CreateTask() ID = %q, want a time ID", created.TaskID)
+	}
+
+	// Both kinds of IDs can be addressed
+	for _, id := range []string{"T001", created.TaskID} {
+		if _, err := toolService.UpdateTask(ctx, UpdateTaskParams{TaskID: id, Status: "done"}); err != nil {
+			t.Errorf("UpdateTask(%s) error = %v", id, err)
+		}
+	}
+	list, err := toolService.ListTasks(ctx, ListTasksParams{Status: "done"})
+	if err != nil {
+		t.Fatalf("ListTasks() error = %v", err)
+	}
+	if list.TotalCount != 2 {
+		t.Errorf("ListTasks() = %+v, want both tasks done", list.Tasks)
+	}
 }
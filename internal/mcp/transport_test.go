@@ -2,8 +2,14 @@ package mcp
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestRunServer(t *testing.T) {
@@ -49,3 +55,145 @@ func TestServerConstants(t *testing.T) {
 		t.Errorf("Expected ServerVersion to be '0.1.0', got '%s'", ServerVersion)
 	}
 }
+
+func TestServeHTTP(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport func(baseURL string) mcpsdk.Transport
+	}{
+		{
+			name: "streamable HTTP",
+			transport: func(baseURL string) mcpsdk.Transport {
+				return mcpsdk.NewStreamableClientTransport(baseURL+StreamablePath, nil)
+			},
+		},
+		{
+			name: "legacy SSE",
+			transport: func(baseURL string) mcpsdk.Transport {
+				return mcpsdk.NewSSEClientTransport(baseURL+SSEPath, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			server := NewServer()
+			AddCreateTaskTool(server, NewToolService(tempDir))
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("net.Listen() error = %v", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error, 1)
+			go func() { done <- serveHTTP(ctx, listener, NewHTTPHandler(server, nil), nil) }()
+
+			client := mcpsdk.NewClient("test-client", "0.0.1", nil)
+			session, err := client.Connect(ctx, tt.transport("http://"+listener.Addr().String()))
+			if err != nil {
+				t.Fatalf("client.Connect() error = %v", err)
+			}
+
+			result, err := session.CallTool(ctx, &mcpsdk.CallToolParams{
+				Name:      "create_task",
+				Arguments: map[string]any{"title": "Shared task"},
+			})
+			if err != nil {
+				t.Fatalf("CallTool() error = %v", err)
+			}
+			if result.IsError {
+				t.Fatalf("CallTool() returned error result: %v", result.Content)
+			}
+			if _, err := os.Stat(filepath.Join(tempDir, ".todo", "task.md")); err != nil {
+				t.Errorf("task.md should be written: %v", err)
+			}
+
+			// Shutting down must not wait for the open session to end
+			cancel()
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("serveHTTP() error = %v", err)
+				}
+			case <-time.After(ShutdownTimeout):
+				t.Fatal("serveHTTP() did not shut down")
+			}
+			_ = session.Close()
+		})
+	}
+}
+
+func TestServeHTTP_DrainsBeforeEndingStreams(t *testing.T) {
+	streaming, streamEnded := make(chan struct{}), make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		close(streaming)
+		<-r.Context().Done()
+		close(streamEnded)
+	})
+	draining, release := make(chan struct{}), make(chan struct{})
+	drain := func() {
+		close(draining)
+		<-release
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serveHTTP(ctx, listener, handler, drain) }()
+
+	resp, err := http.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("http.Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+	<-streaming
+
+	cancel()
+	<-draining
+	// A write still in flight keeps the streams open
+	select {
+	case <-streamEnded:
+		t.Fatal("stream ended before in-flight writes finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("serveHTTP() error = %v", err)
+		}
+	case <-time.After(ShutdownTimeout):
+		t.Fatal("serveHTTP() did not shut down")
+	}
+	select {
+	case <-streamEnded:
+	default:
+		t.Error("stream still open after shutdown")
+	}
+}
+
+func TestToolServiceClose(t *testing.T) {
+	service := NewToolService(t.TempDir())
+	if err := service.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	result, err := service.CreateTaskHandler(context.Background(), nil, &mcpsdk.CallToolParamsFor[CreateTaskParams]{
+		Name:      "create_task",
+		Arguments: CreateTaskParams{Title: "Too late"},
+	})
+	if err != nil {
+		t.Fatalf("CreateTaskHandler() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("CreateTaskHandler() should fail after Close()")
+	}
+}
@@ -0,0 +1,465 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// Task fields reported in update_task results
+const (
+	FieldTitle         = "title"
+	FieldStatus        = "status"
+	FieldCategory      = "category"
+	FieldSubtasks      = "subtasks"
+	FieldDue           = "due"
+	FieldPriorityLabel = "priority_label"
+	FieldAssignee      = "assignee"
+	FieldTags          = "tags"
+	FieldReason        = "reason"
+	FieldNotes         = "notes"
+)
+
+// UpdateTaskParams defines the input parameters for update_task tool
+type UpdateTaskParams struct {
+	TaskID        string         `json:"task_id" description:"ID of the task to update" schema:"pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Title         string         `json:"title,omitempty" description:"New title" schema:"maxLength=100"`
+	Status        string         `json:"status,omitempty" description:"New status, one of the project's workflow statuses (by default todo, in_progress, done, blocked and cancelled)"`
+	Category      string         `json:"category,omitempty" description:"New category; the task moves to the end of it" schema:"maxLength=50"`
+	Subtasks      []SubtaskInput `json:"subtasks,omitempty" description:"Subtasks, replacing the current ones" schema:"maxItems=20"`
+	Due           string         `json:"due,omitempty" description:"New due date as YYYY-MM-DD" schema:"format=date"`
+	PriorityLabel string         `json:"priority_label,omitempty" description:"New priority label" schema:"enum=high|medium|low"`
+	Assignee      string         `json:"assignee,omitempty" description:"New assignee" schema:"maxLength=50"`
+	Tags          []string       `json:"tags,omitempty" description:"Tags, replacing the current ones" schema:"maxItems=20,items.maxLength=50"`
+	Reason        string         `json:"reason,omitempty" description:"Why the task is blocked or cancelled; required when moving it to either status" schema:"maxLength=500"`
+	Notes         string         `json:"notes,omitempty" description:"New notes, kept as lines indented under the task in task.md; blank lines are dropped" schema:"maxLength=2000"`
+	Clear         []string       `json:"clear,omitempty" description:"Metadata fields or notes to remove" schema:"items.enum=due|priority_label|assignee|tags|notes"`
+	Project       string         `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// SubtaskInput describes one subtask given to update_task
+type SubtaskInput struct {
+	Title  string `json:"title" description:"Subtask title" schema:"minLength=1,maxLength=100"`
+	Status string `json:"status,omitempty" description:"Subtask status (default the workflow's first status, todo)"`
+	Notes  string `json:"notes,omitempty" description:"Subtask notes (default the notes of the current subtask with the same title)" schema:"maxLength=2000"`
+}
+
+// UpdateTaskResult defines the response from update_task tool
+type UpdateTaskResult struct {
+	TaskID          string           `json:"task_id" description:"ID of the updated task"`
+	UpdatedFields   []string         `json:"updated_fields" description:"Names of the fields that changed"`
+	UpdatedAt       string           `json:"updated_at" description:"Update time" schema:"format=date-time"`
+	Project         string           `json:"project" description:"Project the task belongs to"`
+	AutoTransitions []AutoTransition `json:"auto_transitions,omitempty" description:"Status changes of the task and its subtasks made by the workflow's rollup rules"`
+}
+
+// GetTaskParams defines the input parameters for get_task tool
+type GetTaskParams struct {
+	TaskID  string `json:"task_id" description:"Task ID" schema:"pattern=^[A-Za-z]+[0-9a-z]+$"`
+	Project string `json:"project,omitempty" description:"Project of the task (optional; required when several projects are open)"`
+}
+
+// TaskDetail defines the response from get_task tool
+type TaskDetail struct {
+	TaskID        string        `json:"task_id" description:"Task ID"`
+	Title         string        `json:"title" description:"Task title"`
+	Status        string        `json:"status" description:"Task status"`
+	Category      string        `json:"category" description:"Task category"`
+	StatusReason  string        `json:"status_reason,omitempty" description:"Why a blocked or cancelled task is in its status"`
+	Priority      int           `json:"priority" description:"Position within the category, 1 is the highest priority"`
+	Due           string        `json:"due,omitempty" description:"Due date as YYYY-MM-DD" schema:"format=date"`
+	PriorityLabel string        `json:"priority_label,omitempty" description:"Priority label" schema:"enum=high|medium|low"`
+	Assignee      string        `json:"assignee,omitempty" description:"Person the task is assigned to"`
+	Tags          []string      `json:"tags,omitempty" description:"Tags"`
+	CreatedAt     string        `json:"created_at,omitempty" description:"Creation time, if recorded" schema:"format=date-time"`
+	StartedAt     string        `json:"started_at,omitempty" description:"Time of the first move to in_progress, if recorded" schema:"format=date-time"`
+	CompletedAt   string        `json:"completed_at,omitempty" description:"Completion time of a done task, if recorded" schema:"format=date-time"`
+	Notes         string        `json:"notes,omitempty" description:"Notes written under the task in task.md, one per line"`
+	Subtasks      []SubtaskInfo `json:"subtasks" description:"Subtasks in order, each followed by those nested under it"`
+	Context       string        `json:"context" description:"Content of the context file, empty if there is none"`
+	ContextFile   string        `json:"context_file" description:"Path of the context file"`
+	Project       string        `json:"project" description:"Project the task belongs to"`
+}
+
+// SubtaskInfo describes one subtask in get_task results
+type SubtaskInfo struct {
+	Path   string `json:"path" description:"Path of the subtask, e.g. T003.2.1 for the first subtask of the second subtask of T003"`
+	Title  string `json:"title" description:"Subtask title"`
+	Status string `json:"status" description:"Subtask status"`
+	Notes  string `json:"notes,omitempty" description:"Notes written under the subtask in task.md, one per line"`
+}
+
+// UpdateTaskHandler handles the update_task MCP tool
+func (ts *ToolService) UpdateTaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[UpdateTaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.UpdateTask, ts.formatUpdatedTask)(ctx, session, params)
+}
+
+// UpdateTask partially updates a main task. Omitted fields are kept; given
+// subtasks replace the current top-level ones, keeping the subtasks nested
+// under those with the same title, and the rollup rules then apply.
+func (ts *ToolService) UpdateTask(ctx context.Context, args UpdateTaskParams) (UpdateTaskResult, error) {
+	if err := validateParams(args); err != nil {
+		return UpdateTaskResult{}, err
+	}
+	if args.Title == "" && args.Status == "" && args.Category == "" && args.Subtasks == nil &&
+		args.Due == "" && args.PriorityLabel == "" && args.Assignee == "" && args.Tags == nil && len(args.Clear) == 0 && args.Reason == "" && args.Notes == "" {
+		return UpdateTaskResult{}, errcode.New(errcode.ValidationError,
+			"nothing to update; give a title, status, category, subtasks, metadata or notes").WithDetails("task_id", args.TaskID)
+	}
+	if err := checkCategory(args.Category); err != nil {
+		return UpdateTaskResult{}, err
+	}
+	if len(args.Subtasks) > ts.limits.MaxSubtasks {
+		return UpdateTaskResult{}, errcode.New(errcode.ValidationError, "at most %d subtasks are allowed", ts.limits.MaxSubtasks).
+			WithDetails("subtasks", len(args.Subtasks))
+	}
+
+	p, unlock, err := ts.lockProject(ctx, args.Project)
+	if err != nil {
+		return UpdateTaskResult{}, err
+	}
+	defer unlock()
+	workflow := p.storage.Workflow()
+	if args.Status != "" {
+		if err := workflow.CheckStatus(args.Status); err != nil {
+			return UpdateTaskResult{}, err
+		}
+	}
+	tasks, err := readTasks(p)
+	if err != nil {
+		return UpdateTaskResult{}, err
+	}
+	i, err := findTask(tasks, args.TaskID)
+	if err != nil {
+		return UpdateTaskResult{}, err
+	}
+	before := taskStatuses(tasks)
+
+	updated := tasks[i]
+	var fields []string
+	if args.Title != "" && args.Title != updated.Task.Title {
+		updated.Task.Title = args.Title
+		fields = append(fields, FieldTitle)
+	}
+	if args.Status != "" && args.Status != updated.Task.Status {
+		if err := workflow.CheckTransition(updated.Task.Status, args.Status); err != nil {
+			return UpdateTaskResult{}, err
+		}
+		updated.Task.Status = args.Status
+		fields = append(fields, FieldStatus)
+	}
+	if args.Category != "" && args.Category != updated.Task.Category {
+		updated.Task.Category = args.Category
+		fields = append(fields, FieldCategory)
+	}
+	if args.Subtasks != nil {
+		subtasks := make([]model.SubTask, 0, len(args.Subtasks))
+		for _, s := range args.Subtasks {
+			status := s.Status
+			if status == "" {
+				status = workflow.Initial()
+			}
+			if err := workflow.CheckStatus(status); err != nil {
+				return UpdateTaskResult{}, err
+			}
+			if err := model.ValidateNotes(s.Notes); err != nil {
+				return UpdateTaskResult{}, err
+			}
+			subtask := model.NewSubTask(s.Title, status)
+			subtask.Notes = model.NormalizeNotes(s.Notes)
+			// Subtasks nested under a subtask that is kept stay with it, and so do its notes unless given
+			if at := slices.IndexFunc(updated.SubTasks, func(old model.SubTask) bool { return old.Title == s.Title }); at >= 0 {
+				subtask.SubTasks = updated.SubTasks[at].SubTasks
+				if subtask.Notes == "" {
+					subtask.Notes = updated.SubTasks[at].Notes
+				}
+			}
+			subtasks = append(subtasks, subtask)
+		}
+		if !slices.EqualFunc(subtasks, updated.SubTasks, model.SubTask.Equal) {
+			updated.SubTasks = subtasks
+			fields = append(fields, FieldSubtasks)
+		}
+	}
+	var transitions []AutoTransition
+	if slices.Contains(fields, FieldSubtasks) {
+		// A status given along with the subtasks wins over the rollup rules
+		transitions = rollupTask(workflow, &updated, args.Status == "")
+		if updated.Task.Status != tasks[i].Task.Status && !slices.Contains(fields, FieldStatus) {
+			fields = append(fields, FieldStatus)
+		}
+	}
+	fields = append(fields, updateMetadata(&updated.Task, args)...)
+	if notes := updateNotes(updated.Task.Notes, args); notes != updated.Task.Notes {
+		updated.Task.Notes = notes
+		fields = append(fields, FieldNotes)
+	}
+	if err := updated.Task.Validate(workflow); err != nil {
+		return UpdateTaskResult{}, err
+	}
+	if err := checkReason(workflow, args.TaskID, updated.Task.Status, slices.Contains(fields, FieldStatus), args.Reason); err != nil {
+		return UpdateTaskResult{}, err
+	}
+	if args.Reason != "" {
+		fields = append(fields, FieldReason)
+	}
+
+	now := time.Now()
+	result := UpdateTaskResult{
+		TaskID:        args.TaskID,
+		UpdatedFields: []string{},
+		UpdatedAt:     now.Format(time.RFC3339),
+		Project:       p.Name,
+	}
+	if len(fields) == 0 {
+		return result, nil
+	}
+	result.UpdatedFields = fields
+	result.AutoTransitions = transitions
+
+	if slices.Contains(fields, FieldCategory) {
+		// A task moving to another category goes to the end of it
+		tasks = append(slices.Delete(tasks, i, i+1), updated)
+	} else {
+		tasks[i] = updated
+	}
+	if err := p.storage.WriteTasksFile(tasks); err != nil {
+		return UpdateTaskResult{}, err
+	}
+	if slices.Contains(fields, FieldStatus) {
+		if err := recordTimestamps(p, before, tasks, now); err != nil {
+			return UpdateTaskResult{}, err
+		}
+	}
+	if args.Reason != "" {
+		if err := appendStatusReason(p.storage, args.TaskID, updated.Task.Status, args.Reason, now); err != nil {
+			return UpdateTaskResult{}, err
+		}
+	}
+	ts.commit(ctx, p, "update %s '%s': %s", args.TaskID, updated.Task.Title, strings.Join(fields, ", "))
+	return result, nil
+}
+
+// GetTaskHandler handles the get_task MCP tool
+func (ts *ToolService) GetTaskHandler(
+	ctx context.Context,
+	session *mcpsdk.ServerSession,
+	params *mcpsdk.CallToolParamsFor[GetTaskParams],
+) (*mcpsdk.CallToolResultFor[any], error) {
+	return toolHandler(ts.GetTask, ts.formatTaskDetail)(ctx, session, params)
+}
+
+// GetTask returns a main task with its subtasks and context
+func (ts *ToolService) GetTask(ctx context.Context, args GetTaskParams) (TaskDetail, error) {
+	if err := validateParams(args); err != nil {
+		return TaskDetail{}, err
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	p, err := ts.project(ctx, args.Project)
+	if err != nil {
+		return TaskDetail{}, err
+	}
+	tasks, err := readTasks(p)
+	if err != nil {
+		return TaskDetail{}, err
+	}
+	i, err := findTask(tasks, args.TaskID)
+	if err != nil {
+		return TaskDetail{}, err
+	}
+
+	times, err := p.storage.ReadTimestamps()
+	if err != nil {
+		return TaskDetail{}, err
+	}
+	summary := summarizeTasks(p, tasks, times)[i]
+	detail := TaskDetail{
+		TaskID:        summary.TaskID,
+		Title:         summary.Title,
+		Status:        summary.Status,
+		Category:      summary.Category,
+		Priority:      summary.Priority,
+		Due:           summary.Due,
+		PriorityLabel: summary.PriorityLabel,
+		Assignee:      summary.Assignee,
+		Tags:          summary.Tags,
+		CreatedAt:     summary.CreatedAt,
+		StartedAt:     summary.StartedAt,
+		CompletedAt:   summary.CompletedAt,
+		Notes:         tasks[i].Task.Notes,
+		Subtasks:      []SubtaskInfo{},
+		ContextFile:   p.storage.ContextFilePath(args.TaskID),
+		Project:       p.Name,
+	}
+	model.WalkSubtasks(tasks[i].SubTasks, func(indexes []int, s model.SubTask) {
+		path := model.SubtaskPath{TaskID: args.TaskID, Indexes: indexes}
+		detail.Subtasks = append(detail.Subtasks, SubtaskInfo{Path: path.String(), Title: s.Title, Status: s.Status, Notes: s.Notes})
+	})
+	detail.StatusReason, err = statusReason(p.storage, tasks[i].Task)
+	if err != nil {
+		return TaskDetail{}, err
+	}
+	context, err := p.storage.ReadContextFile(args.TaskID)
+	if err != nil && !errcode.HasCode(err, errcode.FileNotFound) {
+		return TaskDetail{}, err
+	}
+	detail.Context = context.Content
+	return detail, nil
+}
+
+// updateMetadata applies the metadata given to update_task, clearing
+// fields first, and returns the names of the fields that changed
+func updateMetadata(task *model.Task, args UpdateTaskParams) []string {
+	updated := *task
+	for _, field := range args.Clear {
+		switch field {
+		case FieldDue:
+			updated.Due = ""
+		case FieldPriorityLabel:
+			updated.Priority = ""
+		case FieldAssignee:
+			updated.Assignee = ""
+		case FieldTags:
+			updated.Tags = nil
+		}
+	}
+	if args.Due != "" {
+		updated.Due = args.Due
+	}
+	if args.PriorityLabel != "" {
+		updated.Priority = args.PriorityLabel
+	}
+	if args.Assignee != "" {
+		updated.Assignee = args.Assignee
+	}
+	if args.Tags != nil {
+		updated.Tags = args.Tags
+	}
+
+	var fields []string
+	if updated.Due != task.Due {
+		fields = append(fields, FieldDue)
+	}
+	if updated.Priority != task.Priority {
+		fields = append(fields, FieldPriorityLabel)
+	}
+	if updated.Assignee != task.Assignee {
+		fields = append(fields, FieldAssignee)
+	}
+	if !slices.Equal(updated.Tags, task.Tags) {
+		fields = append(fields, FieldTags)
+	}
+	*task = updated
+	return fields
+}
+
+// updateNotes returns the notes of a task after clearing or replacing them
+// as given to update_task
+func updateNotes(notes string, args UpdateTaskParams) string {
+	if slices.Contains(args.Clear, FieldNotes) {
+		notes = ""
+	}
+	if args.Notes != "" {
+		notes = model.NormalizeNotes(args.Notes)
+	}
+	return notes
+}
+
+// findTask returns the index of the task with the given ID
+func findTask(tasks []parser.ParsedTask, taskID string) (int, error) {
+	i := slices.IndexFunc(tasks, func(t parser.ParsedTask) bool { return t.Task.ID == taskID })
+	if i < 0 {
+		return -1, errcode.New(errcode.TaskNotFound, "task %s not found", taskID).WithDetails("task_id", taskID)
+	}
+	return i, nil
+}
+
+// checkCategory rejects category names that cannot be written as a heading
+func checkCategory(category string) error {
+	if strings.ContainsAny(category, "\r\n") {
+		return errcode.New(errcode.InvalidCategory, "category must be a single line").WithDetails("category", category)
+	}
+	return nil
+}
+
+// modTime returns the modification time of a file in RFC 3339 format, or
+// an empty string if it cannot be read
+func modTime(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return info.ModTime().Format(time.RFC3339)
+}
+
+// formatUpdatedTask renders an update_task result as text
+func (ts *ToolService) formatUpdatedTask(ctx context.Context, result UpdateTaskResult) string {
+	id := ts.qualifiedID(ctx, result.Project, result.TaskID)
+	if len(result.UpdatedFields) == 0 {
+		return fmt.Sprintf("Task %s is unchanged", id)
+	}
+	return fmt.Sprintf("Task %s updated: %s", id, strings.Join(result.UpdatedFields, ", ")) +
+		ts.formatAutoTransitions(ctx, result.Project, result.AutoTransitions)
+}
+
+// formatNotes renders notes as text lines with the given indentation
+func formatNotes(notes, indent string) string {
+	var sb strings.Builder
+	for _, line := range model.NoteLines(notes) {
+		sb.WriteString("\n" + indent + line)
+	}
+	return sb.String()
+}
+
+// formatTaskDetail renders a get_task result as text
+func (ts *ToolService) formatTaskDetail(ctx context.Context, detail TaskDetail) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s [%s] %s (%s, priority %d%s)", ts.qualifiedID(ctx, detail.Project, detail.TaskID),
+		formatStatus(detail.Status, detail.StatusReason), detail.Title, detail.Category, detail.Priority,
+		formatTimestamps(detail.CreatedAt, detail.StartedAt, detail.CompletedAt))
+	metadata := model.Task{Due: detail.Due, Priority: detail.PriorityLabel, Assignee: detail.Assignee, Tags: detail.Tags}.Metadata()
+	if metadata != "" {
+		sb.WriteString(" " + metadata)
+	}
+	sb.WriteString(formatNotes(detail.Notes, "  "))
+	for _, s := range detail.Subtasks {
+		fmt.Fprintf(&sb, "\n%s- [%s] %s %s", subtaskIndent(s.Path), s.Status, s.Path, s.Title)
+		sb.WriteString(formatNotes(s.Notes, subtaskIndent(s.Path)+"  "))
+	}
+	if detail.Context != "" {
+		sb.WriteString("\n\n" + strings.TrimRight(detail.Context, "\n"))
+	}
+	return sb.String()
+}
+
+// AddUpdateTaskTool adds the update_task tool to the MCP server
+func AddUpdateTaskTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[UpdateTaskParams, UpdateTaskResult]("update_task",
+			"Update the title, status, category, subtasks or metadata of a main-task; blocking or cancelling it needs a reason", toolService.UpdateTaskHandler),
+	)
+}
+
+// AddGetTaskTool adds the get_task tool to the MCP server
+func AddGetTaskTool(server *mcpsdk.Server, toolService *ToolService) {
+	server.AddTools(
+		newServerTool[GetTaskParams, TaskDetail]("get_task",
+			"Get a main-task with its subtasks and context", toolService.GetTaskHandler),
+	)
+}
@@ -0,0 +1,359 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/config"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/workspace"
+)
+
+const updateTaskFile = `# Task
+
+## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+- [ ] Set up database #T002
+
+## Frontend
+- [ ] Build login form #T003
+`
+
+func TestUpdateTask(t *testing.T) {
+	tests := []struct {
+		name       string
+		params     UpdateTaskParams
+		wantFields []string
+		wantFile   string
+	}{
+		{
+			name:       "title and status",
+			params:     UpdateTaskParams{TaskID: "T002", Title: "Set up Postgres", Status: "in_progress"},
+			wantFields: []string{FieldTitle, FieldStatus},
+			wantFile: `# Task
+
+## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+- [-] Set up Postgres #T002
+
+## Frontend
+- [ ] Build login form #T003
+
+`,
+		},
+		{
+			name:       "category moves the task to the end",
+			params:     UpdateTaskParams{TaskID: "T001", Category: "Frontend"},
+			wantFields: []string{FieldCategory},
+			wantFile: `# Task
+
+## Backend
+- [ ] Set up database #T002
+
+## Frontend
+- [ ] Build login form #T003
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+
+`,
+		},
+		{
+			name: "subtasks are replaced",
+			params: UpdateTaskParams{TaskID: "T001", Subtasks: []SubtaskInput{
+				{Title: "Hash passwords", Status: "done"},
+				{Title: "Rate limit"},
+			}},
+			wantFields: []string{FieldSubtasks},
+			wantFile: `# Task
+
+## Backend
+- [ ] Add login endpoint #T001
+  - [x] Hash passwords
+  - [ ] Rate limit
+- [ ] Set up database #T002
+
+## Frontend
+- [ ] Build login form #T003
+
+`,
+		},
+		{
+			name: "metadata",
+			params: UpdateTaskParams{
+				TaskID: "T002", Due: "2026-11-01", PriorityLabel: "high", Assignee: "alice", Tags: []string{"infra", "db"},
+			},
+			wantFields: []string{FieldDue, FieldPriorityLabel, FieldAssignee, FieldTags},
+			wantFile: `# Task
+
+## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+- [ ] Set up database @alice +infra +db due:2026-11-01 !high #T002
+
+## Frontend
+- [ ] Build login form #T003
+
+`,
+		},
+		{
+			name:       "unchanged",
+			params:     UpdateTaskParams{TaskID: "T003", Title: "Build login form"},
+			wantFields: []string{},
+			wantFile:   updateTaskFile,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTaskFile(t, dir, updateTaskFile)
+			toolService := NewToolService(dir)
+
+			result, err := toolService.UpdateTask(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("UpdateTask() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantFields, result.UpdatedFields); diff != "" {
+				t.Errorf("UpdateTask() updated fields mismatch (-want +got):\n%s", diff)
+			}
+
+			content, err := os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.wantFile, string(content)); diff != "" {
+				t.Errorf("task.md mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateTask_Rollup(t *testing.T) {
+	tests := []struct {
+		name            string
+		params          UpdateTaskParams
+		wantFields      []string
+		wantTransitions []AutoTransition
+	}{
+		{
+			name:            "all subtasks done",
+			params:          UpdateTaskParams{TaskID: "T001", Subtasks: []SubtaskInput{{Title: "Hash passwords", Status: "done"}}},
+			wantFields:      []string{FieldSubtasks, FieldStatus},
+			wantTransitions: []AutoTransition{{Path: "T001", From: "todo", To: "done"}},
+		},
+		{
+			name: "given status wins",
+			params: UpdateTaskParams{TaskID: "T001", Status: "in_progress", Subtasks: []SubtaskInput{
+				{Title: "Hash passwords", Status: "done"},
+			}},
+			wantFields: []string{FieldStatus, FieldSubtasks},
+		},
+		{
+			name:       "no subtask change",
+			params:     UpdateTaskParams{TaskID: "T001", Title: "Add sign-in endpoint"},
+			wantFields: []string{FieldTitle},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTaskFile(t, dir, updateTaskFile)
+			cfg := config.Default()
+			cfg.Workflow.Rollup = []string{model.RollupStart, model.RollupComplete}
+			toolService, err := NewToolServiceWithConfig(workspace.Root{Path: dir, Source: workspace.SourceWorkingDir}, cfg)
+			if err != nil {
+				t.Fatalf("NewToolServiceWithConfig() error = %v", err)
+			}
+
+			result, err := toolService.UpdateTask(context.Background(), tt.params)
+			if err != nil {
+				t.Fatalf("UpdateTask() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantFields, result.UpdatedFields); diff != "" {
+				t.Errorf("UpdateTask() updated fields mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantTransitions, result.AutoTransitions); diff != "" {
+				t.Errorf("UpdateTask() auto transitions mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestUpdateTask_Errors(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, updateTaskFile)
+	toolService := NewToolService(dir)
+
+	tests := []struct {
+		name     string
+		params   UpdateTaskParams
+		wantCode errcode.Code
+	}{
+		{"unknown task", UpdateTaskParams{TaskID: "T999", Status: "done"}, errcode.TaskNotFound},
+		{"nothing to update", UpdateTaskParams{TaskID: "T001"}, errcode.ValidationError},
+		{"invalid status", UpdateTaskParams{TaskID: "T001", Status: "waiting"}, errcode.InvalidStatus},
+		{"invalid subtask status", UpdateTaskParams{TaskID: "T001", Subtasks: []SubtaskInput{{Title: "A", Status: "waiting"}}}, errcode.InvalidStatus},
+		{"blocked without a reason", UpdateTaskParams{TaskID: "T001", Status: "blocked"}, errcode.ValidationError},
+		{"cancelled without a reason", UpdateTaskParams{TaskID: "T001", Status: "cancelled"}, errcode.ValidationError},
+		{"reason for another status", UpdateTaskParams{TaskID: "T001", Status: "done", Reason: "shipped"}, errcode.ValidationError},
+		{"invalid due date", UpdateTaskParams{TaskID: "T001", Due: "2026-13-01"}, errcode.ValidationError},
+		{"assignee with spaces", UpdateTaskParams{TaskID: "T001", Assignee: "alice b"}, errcode.ValidationError},
+		{"metadata in title", UpdateTaskParams{TaskID: "T001", Title: "Ask @bob"}, errcode.ValidationError},
+		{"multi-line category", UpdateTaskParams{TaskID: "T001", Category: "A\nB"}, errcode.InvalidCategory},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := toolService.UpdateTask(context.Background(), tt.params)
+			if code := errcode.CodeOf(err); code != tt.wantCode {
+				t.Errorf("UpdateTask() error = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestGetTask(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, updateTaskFile)
+	contextPath := filepath.Join(dir, ".todo", "context", "T002.md")
+	if err := os.MkdirAll(filepath.Dir(contextPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(contextPath, []byte("# Context for T002\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	toolService := NewToolService(dir)
+
+	got, err := toolService.GetTask(context.Background(), GetTaskParams{TaskID: "T002"})
+	if err != nil {
+		t.Fatalf("GetTask() error = %v", err)
+	}
+	want := TaskDetail{
+		TaskID:      "T002",
+		Title:       "Set up database",
+		Status:      "todo",
+		Category:    "Backend",
+		Priority:    2,
+		Subtasks:    []SubtaskInfo{},
+		Context:     "# Context for T002\n",
+		ContextFile: contextPath,
+		Project:     filepath.Base(dir),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("GetTask() mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = toolService.GetTask(context.Background(), GetTaskParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatalf("GetTask() error = %v", err)
+	}
+	wantSubtasks := []SubtaskInfo{{Path: "T001.1", Title: "Hash passwords", Status: "todo"}}
+	if diff := cmp.Diff(wantSubtasks, got.Subtasks); diff != "" || got.Context != "" {
+		t.Errorf("GetTask() subtasks mismatch (-want +got):\n%s, context = %q", diff, got.Context)
+	}
+
+	if _, err := toolService.GetTask(context.Background(), GetTaskParams{TaskID: "T999"}); !errcode.HasCode(err, errcode.TaskNotFound) {
+		t.Errorf("GetTask() error = %v, want TASK_NOT_FOUND", err)
+	}
+}
+
+func TestUpdateTask_ClearMetadata(t *testing.T) {
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Deploy @alice +ops due:2026-11-01 !low #T001\n")
+	toolService := NewToolService(dir)
+
+	result, err := toolService.UpdateTask(context.Background(), UpdateTaskParams{
+		TaskID: "T001", Assignee: "bob", Clear: []string{FieldDue, FieldTags, FieldAssignee},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{FieldDue, FieldAssignee, FieldTags}, result.UpdatedFields); diff != "" {
+		t.Errorf("UpdateTask() updated fields mismatch (-want +got):\n%s", diff)
+	}
+
+	detail, err := toolService.GetTask(context.Background(), GetTaskParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if detail.Title != "Deploy" || detail.Assignee != "bob" || detail.Due != "" || detail.Tags != nil || detail.PriorityLabel != "low" {
+		t.Errorf("GetTask() = %+v", detail)
+	}
+}
+
+func TestUpdateTask_TitleWithTaskID(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Deploy #T001\n- [ ] Review #T002\n")
+	toolService := NewToolService(dir)
+
+	if _, err := toolService.UpdateTask(ctx, UpdateTaskParams{TaskID: "T002", Title: "Follow up on #T001"}); err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+
+	// A fresh service reads the file back
+	for id, want := range map[string]string{"T001": "Deploy", "T002": "Follow up on #T001"} {
+		detail, err := NewToolService(dir).GetTask(ctx, GetTaskParams{TaskID: id})
+		if err != nil {
+			t.Fatalf("GetTask(%s) error = %v", id, err)
+		}
+		if detail.Title != want {
+			t.Errorf("GetTask(%s) title = %q, want %q", id, detail.Title, want)
+		}
+	}
+}
+
+func TestUpdateTask_Notes(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTaskFile(t, dir, "# Task\n\n## Default\n- [ ] Deploy #T001\n  Old note\n  - [ ] Build\n    Use buildx\n")
+	toolService := NewToolService(dir)
+
+	result, err := toolService.UpdateTask(ctx, UpdateTaskParams{
+		TaskID: "T001", Notes: "Staging first\n\n  then production ", Subtasks: []SubtaskInput{{Title: "Build"}, {Title: "Tag", Notes: "v1.0"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateTask() error = %v", err)
+	}
+	if diff := cmp.Diff([]string{FieldSubtasks, FieldNotes}, result.UpdatedFields); diff != "" {
+		t.Errorf("UpdateTask() updated fields mismatch (-want +got):\n%s", diff)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, ".todo", "task.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Task\n\n## Default\n- [ ] Deploy #T001\n  Staging first\n  then production\n  - [ ] Build\n    Use buildx\n  - [ ] Tag\n    v1.0\n\n"
+	if diff := cmp.Diff(want, string(content)); diff != "" {
+		t.Errorf("task.md mismatch (-want +got):\n%s", diff)
+	}
+
+	detail, err := toolService.GetTask(ctx, GetTaskParams{TaskID: "T001"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSubtasks := []SubtaskInfo{
+		{Path: "T001.1", Title: "Build", Status: "todo", Notes: "Use buildx"},
+		{Path: "T001.2", Title: "Tag", Status: "todo", Notes: "v1.0"},
+	}
+	if detail.Notes != "Staging first\nthen production" || !cmp.Equal(wantSubtasks, detail.Subtasks) {
+		t.Errorf("GetTask() notes = %q, subtasks = %+v", detail.Notes, detail.Subtasks)
+	}
+
+	if _, err := toolService.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Notes: "- [x] Done"}); !errcode.HasCode(err, errcode.ValidationError) {
+		t.Errorf("UpdateTask() with a checkbox note error = %v, want VALIDATION_ERROR", err)
+	}
+	if _, err := toolService.UpdateTask(ctx, UpdateTaskParams{TaskID: "T001", Clear: []string{FieldNotes}}); err != nil {
+		t.Fatalf("UpdateTask() clearing notes error = %v", err)
+	}
+	if detail, _ := toolService.GetTask(ctx, GetTaskParams{TaskID: "T001"}); detail.Notes != "" {
+		t.Errorf("notes after clearing = %q", detail.Notes)
+	}
+}
@@ -0,0 +1,295 @@
+// Package merge merges diverged versions of task.md by task ID, so that
+// branches which add and update tasks in parallel do not conflict.
+package merge
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// Fields reported in conflicts
+const (
+	FieldTitle    = "title"
+	FieldCategory = "category"
+	FieldDue      = "due"
+	FieldPriority = "priority"
+	FieldAssignee = "assignee"
+	FieldNotes    = "notes"
+	FieldTask     = "task"
+)
+
+// Values of Conflict.Ours and Conflict.Theirs for FieldTask
+const (
+	Deleted = "deleted"
+	Changed = "changed"
+)
+
+// Conflict is a field changed differently on both sides. The merged
+// result keeps our value, or the changed task if one side deleted it.
+type Conflict struct {
+	TaskID string
+	Field  string
+	Ours   string
+	Theirs string
+}
+
+// String describes the conflict for messages
+func (c Conflict) String() string {
+	if c.Field == FieldTask {
+		return fmt.Sprintf("%s: %s on our side, %s on their side", c.TaskID, c.Ours, c.Theirs)
+	}
+	return fmt.Sprintf("%s: %s changed on both sides (ours %q, theirs %q)", c.TaskID, c.Field, c.Ours, c.Theirs)
+}
+
+// Sides holds the two versions of a task with conflicts. Each side keeps
+// the changes merged from the other side and its own value of the
+// conflicting fields; the side that deleted the task has none.
+type Sides struct {
+	Ours   *parser.ParsedTask
+	Theirs *parser.ParsedTask
+}
+
+// Result is the outcome of a merge
+type Result struct {
+	// Renamed maps the IDs of tasks added on their side under an ID our
+	// side also added to the IDs they were given
+	Renamed   map[string]string
+	Tasks     []parser.ParsedTask
+	Conflicts []Conflict
+	// Sides maps the IDs of the tasks with conflicts to their two versions
+	Sides map[string]Sides
+}
+
+// Tasks merges ours and theirs, two versions of the tasks in base.
+// Tasks are matched by ID and merged field by field: a field changed on
+// one side takes that side's value, and a status changed on both sides
+// takes the one furthest along in workflow. Tasks keep our order; tasks
+// added on their side follow the task they follow there. A task both sides
+// added under the same ID is renumbered on their side, even with the same
+// title, so that neither is lost.
+func Tasks(base, ours, theirs []parser.ParsedTask, ids model.IDScheme, workflow model.Workflow) (Result, error) {
+	result := Result{Renamed: map[string]string{}, Sides: map[string]Sides{}}
+	baseByID, oursByID := index(base), index(ours)
+
+	used := append(taskIDs(base), taskIDs(ours)...)
+	used = append(used, taskIDs(theirs)...)
+	theirs = slices.Clone(theirs)
+	for i, t := range theirs {
+		id := t.Task.ID
+		_, inOurs := oursByID[id]
+		if _, inBase := baseByID[id]; inBase || !inOurs {
+			continue
+		}
+		newID, err := ids.Next(used)
+		if err != nil {
+			return Result{}, err
+		}
+		used = append(used, newID)
+		result.Renamed[id] = newID
+		theirs[i].Task.ID = newID
+	}
+	theirsByID := index(theirs)
+
+	for _, o := range ours {
+		id := o.Task.ID
+		b, inBase := baseByID[id]
+		t, inTheirs := theirsByID[id]
+		switch {
+		case inTheirs:
+			conflicts := len(result.Conflicts)
+			merged := mergeTask(b, o, t, workflow, &result.Conflicts)
+			if len(result.Conflicts) > conflicts {
+				theirVersion := mergeTask(b, t, o, workflow, new([]Conflict))
+				result.Sides[id] = Sides{Ours: &merged, Theirs: &theirVersion}
+			}
+			result.Tasks = append(result.Tasks, merged)
+		case !inBase:
+			result.Tasks = append(result.Tasks, o)
+		case !sameTask(b, o):
+			result.Conflicts = append(result.Conflicts, Conflict{TaskID: id, Field: FieldTask, Ours: Changed, Theirs: Deleted})
+			result.Sides[id] = Sides{Ours: &o}
+			result.Tasks = append(result.Tasks, o)
+		}
+	}
+
+	for i, t := range theirs {
+		id := t.Task.ID
+		if _, inOurs := oursByID[id]; inOurs {
+			continue
+		}
+		if b, inBase := baseByID[id]; inBase {
+			if sameTask(b, t) {
+				continue
+			}
+			result.Conflicts = append(result.Conflicts, Conflict{TaskID: id, Field: FieldTask, Ours: Deleted, Theirs: Changed})
+			result.Sides[id] = Sides{Theirs: &t}
+		}
+		result.Tasks = insertTask(result.Tasks, t, theirs[:i], func(id string) bool {
+			_, inBase := baseByID[id]
+			_, inTheirs := theirsByID[id]
+			return !inBase && !inTheirs
+		})
+	}
+	return result, nil
+}
+
+// index maps tasks by ID
+func index(tasks []parser.ParsedTask) map[string]parser.ParsedTask {
+	byID := make(map[string]parser.ParsedTask, len(tasks))
+	for _, t := range tasks {
+		byID[t.Task.ID] = t
+	}
+	return byID
+}
+
+// taskIDs lists the IDs of tasks
+func taskIDs(tasks []parser.ParsedTask) []string {
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.Task.ID
+	}
+	return ids
+}
+
+// sameTask reports whether two versions of a task are identical
+func sameTask(a, b parser.ParsedTask) bool {
+	return a.Task.Equal(b.Task) && slices.EqualFunc(a.SubTasks, b.SubTasks, model.SubTask.Equal)
+}
+
+// mergeTask merges the fields of a task changed on both sides
+func mergeTask(b, o, t parser.ParsedTask, workflow model.Workflow, conflicts *[]Conflict) parser.ParsedTask {
+	merged := o
+	merged.Task.Title = mergeField(o.Task.ID, FieldTitle, b.Task.Title, o.Task.Title, t.Task.Title, conflicts)
+	merged.Task.Category = mergeField(o.Task.ID, FieldCategory, b.Task.Category, o.Task.Category, t.Task.Category, conflicts)
+	merged.Task.Status = mergeStatus(workflow, b.Task.Status, o.Task.Status, t.Task.Status)
+	merged.Task.Due = mergeField(o.Task.ID, FieldDue, b.Task.Due, o.Task.Due, t.Task.Due, conflicts)
+	merged.Task.Priority = mergeField(o.Task.ID, FieldPriority, b.Task.Priority, o.Task.Priority, t.Task.Priority, conflicts)
+	merged.Task.Assignee = mergeField(o.Task.ID, FieldAssignee, b.Task.Assignee, o.Task.Assignee, t.Task.Assignee, conflicts)
+	merged.Task.Tags = mergeTags(b.Task.Tags, o.Task.Tags, t.Task.Tags)
+	merged.Task.Notes = mergeField(o.Task.ID, FieldNotes, b.Task.Notes, o.Task.Notes, t.Task.Notes, conflicts)
+	merged.SubTasks = mergeSubtasks(b.SubTasks, o.SubTasks, t.SubTasks, workflow)
+	return merged
+}
+
+// mergeField takes the side that changed a field, recording a conflict if both did
+func mergeField(id, field, b, o, t string, conflicts *[]Conflict) string {
+	switch {
+	case o == t || t == b:
+		return o
+	case o == b:
+		return t
+	}
+	*conflicts = append(*conflicts, Conflict{TaskID: id, Field: field, Ours: o, Theirs: t})
+	return o
+}
+
+// mergeTags keeps our tags in order, drops those their side removed and
+// appends those their side added
+func mergeTags(b, o, t []string) []string {
+	var merged []string
+	for _, tag := range o {
+		if !slices.Contains(b, tag) || slices.Contains(t, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	for _, tag := range t {
+		if !slices.Contains(b, tag) && !slices.Contains(merged, tag) {
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
+
+// mergeStatus takes the side that changed a status, or the one at the
+// later stage of workflow if both did
+func mergeStatus(workflow model.Workflow, b, o, t string) string {
+	switch {
+	case o == t || t == b:
+		return o
+	case o == b:
+		return t
+	case workflow.Stage(t) > workflow.Stage(o):
+		return t
+	default:
+		return o
+	}
+}
+
+// mergeSubtasks merges subtask lists by title. Subtasks keep our order,
+// those added on their side are appended, and those one side removed
+// are dropped unless the other side changed them. The subtasks nested
+// under a subtask both sides kept are merged the same way.
+func mergeSubtasks(b, o, t []model.SubTask, workflow model.Workflow) []model.SubTask {
+	switch {
+	case slices.EqualFunc(o, t, model.SubTask.Equal) || slices.EqualFunc(t, b, model.SubTask.Equal):
+		return o
+	case slices.EqualFunc(o, b, model.SubTask.Equal):
+		return t
+	}
+
+	base, theirs, ours := byTitle(b), byTitle(t), byTitle(o)
+	merged := []model.SubTask{}
+	for _, s := range o {
+		bs, inBase := base[s.Title]
+		ts, inTheirs := theirs[s.Title]
+		if inBase && !inTheirs && s.Equal(bs) {
+			continue
+		}
+		if inTheirs {
+			s.Status = mergeStatus(workflow, bs.Status, s.Status, ts.Status)
+			if s.Notes == bs.Notes {
+				s.Notes = ts.Notes
+			}
+			s.SubTasks = mergeSubtasks(bs.SubTasks, s.SubTasks, ts.SubTasks, workflow)
+		}
+		merged = append(merged, s)
+	}
+	for _, s := range t {
+		bs, inBase := base[s.Title]
+		if _, inOurs := ours[s.Title]; !inOurs && (!inBase || !s.Equal(bs)) {
+			merged = append(merged, s)
+		}
+	}
+	return merged
+}
+
+// byTitle maps subtask titles to the first subtask with that title
+func byTitle(subtasks []model.SubTask) map[string]model.SubTask {
+	m := make(map[string]model.SubTask, len(subtasks))
+	for _, s := range subtasks {
+		if _, ok := m[s.Title]; !ok {
+			m[s.Title] = s
+		}
+	}
+	return m
+}
+
+// insertTask inserts a task added on their side after the closest task
+// preceding it there in the same category and the tasks our side added
+// after that one, or at the end of its category
+func insertTask(
+	tasks []parser.ParsedTask, t parser.ParsedTask, preceding []parser.ParsedTask, ourAddition func(id string) bool,
+) []parser.ParsedTask {
+	for i := len(preceding) - 1; i >= 0; i-- {
+		if preceding[i].Task.Category != t.Task.Category {
+			continue
+		}
+		at := slices.IndexFunc(tasks, func(p parser.ParsedTask) bool { return p.Task.ID == preceding[i].Task.ID })
+		if at < 0 {
+			continue
+		}
+		for at+1 < len(tasks) && tasks[at+1].Task.Category == t.Task.Category && ourAddition(tasks[at+1].Task.ID) {
+			at++
+		}
+		return slices.Insert(tasks, at+1, t)
+	}
+	for i := len(tasks) - 1; i >= 0; i-- {
+		if tasks[i].Task.Category == t.Task.Category {
+			return slices.Insert(tasks, i+1, t)
+		}
+	}
+	return append(tasks, t)
+}
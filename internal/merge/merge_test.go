@@ -0,0 +1,348 @@
+package merge
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// parse parses task.md content, failing the test on errors
+func parse(t *testing.T, content string) []parser.ParsedTask {
+	t.Helper()
+	tasks, err := parser.ParseTaskContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tasks
+}
+
+const mergeBase = `# Task
+
+## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [ ] Rate limit
+- [ ] Set up database #T002
+`
+
+func TestTasks(t *testing.T) {
+	tests := []struct {
+		name          string
+		ours          string
+		theirs        string
+		want          string
+		wantRenamed   map[string]string
+		wantConflicts []Conflict
+	}{
+		{
+			name: "both sides add a task under the same ID",
+			ours: mergeBase + "- [ ] Write migrations #T003\n",
+			theirs: mergeBase + `- [ ] Add logout endpoint #T003
+
+## Frontend
+- [ ] Build login form #T004
+`,
+			want: mergeBase + `- [ ] Write migrations #T003
+- [ ] Add logout endpoint #T005
+
+## Frontend
+- [ ] Build login form #T004
+`,
+			wantRenamed: map[string]string{"T003": "T005"},
+		},
+		{
+			name:        "both sides add a task with the same title",
+			ours:        mergeBase + "- [-] Write migrations #T003\n",
+			theirs:      mergeBase + "- [ ] Write migrations #T003\n",
+			want:        mergeBase + "- [-] Write migrations #T003\n- [ ] Write migrations #T004\n",
+			wantRenamed: map[string]string{"T003": "T004"},
+		},
+		{
+			name: "fields changed on different sides",
+			ours: `## Backend
+- [x] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [ ] Rate limit
+- [ ] Set up database #T002
+`,
+			theirs: `## Backend
+- [ ] Add login API #T001
+  - [ ] Hash passwords
+  - [ ] Rate limit
+- [-] Set up database #T002
+`,
+			want: `## Backend
+- [x] Add login API #T001
+  - [ ] Hash passwords
+  - [ ] Rate limit
+- [-] Set up database #T002
+`,
+			wantRenamed: map[string]string{},
+		},
+		{
+			name: "status changed on both sides takes the furthest along",
+			ours: `## Backend
+- [-] Add login endpoint #T001
+- [x] Set up database #T002
+`,
+			theirs: `## Backend
+- [x] Add login endpoint #T001
+- [-] Set up database #T002
+`,
+			want: `## Backend
+- [x] Add login endpoint #T001
+- [x] Set up database #T002
+`,
+			wantRenamed: map[string]string{},
+		},
+		{
+			name: "subtasks changed on both sides",
+			ours: `## Backend
+- [ ] Add login endpoint #T001
+  - [x] Hash passwords
+  - [ ] Rate limit
+  - [ ] Audit log
+- [ ] Set up database #T002
+`,
+			theirs: `## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [ ] Session cookie
+- [ ] Set up database #T002
+`,
+			want: `## Backend
+- [ ] Add login endpoint #T001
+  - [x] Hash passwords
+  - [ ] Audit log
+  - [ ] Session cookie
+- [ ] Set up database #T002
+`,
+			wantRenamed: map[string]string{},
+		},
+		{
+			name: "nested subtasks changed on both sides",
+			ours: `## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+    - [x] Pick algorithm
+  - [ ] Rate limit
+- [ ] Set up database #T002
+`,
+			theirs: `## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+    - [ ] Pick algorithm
+    - [ ] Tune cost
+  - [ ] Rate limit
+- [ ] Set up database #T002
+`,
+			want: `## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+    - [x] Pick algorithm
+    - [ ] Tune cost
+  - [ ] Rate limit
+- [ ] Set up database #T002
+`,
+			wantRenamed: map[string]string{},
+		},
+		{
+			name: "title changed on both sides keeps ours",
+			ours: `## Backend
+- [ ] Add login API #T001
+- [ ] Set up database #T002
+`,
+			theirs: `## Backend
+- [ ] Add sign-in endpoint #T001
+- [ ] Set up database #T002
+`,
+			want: `## Backend
+- [ ] Add login API #T001
+- [ ] Set up database #T002
+`,
+			wantRenamed:   map[string]string{},
+			wantConflicts: []Conflict{{TaskID: "T001", Field: FieldTitle, Ours: "Add login API", Theirs: "Add sign-in endpoint"}},
+		},
+		{
+			name: "metadata changed on both sides",
+			ours: `## Backend
+- [ ] Add login endpoint @alice +auth +api due:2026-11-01 #T001
+- [ ] Set up database +infra #T002
+`,
+			theirs: `## Backend
+- [ ] Add login endpoint @bob +auth +security !high #T001
+- [ ] Set up database #T002
+`,
+			want: `## Backend
+- [ ] Add login endpoint @alice +auth +api +security due:2026-11-01 !high #T001
+- [ ] Set up database +infra #T002
+`,
+			wantRenamed:   map[string]string{},
+			wantConflicts: []Conflict{{TaskID: "T001", Field: FieldAssignee, Ours: "alice", Theirs: "bob"}},
+		},
+		{
+			name: "notes changed on both sides",
+			ours: `## Backend
+- [ ] Add login endpoint #T001
+  Ours first
+  - [ ] Hash passwords
+    Use bcrypt
+  - [ ] Rate limit
+- [ ] Set up database #T002
+  Postgres 17
+`,
+			theirs: `## Backend
+- [ ] Add login endpoint #T001
+  Theirs first
+  - [ ] Hash passwords
+  - [ ] Rate limit
+    100 requests a minute
+- [ ] Set up database #T002
+`,
+			want: `## Backend
+- [ ] Add login endpoint #T001
+  Ours first
+  - [ ] Hash passwords
+    Use bcrypt
+  - [ ] Rate limit
+    100 requests a minute
+- [ ] Set up database #T002
+  Postgres 17
+`,
+			wantRenamed:   map[string]string{},
+			wantConflicts: []Conflict{{TaskID: "T001", Field: FieldNotes, Ours: "Ours first", Theirs: "Theirs first"}},
+		},
+		{
+			name: "deletions",
+			ours: `## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [ ] Rate limit
+`,
+			theirs: `## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [x] Rate limit
+- [ ] Set up database #T002
+`,
+			want: `## Backend
+- [ ] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [x] Rate limit
+`,
+			wantRenamed: map[string]string{},
+		},
+		{
+			name: "deleted on one side and changed on the other",
+			ours: `## Backend
+- [x] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [ ] Rate limit
+- [ ] Set up database #T002
+`,
+			theirs: `## Backend
+- [ ] Set up database #T002
+`,
+			want: `## Backend
+- [x] Add login endpoint #T001
+  - [ ] Hash passwords
+  - [ ] Rate limit
+- [ ] Set up database #T002
+`,
+			wantRenamed:   map[string]string{},
+			wantConflicts: []Conflict{{TaskID: "T001", Field: FieldTask, Ours: Changed, Theirs: Deleted}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Tasks(parse(t, mergeBase), parse(t, tt.ours), parse(t, tt.theirs), model.DefaultIDScheme, model.DefaultWorkflow)
+			if err != nil {
+				t.Fatalf("Tasks() error = %v", err)
+			}
+			if diff := cmp.Diff(parse(t, tt.want), got.Tasks); diff != "" {
+				t.Errorf("Tasks() tasks mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRenamed, got.Renamed); diff != "" {
+				t.Errorf("Tasks() renamed mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantConflicts, got.Conflicts); diff != "" {
+				t.Errorf("Tasks() conflicts mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTasks_Sides(t *testing.T) {
+	tests := []struct {
+		name       string
+		ours       string
+		theirs     string
+		wantOurs   string
+		wantTheirs string
+	}{
+		{
+			name:       "fields changed on both sides",
+			ours:       "## Backend\n- [x] Add login API #T001\n- [ ] Set up database #T002\n",
+			theirs:     "## Backend\n- [ ] Add sign-in endpoint @bob #T001\n- [ ] Set up database #T002\n",
+			wantOurs:   "## Backend\n- [x] Add login API @bob #T001\n",
+			wantTheirs: "## Backend\n- [x] Add sign-in endpoint @bob #T001\n",
+		},
+		{
+			name:     "changed on our side and deleted on theirs",
+			ours:     "## Backend\n- [x] Add login endpoint #T001\n- [ ] Set up database #T002\n",
+			theirs:   "## Backend\n- [ ] Set up database #T002\n",
+			wantOurs: "## Backend\n- [x] Add login endpoint #T001\n",
+		},
+		{
+			name:       "deleted on our side and changed on theirs",
+			ours:       "## Backend\n- [ ] Set up database #T002\n",
+			theirs:     "## Backend\n- [x] Add login endpoint #T001\n- [ ] Set up database #T002\n",
+			wantTheirs: "## Backend\n- [x] Add login endpoint #T001\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Tasks(parse(t, mergeBase), parse(t, tt.ours), parse(t, tt.theirs), model.DefaultIDScheme, model.DefaultWorkflow)
+			if err != nil {
+				t.Fatalf("Tasks() error = %v", err)
+			}
+			sides, ok := got.Sides["T001"]
+			if !ok || len(got.Sides) != 1 {
+				t.Fatalf("Tasks() sides = %v, want T001 only", got.Sides)
+			}
+			for _, side := range []struct {
+				name string
+				task *parser.ParsedTask
+				want string
+			}{{"ours", sides.Ours, tt.wantOurs}, {"theirs", sides.Theirs, tt.wantTheirs}} {
+				if side.want == "" {
+					if side.task != nil {
+						t.Errorf("%s side = %+v, want none", side.name, side.task)
+					}
+					continue
+				}
+				want := parse(t, side.want)[0]
+				if side.task == nil {
+					t.Errorf("%s side missing", side.name)
+				} else if diff := cmp.Diff(want.Task, side.task.Task); diff != "" {
+					t.Errorf("%s side mismatch (-want +got):\n%s", side.name, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestTasks_IDLimit(t *testing.T) {
+	scheme := model.IDScheme{Prefix: "T", Digits: 1}
+	p := parser.NewParser(scheme, model.DefaultWorkflow)
+	ours, _ := p.Parse("## A\n- [ ] One #T9\n")
+	theirs, _ := p.Parse("## A\n- [ ] Two #T9\n")
+	if _, err := Tasks(nil, ours, theirs, scheme, model.DefaultWorkflow); err == nil {
+		t.Error("Tasks() with no IDs left succeeded")
+	}
+}
@@ -2,20 +2,19 @@
 // It includes Task, ADR, and Context models with validation and business logic.
 package model
 
-import (
-	"errors"
-	"fmt"
-)
+import "github.com/jnst/agentic-todo-mcp/internal/errcode"
 
 // ADR represents an Architecture Decision Record
 type ADR struct {
-	Title        string `json:"title"`
-	Status       string `json:"status"`
-	Context      string `json:"context"`
-	Decision     string `json:"decision"`
-	Rationale    string `json:"rationale"`
-	Consequences string `json:"consequences,omitempty"`
-	Number       int    `json:"number"`
+	Title        string   `json:"title"`
+	Status       string   `json:"status"`
+	Context      string   `json:"context"`
+	Decision     string   `json:"decision"`
+	Rationale    string   `json:"rationale"`
+	Consequences string   `json:"consequences,omitempty"`
+	Date         string   `json:"date,omitempty"`
+	History      []string `json:"history,omitempty"`
+	Number       int      `json:"number"`
 }
 
 // NewADR creates a new ADR with the given parameters
@@ -33,19 +32,19 @@ func NewADR(number int, title, context, decision, rationale string) ADR {
 // Validate validates the ADR fields
 func (a *ADR) Validate() error {
 	if a.Number <= 0 {
-		return errors.New("ADR number must be positive")
+		return errcode.New(errcode.InvalidADRNumber, "ADR number must be positive").WithDetails("number", a.Number)
 	}
 	if a.Title == "" {
-		return errors.New("ADR title cannot be empty")
+		return errcode.New(errcode.ValidationError, "ADR title cannot be empty").WithDetails("title", "")
 	}
 	if a.Context == "" {
-		return errors.New("ADR context cannot be empty")
+		return errcode.New(errcode.ValidationError, "ADR context cannot be empty").WithDetails("context", "")
 	}
 	if a.Decision == "" {
-		return errors.New("ADR decision cannot be empty")
+		return errcode.New(errcode.ValidationError, "ADR decision cannot be empty").WithDetails("decision", "")
 	}
 	if a.Rationale == "" {
-		return errors.New("ADR rationale cannot be empty")
+		return errcode.New(errcode.ValidationError, "ADR rationale cannot be empty").WithDetails("rationale", "")
 	}
 
 	validStatuses := map[string]bool{
@@ -55,7 +54,7 @@ func (a *ADR) Validate() error {
 	}
 
 	if !validStatuses[a.Status] {
-		return fmt.Errorf("invalid status: %s", a.Status)
+		return errcode.New(errcode.InvalidStatus, "invalid status: %s", a.Status).WithDetails("status", a.Status)
 	}
 
 	return nil
@@ -1,6 +1,6 @@
 package model
 
-import "errors"
+import "github.com/jnst/agentic-todo-mcp/internal/errcode"
 
 // Context represents context information for a task
 type Context struct {
@@ -19,10 +19,10 @@ func NewContext(taskID, content string) Context {
 // Validate validates the context fields
 func (c Context) Validate() error {
 	if c.TaskID == "" {
-		return errors.New("context task ID cannot be empty")
+		return errcode.New(errcode.InvalidTaskID, "context task ID cannot be empty")
 	}
 	if c.Content == "" {
-		return errors.New("context content cannot be empty")
+		return errcode.New(errcode.ValidationError, "context content cannot be empty").WithDetails("content", "")
 	}
 
 	return nil
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// Prefixes of the metadata written inline in a task title, as in
+// "Add login @alice +auth +api due:2026-11-01 !high"
+const (
+	AssigneePrefix = "@"
+	TagPrefix      = "+"
+	DuePrefix      = "due:"
+	PriorityPrefix = "!"
+)
+
+var (
+	// assigneeRegex matches an assignee name
+	assigneeRegex = regexp.MustCompile(`^[\pL\pN][\pL\pN._-]*$`)
+	// tagRegex matches a tag
+	tagRegex = regexp.MustCompile(`^[\pL\pN][\pL\pN._/-]*$`)
+	// dueRegex matches the form of a due date; Validate checks it is a real date
+	dueRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+)
+
+// priorities are the valid priority labels
+var priorities = []string{PriorityHigh, PriorityMedium, PriorityLow}
+
+// SplitMetadata separates the inline metadata from a task title. Words of
+// the forms @assignee, +tag, due:YYYY-MM-DD and !priority are removed from
+// the title wherever they appear; for the single-valued fields the last
+// one wins. Other words, including malformed metadata, stay in the title.
+func SplitMetadata(text string) Task {
+	var task Task
+	var title []string
+	for _, word := range strings.Fields(text) {
+		if !task.setMetadata(word) {
+			title = append(title, word)
+		}
+	}
+	task.Title = strings.Join(title, " ")
+	return task
+}
+
+// QuoteMetadata puts the words of text that would be read as metadata in
+// backquotes, so that free text such as a code comment stays in the title
+func QuoteMetadata(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		var probe Task
+		if probe.setMetadata(word) {
+			words[i] = "`" + word + "`"
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// setMetadata sets the field a metadata word stands for, reporting whether
+// word is metadata
+func (t *Task) setMetadata(word string) bool {
+	if v, ok := strings.CutPrefix(word, AssigneePrefix); ok && assigneeRegex.MatchString(v) {
+		t.Assignee = v
+		return true
+	}
+	if v, ok := strings.CutPrefix(word, TagPrefix); ok && tagRegex.MatchString(v) {
+		if !slices.Contains(t.Tags, v) {
+			t.Tags = append(t.Tags, v)
+		}
+		return true
+	}
+	if v, ok := strings.CutPrefix(word, DuePrefix); ok && dueRegex.MatchString(v) {
+		t.Due = v
+		return true
+	}
+	if v, ok := strings.CutPrefix(word, PriorityPrefix); ok && slices.Contains(priorities, v) {
+		t.Priority = v
+		return true
+	}
+	return false
+}
+
+// Metadata returns the inline form of the task's metadata, empty if it has none
+func (t Task) Metadata() string {
+	var words []string
+	if t.Assignee != "" {
+		words = append(words, AssigneePrefix+t.Assignee)
+	}
+	for _, tag := range t.Tags {
+		words = append(words, TagPrefix+tag)
+	}
+	if t.Due != "" {
+		words = append(words, DuePrefix+t.Due)
+	}
+	if t.Priority != "" {
+		words = append(words, PriorityPrefix+t.Priority)
+	}
+	return strings.Join(words, " ")
+}
+
+// validateMetadata checks the metadata fields, and that the title holds no
+// word that would be read back as metadata
+func (t Task) validateMetadata() error {
+	if t.Due != "" {
+		if _, err := time.Parse(DueDateFormat, t.Due); err != nil || !dueRegex.MatchString(t.Due) {
+			return errcode.New(errcode.ValidationError, "due date must be a date in YYYY-MM-DD form: %s", t.Due).
+				WithDetails("due", t.Due)
+		}
+	}
+	if t.Priority != "" && !slices.Contains(priorities, t.Priority) {
+		return errcode.New(errcode.ValidationError, "priority must be one of %s: %s", strings.Join(priorities, ", "), t.Priority).
+			WithDetails("priority", t.Priority)
+	}
+	if t.Assignee != "" && !assigneeRegex.MatchString(t.Assignee) {
+		return errcode.New(errcode.ValidationError, "assignee must be a single word of letters, digits, '.', '_' and '-': %s", t.Assignee).
+			WithDetails("assignee", t.Assignee)
+	}
+	for i, tag := range t.Tags {
+		if !tagRegex.MatchString(tag) {
+			return errcode.New(errcode.ValidationError, "tag must be a single word of letters, digits, '.', '_', '/' and '-': %s", tag).
+				WithDetails("tags", tag)
+		}
+		if slices.Contains(t.Tags[:i], tag) {
+			return errcode.New(errcode.ValidationError, "duplicate tag: %s", tag).WithDetails("tags", tag)
+		}
+	}
+	for _, word := range strings.Fields(t.Title) {
+		var probe Task
+		if probe.setMetadata(word) {
+			return errcode.New(errcode.ValidationError, "title word %q would be read as metadata; use the assignee, tags, due or priority field", word).
+				WithDetails("title", t.Title)
+		}
+	}
+	return nil
+}
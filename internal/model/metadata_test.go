@@ -0,0 +1,102 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitMetadata(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want Task
+	}{
+		{"no metadata", "Add login", Task{Title: "Add login"}},
+		{
+			"all fields",
+			"Add login @alice +auth +api due:2026-11-01 !high",
+			Task{Title: "Add login", Assignee: "alice", Tags: []string{"auth", "api"}, Due: "2026-11-01", Priority: "high"},
+		},
+		{"anywhere in the title", "Fix @bob the +bug", Task{Title: "Fix the", Assignee: "bob", Tags: []string{"bug"}}},
+		{"last one wins", "Task @alice @bob !low !medium", Task{Title: "Task", Assignee: "bob", Priority: "medium"}},
+		{"duplicate tags", "Task +api +api", Task{Title: "Task", Tags: []string{"api"}}},
+		{"malformed metadata stays", "Email a@b.c + 1 due:tomorrow !urgent", Task{Title: "Email a@b.c + 1 due:tomorrow !urgent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitMetadata(tt.text)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SplitMetadata(%q) mismatch (-want +got):\n%s", tt.text, diff)
+			}
+		})
+	}
+}
+
+func TestQuoteMetadata(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Add login", "Add login"},
+		{"ask @bob about +retry by due:2026-11-01 !high", "ask `@bob` about `+retry` by `due:2026-11-01` `!high`"},
+		{"Email a@b.c about !urgent", "Email a@b.c about !urgent"},
+	}
+
+	for _, tt := range tests {
+		got := QuoteMetadata(tt.text)
+		if got != tt.want {
+			t.Errorf("QuoteMetadata(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+		if task := SplitMetadata(got); task.Title != got || task.Metadata() != "" {
+			t.Errorf("SplitMetadata(%q) = %+v, want the title alone", got, task)
+		}
+	}
+}
+
+func TestTask_Metadata(t *testing.T) {
+	task := Task{Title: "Add login", Assignee: "alice", Tags: []string{"auth", "api"}, Due: "2026-11-01", Priority: "high"}
+	want := "@alice +auth +api due:2026-11-01 !high"
+	if got := task.Metadata(); got != want {
+		t.Errorf("Metadata() = %q, want %q", got, want)
+	}
+	if got := SplitMetadata(task.Title + " " + task.Metadata()); !got.Equal(task) {
+		t.Errorf("SplitMetadata(Metadata()) = %+v, want %+v", got, task)
+	}
+	if got := (Task{Title: "Plain"}).Metadata(); got != "" {
+		t.Errorf("Metadata() without metadata = %q, want empty", got)
+	}
+}
+
+func TestTaskMetadataValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		task    Task
+		wantErr bool
+	}{
+		{"all fields", Task{Due: "2026-11-01", Priority: "low", Assignee: "alice.b", Tags: []string{"api/v2"}}, false},
+		{"impossible date", Task{Due: "2026-02-30"}, true},
+		{"date form", Task{Due: "2026-1-1"}, true},
+		{"unknown priority", Task{Priority: "urgent"}, true},
+		{"assignee with spaces", Task{Assignee: "alice b"}, true},
+		{"empty tag", Task{Tags: []string{""}}, true},
+		{"duplicate tag", Task{Tags: []string{"api", "api"}}, true},
+		{"metadata in title", Task{Title: "Ask @carol"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := tt.task
+			task.ID = "T001"
+			if task.Title == "" {
+				task.Title = "Test Task"
+			}
+			task.Status = "todo"
+			err := task.Validate(DefaultWorkflow)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Task.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
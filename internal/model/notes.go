@@ -0,0 +1,48 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// MaxNotesLength is the maximum length of the notes of a task or subtask
+const MaxNotesLength = 2000
+
+// noteItemRegex matches note lines that task.md would read as a subtask
+var noteItemRegex = regexp.MustCompile(`^([-*+]|\d+[.)])\s+\[.\]\s+\S`)
+
+// NormalizeNotes returns notes as they are kept in task.md: each line
+// without surrounding whitespace, blank lines dropped
+func NormalizeNotes(notes string) string {
+	return strings.Join(NoteLines(notes), "\n")
+}
+
+// NoteLines returns the non-blank lines of notes without surrounding
+// whitespace, as written under a task in task.md
+func NoteLines(notes string) []string {
+	var lines []string
+	for _, line := range strings.Split(notes, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// ValidateNotes rejects notes that are too long or that would be read back
+// from task.md as subtasks
+func ValidateNotes(notes string) error {
+	if len(notes) > MaxNotesLength {
+		return errcode.New(errcode.ValidationError, "notes must be at most %d bytes", MaxNotesLength).
+			WithDetails("notes", len(notes))
+	}
+	for _, line := range NoteLines(notes) {
+		if noteItemRegex.MatchString(line) {
+			return errcode.New(errcode.ValidationError, "notes line %q would be read as a subtask; add it as a subtask instead", line).
+				WithDetails("notes", line)
+		}
+	}
+	return nil
+}
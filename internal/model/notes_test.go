@@ -0,0 +1,35 @@
+package model
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeNotes(t *testing.T) {
+	got := NormalizeNotes("  first line \r\n\n\t- second\n  ")
+	if want := "first line\n- second"; got != want {
+		t.Errorf("NormalizeNotes() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateNotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		notes   string
+		wantErr bool
+	}{
+		{"empty", "", false},
+		{"lines", "Staging first\n- see the runbook\n[x] is not a subtask", false},
+		{"checkbox item", "Staging first\n  - [x] Done already", true},
+		{"too long", strings.Repeat("a", MaxNotesLength+1), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNotes(tt.notes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateNotes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
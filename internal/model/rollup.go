@@ -0,0 +1,79 @@
+package model
+
+// Rollup rules that can be enabled in a workflow
+const (
+	RollupStart    = "start"
+	RollupComplete = "complete"
+)
+
+// Rollup selects the rules that move a task or subtask along with the
+// subtasks nested directly under it. The rules only move it forward, only
+// along allowed transitions, and never out of a status that needs a reason.
+type Rollup struct {
+	// Start moves it from the initial status to the active stage once any
+	// of its subtasks has left the initial status
+	Start bool `json:"start,omitempty"`
+	// Complete moves it to the done stage once all of its subtasks are done
+	Complete bool `json:"complete,omitempty"`
+}
+
+// StatusChange is a status change made by the rollup rules
+type StatusChange struct {
+	Path SubtaskPath
+	From string
+	To   string
+}
+
+// RollupStatus returns the status a task or subtask in status moves to by
+// the rollup rules, given the subtasks nested directly under it, or status
+// if it stays
+func (w Workflow) RollupStatus(status string, subtasks []SubTask) string {
+	if len(subtasks) == 0 || w.RequiresReason(status) {
+		return status
+	}
+	allDone, anyStarted := true, false
+	for _, s := range subtasks {
+		allDone = allDone && w.IsDone(s.Status)
+		anyStarted = anyStarted || w.Stage(s.Status) != StageOpen
+	}
+
+	if w.Rollup.Complete && allDone && !w.IsDone(status) {
+		if to := w.StageStatus(StageDone); to != "" && w.CanTransition(status, to) {
+			return to
+		}
+	}
+	if w.Rollup.Start && anyStarted && w.Stage(status) == StageOpen {
+		if to := w.StageStatus(StageActive); to != "" && w.CanTransition(status, to) {
+			return to
+		}
+	}
+	return status
+}
+
+// RollupSubtasks applies the rollup rules to the subtasks under the task or
+// subtask at parent, deepest first, and returns the changes
+func (w Workflow) RollupSubtasks(parent SubtaskPath, subtasks []SubTask) []StatusChange {
+	var changes []StatusChange
+	for i := range subtasks {
+		s := &subtasks[i]
+		path := parent.Child(i)
+		changes = append(changes, w.RollupSubtasks(path, s.SubTasks)...)
+		if to := w.RollupStatus(s.Status, s.SubTasks); to != s.Status {
+			changes = append(changes, StatusChange{Path: path, From: s.Status, To: to})
+			s.Status = to
+		}
+	}
+	return changes
+}
+
+// RollupTask applies the rollup rules to the subtasks of a task and then to
+// the task itself, and returns the changes
+func (w Workflow) RollupTask(task *Task, subtasks []SubTask) []StatusChange {
+	path := SubtaskPath{TaskID: task.ID}
+	changes := w.RollupSubtasks(path, subtasks)
+	if to := w.RollupStatus(task.Status, subtasks); to != task.Status {
+		changes = append(changes, StatusChange{Path: path, From: task.Status, To: to})
+		task.Status = to
+	}
+	return changes
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWorkflow_RollupStatus(t *testing.T) {
+	both := DefaultWorkflow
+	both.Rollup = Rollup{Start: true, Complete: true}
+	startOnly := DefaultWorkflow
+	startOnly.Rollup = Rollup{Start: true}
+	review := reviewWorkflow
+	review.Rollup = Rollup{Start: true, Complete: true}
+
+	subtasks := func(statuses ...string) []SubTask {
+		var list []SubTask
+		for _, status := range statuses {
+			list = append(list, NewSubTask("s", status))
+		}
+		return list
+	}
+
+	tests := []struct {
+		name     string
+		workflow Workflow
+		status   string
+		subtasks []SubTask
+		want     string
+	}{
+		{"rules disabled", DefaultWorkflow, "todo", subtasks("done"), "todo"},
+		{"no subtasks", both, "todo", nil, "todo"},
+		{"none started", both, "todo", subtasks("todo", "todo"), "todo"},
+		{"one started", both, "todo", subtasks("in_progress", "todo"), "in_progress"},
+		{"one done", both, "todo", subtasks("done", "todo"), "in_progress"},
+		{"one blocked", both, "todo", subtasks("blocked", "todo"), "in_progress"},
+		{"already active", both, "in_progress", subtasks("in_progress"), "in_progress"},
+		{"all done", both, "todo", subtasks("done", "cancelled"), "done"},
+		{"all done from active", both, "in_progress", subtasks("done"), "done"},
+		{"all done without complete", startOnly, "todo", subtasks("done"), "in_progress"},
+		{"never leaves a reason status", both, "blocked", subtasks("done"), "blocked"},
+		{"never moves back", both, "done", subtasks("todo"), "done"},
+		{"done not reachable", review, "in_progress", subtasks("deployed"), "in_progress"},
+		{"done not reachable from the start", review, "todo", subtasks("deployed"), "in_progress"},
+		{"done reachable", review, "review", subtasks("deployed"), "deployed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.workflow.RollupStatus(tt.status, tt.subtasks); got != tt.want {
+				t.Errorf("RollupStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkflow_RollupTask(t *testing.T) {
+	workflow := DefaultWorkflow
+	workflow.Rollup = Rollup{Start: true, Complete: true}
+	task := Task{ID: "T001", Status: "todo"}
+	subtasks := []SubTask{
+		{Task: Task{Title: "Build", Status: "todo"}, SubTasks: []SubTask{NewSubTask("Pin", "done"), NewSubTask("Scan", "done")}},
+		{Task: Task{Title: "Tag", Status: "todo"}, SubTasks: []SubTask{NewSubTask("Draft", "in_progress")}},
+	}
+
+	changes := workflow.RollupTask(&task, subtasks)
+	want := []StatusChange{
+		{Path: SubtaskPath{TaskID: "T001", Indexes: []int{0}}, From: "todo", To: "done"},
+		{Path: SubtaskPath{TaskID: "T001", Indexes: []int{1}}, From: "todo", To: "in_progress"},
+		{Path: SubtaskPath{TaskID: "T001"}, From: "todo", To: "in_progress"},
+	}
+	if diff := cmp.Diff(want, changes); diff != "" {
+		t.Errorf("RollupTask() mismatch (-want +got):\n%s", diff)
+	}
+	if task.Status != "in_progress" || subtasks[0].Status != "done" || subtasks[1].Status != "in_progress" {
+		t.Errorf("statuses after RollupTask() = %s, %s, %s", task.Status, subtasks[0].Status, subtasks[1].Status)
+	}
+}
@@ -0,0 +1,123 @@
+package model
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// SubTask is a subtask with the subtasks nested under it. Only the title,
+// status and notes of the embedded Task are used.
+type SubTask struct {
+	Task
+	SubTasks []SubTask
+}
+
+// NewSubTask creates a subtask without nested subtasks
+func NewSubTask(title, status string) SubTask {
+	return SubTask{Task: Task{Title: title, Status: status}}
+}
+
+// Equal reports whether two subtasks have the same fields and nested subtasks
+func (s SubTask) Equal(other SubTask) bool {
+	return s.Task.Equal(other.Task) && slices.EqualFunc(s.SubTasks, other.SubTasks, SubTask.Equal)
+}
+
+// SubtaskPath addresses a subtask by the task ID and its 1-based position at
+// each level, written as T003.2.1
+type SubtaskPath struct {
+	TaskID  string
+	Indexes []int // 0-based
+}
+
+// ParseSubtaskPath parses a path like T003.2.1. A bare task ID yields a path
+// without indexes, which addresses the task itself.
+func ParseSubtaskPath(path string) (SubtaskPath, error) {
+	parts := strings.Split(path, ".")
+	p := SubtaskPath{TaskID: parts[0]}
+	for _, part := range parts[1:] {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 || strconv.Itoa(n) != part {
+			return SubtaskPath{}, errcode.New(errcode.InvalidTaskID, "invalid subtask path %s; use the task ID followed by positions, e.g. T003.2.1", path).
+				WithDetails("path", path)
+		}
+		p.Indexes = append(p.Indexes, n-1)
+	}
+	return p, nil
+}
+
+// String returns the path written as T003.2.1
+func (p SubtaskPath) String() string {
+	var sb strings.Builder
+	sb.WriteString(p.TaskID)
+	for _, i := range p.Indexes {
+		sb.WriteString("." + strconv.Itoa(i+1))
+	}
+	return sb.String()
+}
+
+// Child returns the path of the i-th (0-based) subtask under p
+func (p SubtaskPath) Child(i int) SubtaskPath {
+	return SubtaskPath{TaskID: p.TaskID, Indexes: append(slices.Clip(p.Indexes), i)}
+}
+
+// Parent returns the path of the task or subtask p is nested under
+func (p SubtaskPath) Parent() SubtaskPath {
+	return SubtaskPath{TaskID: p.TaskID, Indexes: p.Indexes[:max(0, len(p.Indexes)-1)]}
+}
+
+// Contains reports whether other is p or nested under it
+func (p SubtaskPath) Contains(other SubtaskPath) bool {
+	return p.TaskID == other.TaskID && len(other.Indexes) >= len(p.Indexes) &&
+		slices.Equal(p.Indexes, other.Indexes[:len(p.Indexes)])
+}
+
+// SubtaskList returns the list of subtasks nested directly under the
+// subtask at indexes, or subtasks itself for no indexes. It reports false
+// if no subtask is at indexes.
+func SubtaskList(subtasks *[]SubTask, indexes []int) (*[]SubTask, bool) {
+	list := subtasks
+	for _, i := range indexes {
+		if i < 0 || i >= len(*list) {
+			return nil, false
+		}
+		list = &(*list)[i].SubTasks
+	}
+	return list, true
+}
+
+// WalkSubtasks calls fn for every subtask, parents before their nested
+// subtasks, with its 0-based position at each level
+func WalkSubtasks(subtasks []SubTask, fn func(indexes []int, s SubTask)) {
+	walkSubtasks(subtasks, nil, fn)
+}
+
+// walkSubtasks walks subtasks nested under the subtask at parent
+func walkSubtasks(subtasks []SubTask, parent []int, fn func(indexes []int, s SubTask)) {
+	for i, s := range subtasks {
+		indexes := append(slices.Clip(parent), i)
+		fn(indexes, s)
+		walkSubtasks(s.SubTasks, indexes, fn)
+	}
+}
+
+// CountSubtasks returns the number of subtasks including nested ones
+func CountSubtasks(subtasks []SubTask) int {
+	n := 0
+	WalkSubtasks(subtasks, func([]int, SubTask) { n++ })
+	return n
+}
+
+// CountDoneSubtasks returns the number of subtasks including nested ones
+// whose status counts as done in the workflow
+func CountDoneSubtasks(w Workflow, subtasks []SubTask) int {
+	n := 0
+	WalkSubtasks(subtasks, func(_ []int, s SubTask) {
+		if w.IsDone(s.Status) {
+			n++
+		}
+	})
+	return n
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseSubtaskPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    SubtaskPath
+		wantErr bool
+	}{
+		{"T003", SubtaskPath{TaskID: "T003"}, false},
+		{"T003.2", SubtaskPath{TaskID: "T003", Indexes: []int{1}}, false},
+		{"T003.2.10", SubtaskPath{TaskID: "T003", Indexes: []int{1, 9}}, false},
+		{"T0k9gu4x2.1", SubtaskPath{TaskID: "T0k9gu4x2", Indexes: []int{0}}, false},
+		{"T003.0", SubtaskPath{}, true},
+		{"T003.02", SubtaskPath{}, true},
+		{"T003.", SubtaskPath{}, true},
+		{"T003.x", SubtaskPath{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseSubtaskPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSubtaskPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseSubtaskPath() mismatch (-want +got):\n%s", diff)
+			}
+			if got.String() != tt.path {
+				t.Errorf("String() = %q, want %q", got.String(), tt.path)
+			}
+		})
+	}
+}
+
+func TestSubtaskPath_Relatives(t *testing.T) {
+	path := SubtaskPath{TaskID: "T003", Indexes: []int{1, 0}}
+	if got := path.Parent().String(); got != "T003.2" {
+		t.Errorf("Parent() = %s, want T003.2", got)
+	}
+	if got := path.Parent().Parent().Parent().String(); got != "T003" {
+		t.Errorf("Parent() of a task = %s, want T003", got)
+	}
+	if got := path.Child(2).String(); got != "T003.2.1.3" {
+		t.Errorf("Child(2) = %s, want T003.2.1.3", got)
+	}
+
+	tests := []struct {
+		other SubtaskPath
+		want  bool
+	}{
+		{path, true},
+		{path.Child(0), true},
+		{path.Parent(), false},
+		{SubtaskPath{TaskID: "T003", Indexes: []int{1, 1}}, false},
+		{SubtaskPath{TaskID: "T004", Indexes: []int{1, 0}}, false},
+	}
+	for _, tt := range tests {
+		if got := path.Contains(tt.other); got != tt.want {
+			t.Errorf("Contains(%s) = %v, want %v", tt.other, got, tt.want)
+		}
+	}
+}
+
+func TestWalkSubtasks(t *testing.T) {
+	subtasks := []SubTask{
+		{Task: Task{Title: "Build", Status: "todo"}, SubTasks: []SubTask{
+			NewSubTask("Pin base image", "done"),
+			{Task: Task{Title: "Scan", Status: "todo"}, SubTasks: []SubTask{NewSubTask("Fix CVEs", "todo")}},
+		}},
+		NewSubTask("Tag", "done"),
+	}
+
+	var got []string
+	WalkSubtasks(subtasks, func(indexes []int, s SubTask) {
+		got = append(got, SubtaskPath{TaskID: "T001", Indexes: indexes}.String()+" "+s.Title)
+	})
+	want := []string{"T001.1 Build", "T001.1.1 Pin base image", "T001.1.2 Scan", "T001.1.2.1 Fix CVEs", "T001.2 Tag"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("WalkSubtasks() mismatch (-want +got):\n%s", diff)
+	}
+	if n := CountSubtasks(subtasks); n != len(want) {
+		t.Errorf("CountSubtasks() = %d, want %d", n, len(want))
+	}
+
+	list, ok := SubtaskList(&subtasks, []int{0, 1})
+	if !ok || len(*list) != 1 || (*list)[0].Title != "Fix CVEs" {
+		t.Errorf("SubtaskList([0 1]) = %v, %v", list, ok)
+	}
+	if _, ok := SubtaskList(&subtasks, []int{2}); ok {
+		t.Error("SubtaskList() of a missing subtask reports ok")
+	}
+}
@@ -1,16 +1,35 @@
 package model
 
 import (
-	"errors"
-	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// Priority labels of a task
+const (
+	PriorityHigh   = "high"
+	PriorityMedium = "medium"
+	PriorityLow    = "low"
 )
 
+// DueDateFormat is the layout of due dates
+const DueDateFormat = time.DateOnly
+
 // Task represents a task in the system
 type Task struct {
-	ID       string `json:"id"`
-	Title    string `json:"title"`
-	Status   string `json:"status"`
-	Category string `json:"category"`
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Status   string   `json:"status"`
+	Category string   `json:"category"`
+	Due      string   `json:"due,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Assignee string   `json:"assignee,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	// Notes are the free-form lines indented under the task in task.md,
+	// separated by newlines
+	Notes string `json:"notes,omitempty"`
 }
 
 // NewTask creates a new task with the given parameters
@@ -18,29 +37,33 @@ func NewTask(id, title, category string) Task {
 	return Task{
 		ID:       id,
 		Title:    title,
-		Status:   "todo",
+		Status:   DefaultWorkflow.Initial(),
 		Category: category,
 	}
 }
 
-// Validate validates the task fields
-func (t Task) Validate() error {
+// Validate validates the task fields, with the statuses of workflow
+func (t Task) Validate(workflow Workflow) error {
 	if t.ID == "" {
-		return errors.New("task ID cannot be empty")
+		return errcode.New(errcode.InvalidTaskID, "task ID cannot be empty")
 	}
 	if t.Title == "" {
-		return errors.New("task title cannot be empty")
+		return errcode.New(errcode.ValidationError, "task title cannot be empty").WithDetails("title", t.Title)
 	}
 
-	validStatuses := map[string]bool{
-		"todo":        true,
-		"in_progress": true,
-		"done":        true,
+	if err := workflow.CheckStatus(t.Status); err != nil {
+		return err
 	}
-
-	if !validStatuses[t.Status] {
-		return fmt.Errorf("invalid status: %s", t.Status)
+	if err := ValidateNotes(t.Notes); err != nil {
+		return err
 	}
 
-	return nil
+	return t.validateMetadata()
+}
+
+// Equal reports whether two tasks have the same fields
+func (t Task) Equal(other Task) bool {
+	return t.ID == other.ID && t.Title == other.Title && t.Status == other.Status && t.Category == other.Category &&
+		t.Due == other.Due && t.Priority == other.Priority && t.Assignee == other.Assignee && slices.Equal(t.Tags, other.Tags) &&
+		t.Notes == other.Notes
 }
@@ -0,0 +1,170 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+const (
+	// DefaultIDPrefix is the prefix of task IDs (T001)
+	DefaultIDPrefix = "T"
+	// DefaultIDDigits is the number of digits in task IDs (T001)
+	DefaultIDDigits = 3
+	// MaxIDDigits bounds the number of digits so that IDs fit in an int
+	MaxIDDigits = 9
+)
+
+// Task ID modes
+const (
+	// IDModeSequential numbers tasks in order: T001, T002, ...
+	IDModeSequential = "sequential"
+	// IDModeTime gives tasks a time-sortable random ID such as T0lfls4k2,
+	// which branches and machines can allocate without coordination
+	IDModeTime = "time"
+)
+
+// Time IDs are the minutes since timeIDEpoch followed by a random
+// suffix, both in lower-case base 36 and padded so that they sort by time
+const (
+	timeIDClockChars  = 5
+	timeIDRandomChars = 3
+	timeIDLength      = timeIDClockChars + timeIDRandomChars
+	// timeIDRandomValues is 36^timeIDRandomChars
+	timeIDRandomValues = 36 * 36 * 36
+	// timeIDAttempts bounds the retries when a generated ID is taken
+	timeIDAttempts = 16
+)
+
+// timeIDEpoch is the start of the clock part of time IDs
+var timeIDEpoch = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// idPrefixRegex restricts prefixes to letters so that IDs stay unambiguous in Markdown
+var idPrefixRegex = regexp.MustCompile(`^[A-Za-z]+$`)
+
+// IDScheme describes how task IDs are formatted and allocated, e.g. T001.
+// Sequential IDs are recognized in every mode, so that a project can
+// switch to time IDs and keep its existing tasks.
+type IDScheme struct {
+	Prefix string `json:"prefix"`
+	// Mode is IDModeSequential or IDModeTime; empty means sequential
+	Mode   string `json:"mode,omitempty"`
+	Digits int    `json:"digits"`
+}
+
+// DefaultIDScheme is the T001 style used by default
+var DefaultIDScheme = IDScheme{Prefix: DefaultIDPrefix, Digits: DefaultIDDigits}
+
+// Validate validates the scheme fields
+func (s IDScheme) Validate() error {
+	if !idPrefixRegex.MatchString(s.Prefix) {
+		return errcode.New(errcode.ValidationError, "ID prefix must consist of letters: %q", s.Prefix).
+			WithDetails("prefix", s.Prefix)
+	}
+	if s.Digits < 1 || s.Digits > MaxIDDigits {
+		return errcode.New(errcode.ValidationError, "ID digits must be between 1 and %d: %d", MaxIDDigits, s.Digits).
+			WithDetails("digits", s.Digits)
+	}
+	switch s.Mode {
+	case "", IDModeSequential, IDModeTime:
+	default:
+		return errcode.New(errcode.ValidationError, "ID mode must be %q or %q: %q", IDModeSequential, IDModeTime, s.Mode).
+			WithDetails("mode", s.Mode)
+	}
+	return nil
+}
+
+// Format returns the ID with the given number
+func (s IDScheme) Format(n int) string {
+	return fmt.Sprintf("%s%0*d", s.Prefix, s.Digits, n)
+}
+
+// Parse returns the number of an ID in this scheme
+func (s IDScheme) Parse(id string) (int, bool) {
+	digits, ok := strings.CutPrefix(id, s.Prefix)
+	if !ok || len(digits) != s.Digits {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// Pattern returns an unanchored regular expression matching IDs in this scheme
+func (s IDScheme) Pattern() string {
+	if s.Mode == IDModeTime {
+		return fmt.Sprintf(`%s(?:\d{%d}|[0-9a-z]{%d})`, regexp.QuoteMeta(s.Prefix), s.Digits, timeIDLength)
+	}
+	return fmt.Sprintf(`%s\d{%d}`, regexp.QuoteMeta(s.Prefix), s.Digits)
+}
+
+// Max returns the largest number an ID can carry
+func (s IDScheme) Max() int {
+	maxNum := 1
+	for range s.Digits {
+		maxNum *= 10
+	}
+	return maxNum - 1
+}
+
+// Next returns a new ID. In time mode it is a time ID for the current
+// time not among existingIDs; otherwise it follows the highest existing
+// sequential ID, ignoring IDs in other formats.
+func (s IDScheme) Next(existingIDs []string) (string, error) {
+	if s.Mode == IDModeTime {
+		return s.nextTimeID(existingIDs, time.Now(), rand.Reader)
+	}
+	maxNum := 0
+	for _, id := range existingIDs {
+		if n, ok := s.Parse(id); ok && n > maxNum {
+			maxNum = n
+		}
+	}
+	if maxNum >= s.Max() {
+		return "", errcode.New(errcode.TaskLimitExceeded, "task limit of %d reached", s.Max())
+	}
+	return s.Format(maxNum + 1), nil
+}
+
+// TimeID returns the time ID for t with the given random value
+func (s IDScheme) TimeID(t time.Time, random uint32) string {
+	minutes := max(int64(t.Sub(timeIDEpoch)/time.Minute), 0)
+	clock := strconv.FormatInt(minutes, 36)
+	suffix := strconv.FormatInt(int64(random%timeIDRandomValues), 36)
+	return s.Prefix + pad(clock, timeIDClockChars) + pad(suffix, timeIDRandomChars)
+}
+
+// nextTimeID returns a time ID for now not among existingIDs, drawing the random part from r
+func (s IDScheme) nextTimeID(existingIDs []string, now time.Time, r io.Reader) (string, error) {
+	taken := make(map[string]bool, len(existingIDs))
+	for _, id := range existingIDs {
+		taken[id] = true
+	}
+	var buf [4]byte
+	for range timeIDAttempts {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return "", errcode.Wrap(err, errcode.Internal, "failed to generate a task ID")
+		}
+		if id := s.TimeID(now, binary.BigEndian.Uint32(buf[:])); !taken[id] {
+			return id, nil
+		}
+	}
+	return "", errcode.New(errcode.Internal, "failed to generate an unused task ID")
+}
+
+// pad left-pads s with zeros to width
+func pad(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return strings.Repeat("0", width-len(s)) + s
+}
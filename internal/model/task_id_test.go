@@ -0,0 +1,141 @@
+package model
+
+import (
+	"bytes"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+func TestIDScheme_Next(t *testing.T) {
+	tests := []struct {
+		name     string
+		scheme   IDScheme
+		existing []string
+		want     string
+		wantCode errcode.Code
+	}{
+		{"first task", DefaultIDScheme, nil, "T001", ""},
+		{"after highest", DefaultIDScheme, []string{"T001", "T005", "T003"}, "T006", ""},
+		{"ignores other formats", DefaultIDScheme, []string{"T001", "PRJ0009", "T12"}, "T002", ""},
+		{"custom scheme", IDScheme{Prefix: "PRJ", Digits: 4}, []string{"PRJ0041", "T999"}, "PRJ0042", ""},
+		{"limit reached", IDScheme{Prefix: "T", Digits: 2}, []string{"T99"}, "", errcode.TaskLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.scheme.Next(tt.existing)
+			if code := errcode.CodeOf(err); err != nil && code != tt.wantCode || err == nil && tt.wantCode != "" {
+				t.Fatalf("Next() error = %v, want code %q", err, tt.wantCode)
+			}
+			if got != tt.want {
+				t.Errorf("Next() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIDScheme_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  IDScheme
+		wantErr bool
+	}{
+		{"default", DefaultIDScheme, false},
+		{"long prefix", IDScheme{Prefix: "TASK", Digits: 5}, false},
+		{"empty prefix", IDScheme{Prefix: "", Digits: 3}, true},
+		{"prefix with digits", IDScheme{Prefix: "T1", Digits: 3}, true},
+		{"zero digits", IDScheme{Prefix: "T", Digits: 0}, true},
+		{"too many digits", IDScheme{Prefix: "T", Digits: MaxIDDigits + 1}, true},
+		{"time mode", IDScheme{Prefix: "T", Mode: IDModeTime, Digits: 3}, false},
+		{"unknown mode", IDScheme{Prefix: "T", Mode: "uuid", Digits: 3}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scheme.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestIDScheme_TimeID(t *testing.T) {
+	scheme := IDScheme{Prefix: "T", Mode: IDModeTime, Digits: 3}
+	tests := []struct {
+		name   string
+		t      time.Time
+		random uint32
+		want   string
+	}{
+		{"epoch", timeIDEpoch, 0, "T00000000"},
+		{"before epoch", timeIDEpoch.Add(-time.Hour), 1, "T00000001"},
+		{"later", time.Date(2026, time.October, 19, 12, 30, 45, 0, time.UTC), 46655 + 46656, "T0k9guzzz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheme.TimeID(tt.t, tt.random); got != tt.want {
+				t.Errorf("TimeID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// IDs sort by the minute they were created in
+	var ids []string
+	for minutes := range 5 {
+		ids = append(ids, scheme.TimeID(timeIDEpoch.Add(time.Duration(minutes*minutes*1000)*time.Minute), uint32(5-minutes)))
+	}
+	if !slices.IsSorted(ids) {
+		t.Errorf("time IDs are not sorted: %v", ids)
+	}
+}
+
+func TestIDScheme_NextTimeID(t *testing.T) {
+	scheme := IDScheme{Prefix: "T", Mode: IDModeTime, Digits: 3}
+	now := timeIDEpoch.Add(time.Minute)
+	random := []byte{0, 0, 0, 1, 0, 0, 0, 2}
+
+	got, err := scheme.nextTimeID([]string{"T00001001"}, now, bytes.NewReader(random))
+	if err != nil {
+		t.Fatalf("nextTimeID() error = %v", err)
+	}
+	if want := "T00001002"; got != want {
+		t.Errorf("nextTimeID() = %q, want %q skipping the taken ID", got, want)
+	}
+
+	if _, err := scheme.nextTimeID([]string{"T00001001"}, now, bytes.NewReader(random[:4])); err == nil {
+		t.Error("nextTimeID() with no unused IDs succeeded")
+	}
+
+	id, err := scheme.Next([]string{"T001"})
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if !regexp.MustCompile(`^`+scheme.Pattern()+`$`).MatchString(id) || len(id) != 1+timeIDLength {
+		t.Errorf("Next() = %q, not a time ID", id)
+	}
+}
+
+func TestIDScheme_Pattern(t *testing.T) {
+	tests := []struct {
+		scheme IDScheme
+		id     string
+		want   bool
+	}{
+		{DefaultIDScheme, "T001", true},
+		{DefaultIDScheme, "T0lfls4k2", false},
+		{IDScheme{Prefix: "T", Mode: IDModeTime, Digits: 3}, "T001", true},
+		{IDScheme{Prefix: "T", Mode: IDModeTime, Digits: 3}, "T0lfls4k2", true},
+		{IDScheme{Prefix: "T", Mode: IDModeTime, Digits: 3}, "T0LFLS4K2", false},
+	}
+	for _, tt := range tests {
+		re := regexp.MustCompile(`^` + tt.scheme.Pattern() + `$`)
+		if got := re.MatchString(tt.id); got != tt.want {
+			t.Errorf("%+v Pattern() matches %q = %v, want %v", tt.scheme, tt.id, got, tt.want)
+		}
+	}
+}
@@ -31,6 +31,8 @@ func TestTaskStatusValidation(t *testing.T) {
 		{"valid todo", "todo", false},
 		{"valid in_progress", "in_progress", false},
 		{"valid done", "done", false},
+		{"valid blocked", "blocked", false},
+		{"valid cancelled", "cancelled", false},
 		{"invalid status", "invalid", true},
 	}
 
@@ -41,7 +43,7 @@ func TestTaskStatusValidation(t *testing.T) {
 				Title:  "Test Task",
 				Status: tt.status,
 			}
-			err := task.Validate()
+			err := task.Validate(DefaultWorkflow)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Task.Validate() error = %v, wantErr %v", err, tt.wantErr)
 			}
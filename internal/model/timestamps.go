@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// TimestampFormat is the layout of task timestamps
+const TimestampFormat = time.RFC3339
+
+// Timestamps records when a task was created, started and completed.
+// Empty fields are not known: tasks created before timestamps were
+// recorded have none, and tasks completed without being in progress have
+// no start.
+type Timestamps struct {
+	CreatedAt   string `json:"created_at,omitempty"`
+	StartedAt   string `json:"started_at,omitempty"`
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+// Transition updates the timestamps for a status change at now and reports
+// whether they changed. The first move to an active status that needs no
+// reason sets StartedAt, a move into the done statuses sets CompletedAt and
+// a move out of them clears it.
+func (t *Timestamps) Transition(workflow Workflow, from, to string, now time.Time) bool {
+	before := *t
+	switch {
+	case from == to:
+		return false
+	case workflow.Stage(to) == StageActive && !workflow.RequiresReason(to) && t.StartedAt == "":
+		t.StartedAt = now.Format(TimestampFormat)
+	case workflow.IsDone(to) && !workflow.IsDone(from):
+		t.CompletedAt = now.Format(TimestampFormat)
+	}
+	if workflow.IsDone(from) && !workflow.IsDone(to) {
+		t.CompletedAt = ""
+	}
+	return *t != before
+}
+
+// IsZero reports whether no timestamp is known
+func (t Timestamps) IsZero() bool {
+	return t == Timestamps{}
+}
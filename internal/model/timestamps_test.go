@@ -0,0 +1,57 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTimestamps_Transition(t *testing.T) {
+	now := time.Date(2026, 10, 19, 9, 30, 0, 0, time.UTC)
+	const (
+		earlier = "2026-10-01T08:00:00Z"
+		stamp   = "2026-10-19T09:30:00Z"
+	)
+
+	tests := []struct {
+		name        string
+		times       Timestamps
+		from, to    string
+		want        Timestamps
+		wantChanged bool
+	}{
+		{"start", Timestamps{CreatedAt: earlier}, "todo", "in_progress", Timestamps{CreatedAt: earlier, StartedAt: stamp}, true},
+		{
+			"restart keeps the first start",
+			Timestamps{StartedAt: earlier}, "todo", "in_progress",
+			Timestamps{StartedAt: earlier}, false,
+		},
+		{"complete", Timestamps{StartedAt: earlier}, "in_progress", "done", Timestamps{StartedAt: earlier, CompletedAt: stamp}, true},
+		{"complete without start", Timestamps{}, "todo", "done", Timestamps{CompletedAt: stamp}, true},
+		{
+			"reopen clears completion",
+			Timestamps{StartedAt: earlier, CompletedAt: earlier}, "done", "in_progress",
+			Timestamps{StartedAt: earlier}, true,
+		},
+		{"reopen to todo", Timestamps{CompletedAt: earlier}, "done", "todo", Timestamps{}, true},
+		{"back to todo", Timestamps{StartedAt: earlier}, "in_progress", "todo", Timestamps{StartedAt: earlier}, false},
+		{"unchanged status", Timestamps{}, "done", "done", Timestamps{}, false},
+		{"blocking does not start", Timestamps{}, "todo", "blocked", Timestamps{}, false},
+		{"cancel completes", Timestamps{StartedAt: earlier}, "blocked", "cancelled", Timestamps{StartedAt: earlier, CompletedAt: stamp}, true},
+		{"done to cancelled keeps completion", Timestamps{CompletedAt: earlier}, "done", "cancelled", Timestamps{CompletedAt: earlier}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.times
+			changed := got.Transition(DefaultWorkflow, tt.from, tt.to, now)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Transition() mismatch (-want +got):\n%s", diff)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("Transition() = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+}
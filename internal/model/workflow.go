@@ -0,0 +1,227 @@
+package model
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// Statuses of the default workflow
+const (
+	StatusTodo       = "todo"
+	StatusInProgress = "in_progress"
+	StatusDone       = "done"
+	StatusBlocked    = "blocked"
+	StatusCancelled  = "cancelled"
+)
+
+// Stages group the statuses of a workflow by how far along a task is
+const (
+	// StageOpen is the initial status, which new tasks start in
+	StageOpen = iota
+	// StageActive holds the statuses that are neither initial nor done
+	StageActive
+	// StageDone holds the statuses in which a task counts as done
+	StageDone
+)
+
+// statusNameRegex restricts status names so that they read as single words in Markdown and messages
+var statusNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Status is one state of a workflow
+type Status struct {
+	Name string `json:"name"`
+	// Marker is the character between the brackets of the task.md checkbox, e.g. "x" for [x]
+	Marker string `json:"marker"`
+	// Done marks statuses in which a task counts as done
+	Done bool `json:"done,omitempty"`
+	// Reason marks statuses a task moves to only with a reason
+	Reason bool `json:"reason,omitempty"`
+}
+
+// Workflow defines the statuses of tasks, how they are written in task.md
+// and which moves between them are allowed. The first status is the
+// status of new tasks.
+type Workflow struct {
+	Statuses []Status `json:"statuses"`
+	// Transitions lists the statuses each status may move to. Statuses
+	// without an entry may move to any status.
+	Transitions map[string][]string `json:"transitions,omitempty"`
+	// Rollup selects the rules moving tasks along with their subtasks
+	Rollup Rollup `json:"rollup"`
+}
+
+// DefaultWorkflow is the workflow used unless a project configures one
+var DefaultWorkflow = Workflow{
+	Statuses: []Status{
+		{Name: StatusTodo, Marker: " "},
+		{Name: StatusInProgress, Marker: "-"},
+		{Name: StatusDone, Marker: "x", Done: true},
+		{Name: StatusBlocked, Marker: "!", Reason: true},
+		{Name: StatusCancelled, Marker: "~", Done: true, Reason: true},
+	},
+}
+
+// Validate validates the workflow definition
+func (w Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return errcode.New(errcode.ValidationError, "workflow must have at least one status")
+	}
+	names := make(map[string]bool, len(w.Statuses))
+	markers := make(map[string]string, len(w.Statuses))
+	hasDone := false
+	for _, s := range w.Statuses {
+		if !statusNameRegex.MatchString(s.Name) {
+			return errcode.New(errcode.ValidationError, "status name must be lower-case letters, digits and underscores: %q", s.Name).
+				WithDetails("status", s.Name)
+		}
+		if names[s.Name] {
+			return errcode.New(errcode.ValidationError, "duplicate status %s", s.Name).WithDetails("status", s.Name)
+		}
+		names[s.Name] = true
+		if utf8.RuneCountInString(s.Marker) != 1 || strings.ContainsAny(s.Marker, "[]\t\r\n") {
+			return errcode.New(errcode.ValidationError, "marker of status %s must be a single character other than brackets: %q", s.Name, s.Marker).
+				WithDetails("status", s.Name)
+		}
+		if other, ok := markers[s.Marker]; ok {
+			return errcode.New(errcode.ValidationError, "statuses %s and %s have the same marker [%s]", other, s.Name, s.Marker).
+				WithDetails("status", s.Name)
+		}
+		markers[s.Marker] = s.Name
+		hasDone = hasDone || s.Done
+	}
+	if initial := w.Statuses[0]; initial.Done || initial.Reason {
+		return errcode.New(errcode.ValidationError, "initial status %s cannot be done or require a reason", initial.Name).
+			WithDetails("status", initial.Name)
+	}
+	if !hasDone {
+		return errcode.New(errcode.ValidationError, "workflow must have a done status")
+	}
+	for from, targets := range w.Transitions {
+		for _, name := range append([]string{from}, targets...) {
+			if !names[name] {
+				return errcode.New(errcode.ValidationError, "transition from %s refers to unknown status %q", from, name).
+					WithDetails("status", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the statuses in order
+func (w Workflow) Names() []string {
+	names := make([]string, len(w.Statuses))
+	for i, s := range w.Statuses {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Lookup returns the status called name
+func (w Workflow) Lookup(name string) (Status, bool) {
+	i := slices.IndexFunc(w.Statuses, func(s Status) bool { return s.Name == name })
+	if i < 0 {
+		return Status{}, false
+	}
+	return w.Statuses[i], true
+}
+
+// Initial returns the status of new tasks
+func (w Workflow) Initial() string {
+	return w.Statuses[0].Name
+}
+
+// IsDone reports whether a task in status counts as done
+func (w Workflow) IsDone(status string) bool {
+	s, _ := w.Lookup(status)
+	return s.Done
+}
+
+// RequiresReason reports whether a task moving to status needs a reason
+func (w Workflow) RequiresReason(status string) bool {
+	s, _ := w.Lookup(status)
+	return s.Reason
+}
+
+// Stage returns StageOpen, StageActive or StageDone for status
+func (w Workflow) Stage(status string) int {
+	switch {
+	case status == w.Initial():
+		return StageOpen
+	case w.IsDone(status):
+		return StageDone
+	default:
+		return StageActive
+	}
+}
+
+// StageStatus returns the status tasks are moved to when they reach stage:
+// the initial status, or the first status of the stage that needs no
+// reason. It returns an empty string if the workflow has no such status.
+func (w Workflow) StageStatus(stage int) string {
+	for _, s := range w.Statuses {
+		if w.Stage(s.Name) == stage && !s.Reason {
+			return s.Name
+		}
+	}
+	return ""
+}
+
+// Checkbox returns the task.md checkbox of status, e.g. "[x]". Unknown
+// statuses are written as the initial status.
+func (w Workflow) Checkbox(status string) string {
+	s, ok := w.Lookup(status)
+	if !ok {
+		s = w.Statuses[0]
+	}
+	return "[" + s.Marker + "]"
+}
+
+// ParseCheckbox returns the status of a task.md checkbox such as "[x]".
+// A marker no status uses exactly matches one differing only in case, as
+// in "[X]"; other unknown markers are read as the initial status.
+func (w Workflow) ParseCheckbox(checkbox string) string {
+	marker := strings.TrimSuffix(strings.TrimPrefix(checkbox, "["), "]")
+	for _, s := range w.Statuses {
+		if s.Marker == marker {
+			return s.Name
+		}
+	}
+	for _, s := range w.Statuses {
+		if strings.EqualFold(s.Marker, marker) {
+			return s.Name
+		}
+	}
+	return w.Initial()
+}
+
+// CheckStatus returns an InvalidStatus error unless status is part of the workflow
+func (w Workflow) CheckStatus(status string) error {
+	if _, ok := w.Lookup(status); !ok {
+		return errcode.New(errcode.InvalidStatus, "invalid status: %s (statuses: %s)", status, strings.Join(w.Names(), ", ")).
+			WithDetails("status", status)
+	}
+	return nil
+}
+
+// CanTransition reports whether a task may move from one status to another
+func (w Workflow) CanTransition(from, to string) bool {
+	targets, ok := w.Transitions[from]
+	return from == to || !ok || slices.Contains(targets, to)
+}
+
+// CheckTransition returns an InvalidStatus error unless a task may move from one status to another
+func (w Workflow) CheckTransition(from, to string) error {
+	if !w.CanTransition(from, to) {
+		allowed := strings.Join(w.Transitions[from], ", ")
+		if allowed == "" {
+			allowed = "none"
+		}
+		return errcode.New(errcode.InvalidStatus, "cannot move from %s to %s (allowed: %s)", from, to, allowed).
+			WithDetails("status", to)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// reviewWorkflow is a workflow with a review step before done
+var reviewWorkflow = Workflow{
+	Statuses: []Status{
+		{Name: "todo", Marker: " "},
+		{Name: "in_progress", Marker: "-"},
+		{Name: "review", Marker: "r"},
+		{Name: "deployed", Marker: "x", Done: true},
+	},
+	Transitions: map[string][]string{
+		"todo":        {"in_progress"},
+		"in_progress": {"review", "todo"},
+		"review":      {"deployed", "in_progress"},
+	},
+}
+
+func TestWorkflow_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow Workflow
+		wantErr  bool
+	}{
+		{"default", DefaultWorkflow, false},
+		{"review", reviewWorkflow, false},
+		{"empty", Workflow{}, true},
+		{"upper-case name", Workflow{Statuses: []Status{{Name: "Todo", Marker: " "}, {Name: "done", Marker: "x", Done: true}}}, true},
+		{"duplicate name", Workflow{Statuses: []Status{{Name: "todo", Marker: " "}, {Name: "todo", Marker: "x", Done: true}}}, true},
+		{"long marker", Workflow{Statuses: []Status{{Name: "todo", Marker: "  "}, {Name: "done", Marker: "x", Done: true}}}, true},
+		{"bracket marker", Workflow{Statuses: []Status{{Name: "todo", Marker: " "}, {Name: "done", Marker: "]", Done: true}}}, true},
+		{"duplicate marker", Workflow{Statuses: []Status{{Name: "todo", Marker: " "}, {Name: "done", Marker: " ", Done: true}}}, true},
+		{"no done status", Workflow{Statuses: []Status{{Name: "todo", Marker: " "}, {Name: "doing", Marker: "-"}}}, true},
+		{"done initial status", Workflow{Statuses: []Status{{Name: "done", Marker: "x", Done: true}}}, true},
+		{
+			"unknown transition target",
+			Workflow{Statuses: reviewWorkflow.Statuses, Transitions: map[string][]string{"todo": {"qa"}}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.workflow.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWorkflow_Checkbox(t *testing.T) {
+	tests := []struct {
+		status   string
+		checkbox string
+	}{
+		{"todo", "[ ]"},
+		{"in_progress", "[-]"},
+		{"review", "[r]"},
+		{"deployed", "[x]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := reviewWorkflow.Checkbox(tt.status); got != tt.checkbox {
+				t.Errorf("Checkbox(%q) = %q, want %q", tt.status, got, tt.checkbox)
+			}
+			if got := reviewWorkflow.ParseCheckbox(tt.checkbox); got != tt.status {
+				t.Errorf("ParseCheckbox(%q) = %q, want %q", tt.checkbox, got, tt.status)
+			}
+		})
+	}
+
+	if got := reviewWorkflow.ParseCheckbox("[X]"); got != "deployed" {
+		t.Errorf("ParseCheckbox() of an upper-case marker = %q, want deployed", got)
+	}
+	if got := reviewWorkflow.ParseCheckbox("[!]"); got != "todo" {
+		t.Errorf("ParseCheckbox() of an unknown marker = %q, want the initial status", got)
+	}
+	if got := reviewWorkflow.Checkbox("blocked"); got != "[ ]" {
+		t.Errorf("Checkbox() of an unknown status = %q, want the initial status", got)
+	}
+}
+
+func TestWorkflow_Stages(t *testing.T) {
+	tests := []struct {
+		workflow Workflow
+		status   string
+		want     int
+	}{
+		{DefaultWorkflow, "todo", StageOpen},
+		{DefaultWorkflow, "in_progress", StageActive},
+		{DefaultWorkflow, "blocked", StageActive},
+		{DefaultWorkflow, "done", StageDone},
+		{DefaultWorkflow, "cancelled", StageDone},
+		{reviewWorkflow, "review", StageActive},
+		{reviewWorkflow, "deployed", StageDone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := tt.workflow.Stage(tt.status); got != tt.want {
+				t.Errorf("Stage(%q) = %d, want %d", tt.status, got, tt.want)
+			}
+		})
+	}
+
+	for stage, want := range []string{"todo", "in_progress", "done"} {
+		if got := DefaultWorkflow.StageStatus(stage); got != want {
+			t.Errorf("StageStatus(%d) = %q, want %q", stage, got, want)
+		}
+	}
+	if got := reviewWorkflow.StageStatus(StageDone); got != "deployed" {
+		t.Errorf("StageStatus(StageDone) = %q, want deployed", got)
+	}
+}
+
+func TestWorkflow_CheckTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow Workflow
+		from, to string
+		wantErr  bool
+	}{
+		{"default allows any move", DefaultWorkflow, "done", "todo", false},
+		{"allowed", reviewWorkflow, "in_progress", "review", false},
+		{"unchanged", reviewWorkflow, "deployed", "deployed", false},
+		{"skipping review", reviewWorkflow, "in_progress", "deployed", true},
+		{"status without rules", reviewWorkflow, "deployed", "todo", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.workflow.CheckTransition(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckTransition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && errcode.CodeOf(err) != errcode.InvalidStatus {
+				t.Errorf("CheckTransition() code = %s, want %s", errcode.CodeOf(err), errcode.InvalidStatus)
+			}
+		})
+	}
+
+	if err := reviewWorkflow.CheckStatus("blocked"); errcode.CodeOf(err) != errcode.InvalidStatus {
+		t.Errorf("CheckStatus() of an unknown status error = %v, want %s", err, errcode.InvalidStatus)
+	}
+}
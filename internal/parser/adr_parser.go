@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+// ADR section names, as written by storage
+const (
+	ADRSectionStatus       = "Status"
+	ADRSectionDate         = "Date"
+	ADRSectionContext      = "Context"
+	ADRSectionDecision     = "Decision"
+	ADRSectionRationale    = "Rationale"
+	ADRSectionConsequences = "Consequences"
+	ADRSectionHistory      = "Status History"
+)
+
+// adrTitleRegex matches the ADR heading (# ADR-001: Title)
+var adrTitleRegex = regexp.MustCompile(`^#\s+ADR-(\d+):\s*(.*)$`)
+
+// ParseADR parses an ADR file. Unknown sections are ignored.
+func ParseADR(content string) (model.ADR, error) {
+	var adr model.ADR
+	sections := make(map[string]*strings.Builder)
+	var current *strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if matches := adrTitleRegex.FindStringSubmatch(line); matches != nil && adr.Title == "" {
+			adr.Number, _ = strconv.Atoi(matches[1])
+			adr.Title = strings.TrimSpace(matches[2])
+			current = nil
+			continue
+		}
+		if matches := categoryRegex.FindStringSubmatch(line); matches != nil {
+			current = &strings.Builder{}
+			sections[strings.TrimSpace(matches[1])] = current
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+			current.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return model.ADR{}, errcode.Wrap(err, errcode.ParseError, "failed to parse ADR content")
+	}
+
+	section := func(name string) string {
+		if sb, ok := sections[name]; ok {
+			return strings.TrimSpace(sb.String())
+		}
+		return ""
+	}
+	adr.Status = section(ADRSectionStatus)
+	adr.Date = section(ADRSectionDate)
+	adr.Context = section(ADRSectionContext)
+	adr.Decision = section(ADRSectionDecision)
+	adr.Rationale = section(ADRSectionRationale)
+	adr.Consequences = section(ADRSectionConsequences)
+	for _, line := range strings.Split(section(ADRSectionHistory), "\n") {
+		if item, ok := strings.CutPrefix(line, "- "); ok {
+			adr.History = append(adr.History, item)
+		}
+	}
+
+	return adr, nil
+}
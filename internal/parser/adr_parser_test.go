@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+func TestParseADR(t *testing.T) {
+	content := `# ADR-002: Use SQLite for storage
+
+## Status
+Accepted
+
+## Date
+2026-10-19
+
+## Context
+We need an embedded database.
+
+## Decision
+Use SQLite.
+
+## Rationale
+No server to run.
+Well known.
+
+## Consequences
+Single writer.
+
+## Notes
+Ignored by the parser.
+
+## Status History
+- 2026-10-20: Proposed → Accepted: reviewed by the team
+`
+	want := model.ADR{
+		Number:       2,
+		Title:        "Use SQLite for storage",
+		Status:       "Accepted",
+		Date:         "2026-10-19",
+		Context:      "We need an embedded database.",
+		Decision:     "Use SQLite.",
+		Rationale:    "No server to run.\nWell known.",
+		Consequences: "Single writer.",
+		History:      []string{"2026-10-20: Proposed → Accepted: reviewed by the team"},
+	}
+
+	got, err := ParseADR(content)
+	if err != nil {
+		t.Fatalf("ParseADR() error = %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseADR() mismatch (-want +got):\n%s", diff)
+	}
+}
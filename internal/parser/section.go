@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"strings"
+)
+
+// SetSection sets the body of the "## name" section of a Markdown document.
+// With appendBody the text is added after the existing body, otherwise it
+// replaces it. A missing section is added at the end of the document.
+func SetSection(content, name, text string, appendBody bool) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	text = strings.TrimRight(text, "\n")
+
+	start := -1
+	for i, line := range lines {
+		if matches := categoryRegex.FindStringSubmatch(strings.TrimRight(line, " \t\r")); matches != nil && strings.TrimSpace(matches[1]) == name {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return AppendText(content, "## "+name+"\n"+text)
+	}
+
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "## ") || strings.HasPrefix(lines[i], "# ") {
+			end = i
+			break
+		}
+	}
+
+	body := strings.Trim(strings.Join(lines[start+1:end], "\n"), "\n")
+	if appendBody && body != "" {
+		body += "\n" + text
+	} else {
+		body = text
+	}
+
+	var sb strings.Builder
+	for _, line := range lines[:start+1] {
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString(body + "\n")
+	if end < len(lines) {
+		sb.WriteString("\n")
+		for _, line := range lines[end:] {
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// AppendText adds a paragraph at the end of a Markdown document
+func AppendText(content, text string) string {
+	content = strings.TrimRight(content, "\n")
+	text = strings.TrimRight(text, "\n")
+	if content == "" {
+		return text + "\n"
+	}
+	return content + "\n\n" + text + "\n"
+}
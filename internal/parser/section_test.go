@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSetSection(t *testing.T) {
+	doc := "# Context for T001\n\n## Task Description\nFirst line\n\n## Created\n2026-10-19\n"
+
+	tests := []struct {
+		name       string
+		section    string
+		text       string
+		appendBody bool
+		want       string
+	}{
+		{
+			name:       "append to a section in the middle",
+			section:    "Task Description",
+			text:       "Second line",
+			appendBody: true,
+			want:       "# Context for T001\n\n## Task Description\nFirst line\nSecond line\n\n## Created\n2026-10-19\n",
+		},
+		{
+			name:    "replace a section",
+			section: "Task Description",
+			text:    "Rewritten",
+			want:    "# Context for T001\n\n## Task Description\nRewritten\n\n## Created\n2026-10-19\n",
+		},
+		{
+			name:       "append to the last section",
+			section:    "Created",
+			text:       "Moved to backlog",
+			appendBody: true,
+			want:       "# Context for T001\n\n## Task Description\nFirst line\n\n## Created\n2026-10-19\nMoved to backlog\n",
+		},
+		{
+			name:       "add a missing section",
+			section:    "Notes",
+			text:       "Remember the cache",
+			appendBody: true,
+			want:       "# Context for T001\n\n## Task Description\nFirst line\n\n## Created\n2026-10-19\n\n## Notes\nRemember the cache\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SetSection(doc, tt.section, tt.text, tt.appendBody)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("SetSection() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAppendText(t *testing.T) {
+	if got, want := AppendText("# Doc\n\n\n", "More"), "# Doc\n\nMore\n"; got != want {
+		t.Errorf("AppendText() = %q, want %q", got, want)
+	}
+	if got, want := AppendText("", "Only"), "Only\n"; got != want {
+		t.Errorf("AppendText() = %q, want %q", got, want)
+	}
+}
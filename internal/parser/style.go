@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+// bom is the UTF-8 byte order mark some editors write at the start of a file
+const bom = "\uFEFF"
+
+// tabWidth is the number of columns a tab advances indentation to the next multiple of
+const tabWidth = 4
+
+// Style records the Markdown conventions of a task.md file, so that it is
+// written back the way it was read
+type Style struct {
+	// Bullet is the list marker: "-", "*" or "+", or "1." or "1)" for
+	// numbered lists, which are renumbered when written
+	Bullet string
+	// Indent is one level of subtask indentation, e.g. two spaces or a tab
+	Indent string
+	// UpperMarkers writes letter markers in upper case, as in [X]
+	UpperMarkers bool
+	// LineEnding is "\n" or "\r\n"
+	LineEnding string
+	// BOM starts the file with a UTF-8 byte order mark
+	BOM bool
+}
+
+// DefaultStyle is the style of files written from scratch
+var DefaultStyle = Style{Bullet: "-", Indent: "  ", LineEnding: "\n"}
+
+// ListItem returns the list marker of the n-th (1-based) item of a list
+func (s Style) ListItem(n int) string {
+	if delim, ok := strings.CutPrefix(s.Bullet, "1"); ok {
+		return strconv.Itoa(n) + delim
+	}
+	return s.Bullet
+}
+
+// Checkbox returns the checkbox of status in workflow, e.g. "[x]", with the
+// marker in upper case if the style asks for it and no other status uses
+// the upper-case marker
+func (s Style) Checkbox(workflow model.Workflow, status string) string {
+	checkbox := workflow.Checkbox(status)
+	if !s.UpperMarkers {
+		return checkbox
+	}
+	upper := strings.ToUpper(checkbox)
+	if upper != checkbox && workflow.ParseCheckbox(upper) == status {
+		return upper
+	}
+	return checkbox
+}
+
+// Finish converts content written with "\n" line endings to the style's
+// line endings and byte order mark
+func (s Style) Finish(content string) string {
+	if s.LineEnding == "\r\n" {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	if s.BOM {
+		content = bom + content
+	}
+	return content
+}
+
+// styleRecorder builds the style of a file from its first lines of each kind
+type styleRecorder struct {
+	style                              Style
+	seenBullet, seenIndent, seenMarker bool
+}
+
+// bullet records the list marker of a task line
+func (r *styleRecorder) bullet(marker string) {
+	if r.seenBullet {
+		return
+	}
+	r.seenBullet = true
+	if marker[0] >= '0' && marker[0] <= '9' {
+		marker = "1" + marker[len(marker)-1:]
+	}
+	r.style.Bullet = marker
+}
+
+// indent records the indentation of a subtask nested directly under a task
+func (r *styleRecorder) indent(whitespace string) {
+	if r.seenIndent {
+		return
+	}
+	r.seenIndent = true
+	if strings.HasPrefix(whitespace, "\t") {
+		r.style.Indent = "\t"
+		return
+	}
+	r.style.Indent = whitespace
+}
+
+// marker records whether a letter checkbox marker is written in upper case
+func (r *styleRecorder) marker(marker string) {
+	if r.seenMarker {
+		return
+	}
+	for _, c := range marker {
+		if unicode.IsLetter(c) {
+			r.seenMarker = true
+			r.style.UpperMarkers = unicode.IsUpper(c)
+		}
+	}
+}
+
+// indentWidth returns the number of columns of leading whitespace, with
+// tabs advancing to the next multiple of tabWidth
+func indentWidth(whitespace string) int {
+	width := 0
+	for _, c := range whitespace {
+		if c == '\t' {
+			width += tabWidth - width%tabWidth
+		} else {
+			width++
+		}
+	}
+	return width
+}
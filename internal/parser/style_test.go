@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+func TestParseWithStyle_Dialects(t *testing.T) {
+	content := bom + "# Task\r\n\r\n## Ops\r\n" +
+		"* [X] Deploy #T001\r\n" +
+		"\t+ [ ] Build image\r\n" +
+		"\t\t1) [x] Pin base image\r\n" +
+		"\tNote under the task\r\n" +
+		"+ [-] Monitor #T002\r\n" +
+		"1. [ ] Rotate keys #T003\r\n"
+
+	tasks, style, err := defaultParser.ParseWithStyle(content)
+	if err != nil {
+		t.Fatalf("ParseWithStyle() error = %v", err)
+	}
+	want := []ParsedTask{
+		{
+			Task: model.Task{ID: "T001", Title: "Deploy", Status: "done", Category: "Ops", Notes: "Note under the task"},
+			SubTasks: []model.SubTask{
+				{Task: model.Task{Title: "Build image", Status: "todo"}, SubTasks: []model.SubTask{model.NewSubTask("Pin base image", "done")}},
+			},
+		},
+		{Task: model.Task{ID: "T002", Title: "Monitor", Status: "in_progress", Category: "Ops"}, SubTasks: []model.SubTask{}},
+		{Task: model.Task{ID: "T003", Title: "Rotate keys", Status: "todo", Category: "Ops"}, SubTasks: []model.SubTask{}},
+	}
+	if diff := cmp.Diff(want, tasks); diff != "" {
+		t.Errorf("ParseWithStyle() tasks mismatch (-want +got):\n%s", diff)
+	}
+	wantStyle := Style{Bullet: "*", Indent: "\t", UpperMarkers: true, LineEnding: "\r\n", BOM: true}
+	if diff := cmp.Diff(wantStyle, style); diff != "" {
+		t.Errorf("ParseWithStyle() style mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseWithStyle_Style(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Style
+	}{
+		{"empty", "", DefaultStyle},
+		{"default", "## A\n- [x] a #T001\n  - [ ] b\n", DefaultStyle},
+		{"numbered", "## A\n1) [ ] a #T001\n    1) [ ] b\n", Style{Bullet: "1)", Indent: "    ", LineEnding: "\n"}},
+		{"lower-case marker first", "## A\n- [x] a #T001\n- [X] b #T002\n", DefaultStyle},
+		{"upper-case marker on a subtask", "## A\n- [ ] a #T001\n  - [X] b\n", Style{Bullet: "-", Indent: "  ", UpperMarkers: true, LineEnding: "\n"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, err := defaultParser.ParseWithStyle(tt.content)
+			if err != nil {
+				t.Fatalf("ParseWithStyle() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseWithStyle() style mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStyle_ListItem(t *testing.T) {
+	if got := (Style{Bullet: "*"}).ListItem(3); got != "*" {
+		t.Errorf("ListItem() = %q, want *", got)
+	}
+	if got := (Style{Bullet: "1."}).ListItem(12); got != "12." {
+		t.Errorf("ListItem() = %q, want 12.", got)
+	}
+}
@@ -5,35 +5,71 @@ package parser
 import (
 	"bufio"
 	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
 	"github.com/jnst/agentic-todo-mcp/internal/model"
 )
 
-const (
-	// DefaultTaskStatus is the default status for tasks
-	DefaultTaskStatus = "todo"
-)
-
 // ParsedTask represents a main task with its subtasks
 type ParsedTask struct {
 	Task     model.Task
-	SubTasks []model.Task
+	SubTasks []model.SubTask
 }
 
 var (
-	// Regular expressions for parsing
+	// Regular expressions for parsing. List items may use -, * or + bullets
+	// or be numbered as in 1. or 1).
 	categoryRegex = regexp.MustCompile(`^##\s+(.+)$`)
-	taskRegex     = regexp.MustCompile(`^-\s+\[(.)\]\s+(.+)$`)
-	subTaskRegex  = regexp.MustCompile(`^\s+-\s+\[(.)\]\s+(.+)$`)
-	taskIDRegex   = regexp.MustCompile(`#(T\d{3})`)
+	taskRegex     = regexp.MustCompile(`^([-*+]|\d+[.)])\s+\[(.)\]\s+(.+)$`)
+	subTaskRegex  = regexp.MustCompile(`^(\s+)([-*+]|\d+[.)])\s+\[(.)\]\s+(.+)$`)
+
+	// defaultParser parses task IDs in the default T001 format and statuses of the default workflow
+	defaultParser = NewParser(model.DefaultIDScheme, model.DefaultWorkflow)
 )
 
-// ParseTaskContent parses markdown content and returns parsed tasks
+// Parser parses task.md content for a given task ID scheme and workflow
+type Parser struct {
+	taskIDRegex *regexp.Regexp
+	workflow    model.Workflow
+}
+
+// NewParser creates a Parser recognizing task IDs in the given scheme and
+// checkboxes of the given workflow
+func NewParser(scheme model.IDScheme, workflow model.Workflow) *Parser {
+	return &Parser{
+		taskIDRegex: regexp.MustCompile(`#(` + scheme.Pattern() + `)\b`),
+		workflow:    workflow,
+	}
+}
+
+// ParseTaskContent parses markdown content with the default task ID scheme
 func ParseTaskContent(content string) ([]ParsedTask, error) {
+	return defaultParser.Parse(content)
+}
+
+// Parse parses markdown content and returns parsed tasks
+func (p *Parser) Parse(content string) ([]ParsedTask, error) {
+	tasks, _, err := p.ParseWithStyle(content)
+	return tasks, err
+}
+
+// ParseWithStyle parses markdown content and returns parsed tasks with the
+// style the content is written in. It accepts -, * and + bullets, numbered
+// lists, markers in either case, tab indentation, CRLF line endings and a
+// byte order mark.
+func (p *Parser) ParseWithStyle(content string) ([]ParsedTask, Style, error) {
 	var result []ParsedTask
 	var currentCategory string
 	var currentMainTask *ParsedTask
+	var levels []subtaskLevel
+
+	recorder := styleRecorder{style: DefaultStyle}
+	if strings.Contains(content, "\r\n") {
+		recorder.style.LineEnding = "\r\n"
+	}
+	content, recorder.style.BOM = strings.CutPrefix(content, bom)
 
 	scanner := bufio.NewScanner(strings.NewReader(content))
 	for scanner.Scan() {
@@ -52,13 +88,26 @@ func ParseTaskContent(content string) ([]ParsedTask, error) {
 
 		// Parse main task (- [x] task #T001)
 		if matches := taskRegex.FindStringSubmatch(line); matches != nil {
-			currentMainTask = parseMainTask(matches, currentCategory, &result, currentMainTask)
+			currentMainTask = p.parseMainTask(matches, currentCategory, &result, currentMainTask)
+			levels = nil
+			recorder.bullet(matches[1])
+			recorder.marker(matches[2])
 			continue
 		}
 
-		// Parse subtask (  - [x] subtask)
+		// Parse subtask (  - [x] subtask), nested by indentation
 		if matches := subTaskRegex.FindStringSubmatch(line); matches != nil && currentMainTask != nil {
-			parseSubTask(matches, currentMainTask)
+			levels = p.parseSubTask(matches, currentMainTask, levels)
+			if len(levels) == 1 {
+				recorder.indent(matches[1])
+			}
+			recorder.marker(matches[3])
+			continue
+		}
+
+		// Parse notes (other indented lines) of the task or subtask above
+		if currentMainTask != nil && strings.TrimLeft(line, " \t") != line {
+			p.parseNote(line, currentMainTask, levels)
 			continue
 		}
 	}
@@ -68,72 +117,141 @@ func ParseTaskContent(content string) ([]ParsedTask, error) {
 		result = append(result, *currentMainTask)
 	}
 
-	return result, scanner.Err()
+	if err := scanner.Err(); err != nil {
+		return nil, DefaultStyle, errcode.Wrap(err, errcode.ParseError, "failed to parse task content")
+	}
+
+	return result, recorder.style, nil
+}
+
+// ParseCategories returns the category headers of markdown content in
+// order of first appearance, including those without tasks
+func ParseCategories(content string) []string {
+	var categories []string
+	for _, line := range strings.Split(strings.TrimPrefix(content, bom), "\n") {
+		matches := categoryRegex.FindStringSubmatch(strings.TrimRight(line, " \t\r"))
+		if matches == nil {
+			continue
+		}
+		if category := strings.TrimSpace(matches[1]); !slices.Contains(categories, category) {
+			categories = append(categories, category)
+		}
+	}
+	return categories
 }
 
 // parseMainTask parses a main task line and updates the result
-func parseMainTask(matches []string, currentCategory string, result *[]ParsedTask, currentMainTask *ParsedTask) *ParsedTask {
+func (p *Parser) parseMainTask(matches []string, currentCategory string, result *[]ParsedTask, currentMainTask *ParsedTask) *ParsedTask {
 	// Save previous main task if exists
 	if currentMainTask != nil {
 		*result = append(*result, *currentMainTask)
 	}
 
-	status := ParseStatus("[" + matches[1] + "]")
-	titleWithID := strings.TrimSpace(matches[2])
-	taskID, hasID := ExtractTaskID(titleWithID)
+	status := p.ParseStatus("[" + matches[2] + "]")
+	titleWithID := strings.TrimSpace(matches[3])
+	taskID, hasID := p.ExtractTaskID(titleWithID)
 
 	if hasID {
-		// Remove task ID from title
-		title := strings.TrimSpace(taskIDRegex.ReplaceAllString(titleWithID, ""))
-
-		task := model.Task{
-			ID:       taskID,
-			Title:    title,
-			Status:   status,
-			Category: currentCategory,
-		}
+		// Remove task ID and inline metadata from title, keeping the IDs
+		// of other tasks the title refers to
+		loc := p.lastTaskID(titleWithID)
+		task := model.SplitMetadata(titleWithID[:loc[0]] + titleWithID[loc[1]:])
+		task.ID = taskID
+		task.Status = status
+		task.Category = currentCategory
 
 		return &ParsedTask{
 			Task:     task,
-			SubTasks: []model.Task{},
+			SubTasks: []model.SubTask{},
 		}
 	}
-	return currentMainTask
+	// Lines without a task ID are not tasks; the previous task is already saved
+	return nil
+}
+
+// subtaskLevel is a subtask that lines indented further are nested under
+type subtaskLevel struct {
+	indent  int
+	indexes []int
 }
 
-// parseSubTask parses a subtask line and adds it to the current main task
-func parseSubTask(matches []string, currentMainTask *ParsedTask) {
-	status := ParseStatus("[" + matches[1] + "]")
-	title := strings.TrimSpace(matches[2])
+// parseSubTask parses a subtask line and adds it to the current main task,
+// under the closest preceding subtask indented less. It returns the levels
+// for the following lines.
+func (p *Parser) parseSubTask(matches []string, currentMainTask *ParsedTask, levels []subtaskLevel) []subtaskLevel {
+	indent := indentWidth(matches[1])
+	status := p.ParseStatus("[" + matches[3] + "]")
+	title := strings.TrimSpace(matches[4])
 
-	subTask := model.Task{
-		Title:  title,
-		Status: status,
+	for len(levels) > 0 && levels[len(levels)-1].indent >= indent {
+		levels = levels[:len(levels)-1]
 	}
+	var parent []int
+	if len(levels) > 0 {
+		parent = levels[len(levels)-1].indexes
+	}
+	list, _ := model.SubtaskList(&currentMainTask.SubTasks, parent)
+	*list = append(*list, model.NewSubTask(title, status))
 
-	currentMainTask.SubTasks = append(currentMainTask.SubTasks, subTask)
+	indexes := append(slices.Clip(parent), len(*list)-1)
+	return append(levels, subtaskLevel{indent: indent, indexes: indexes})
 }
 
-// ParseStatus converts markdown checkbox to status string
-func ParseStatus(checkbox string) string {
-	switch checkbox {
-	case "[ ]":
-		return DefaultTaskStatus
-	case "[-]":
-		return "in_progress"
-	case "[x]":
-		return "done"
-	default:
-		return DefaultTaskStatus
+// parseNote adds an indented line to the notes of the closest preceding
+// subtask indented less, or of the main task
+func (p *Parser) parseNote(line string, currentMainTask *ParsedTask, levels []subtaskLevel) {
+	text := strings.TrimLeft(line, " \t")
+	indent := indentWidth(line[:len(line)-len(text)])
+
+	target := &currentMainTask.Task
+	for i := len(levels) - 1; i >= 0; i-- {
+		if levels[i].indent < indent {
+			indexes := levels[i].indexes
+			list, _ := model.SubtaskList(&currentMainTask.SubTasks, indexes[:len(indexes)-1])
+			target = &(*list)[indexes[len(indexes)-1]].Task
+			break
+		}
+	}
+	if target.Notes != "" {
+		target.Notes += "\n"
 	}
+	target.Notes += text
+}
+
+// ParseStatus converts a markdown checkbox to a status of the default workflow
+func ParseStatus(checkbox string) string {
+	return defaultParser.ParseStatus(checkbox)
+}
+
+// ParseStatus converts a markdown checkbox to a status of the parser's
+// workflow; markers match regardless of case if no status uses the exact
+// marker, and unknown markers are read as its initial status
+func (p *Parser) ParseStatus(checkbox string) string {
+	return p.workflow.ParseCheckbox(checkbox)
 }
 
 // ExtractTaskID extracts task ID from text (e.g., "task #T001" -> "T001", true)
 func ExtractTaskID(text string) (string, bool) {
-	matches := taskIDRegex.FindStringSubmatch(text)
-	const minMatches = 2
-	if len(matches) >= minMatches {
-		return matches[1], true
+	return defaultParser.ExtractTaskID(text)
+}
+
+// ExtractTaskID extracts a task ID in the parser's scheme from text. The
+// last ID is the task's own, since tasks are written with their ID last
+// and earlier ones are references in the title.
+func (p *Parser) ExtractTaskID(text string) (string, bool) {
+	loc := p.lastTaskID(text)
+	if loc == nil {
+		return "", false
+	}
+	return text[loc[2]:loc[3]], true
+}
+
+// lastTaskID returns the index pairs of the last task ID match in text and
+// its submatch, nil if there is none
+func (p *Parser) lastTaskID(text string) []int {
+	all := p.taskIDRegex.FindAllStringSubmatchIndex(text, -1)
+	if len(all) == 0 {
+		return nil
 	}
-	return "", false
+	return all[len(all)-1]
 }
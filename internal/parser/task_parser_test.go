@@ -27,7 +27,7 @@ func TestParseTaskContent(t *testing.T) {
 						Status:   "todo",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{},
+					SubTasks: []model.SubTask{},
 				},
 			},
 		},
@@ -47,7 +47,7 @@ func TestParseTaskContent(t *testing.T) {
 						Status:   "todo",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{},
+					SubTasks: []model.SubTask{},
 				},
 				{
 					Task: model.Task{
@@ -56,7 +56,7 @@ func TestParseTaskContent(t *testing.T) {
 						Status:   "in_progress",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{},
+					SubTasks: []model.SubTask{},
 				},
 				{
 					Task: model.Task{
@@ -65,7 +65,7 @@ func TestParseTaskContent(t *testing.T) {
 						Status:   "done",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{},
+					SubTasks: []model.SubTask{},
 				},
 			},
 		},
@@ -85,15 +85,66 @@ func TestParseTaskContent(t *testing.T) {
 						Status:   "todo",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{
-						{
+					SubTasks: []model.SubTask{
+						{Task: model.Task{
 							Title:  "エラーハンドリング仕様確認",
 							Status: "todo",
-						},
-						{
+						}},
+						{Task: model.Task{
 							Title:  "レート制限設計完了",
 							Status: "done",
-						},
+						}},
+					},
+				},
+			},
+		},
+		{
+			name: "parse nested subtasks",
+			content: `## Ops
+- [ ] Deploy #T001
+  - [ ] Build image
+      - [x] Pin base image
+      - [ ] Scan
+         - [ ] Fix CVEs
+    - [x] Push
+  - [x] Tag release`,
+			expected: []ParsedTask{
+				{
+					Task: model.Task{ID: "T001", Title: "Deploy", Status: "todo", Category: "Ops"},
+					SubTasks: []model.SubTask{
+						{Task: model.Task{Title: "Build image", Status: "todo"}, SubTasks: []model.SubTask{
+							model.NewSubTask("Pin base image", "done"),
+							{Task: model.Task{Title: "Scan", Status: "todo"}, SubTasks: []model.SubTask{model.NewSubTask("Fix CVEs", "todo")}},
+							model.NewSubTask("Push", "done"),
+						}},
+						model.NewSubTask("Tag release", "done"),
+					},
+				},
+			},
+		},
+		{
+			name: "parse notes",
+			content: `## Ops
+- [ ] Deploy #T001
+  Staging first, then production.
+  - [ ] Build image
+    Use buildx
+    - [x] Pin base image
+    - see https://example.com/base
+  - [ ] Tag release
+  Ask in #release before tagging
+Not indented, so not a note`,
+			expected: []ParsedTask{
+				{
+					Task: model.Task{
+						ID: "T001", Title: "Deploy", Status: "todo", Category: "Ops",
+						Notes: "Staging first, then production.\nAsk in #release before tagging",
+					},
+					SubTasks: []model.SubTask{
+						{Task: model.Task{Title: "Build image", Status: "todo", Notes: "Use buildx\n- see https://example.com/base"}, SubTasks: []model.SubTask{
+							model.NewSubTask("Pin base image", "done"),
+						}},
+						model.NewSubTask("Tag release", "todo"),
 					},
 				},
 			},
@@ -115,7 +166,7 @@ func TestParseTaskContent(t *testing.T) {
 						Status:   "todo",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{},
+					SubTasks: []model.SubTask{},
 				},
 				{
 					Task: model.Task{
@@ -124,7 +175,7 @@ func TestParseTaskContent(t *testing.T) {
 						Status:   "in_progress",
 						Category: "Frontend",
 					},
-					SubTasks: []model.Task{},
+					SubTasks: []model.SubTask{},
 				},
 			},
 		},
@@ -144,6 +195,14 @@ func TestParseTaskContent(t *testing.T) {
 	}
 }
 
+func TestParseCategories(t *testing.T) {
+	content := "\uFEFF# Task\r\n\r\n## Backend \r\n- [ ] API #T001\r\n\r\n## Ops\r\n\r\n## Backend\r\n- [ ] Jobs #T002\r\n### Notes\r\n"
+	want := []string{"Backend", "Ops"}
+	if diff := cmp.Diff(want, ParseCategories(content)); diff != "" {
+		t.Errorf("ParseCategories() mismatch (-want +got):\n%s", diff)
+	}
+}
+
 func TestParseStatus(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -165,6 +224,16 @@ func TestParseStatus(t *testing.T) {
 			checkbox: "[x]",
 			expected: "done",
 		},
+		{
+			name:     "parse blocked status",
+			checkbox: "[!]",
+			expected: "blocked",
+		},
+		{
+			name:     "parse cancelled status",
+			checkbox: "[~]",
+			expected: "cancelled",
+		},
 	}
 
 	for _, tt := range tests {
@@ -196,6 +265,12 @@ func TestExtractTaskID(t *testing.T) {
 			expected: "T002",
 			found:    true,
 		},
+		{
+			name:     "last task ID is the task's own",
+			text:     "Follow up on #T001 #T005",
+			expected: "T005",
+			found:    true,
+		},
 		{
 			name:     "no task ID found",
 			text:     "サブタスクには task-id がない",
@@ -216,3 +291,109 @@ func TestExtractTaskID(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_CustomIDScheme(t *testing.T) {
+	p := NewParser(model.IDScheme{Prefix: "PRJ", Digits: 4}, model.DefaultWorkflow)
+
+	content := `## Default
+- [ ] Custom ID #PRJ0007
+- [ ] Default ID is not recognized #T001
+`
+	result, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	expected := []ParsedTask{
+		{Task: model.Task{ID: "PRJ0007", Title: "Custom ID", Status: "todo", Category: "Default"}, SubTasks: []model.SubTask{}},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParser_TimeIDScheme(t *testing.T) {
+	p := NewParser(model.IDScheme{Prefix: "T", Mode: model.IDModeTime, Digits: 3}, model.DefaultWorkflow)
+
+	content := `## Default
+- [ ] Sequential ID #T001
+- [ ] Time ID #T0k9gu4x2
+- [ ] Upper case is not a time ID #T0K9GU4X2
+`
+	result, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	expected := []ParsedTask{
+		{Task: model.Task{ID: "T001", Title: "Sequential ID", Status: "todo", Category: "Default"}, SubTasks: []model.SubTask{}},
+		{Task: model.Task{ID: "T0k9gu4x2", Title: "Time ID", Status: "todo", Category: "Default"}, SubTasks: []model.SubTask{}},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParseTaskContent_Metadata(t *testing.T) {
+	content := `## Backend
+- [ ] Add login @alice +auth +api due:2026-11-01 !high #T001
+  - [ ] Ask @bob
+- [-] Mail a@b.c about !urgent #T002 +ops
+- [ ] Follow up on #T001 @carol #T003
+`
+	result, err := ParseTaskContent(content)
+	if err != nil {
+		t.Fatalf("ParseTaskContent() error = %v", err)
+	}
+
+	expected := []ParsedTask{
+		{
+			Task: model.Task{
+				ID: "T001", Title: "Add login", Status: "todo", Category: "Backend",
+				Due: "2026-11-01", Priority: "high", Assignee: "alice", Tags: []string{"auth", "api"},
+			},
+			SubTasks: []model.SubTask{{Task: model.Task{Title: "Ask @bob", Status: "todo"}}},
+		},
+		{
+			Task:     model.Task{ID: "T002", Title: "Mail a@b.c about !urgent", Status: "in_progress", Category: "Backend", Tags: []string{"ops"}},
+			SubTasks: []model.SubTask{},
+		},
+		{
+			Task:     model.Task{ID: "T003", Title: "Follow up on #T001", Status: "todo", Category: "Backend", Assignee: "carol"},
+			SubTasks: []model.SubTask{},
+		},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("ParseTaskContent() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestParser_CustomWorkflow(t *testing.T) {
+	workflow := model.Workflow{Statuses: []model.Status{
+		{Name: "open", Marker: " "},
+		{Name: "review", Marker: "r"},
+		{Name: "deployed", Marker: "d", Done: true},
+	}}
+	p := NewParser(model.DefaultIDScheme, workflow)
+
+	content := `## Default
+- [r] In review #T001
+  - [d] Shipped part
+- [x] Unknown marker #T002
+`
+	result, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	expected := []ParsedTask{
+		{
+			Task:     model.Task{ID: "T001", Title: "In review", Status: "review", Category: "Default"},
+			SubTasks: []model.SubTask{{Task: model.Task{Title: "Shipped part", Status: "deployed"}}},
+		},
+		{Task: model.Task{ID: "T002", Title: "Unknown marker", Status: "open", Category: "Default"}, SubTasks: []model.SubTask{}},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+	}
+}
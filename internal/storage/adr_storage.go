@@ -0,0 +1,216 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+const (
+	// MaxADRNumber is the highest ADR number (adr-999-*.md)
+	MaxADRNumber = 999
+	// maxSlugLength bounds the title part of ADR file names, in characters
+	maxSlugLength = 50
+)
+
+// adrFileRegex matches ADR file names (adr-001-title.md)
+var adrFileRegex = regexp.MustCompile(`^adr-(\d{3})-.*\.md$`)
+
+// ADRDir returns the path of the ADR directory
+func (fs *FileStorage) ADRDir() string {
+	return filepath.Join(fs.DataDir(), "adr")
+}
+
+// ADRFiles returns the paths of the ADR files by number.
+// A missing ADR directory holds no ADRs.
+func (fs *FileStorage) ADRFiles() (map[int]string, error) {
+	entries, err := os.ReadDir(fs.ADRDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return map[int]string{}, nil
+	}
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read ADR directory")
+	}
+
+	files := make(map[int]string)
+	for _, entry := range entries {
+		matches := adrFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil || entry.IsDir() {
+			continue
+		}
+		number, _ := strconv.Atoi(matches[1])
+		files[number] = filepath.Join(fs.ADRDir(), entry.Name())
+	}
+	return files, nil
+}
+
+// ReadADRs reads all ADRs in number order
+func (fs *FileStorage) ReadADRs() ([]model.ADR, error) {
+	files, err := fs.ADRFiles()
+	if err != nil {
+		return nil, err
+	}
+	numbers := make([]int, 0, len(files))
+	for number := range files {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	adrs := make([]model.ADR, 0, len(numbers))
+	for _, number := range numbers {
+		adr, err := fs.readADRFile(number, files[number])
+		if err != nil {
+			return nil, err
+		}
+		adrs = append(adrs, adr)
+	}
+	return adrs, nil
+}
+
+// ReadADR reads the ADR with the given number and returns it with its file path
+func (fs *FileStorage) ReadADR(number int) (model.ADR, string, error) {
+	files, err := fs.ADRFiles()
+	if err != nil {
+		return model.ADR{}, "", err
+	}
+	path, ok := files[number]
+	if !ok {
+		return model.ADR{}, "", errcode.New(errcode.ADRNotFound, "ADR %d not found", number).WithDetails("adr_number", number)
+	}
+	adr, err := fs.readADRFile(number, path)
+	return adr, path, err
+}
+
+// readADRFile reads and parses one ADR file
+func (fs *FileStorage) readADRFile(number int, path string) (model.ADR, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return model.ADR{}, errcode.WrapFS(err, errcode.FileReadError, "failed to read ADR %d", number)
+	}
+	adr, err := parser.ParseADR(string(content))
+	if err != nil {
+		return model.ADR{}, err
+	}
+	// The file name is authoritative for the number
+	adr.Number = number
+	return adr, nil
+}
+
+// NextADRNumber returns the number following the highest existing ADR
+func (fs *FileStorage) NextADRNumber() (int, error) {
+	files, err := fs.ADRFiles()
+	if err != nil {
+		return 0, err
+	}
+	next := 1
+	for number := range files {
+		if number >= next {
+			next = number + 1
+		}
+	}
+	if next > MaxADRNumber {
+		return 0, errcode.New(errcode.ADRLimitExceeded, "ADR limit of %d reached", MaxADRNumber)
+	}
+	return next, nil
+}
+
+// WriteNewADR writes a new ADR file and returns its path
+func (fs *FileStorage) WriteNewADR(adr model.ADR) (string, error) {
+	if err := os.MkdirAll(fs.ADRDir(), fs.opts.DirPerm); err != nil {
+		return "", errcode.WrapFS(err, errcode.FileWriteError, "failed to create ADR directory")
+	}
+
+	path := filepath.Join(fs.ADRDir(), ADRFileName(adr.Number, adr.Title))
+	// O_EXCL keeps a concurrent writer from replacing an ADR
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fs.opts.FilePerm)
+	if err != nil {
+		return "", errcode.WrapFS(err, errcode.FileWriteError, "failed to create ADR %d", adr.Number)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(formatADR(adr)); err != nil {
+		return "", errcode.WrapFS(err, errcode.FileWriteError, "failed to write ADR %d", adr.Number)
+	}
+	return path, nil
+}
+
+// UpdateADRStatus sets the status of an ADR and records the change in its
+// status history. Other content of the file is kept as is.
+func (fs *FileStorage) UpdateADRStatus(number int, status, historyEntry string) (model.ADR, error) {
+	adr, path, err := fs.ReadADR(number)
+	if err != nil {
+		return model.ADR{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return model.ADR{}, errcode.WrapFS(err, errcode.FileReadError, "failed to read ADR %d", number)
+	}
+
+	updated := parser.SetSection(string(content), parser.ADRSectionStatus, status, false)
+	updated = parser.SetSection(updated, parser.ADRSectionHistory, "- "+historyEntry, true)
+	if err := os.WriteFile(path, []byte(updated), fs.opts.FilePerm); err != nil {
+		return model.ADR{}, errcode.WrapFS(err, errcode.FileWriteError, "failed to write ADR %d", number)
+	}
+
+	adr.Status = status
+	adr.History = append(adr.History, historyEntry)
+	return adr, nil
+}
+
+// ADRFileName returns the file name of an ADR (adr-001-use-sqlite.md)
+func ADRFileName(number int, title string) string {
+	return fmt.Sprintf("adr-%03d-%s.md", number, slug(title))
+}
+
+// slug converts a title to a file name part, keeping letters and digits of any script
+func slug(title string) string {
+	var sb strings.Builder
+	dash := false
+	count := 0
+	for _, r := range strings.ToLower(title) {
+		if count >= maxSlugLength {
+			break
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			dash = false
+			count++
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteRune('-')
+			dash = true
+			count++
+		}
+	}
+	s := strings.TrimRight(sb.String(), "-")
+	if s == "" {
+		return "untitled"
+	}
+	return s
+}
+
+// formatADR renders a new ADR file
+func formatADR(adr model.ADR) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# ADR-%03d: %s\n", adr.Number, adr.Title)
+	section := func(name, body string) {
+		if body != "" {
+			fmt.Fprintf(&sb, "\n## %s\n%s\n", name, strings.TrimRight(body, "\n"))
+		}
+	}
+	section(parser.ADRSectionStatus, adr.Status)
+	section(parser.ADRSectionDate, adr.Date)
+	section(parser.ADRSectionContext, adr.Context)
+	section(parser.ADRSectionDecision, adr.Decision)
+	section(parser.ADRSectionRationale, adr.Rationale)
+	section(parser.ADRSectionConsequences, adr.Consequences)
+	return sb.String()
+}
@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/model"
+)
+
+func TestFileStorage_ADRs(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+
+	adrs, err := storage.ReadADRs()
+	if err != nil || len(adrs) != 0 {
+		t.Fatalf("ReadADRs() = %v, %v; want no ADRs", adrs, err)
+	}
+
+	number, err := storage.NextADRNumber()
+	if err != nil || number != 1 {
+		t.Fatalf("NextADRNumber() = %d, %v; want 1", number, err)
+	}
+
+	adr := model.ADR{
+		Number:    number,
+		Title:     "Use Markdown files",
+		Status:    "Proposed",
+		Date:      "2026-10-19",
+		Context:   "Agents and humans edit tasks.",
+		Decision:  "Store tasks in Markdown.",
+		Rationale: "Readable diffs.",
+	}
+	path, err := storage.WriteNewADR(adr)
+	if err != nil {
+		t.Fatalf("WriteNewADR() error = %v", err)
+	}
+	if want := filepath.Join(storage.ADRDir(), "adr-001-use-markdown-files.md"); path != want {
+		t.Errorf("WriteNewADR() path = %s, want %s", path, want)
+	}
+	if _, err := storage.WriteNewADR(adr); err == nil {
+		t.Error("WriteNewADR() should not overwrite an existing ADR")
+	}
+
+	updated, err := storage.UpdateADRStatus(1, "Accepted", "2026-10-20: Proposed → Accepted")
+	if err != nil {
+		t.Fatalf("UpdateADRStatus() error = %v", err)
+	}
+
+	got, _, err := storage.ReadADR(1)
+	if err != nil {
+		t.Fatalf("ReadADR() error = %v", err)
+	}
+	adr.Status = "Accepted"
+	adr.History = []string{"2026-10-20: Proposed → Accepted"}
+	if diff := cmp.Diff(adr, got); diff != "" {
+		t.Errorf("ReadADR() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(adr, updated); diff != "" {
+		t.Errorf("UpdateADRStatus() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, _, err := storage.ReadADR(2); !errcode.HasCode(err, errcode.ADRNotFound) {
+		t.Errorf("ReadADR(2) error = %v, want %v", err, errcode.ADRNotFound)
+	}
+}
+
+func TestFileStorage_NextADRNumber(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+	if err := os.MkdirAll(storage.ADRDir(), 0755); err != nil {
+		t.Fatalf("Failed to create ADR dir: %v", err)
+	}
+	for _, name := range []string{"adr-002-core.md", "adr-007-techstack.md", "notes.md"} {
+		if err := os.WriteFile(filepath.Join(storage.ADRDir(), name), []byte("# ADR\n"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	number, err := storage.NextADRNumber()
+	if err != nil || number != 8 {
+		t.Errorf("NextADRNumber() = %d, %v; want 8", number, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(storage.ADRDir(), "adr-999-last.md"), nil, 0644); err != nil {
+		t.Fatalf("Failed to write ADR: %v", err)
+	}
+	if _, err := storage.NextADRNumber(); !errcode.HasCode(err, errcode.ADRLimitExceeded) {
+		t.Errorf("NextADRNumber() error = %v, want %v", err, errcode.ADRLimitExceeded)
+	}
+}
+
+func TestADRFileName(t *testing.T) {
+	tests := []struct {
+		number int
+		title  string
+		want   string
+	}{
+		{1, "Use SQLite for storage", "adr-001-use-sqlite-for-storage.md"},
+		{12, "  API: v2 / REST?  ", "adr-012-api-v2-rest.md"},
+		{3, "技術スタックの選定", "adr-003-技術スタックの選定.md"},
+		{4, "!!!", "adr-004-untitled.md"},
+	}
+
+	for _, tt := range tests {
+		if got := ADRFileName(tt.number, tt.title); got != tt.want {
+			t.Errorf("ADRFileName(%d, %q) = %q, want %q", tt.number, tt.title, got, tt.want)
+		}
+	}
+}
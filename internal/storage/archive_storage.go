@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+	"github.com/jnst/agentic-todo-mcp/internal/parser"
+)
+
+// ArchiveMonthLayout is the time layout of archive months, which name the
+// archive files (2026-09.md)
+const ArchiveMonthLayout = "2006-01"
+
+// archiveFileRegex matches archive file names (2026-09.md)
+var archiveFileRegex = regexp.MustCompile(`^(\d{4}-\d{2})\.md$`)
+
+// ArchiveDir returns the path of the directory holding archived tasks
+func (fs *FileStorage) ArchiveDir() string {
+	return filepath.Join(fs.DataDir(), "archive")
+}
+
+// ArchiveFilePath returns the path of the archive file of a month, given
+// in ArchiveMonthLayout
+func (fs *FileStorage) ArchiveFilePath(month string) string {
+	return filepath.Join(fs.ArchiveDir(), month+".md")
+}
+
+// ReadArchives reads the archived tasks by month.
+// A missing archive directory holds no tasks.
+func (fs *FileStorage) ReadArchives() (map[string][]parser.ParsedTask, error) {
+	entries, err := os.ReadDir(fs.ArchiveDir())
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string][]parser.ParsedTask{}, nil
+	}
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read archive directory")
+	}
+
+	archives := make(map[string][]parser.ParsedTask)
+	for _, entry := range entries {
+		matches := archiveFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil || entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(fs.ArchiveFilePath(matches[1]))
+		if err != nil {
+			return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read archive file %s", entry.Name())
+		}
+		if archives[matches[1]], err = fs.ParseTasks(string(content)); err != nil {
+			return nil, err
+		}
+	}
+	return archives, nil
+}
+
+// WriteArchiveFile writes the archived tasks of a month in the task.md
+// format, keeping the style of the existing file. Writing no tasks removes
+// the file.
+func (fs *FileStorage) WriteArchiveFile(month string, tasks []parser.ParsedTask) error {
+	path := fs.ArchiveFilePath(month)
+	if len(tasks) == 0 {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errcode.WrapFS(err, errcode.FileWriteError, "failed to remove archive file %s", filepath.Base(path))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(fs.ArchiveDir(), fs.opts.DirPerm); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to create archive directory")
+	}
+	if err := fs.writeTasks(path, nil, tasks); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to write archive file %s", filepath.Base(path))
+	}
+	return nil
+}
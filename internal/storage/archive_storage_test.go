@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFileStorage_Archives(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+
+	archives, err := storage.ReadArchives()
+	if err != nil {
+		t.Fatalf("ReadArchives() without an archive directory error = %v", err)
+	}
+	if len(archives) != 0 {
+		t.Errorf("ReadArchives() = %v, want none", archives)
+	}
+
+	tasks, err := storage.ParseTasks("# Task\n\n## Backend\n- [x] API #T001\n  - [x] Tests\n\n## Docs\n- [x] Guide #T003\n")
+	if err != nil {
+		t.Fatalf("ParseTasks() error = %v", err)
+	}
+	if err := storage.WriteArchiveFile("2026-09", tasks[:1]); err != nil {
+		t.Fatalf("WriteArchiveFile() error = %v", err)
+	}
+	if err := storage.WriteArchiveFile("2026-10", tasks[1:]); err != nil {
+		t.Fatalf("WriteArchiveFile() error = %v", err)
+	}
+	// Files not named by month are not archives
+	if err := os.WriteFile(filepath.Join(storage.ArchiveDir(), "notes.md"), []byte("- [x] Stray #T009\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(storage.ArchiveFilePath("2026-09"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Task\n\n## Backend\n- [x] API #T001\n  - [x] Tests\n\n"; string(content) != want {
+		t.Errorf("archive file = %q, want %q", content, want)
+	}
+
+	archives, err = storage.ReadArchives()
+	if err != nil {
+		t.Fatalf("ReadArchives() error = %v", err)
+	}
+	got := map[string][]string{}
+	for month, archived := range archives {
+		for _, task := range archived {
+			got[month] = append(got[month], task.Task.ID)
+		}
+	}
+	if diff := cmp.Diff(map[string][]string{"2026-09": {"T001"}, "2026-10": {"T003"}}, got); diff != "" {
+		t.Errorf("ReadArchives() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Writing no tasks removes the file
+	if err := storage.WriteArchiveFile("2026-09", nil); err != nil {
+		t.Fatalf("WriteArchiveFile() error = %v", err)
+	}
+	if _, err := os.Stat(storage.ArchiveFilePath("2026-09")); !os.IsNotExist(err) {
+		t.Errorf("archive file of 2026-09 still exists: %v", err)
+	}
+	if err := storage.WriteArchiveFile("2026-08", nil); err != nil {
+		t.Errorf("WriteArchiveFile() of a missing file error = %v", err)
+	}
+}
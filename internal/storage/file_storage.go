@@ -3,74 +3,185 @@
 package storage
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
 	"github.com/jnst/agentic-todo-mcp/internal/model"
 	"github.com/jnst/agentic-todo-mcp/internal/parser"
 )
 
 const (
-	// DefaultCheckbox is the default checkbox for todo status
-	DefaultCheckbox = "[ ]"
 	// DefaultDirPerm is the default permission for directories
 	DefaultDirPerm = 0o750
 	// DefaultFilePerm is the default permission for files
 	DefaultFilePerm = 0o600
+	// DefaultDataDir is the default directory holding task.md, relative to the project root
+	DefaultDataDir = ".todo"
+	// DefaultCategory is the category of tasks written without one
+	DefaultCategory = "Default"
 )
 
+// Options configures a FileStorage
+type Options struct {
+	// DataDir is the data directory, relative to the base path unless absolute
+	DataDir string
+	// DefaultCategory is the category of tasks written without one
+	DefaultCategory string
+	// IDScheme is the task ID format recognized when parsing task.md
+	IDScheme model.IDScheme
+	// Workflow defines the statuses of tasks and their checkboxes in task.md
+	Workflow model.Workflow
+	// DirPerm is the permission for created directories
+	DirPerm os.FileMode
+	// FilePerm is the permission for written files
+	FilePerm os.FileMode
+}
+
+// DefaultOptions returns the options used by NewFileStorage
+func DefaultOptions() Options {
+	return Options{
+		DataDir:         DefaultDataDir,
+		DefaultCategory: DefaultCategory,
+		IDScheme:        model.DefaultIDScheme,
+		Workflow:        model.DefaultWorkflow,
+		DirPerm:         DefaultDirPerm,
+		FilePerm:        DefaultFilePerm,
+	}
+}
+
 // FileStorage handles file operations for the todo system
 type FileStorage struct {
+	parser   *parser.Parser
 	basePath string
+	opts     Options
 }
 
 // NewFileStorage creates a new FileStorage instance
 func NewFileStorage(basePath string) *FileStorage {
+	return NewFileStorageWithOptions(basePath, DefaultOptions())
+}
+
+// NewFileStorageWithOptions creates a new FileStorage instance with the given options
+func NewFileStorageWithOptions(basePath string, opts Options) *FileStorage {
 	return &FileStorage{
+		parser:   parser.NewParser(opts.IDScheme, opts.Workflow),
 		basePath: basePath,
+		opts:     opts,
 	}
 }
 
+// BasePath returns the project root the storage was created for
+func (fs *FileStorage) BasePath() string {
+	return fs.basePath
+}
+
+// IDScheme returns the task ID format of task.md
+func (fs *FileStorage) IDScheme() model.IDScheme {
+	return fs.opts.IDScheme
+}
+
+// Workflow returns the statuses of tasks in task.md
+func (fs *FileStorage) Workflow() model.Workflow {
+	return fs.opts.Workflow
+}
+
+// FilePerm returns the permission for written files
+func (fs *FileStorage) FilePerm() os.FileMode {
+	return fs.opts.FilePerm
+}
+
+// DataDir returns the path of the data directory
+func (fs *FileStorage) DataDir() string {
+	if filepath.IsAbs(fs.opts.DataDir) {
+		return fs.opts.DataDir
+	}
+	return filepath.Join(fs.basePath, fs.opts.DataDir)
+}
+
+// TaskFilePath returns the path of the task.md file
+func (fs *FileStorage) TaskFilePath() string {
+	return filepath.Join(fs.DataDir(), "task.md")
+}
+
 // ReadTasksFile reads and parses the task.md file
 func (fs *FileStorage) ReadTasksFile() ([]parser.ParsedTask, error) {
-	taskFilePath := filepath.Join(fs.basePath, ".todo", "task.md")
+	content, err := os.ReadFile(fs.TaskFilePath())
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read task file")
+	}
+
+	return fs.ParseTasks(string(content))
+}
 
-	content, err := os.ReadFile(taskFilePath)
+// ReadCategories returns the category headers of task.md in file order,
+// including those without tasks
+func (fs *FileStorage) ReadCategories() ([]string, error) {
+	content, err := os.ReadFile(fs.TaskFilePath())
 	if err != nil {
-		return nil, fmt.Errorf("failed to read task file: %w", err)
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read task file")
 	}
+	return parser.ParseCategories(string(content)), nil
+}
+
+// ParseTasks parses task.md content in the storage's task ID scheme
+func (fs *FileStorage) ParseTasks(content string) ([]parser.ParsedTask, error) {
+	return fs.parser.Parse(content)
+}
 
-	return parser.ParseTaskContent(string(content))
+// ParseTasksWithStyle parses task.md content in the storage's task ID
+// scheme and returns the style it is written in
+func (fs *FileStorage) ParseTasksWithStyle(content string) ([]parser.ParsedTask, parser.Style, error) {
+	return fs.parser.ParseWithStyle(content)
 }
 
-// WriteTasksFile writes the parsed tasks to task.md file
+// WriteTasksFile writes the parsed tasks to task.md file, keeping the style
+// and the category headers of the existing file, including those left
+// without tasks
 func (fs *FileStorage) WriteTasksFile(tasks []parser.ParsedTask) error {
-	todoDir := filepath.Join(fs.basePath, ".todo")
-	err := os.MkdirAll(todoDir, DefaultDirPerm)
+	// A file that cannot be read has no headers to keep, like its style
+	categories, _ := fs.ReadCategories()
+	return fs.WriteTasksFileWithCategories(categories, tasks)
+}
+
+// WriteTasksFileWithCategories writes the parsed tasks to task.md file under
+// the given category headers in order, including headers without tasks.
+// Categories of tasks missing from the list follow in order of appearance.
+func (fs *FileStorage) WriteTasksFileWithCategories(categories []string, tasks []parser.ParsedTask) error {
+	err := os.MkdirAll(fs.DataDir(), fs.opts.DirPerm)
 	if err != nil {
-		return fmt.Errorf("failed to create .todo directory: %w", err)
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to create %s directory", fs.opts.DataDir)
 	}
 
-	content := fs.formatTasksAsMarkdown(tasks)
-	taskFilePath := filepath.Join(todoDir, "task.md")
-
-	err = os.WriteFile(taskFilePath, []byte(content), DefaultFilePerm)
+	err = fs.writeTasks(fs.TaskFilePath(), categories, tasks)
 	if err != nil {
-		return fmt.Errorf("failed to write task file: %w", err)
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to write task file")
 	}
 
 	return nil
 }
 
+// writeTasks writes tasks in the task.md format to path under the given
+// categories, keeping the style of the existing file
+func (fs *FileStorage) writeTasks(path string, categories []string, tasks []parser.ParsedTask) error {
+	style := parser.DefaultStyle
+	if existing, err := os.ReadFile(path); err == nil {
+		if _, existingStyle, err := fs.parser.ParseWithStyle(string(existing)); err == nil {
+			style = existingStyle
+		}
+	}
+	return os.WriteFile(path, []byte(fs.FormatCategorizedTasks(categories, tasks, style)), fs.opts.FilePerm)
+}
+
 // ReadContextFile reads the context file for a given task ID
 func (fs *FileStorage) ReadContextFile(taskID string) (model.Context, error) {
-	contextFilePath := filepath.Join(fs.basePath, ".todo", "context", taskID+".md")
-
-	content, err := os.ReadFile(contextFilePath)
+	content, err := os.ReadFile(fs.ContextFilePath(taskID))
 	if err != nil {
-		return model.Context{}, fmt.Errorf("failed to read context file for %s: %w", taskID, err)
+		return model.Context{}, errcode.WrapFS(err, errcode.FileReadError, "failed to read context file for %s", taskID)
 	}
 
 	return model.Context{
@@ -79,70 +190,185 @@ func (fs *FileStorage) ReadContextFile(taskID string) (model.Context, error) {
 	}, nil
 }
 
+// ContextFilePath returns the path of the context file for a given task ID
+func (fs *FileStorage) ContextFilePath(taskID string) string {
+	return filepath.Join(fs.DataDir(), "context", taskID+".md")
+}
+
 // WriteContextFile writes the context to a file
 func (fs *FileStorage) WriteContextFile(context model.Context) error {
-	contextDir := filepath.Join(fs.basePath, ".todo", "context")
-	err := os.MkdirAll(contextDir, DefaultDirPerm)
+	contextDir := filepath.Join(fs.DataDir(), "context")
+	err := os.MkdirAll(contextDir, fs.opts.DirPerm)
 	if err != nil {
-		return fmt.Errorf("failed to create context directory: %w", err)
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to create context directory")
 	}
 
 	contextFilePath := filepath.Join(contextDir, context.TaskID+".md")
 
-	err = os.WriteFile(contextFilePath, []byte(context.Content), DefaultFilePerm)
+	err = os.WriteFile(contextFilePath, []byte(context.Content), fs.opts.FilePerm)
+	if err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to write context file for %s", context.TaskID)
+	}
+
+	return nil
+}
+
+// TimestampsFilePath returns the path of the file holding the timestamps of a task
+func (fs *FileStorage) TimestampsFilePath(taskID string) string {
+	return filepath.Join(fs.DataDir(), "meta", taskID+".json")
+}
+
+// ReadTimestamps reads the timestamps of all tasks, by task ID. Tasks
+// without a timestamps file are missing from the map.
+func (fs *FileStorage) ReadTimestamps() (map[string]model.Timestamps, error) {
+	dir := filepath.Dir(fs.TimestampsFilePath(""))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return map[string]model.Timestamps{}, nil
+	}
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read timestamps directory")
+	}
+
+	times := make(map[string]model.Timestamps, len(entries))
+	for _, entry := range entries {
+		taskID, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to read timestamps of %s", taskID)
+		}
+		var t model.Timestamps
+		if err := json.Unmarshal(content, &t); err != nil {
+			return nil, errcode.Wrap(err, errcode.ParseError, "failed to parse timestamps of %s", taskID)
+		}
+		times[taskID] = t
+	}
+	return times, nil
+}
+
+// WriteTimestamps writes the timestamps of a task, removing its file when
+// none are known
+func (fs *FileStorage) WriteTimestamps(taskID string, t model.Timestamps) error {
+	path := fs.TimestampsFilePath(taskID)
+	if t.IsZero() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errcode.WrapFS(err, errcode.FileWriteError, "failed to remove timestamps of %s", taskID)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), fs.opts.DirPerm); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to create timestamps directory")
+	}
+	content, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return errcode.Wrap(err, errcode.FileWriteError, "failed to encode timestamps of %s", taskID)
+	}
+	if err := os.WriteFile(path, append(content, '\n'), fs.opts.FilePerm); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to write timestamps of %s", taskID)
+	}
+	return nil
+}
+
+// CommitMarkerPath returns the path of the file holding the last commit
+// scanned for task references
+func (fs *FileStorage) CommitMarkerPath() string {
+	return filepath.Join(fs.DataDir(), "last-commit")
+}
+
+// ReadCommitMarker returns the last commit scanned for task references, or
+// an empty string if none was scanned yet
+func (fs *FileStorage) ReadCommitMarker() (string, error) {
+	content, err := os.ReadFile(fs.CommitMarkerPath())
+	if os.IsNotExist(err) {
+		return "", nil
+	}
 	if err != nil {
-		return fmt.Errorf("failed to write context file for %s: %w", context.TaskID, err)
+		return "", errcode.WrapFS(err, errcode.FileReadError, "failed to read commit marker")
 	}
+	return strings.TrimSpace(string(content)), nil
+}
 
+// WriteCommitMarker stores the last commit scanned for task references
+func (fs *FileStorage) WriteCommitMarker(commit string) error {
+	if err := os.MkdirAll(fs.DataDir(), fs.opts.DirPerm); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to create %s directory", fs.opts.DataDir)
+	}
+	if err := os.WriteFile(fs.CommitMarkerPath(), []byte(commit+"\n"), fs.opts.FilePerm); err != nil {
+		return errcode.WrapFS(err, errcode.FileWriteError, "failed to write commit marker")
+	}
 	return nil
 }
 
-// formatTasksAsMarkdown converts parsed tasks back to markdown format
-func (fs *FileStorage) formatTasksAsMarkdown(tasks []parser.ParsedTask) string {
+// writeNotes writes the lines of notes with the given indentation
+func writeNotes(sb *strings.Builder, notes, indent string) {
+	for _, line := range model.NoteLines(notes) {
+		sb.WriteString(indent + line + "\n")
+	}
+}
+
+// FormatTasks converts parsed tasks back to the markdown of task.md in the
+// default style
+func (fs *FileStorage) FormatTasks(tasks []parser.ParsedTask) string {
+	return fs.FormatTasksWithStyle(tasks, parser.DefaultStyle)
+}
+
+// FormatTasksWithStyle converts parsed tasks back to the markdown of
+// task.md in the given style
+func (fs *FileStorage) FormatTasksWithStyle(tasks []parser.ParsedTask, style parser.Style) string {
+	return fs.FormatCategorizedTasks(nil, tasks, style)
+}
+
+// FormatCategorizedTasks converts parsed tasks back to the markdown of
+// task.md in the given style, writing a header for each of categories in
+// order even if it has no tasks
+func (fs *FileStorage) FormatCategorizedTasks(categories []string, tasks []parser.ParsedTask, style parser.Style) string {
 	var sb strings.Builder
 	sb.WriteString("# Task\n\n")
 
-	// Group tasks by category
-	categories := make(map[string][]parser.ParsedTask)
+	// Group tasks by category, keeping the given categories first and the
+	// others in order of first appearance
+	order := slices.Clone(categories)
+	grouped := make(map[string][]parser.ParsedTask)
 	for _, task := range tasks {
 		category := task.Task.Category
 		if category == "" {
-			category = "Default"
+			category = fs.opts.DefaultCategory
+		}
+		if !slices.Contains(order, category) {
+			order = append(order, category)
 		}
-		categories[category] = append(categories[category], task)
+		grouped[category] = append(grouped[category], task)
 	}
 
 	// Write each category
-	for category, categoryTasks := range categories {
+	for _, category := range order {
 		sb.WriteString(fmt.Sprintf("## %s\n", category))
 
-		for _, parsedTask := range categoryTasks {
+		for i, parsedTask := range grouped[category] {
 			task := parsedTask.Task
-			checkbox := fs.statusToCheckbox(task.Status)
-			sb.WriteString(fmt.Sprintf("- %s %s #%s\n", checkbox, task.Title, task.ID))
-
-			// Write subtasks
-			for _, subTask := range parsedTask.SubTasks {
-				subCheckbox := fs.statusToCheckbox(subTask.Status)
-				sb.WriteString(fmt.Sprintf("  - %s %s\n", subCheckbox, subTask.Title))
+			checkbox := style.Checkbox(fs.opts.Workflow, task.Status)
+			title := task.Title
+			if metadata := task.Metadata(); metadata != "" {
+				title += " " + metadata
 			}
+			sb.WriteString(fmt.Sprintf("%s %s %s #%s\n", style.ListItem(i+1), checkbox, title, task.ID))
+			writeNotes(&sb, task.Notes, style.Indent)
+
+			// Write subtasks, indented by one level of the style per level
+			model.WalkSubtasks(parsedTask.SubTasks, func(indexes []int, subTask model.SubTask) {
+				indent := strings.Repeat(style.Indent, len(indexes))
+				subCheckbox := style.Checkbox(fs.opts.Workflow, subTask.Status)
+				item := style.ListItem(indexes[len(indexes)-1] + 1)
+				sb.WriteString(fmt.Sprintf("%s%s %s %s\n", indent, item, subCheckbox, subTask.Title))
+				writeNotes(&sb, subTask.Notes, indent+style.Indent)
+			})
 		}
 		sb.WriteString("\n")
 	}
 
-	return sb.String()
-}
-
-// statusToCheckbox converts status string to markdown checkbox
-func (fs *FileStorage) statusToCheckbox(status string) string {
-	switch status {
-	case "todo":
-		return DefaultCheckbox
-	case "in_progress":
-		return "[-]"
-	case "done":
-		return "[x]"
-	default:
-		return DefaultCheckbox
-	}
+	return style.Finish(sb.String())
 }
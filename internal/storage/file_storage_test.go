@@ -3,9 +3,11 @@ package storage
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
 	"github.com/jnst/agentic-todo-mcp/internal/model"
 	"github.com/jnst/agentic-todo-mcp/internal/parser"
 )
@@ -52,15 +54,15 @@ func TestFileStorage_ReadTasksFile(t *testing.T) {
 						Status:   "todo",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{
-						{
+					SubTasks: []model.SubTask{
+						{Task: model.Task{
 							Title:  "エラーハンドリング仕様確認",
 							Status: "todo",
-						},
-						{
+						}},
+						{Task: model.Task{
 							Title:  "レート制限設計完了",
 							Status: "done",
-						},
+						}},
 					},
 				},
 				{
@@ -70,7 +72,7 @@ func TestFileStorage_ReadTasksFile(t *testing.T) {
 						Status:   "in_progress",
 						Category: "Frontend",
 					},
-					SubTasks: []model.Task{},
+					SubTasks: []model.SubTask{},
 				},
 			},
 		},
@@ -116,15 +118,75 @@ func TestFileStorage_WriteTasksFile(t *testing.T) {
 						Status:   "todo",
 						Category: "SPEC",
 					},
-					SubTasks: []model.Task{
-						{
+					SubTasks: []model.SubTask{
+						{Task: model.Task{
 							Title:  "エラーハンドリング仕様確認",
 							Status: "todo",
-						},
+						}},
 					},
 				},
 			},
 		},
+		{
+			name:     "write task metadata",
+			basePath: tempDir,
+			tasks: []parser.ParsedTask{
+				{
+					Task: model.Task{
+						ID:       "T001",
+						Title:    "ログイン画面",
+						Status:   "in_progress",
+						Category: "UI",
+						Due:      "2026-11-01",
+						Priority: "medium",
+						Assignee: "alice",
+						Tags:     []string{"auth", "frontend"},
+					},
+					SubTasks: []model.SubTask{},
+				},
+			},
+		},
+		{
+			name:     "write nested subtasks",
+			basePath: tempDir,
+			tasks: []parser.ParsedTask{
+				{
+					Task: model.Task{ID: "T001", Title: "デプロイ", Status: "todo", Category: "Ops"},
+					SubTasks: []model.SubTask{
+						{Task: model.Task{Title: "イメージ作成", Status: "todo"}, SubTasks: []model.SubTask{
+							{Task: model.Task{Title: "脆弱性スキャン", Status: "todo"}, SubTasks: []model.SubTask{model.NewSubTask("修正", "done")}},
+						}},
+						model.NewSubTask("タグ付け", "todo"),
+					},
+				},
+			},
+		},
+		{
+			name:     "write notes",
+			basePath: tempDir,
+			tasks: []parser.ParsedTask{
+				{
+					Task: model.Task{ID: "T001", Title: "デプロイ", Status: "todo", Category: "Ops", Notes: "ステージングを先に\n- 手順書を参照"},
+					SubTasks: []model.SubTask{
+						{Task: model.Task{Title: "イメージ作成", Status: "todo", Notes: "buildx を使う"}, SubTasks: []model.SubTask{model.NewSubTask("脆弱性スキャン", "done")}},
+					},
+				},
+			},
+		},
+		{
+			name:     "write blocked and cancelled tasks",
+			basePath: tempDir,
+			tasks: []parser.ParsedTask{
+				{
+					Task:     model.Task{ID: "T001", Title: "デプロイ", Status: "blocked", Category: "Ops"},
+					SubTasks: []model.SubTask{{Task: model.Task{Title: "承認待ち", Status: "blocked"}}, {Task: model.Task{Title: "旧手順", Status: "cancelled"}}},
+				},
+				{
+					Task:     model.Task{ID: "T002", Title: "移行", Status: "cancelled", Category: "Ops"},
+					SubTasks: []model.SubTask{},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -262,3 +324,194 @@ func TestFileStorage_WriteContextFile(t *testing.T) {
 		})
 	}
 }
+
+func TestFileStorage_ErrorCodes(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := NewFileStorage(tempDir)
+
+	_, err := storage.ReadTasksFile()
+	if got := errcode.CodeOf(err); got != errcode.FileNotFound {
+		t.Errorf("ReadTasksFile() code = %v, want %v", got, errcode.FileNotFound)
+	}
+
+	_, err = storage.ReadContextFile("T001")
+	if got := errcode.CodeOf(err); got != errcode.FileNotFound {
+		t.Errorf("ReadContextFile() code = %v, want %v", got, errcode.FileNotFound)
+	}
+
+	// A regular file where the .todo directory should be makes writes fail
+	if err := os.WriteFile(filepath.Join(tempDir, ".todo"), nil, 0644); err != nil {
+		t.Fatalf("Failed to create blocking file: %v", err)
+	}
+	err = storage.WriteTasksFile(nil)
+	if got := errcode.CodeOf(err); got != errcode.FileWriteError {
+		t.Errorf("WriteTasksFile() code = %v, want %v", got, errcode.FileWriteError)
+	}
+}
+
+func TestFileStorage_Options(t *testing.T) {
+	tempDir := t.TempDir()
+	opts := DefaultOptions()
+	opts.DataDir = "tasks"
+	opts.DefaultCategory = "Inbox"
+	opts.IDScheme = model.IDScheme{Prefix: "PRJ", Digits: 4}
+	opts.Workflow = model.Workflow{Statuses: []model.Status{
+		{Name: "todo", Marker: " "},
+		{Name: "review", Marker: "r"},
+		{Name: "done", Marker: "x", Done: true},
+	}}
+	storage := NewFileStorageWithOptions(tempDir, opts)
+
+	tasks := []parser.ParsedTask{
+		{Task: model.Task{ID: "PRJ0002", Title: "Second", Status: "review", Category: "Work"}},
+		{Task: model.Task{ID: "PRJ0001", Title: "Uncategorized", Status: "done"}},
+	}
+	if err := storage.WriteTasksFile(tasks); err != nil {
+		t.Fatalf("WriteTasksFile() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(tempDir, "tasks", "task.md"))
+	if err != nil {
+		t.Fatalf("task.md not written to custom data dir: %v", err)
+	}
+	if !strings.Contains(string(content), "- [r] Second #PRJ0002\n") {
+		t.Errorf("task.md lacks the checkbox of the review status:\n%s", content)
+	}
+
+	result, err := storage.ReadTasksFile()
+	if err != nil {
+		t.Fatalf("ReadTasksFile() error = %v", err)
+	}
+	want := []parser.ParsedTask{
+		{Task: model.Task{ID: "PRJ0002", Title: "Second", Status: "review", Category: "Work"}, SubTasks: []model.SubTask{}},
+		{Task: model.Task{ID: "PRJ0001", Title: "Uncategorized", Status: "done", Category: "Inbox"}, SubTasks: []model.SubTask{}},
+	}
+	if diff := cmp.Diff(want, result); diff != "" {
+		t.Errorf("ReadTasksFile() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFileStorage_Timestamps(t *testing.T) {
+	tempDir := t.TempDir()
+	storage := NewFileStorage(tempDir)
+
+	times, err := storage.ReadTimestamps()
+	if err != nil {
+		t.Fatalf("ReadTimestamps() without a meta directory error = %v", err)
+	}
+	if len(times) != 0 {
+		t.Errorf("ReadTimestamps() = %v, want none", times)
+	}
+
+	done := model.Timestamps{CreatedAt: "2026-10-01T09:00:00Z", CompletedAt: "2026-10-05T18:00:00Z"}
+	if err := storage.WriteTimestamps("T001", done); err != nil {
+		t.Fatalf("WriteTimestamps() error = %v", err)
+	}
+	if err := storage.WriteTimestamps("T002", model.Timestamps{CreatedAt: "2026-10-02T09:00:00Z"}); err != nil {
+		t.Fatalf("WriteTimestamps() error = %v", err)
+	}
+	// Writing no timestamps removes the file
+	if err := storage.WriteTimestamps("T002", model.Timestamps{}); err != nil {
+		t.Fatalf("WriteTimestamps() error = %v", err)
+	}
+	if _, err := os.Stat(storage.TimestampsFilePath("T002")); !os.IsNotExist(err) {
+		t.Errorf("timestamps file of T002 still exists: %v", err)
+	}
+
+	times, err = storage.ReadTimestamps()
+	if err != nil {
+		t.Fatalf("ReadTimestamps() error = %v", err)
+	}
+	if diff := cmp.Diff(map[string]model.Timestamps{"T001": done}, times); diff != "" {
+		t.Errorf("ReadTimestamps() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := os.WriteFile(storage.TimestampsFilePath("T003"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := storage.ReadTimestamps(); errcode.CodeOf(err) != errcode.ParseError {
+		t.Errorf("ReadTimestamps() with a broken file error = %v, want %s", err, errcode.ParseError)
+	}
+}
+
+func TestFileStorage_WriteTasksFileWithCategories(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+	tasks := []parser.ParsedTask{
+		{Task: model.Task{ID: "T001", Title: "API", Status: "todo", Category: "Backend"}},
+		{Task: model.Task{ID: "T002", Title: "Guide", Status: "todo", Category: "Docs"}},
+	}
+	if err := storage.WriteTasksFileWithCategories([]string{"Empty", "Backend", "Later"}, tasks); err != nil {
+		t.Fatalf("WriteTasksFileWithCategories() error = %v", err)
+	}
+
+	content, err := os.ReadFile(storage.TaskFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Categories not in the list follow the listed ones
+	want := "# Task\n\n## Empty\n\n## Backend\n- [ ] API #T001\n\n## Later\n\n## Docs\n- [ ] Guide #T002\n\n"
+	if string(content) != want {
+		t.Errorf("task.md = %q, want %q", content, want)
+	}
+}
+
+func TestFileStorage_WriteTasksFile_KeepsCategories(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+	if err := os.MkdirAll(storage.DataDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(storage.TaskFilePath(), []byte("# Task\n\n## Empty\n\n## Backend\n- [ ] API #T001\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tasks := []parser.ParsedTask{{Task: model.Task{ID: "T002", Title: "Guide", Status: "todo", Category: "Docs"}}}
+	if err := storage.WriteTasksFile(tasks); err != nil {
+		t.Fatalf("WriteTasksFile() error = %v", err)
+	}
+	content, err := os.ReadFile(storage.TaskFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Task\n\n## Empty\n\n## Backend\n\n## Docs\n- [ ] Guide #T002\n\n"
+	if string(content) != want {
+		t.Errorf("task.md = %q, want %q", content, want)
+	}
+}
+
+func TestFileStorage_WriteTasksFile_KeepsStyle(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"default", "# Task\n\n## Ops\n- [x] Deploy #T001\n  - [ ] Build\n\n"},
+		{"asterisks and tabs", "# Task\n\n## Ops\n* [X] Deploy #T001\n\tStaging first\n\t* [ ] Build\n\t\t* [X] Pin\n\n"},
+		{"numbered", "# Task\n\n## Ops\n1. [ ] Deploy #T001\n    1. [ ] Build\n    2. [ ] Tag\n2. [ ] Monitor #T002\n\n"},
+		{"crlf and bom", "\uFEFF# Task\r\n\r\n## Ops\r\n+ [ ] Deploy #T001\r\n\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := NewFileStorage(t.TempDir())
+			if err := os.MkdirAll(storage.DataDir(), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(storage.TaskFilePath(), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			tasks, err := storage.ReadTasksFile()
+			if err != nil {
+				t.Fatalf("ReadTasksFile() error = %v", err)
+			}
+			if err := storage.WriteTasksFile(tasks); err != nil {
+				t.Fatalf("WriteTasksFile() error = %v", err)
+			}
+			got, err := os.ReadFile(storage.TaskFilePath())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tt.content, string(got)); diff != "" {
+				t.Errorf("task.md after a round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
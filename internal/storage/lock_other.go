@@ -0,0 +1,9 @@
+//go:build !unix
+
+package storage
+
+// Lock does nothing on this platform, where writes are serialized only
+// within one process
+func (fs *FileStorage) Lock() (unlock func(), err error) {
+	return func() {}, nil
+}
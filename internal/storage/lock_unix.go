@@ -0,0 +1,32 @@
+//go:build unix
+
+package storage
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/jnst/agentic-todo-mcp/internal/errcode"
+)
+
+// Lock takes an exclusive lock on the data directory, waiting for other
+// processes holding it, and returns the function releasing it. Writers hold
+// it around their read-modify-write cycles so that the server and the CLI
+// never interleave them. The directory is created if needed.
+func (fs *FileStorage) Lock() (unlock func(), err error) {
+	if err := os.MkdirAll(fs.DataDir(), fs.opts.DirPerm); err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileWriteError, "failed to create data directory")
+	}
+	dir, err := os.Open(fs.DataDir())
+	if err != nil {
+		return nil, errcode.WrapFS(err, errcode.FileReadError, "failed to open data directory")
+	}
+	if err := syscall.Flock(int(dir.Fd()), syscall.LOCK_EX); err != nil {
+		dir.Close()
+		return nil, errcode.New(errcode.FileWriteError, "failed to lock data directory: %v", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(dir.Fd()), syscall.LOCK_UN)
+		dir.Close()
+	}, nil
+}
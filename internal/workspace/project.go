@@ -0,0 +1,134 @@
+package workspace
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// skipDirs are directories never searched for projects
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Project is a project root known by a short name
+type Project struct {
+	Name string `json:"name"`
+	Root
+}
+
+// Discover returns dir and the directories below it, up to maxDepth levels
+// down, that hold dataDir. Hidden directories, node_modules and vendor are
+// not searched. The result is in lexical order with dir first.
+func Discover(dir, dataDir string, maxDepth int) ([]Root, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []Root
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directories cannot hold projects we can use
+			if path != dir && d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && (strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()]) {
+			return fs.SkipDir
+		}
+		if isDir(filepath.Join(path, dataDir)) {
+			roots = append(roots, Root{Path: path, Source: SourceDataDir})
+		}
+		if depth(dir, path) >= maxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	return roots, err
+}
+
+// depth returns how many levels path lies below dir
+func depth(dir, path string) int {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == "." {
+		return 0
+	}
+	return strings.Count(rel, string(filepath.Separator)) + 1
+}
+
+// NameProjects names each root after its directory. Roots sharing a
+// directory name get parent directories prepended until names are unique.
+func NameProjects(roots []Root) []Project {
+	projects := make([]Project, len(roots))
+	parts := make([][]string, len(roots))
+	levels := make([]int, len(roots))
+	for i, root := range roots {
+		projects[i].Root = root
+		parts[i] = strings.Split(filepath.ToSlash(filepath.Clean(root.Path)), "/")
+		levels[i] = 1
+	}
+
+	for {
+		byName := make(map[string][]int)
+		for i := range projects {
+			projects[i].Name = suffix(parts[i], levels[i])
+			byName[projects[i].Name] = append(byName[projects[i].Name], i)
+		}
+
+		grown := false
+		for _, indexes := range byName {
+			if len(indexes) < 2 {
+				continue
+			}
+			for _, i := range indexes {
+				if levels[i] < len(parts[i]) {
+					levels[i]++
+					grown = true
+				}
+			}
+		}
+		if !grown {
+			return projects
+		}
+	}
+}
+
+// suffix joins the last n path elements
+func suffix(parts []string, n int) string {
+	name := strings.Join(parts[len(parts)-n:], "/")
+	if name == "" {
+		return "/"
+	}
+	return name
+}
+
+// Resolve returns the roots for the given project paths, relative to dir
+// unless absolute. Paths that are not directories are reported as errors.
+func Resolve(dir string, paths []string) ([]Root, error) {
+	var roots []Root
+	for _, path := range paths {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrInvalid}
+		}
+		root := Root{Path: filepath.Clean(path), Source: SourceConfig}
+		if !slices.Contains(roots, root) {
+			roots = append(roots, root)
+		}
+	}
+	return roots, nil
+}
@@ -0,0 +1,72 @@
+package workspace
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiscover(t *testing.T) {
+	base := t.TempDir()
+	mkdirs(t, base,
+		".todo",
+		"services/api/.todo",
+		"services/web/.todo",
+		"services/web/node_modules/dep/.todo",
+		".hidden/.todo",
+		"a/b/c/d/.todo",
+	)
+
+	got, err := Discover(base, ".todo", 3)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	want := []Root{
+		{Path: base, Source: SourceDataDir},
+		{Path: filepath.Join(base, "services", "api"), Source: SourceDataDir},
+		{Path: filepath.Join(base, "services", "web"), Source: SourceDataDir},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Discover() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestNameProjects(t *testing.T) {
+	roots := []Root{
+		{Path: filepath.FromSlash("/repo")},
+		{Path: filepath.FromSlash("/repo/services/api")},
+		{Path: filepath.FromSlash("/repo/tools/api")},
+		{Path: filepath.FromSlash("/repo/web")},
+	}
+
+	var got []string
+	for _, p := range NameProjects(roots) {
+		got = append(got, p.Name)
+	}
+	want := []string{"repo", "services/api", "tools/api", "web"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("NameProjects() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	base := t.TempDir()
+	mkdirs(t, base, "api", "web")
+
+	got, err := Resolve(base, []string{"api", filepath.Join(base, "web"), "api"})
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	want := []Root{
+		{Path: filepath.Join(base, "api"), Source: SourceConfig},
+		{Path: filepath.Join(base, "web"), Source: SourceConfig},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Resolve() mismatch (-want +got):\n%s", diff)
+	}
+
+	if _, err := Resolve(base, []string{"missing"}); err == nil {
+		t.Error("Resolve() should fail for a missing directory")
+	}
+}
@@ -0,0 +1,103 @@
+// Package workspace locates the project roots that hold task data.
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Source tells how a project root was chosen
+type Source string
+
+const (
+	// SourceDataDir means the root holds an existing data directory
+	SourceDataDir Source = "data_dir"
+	// SourceGit means the root is the top of a git work tree
+	SourceGit Source = "git"
+	// SourceWorkingDir means no marker was found and the start directory is used
+	SourceWorkingDir Source = "working_dir"
+	// SourceClient means the root was given by the MCP client
+	SourceClient Source = "client"
+	// SourceConfig means the root was listed in the configuration
+	SourceConfig Source = "config"
+)
+
+// Root is a project root directory
+type Root struct {
+	Path   string `json:"path"`
+	Source Source `json:"source"`
+}
+
+// Find walks up from start to the nearest directory holding dataDir or a
+// .git entry and returns it. A directory holding both is reported as
+// SourceDataDir. If neither is found, start itself is returned.
+func Find(start, dataDir string) (Root, error) {
+	start, err := filepath.Abs(start)
+	if err != nil {
+		return Root{}, err
+	}
+
+	for dir := start; ; {
+		if isDir(filepath.Join(dir, dataDir)) {
+			return Root{Path: dir, Source: SourceDataDir}, nil
+		}
+		// .git is a file in worktrees and submodules
+		if exists(filepath.Join(dir, ".git")) {
+			return Root{Path: dir, Source: SourceGit}, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Root{Path: start, Source: SourceWorkingDir}, nil
+		}
+		dir = parent
+	}
+}
+
+// PathFromURI converts a file:// root URI to a local path
+func PathFromURI(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", fmt.Errorf("root %q is not a file URI", uri)
+	}
+	if u.Host != "" && u.Host != "localhost" {
+		return "", fmt.Errorf("root %q is on a remote host", uri)
+	}
+
+	path := u.Path
+	// file:///C:/dir has the path /C:/dir on Windows
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	path = filepath.FromSlash(path)
+	if !filepath.IsAbs(path) {
+		return "", fmt.Errorf("root %q is not absolute", uri)
+	}
+	return filepath.Clean(path), nil
+}
+
+// Contains reports whether path is dir or lies below it
+func Contains(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// isDir reports whether path is an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// exists reports whether path exists
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, os.ErrNotExist)
+}
@@ -0,0 +1,143 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// mkdirs creates the given directories under base
+func mkdirs(t *testing.T, base string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name  string
+		dirs  []string
+		files []string
+		start string
+		want  func(base string) Root
+	}{
+		{
+			name:  "data dir in a parent",
+			dirs:  []string{"project/.todo", "project/.git", "project/src/pkg"},
+			start: "project/src/pkg",
+			want:  func(base string) Root { return Root{Path: filepath.Join(base, "project"), Source: SourceDataDir} },
+		},
+		{
+			name:  "git root without data dir",
+			dirs:  []string{"project/.git", "project/src"},
+			start: "project/src",
+			want:  func(base string) Root { return Root{Path: filepath.Join(base, "project"), Source: SourceGit} },
+		},
+		{
+			name:  "git file of a worktree",
+			dirs:  []string{"worktree/src"},
+			files: []string{"worktree/.git"},
+			start: "worktree/src",
+			want:  func(base string) Root { return Root{Path: filepath.Join(base, "worktree"), Source: SourceGit} },
+		},
+		{
+			name:  "nearest marker wins",
+			dirs:  []string{".todo", "nested/.git", "nested/src"},
+			start: "nested/src",
+			want:  func(base string) Root { return Root{Path: filepath.Join(base, "nested"), Source: SourceGit} },
+		},
+		{
+			name:  "data dir file is not a data dir",
+			dirs:  []string{"project/.git"},
+			files: []string{"project/src/.todo"},
+			start: "project/src",
+			want:  func(base string) Root { return Root{Path: filepath.Join(base, "project"), Source: SourceGit} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := t.TempDir()
+			mkdirs(t, base, tt.dirs...)
+			for _, file := range tt.files {
+				mkdirs(t, base, filepath.Dir(file))
+				if err := os.WriteFile(filepath.Join(base, file), nil, 0644); err != nil {
+					t.Fatalf("Failed to create %s: %v", file, err)
+				}
+			}
+
+			got, err := Find(filepath.Join(base, tt.start), ".todo")
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want(base), got); diff != "" {
+				t.Errorf("Find() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFind_NoMarker(t *testing.T) {
+	// A temp dir may live under a directory with markers, so only check the
+	// fallback when nothing is found above it.
+	start := t.TempDir()
+	got, err := Find(start, ".todo-marker-that-does-not-exist")
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	if got.Source == SourceWorkingDir && got.Path != start {
+		t.Errorf("Find() = %+v, want %s", got, start)
+	}
+}
+
+func TestPathFromURI(t *testing.T) {
+	tests := []struct {
+		name    string
+		uri     string
+		want    string
+		wantErr bool
+	}{
+		{"file URI", "file:///home/user/project", filepath.FromSlash("/home/user/project"), false},
+		{"escaped", "file:///home/user/my%20project/", filepath.FromSlash("/home/user/my project"), false},
+		{"localhost", "file://localhost/srv/repo", filepath.FromSlash("/srv/repo"), false},
+		{"other scheme", "https://example.com/repo", "", true},
+		{"remote host", "file://server/share", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PathFromURI(tt.uri)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PathFromURI() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("PathFromURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	dir := filepath.FromSlash("/repo")
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/repo", true},
+		{"/repo/src", true},
+		{"/repository", false},
+		{"/", false},
+		{"/repo/../other", false},
+	}
+
+	for _, tt := range tests {
+		if got := Contains(dir, filepath.FromSlash(tt.path)); got != tt.want {
+			t.Errorf("Contains(%q, %q) = %v, want %v", dir, tt.path, got, tt.want)
+		}
+	}
+}